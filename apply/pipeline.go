@@ -34,6 +34,10 @@ type pipelineGen struct {
 
 type resultWrapper struct {
 	Plan *plan.Result
+
+	// notified is set for handlers that were notified, so they're applied
+	// even if their plan has no changes
+	notified bool
 }
 
 // Pipeline generates a pipeline to evaluate a single graph node
@@ -42,9 +46,11 @@ func Pipeline(g *graph.Graph, id string, factory *render.Factory) executor.Pipel
 	return executor.NewPipeline().
 		AndThen(gen.GetTask).
 		AndThen(gen.DependencyCheck).
+		AndThen(gen.maybeSkipHandler).
 		AndThen(gen.maybeSkipApplication).
 		AndThen(gen.applyNode).
-		AndThen(gen.maybeRunFinalCheck)
+		AndThen(gen.maybeRunFinalCheck).
+		AndThen(gen.maybeAllowFailure)
 }
 
 // GetResult returns Right resultWrapper if the value is a *plan.Result, or Left
@@ -66,7 +72,14 @@ func (g *pipelineGen) DependencyCheck(ctx context.Context, taskI interface{}) (i
 	if !ok {
 		return nil, errors.New("input node is not a task wrapper")
 	}
-	for _, depID := range graph.Targets(g.Graph.DownEdges(g.ID)) {
+	for _, edge := range g.Graph.DownEdges(g.ID) {
+		// vertices that are only ordered before this one (like the siblings
+		// of a handler) don't affect it when they fail
+		if _, isOrder := edge.(*graph.OrderEdge); isOrder {
+			continue
+		}
+
+		depID := edge.Target().(string)
 		meta, ok := g.Graph.Get(depID)
 		if !ok {
 			return nil, nil
@@ -85,10 +98,78 @@ func (g *pipelineGen) DependencyCheck(ctx context.Context, taskI interface{}) (i
 			}
 			return errResult, nil
 		}
+
+		// resources that fail with on_failure = "continue" skip their
+		// dependents, but not the modules that contain them
+		if _, isChild := edge.(*graph.ParentEdge); isChild {
+			continue
+		}
+		if allowed, ok := elem.(*Result); ok && allowed.AllowedErr != nil && allowed.OnFailure == resource.FailureContinue {
+			skipped := &Result{
+				Ran:        false,
+				Status:     &resource.Status{Level: resource.StatusWillChange},
+				AllowedErr: fmt.Errorf("skipped because dependency %q failed", depID),
+				OnFailure:  resource.FailureContinue,
+			}
+			return skipped, nil
+		}
 	}
 	return result, nil
 }
 
+// maybeSkipHandler returns a result for handlers that none of their notifiers
+// have notified, so that they stay idle. Notified handlers are returned as a
+// resultWrapper that will be applied.
+func (g *pipelineGen) maybeSkipHandler(ctx context.Context, resultI interface{}) (interface{}, error) {
+	asPlan, ok := resultI.(resultWrapper)
+	if !ok {
+		return resultI, nil
+	}
+
+	meta, ok := g.Graph.Get(g.ID)
+	if !ok {
+		return asPlan, nil
+	}
+
+	notifiers, isHandler := resource.NotifiersFor(meta)
+	if !isHandler {
+		return asPlan, nil
+	}
+
+	for _, id := range notifiers {
+		if g.notified(id) {
+			asPlan.notified = true
+			return asPlan, nil
+		}
+	}
+
+	status := resource.NewStatus()
+	status.AddMessage("handler was not notified")
+	return &Result{
+		Ran:    false,
+		Status: status,
+		Task:   asPlan.Plan.Task,
+		Plan:   asPlan.Plan,
+		Err:    asPlan.Plan.Err,
+	}, nil
+}
+
+// notified checks whether the node with the given ID was applied successfully,
+// which notifies its handlers
+func (g *pipelineGen) notified(id string) bool {
+	meta, ok := g.Graph.Get(id)
+	if !ok {
+		return false
+	}
+
+	result, ok := meta.Value().(*Result)
+	if !ok {
+		return false
+	}
+
+	return result.HasChanges() && result.Err == nil && result.AllowedErr == nil
+}
+
 // maybeSkipAppliation will return a result if it's given a *Result, if it's
 // given a taskWrapper it will return a result if there are no changes,
 // otherwise it returns the taskWrapper
@@ -100,7 +181,7 @@ func (g *pipelineGen) maybeSkipApplication(ctx context.Context, resultI interfac
 	if !ok {
 		return nil, fmt.Errorf("expected *Result or *resultWrapper but got type %T", resultI)
 	}
-	if !asPlan.Plan.Status.HasChanges() {
+	if !asPlan.Plan.Status.HasChanges() && !asPlan.notified {
 		return &Result{
 			Ran:    false,
 			Status: asPlan.Plan.Status,
@@ -125,7 +206,7 @@ func (g *pipelineGen) applyNode(ctx context.Context, val interface{}) (interface
 		return nil, fmt.Errorf("apply expected a resultWrappert but got %T", val)
 	}
 
-	status, err := twrapper.Plan.Task.Apply(ctx)
+	status, err := g.policy().Run(ctx, twrapper.Plan.Task.Apply)
 
 	if status == nil {
 		status = &resource.Status{}
@@ -183,6 +264,41 @@ func (g *pipelineGen) maybeRunFinalCheck(ctx context.Context, resultI interface{
 	return result, nil
 }
 
+// maybeAllowFailure moves the error of a failed result out of the way if the
+// node's failure mode allows it to fail, so that it doesn't fail the run
+func (g *pipelineGen) maybeAllowFailure(ctx context.Context, resultI interface{}) (interface{}, error) {
+	result, ok := resultI.(*Result)
+	if !ok {
+		return nil, fmt.Errorf("expected *Result but got %T", resultI)
+	}
+	if result.Err == nil {
+		return result, nil
+	}
+
+	meta, ok := g.Graph.Get(g.ID)
+	if !ok {
+		return result, nil
+	}
+
+	mode := resource.FailureModeFor(meta)
+	if mode == resource.FailureFail {
+		return result, nil
+	}
+
+	result.AllowedErr, result.Err = result.Err, nil
+	result.OnFailure = mode
+	return result, nil
+}
+
+// policy gets the retry and timeout policy for the node, if there is one
+func (g *pipelineGen) policy() *resource.Policy {
+	meta, ok := g.Graph.Get(g.ID)
+	if !ok {
+		return nil
+	}
+	return resource.PolicyFor(meta)
+}
+
 func (g *pipelineGen) Renderer(id string) (*render.Renderer, error) {
 	return g.RenderingPlant.GetRenderer(id)
 }
@@ -50,6 +50,203 @@ func TestExportedFields(t *testing.T) {
 	assert.Equal(t, "changed", statusMap["status"])
 }
 
+// TestApplyPolicy tests that apply is retried according to the node's policy
+func TestApplyPolicy(t *testing.T) {
+	g := graph.New()
+	g.Add(node.New(graph.ID("root"), nil))
+
+	meta := node.New(graph.ID("root", "a"), &plan.Result{
+		Task:   faketask.Error(),
+		Status: &resource.Status{Level: resource.StatusWillChange},
+	})
+	meta.AddMetadata(resource.MetaPolicy, &resource.Policy{Attempts: 3})
+	g.Add(meta)
+	g.ConnectParent(graph.ID("root"), graph.ID("root", "a"))
+
+	factory, _ := render.NewFactory(context.Background(), g)
+	result, err := apply.Pipeline(g, "root/a", factory).Exec(context.Background(), meta.Value())
+	require.NoError(t, err)
+
+	asResult, ok := result.(*apply.Result)
+	require.True(t, ok)
+	require.Error(t, asResult.Err)
+	assert.Contains(t, asResult.Err.Error(), "failed after 3 attempts: error")
+}
+
+// TestApplyOnFailure tests that failures are allowed according to the node's
+// failure mode, and that dependents are skipped or run accordingly
+func TestApplyOnFailure(t *testing.T) {
+	t.Run("fail", func(t *testing.T) {
+		g := failingGraph(resource.FailureFail)
+
+		result := execApply(t, g, "root/a")
+		assert.Error(t, result.Err)
+		assert.Nil(t, result.AllowedFailure())
+
+		dep := execApply(t, g, "root/b")
+		assert.EqualError(t, dep.Err, `error in dependency "root/a"`)
+	})
+
+	t.Run("continue", func(t *testing.T) {
+		g := failingGraph(resource.FailureContinue)
+
+		result := execApply(t, g, "root/a")
+		assert.NoError(t, result.Err)
+		assert.EqualError(t, result.AllowedFailure(), "error (on_failure = continue)")
+
+		dep := execApply(t, g, "root/b")
+		assert.NoError(t, dep.Err)
+		assert.False(t, dep.Ran)
+		assert.EqualError(t, dep.AllowedFailure(), `skipped because dependency "root/a" failed (on_failure = continue)`)
+
+		parent := execApply(t, g, "root")
+		assert.NoError(t, parent.Err)
+		assert.Nil(t, parent.AllowedFailure())
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		g := failingGraph(resource.FailureWarn)
+
+		result := execApply(t, g, "root/a")
+		assert.NoError(t, result.Err)
+		assert.EqualError(t, result.AllowedFailure(), "error (on_failure = warn)")
+
+		dep := execApply(t, g, "root/b")
+		assert.NoError(t, dep.Err)
+		assert.True(t, dep.Ran)
+		assert.Nil(t, dep.AllowedFailure())
+	})
+}
+
+// TestApplyHandler tests that handlers only run when one of the resources
+// notifying them has changed
+func TestApplyHandler(t *testing.T) {
+	t.Run("notified", func(t *testing.T) {
+		g := handlerGraph(true)
+
+		execApply(t, g, "root/notifier")
+		handler := execApply(t, g, "root/handler")
+		assert.NoError(t, handler.Err)
+		assert.True(t, handler.Ran)
+	})
+
+	t.Run("not notified", func(t *testing.T) {
+		g := handlerGraph(false)
+
+		execApply(t, g, "root/notifier")
+		handler := execApply(t, g, "root/handler")
+		assert.NoError(t, handler.Err)
+		assert.False(t, handler.Ran)
+		assert.Equal(t, []string{"handler was not notified"}, handler.Messages())
+	})
+}
+
+// TestApplyHandlerFailingSibling tests that a handler still runs when a
+// sibling it is only ordered after fails
+func TestApplyHandlerFailingSibling(t *testing.T) {
+	for _, mode := range []resource.FailureMode{resource.FailureFail, resource.FailureContinue} {
+		t.Run(string(mode), func(t *testing.T) {
+			g := handlerGraph(true)
+
+			failing := node.New(graph.ID("root", "failing"), &plan.Result{
+				Task:   faketask.Error(),
+				Status: &resource.Status{Level: resource.StatusWillChange},
+			})
+			failing.AddMetadata(resource.MetaOnFailure, mode)
+			g.Add(failing)
+			g.ConnectParent(graph.ID("root"), graph.ID("root", "failing"))
+			g.ConnectOrder(graph.ID("root", "handler"), graph.ID("root", "failing"))
+
+			execApply(t, g, "root/notifier")
+			execApply(t, g, "root/failing")
+
+			handler := execApply(t, g, "root/handler")
+			assert.NoError(t, handler.Err)
+			assert.Nil(t, handler.AllowedFailure())
+			assert.True(t, handler.Ran)
+		})
+	}
+}
+
+// handlerGraph returns a graph where root/handler is notified by
+// root/notifier, which will change if changed is set
+func handlerGraph(changed bool) *graph.Graph {
+	g := graph.New()
+	g.Add(node.New(graph.ID("root"), nil))
+
+	notifier := &plan.Result{
+		Task:   faketask.NoOp(),
+		Status: &resource.Status{Level: resource.StatusNoChange},
+	}
+	if changed {
+		notifier.Task = faketask.Swapper()
+		notifier.Status = &resource.Status{Level: resource.StatusWillChange}
+	}
+	g.Add(node.New(graph.ID("root", "notifier"), notifier))
+
+	// the handler has no changes of its own, but is still applied when it's
+	// notified
+	handler := node.New(graph.ID("root", "handler"), &plan.Result{
+		Task:   faketask.NoOp(),
+		Status: &resource.Status{Level: resource.StatusNoChange},
+	})
+	handler.AddMetadata(resource.MetaNotifiedBy, []string{graph.ID("root", "notifier")})
+	g.Add(handler)
+
+	g.ConnectParent(graph.ID("root"), graph.ID("root", "notifier"))
+	g.ConnectParent(graph.ID("root"), graph.ID("root", "handler"))
+	g.Connect(graph.ID("root", "handler"), graph.ID("root", "notifier"))
+
+	return g
+}
+
+// failingGraph returns a graph where root/a fails with the given failure mode
+// and root/b depends on it
+func failingGraph(mode resource.FailureMode) *graph.Graph {
+	g := graph.New()
+	g.Add(node.New(graph.ID("root"), &plan.Result{
+		Task:   faketask.NoOp(),
+		Status: &resource.Status{},
+	}))
+
+	failing := node.New(graph.ID("root", "a"), &plan.Result{
+		Task:   faketask.Error(),
+		Status: &resource.Status{Level: resource.StatusWillChange},
+	})
+	failing.AddMetadata(resource.MetaOnFailure, mode)
+	g.Add(failing)
+
+	g.Add(node.New(graph.ID("root", "b"), &plan.Result{
+		Task:   faketask.Swapper(),
+		Status: &resource.Status{Level: resource.StatusWillChange},
+	}))
+
+	g.ConnectParent(graph.ID("root"), graph.ID("root", "a"))
+	g.ConnectParent(graph.ID("root"), graph.ID("root", "b"))
+	g.Connect(graph.ID("root", "b"), graph.ID("root", "a"))
+
+	return g
+}
+
+// execApply runs the apply pipeline for a single node and stores the result
+// in the graph, like the apply walk does
+func execApply(t *testing.T, g *graph.Graph, id string) *apply.Result {
+	meta, ok := g.Get(id)
+	require.True(t, ok)
+
+	factory, err := render.NewFactory(context.Background(), g)
+	require.NoError(t, err)
+
+	out, err := apply.Pipeline(g, id, factory).Exec(context.Background(), meta.Value())
+	require.NoError(t, err)
+
+	result, ok := out.(*apply.Result)
+	require.True(t, ok)
+
+	g.Add(meta.WithValue(result))
+	return result
+}
+
 func sampleGraph() *graph.Graph {
 	planResult := &plan.Result{
 		Task:   faketask.WillChange(),
@@ -15,6 +15,8 @@
 package apply
 
 import (
+	"fmt"
+
 	"github.com/asteris-llc/converge/plan"
 	"github.com/asteris-llc/converge/resource"
 )
@@ -28,6 +30,11 @@ type Result struct {
 	Ran       bool
 	Plan      *plan.Result
 	PostCheck resource.TaskStatus
+
+	// AllowedErr is the error of a result that was allowed to fail by its
+	// failure mode. Err is nil when this is set.
+	AllowedErr error
+	OnFailure  resource.FailureMode
 }
 
 // Messages returns any result status messages supplied by the task
@@ -56,6 +63,15 @@ func (r *Result) HasChanges() bool { return r.Ran }
 // Error returns the error assigned to this Result, if any
 func (r *Result) Error() error { return r.Err }
 
+// AllowedFailure returns the error this result was allowed to fail with, if
+// any, along with the failure mode that allowed it
+func (r *Result) AllowedFailure() error {
+	if r.AllowedErr == nil {
+		return nil
+	}
+	return fmt.Errorf("%s (on_failure = %s)", r.AllowedErr, r.OnFailure)
+}
+
 // Warning returns the warning assigned to this Result, if any
 func (r *Result) Warning() string {
 	if r.Status != nil {
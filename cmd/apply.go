@@ -15,14 +15,17 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/graph/node"
 	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/plan/planfile"
 	"github.com/asteris-llc/converge/rpc/pb"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -36,6 +39,12 @@ var applyCmd = &cobra.Command{
 	Long: `application is where the actual work of making your execution graph
 real happens.`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetString("plan") != "" {
+			if len(args) > 1 {
+				return fmt.Errorf("--plan can only be used with a single module, got %d", len(args))
+			}
+			return nil
+		}
 		if len(args) == 0 {
 			return errors.New("Need at least one module filename as argument, got 0")
 		}
@@ -63,12 +72,31 @@ real happens.`,
 		}
 
 		rpcParams := getParamsRPC(cmd)
+		parallelism, kindParallelism := getLimitsRPC()
 
 		verifyModules := viper.GetBool("verify-modules")
 		if !verifyModules {
 			clog.Warn("skipping module verification")
 		}
 
+		// a saved plan names the module it was made for, and must match the
+		// module being applied
+		var savedPlan string
+		if planFile := viper.GetString("plan"); planFile != "" {
+			location, content, err := readPlan(planFile)
+			if err != nil {
+				clog.WithError(err).WithField("plan", planFile).Fatal("could not read plan")
+			}
+
+			if len(args) == 0 {
+				args = []string{location}
+			} else if args[0] != location {
+				clog.WithField("plan", planFile).Fatalf("plan was made for %q, not %q", location, args[0])
+			}
+
+			savedPlan = content
+		}
+
 		// execute files
 		for _, fname := range args {
 			flog := clog.WithField("file", fname)
@@ -78,9 +106,12 @@ real happens.`,
 			stream, err := client.Apply(
 				ctx,
 				&pb.LoadRequest{
-					Location:   fname,
-					Parameters: rpcParams,
-					Verify:     verifyModules,
+					Location:        fname,
+					Parameters:      rpcParams,
+					Verify:          verifyModules,
+					Parallelism:     parallelism,
+					KindParallelism: kindParallelism,
+					Plan:            savedPlan,
 				},
 			)
 			if err != nil {
@@ -164,14 +195,31 @@ real happens.`,
 	},
 }
 
+func readPlan(path string) (location, content string, err error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	saved, err := planfile.Read(bytes.NewReader(raw))
+	if err != nil {
+		return "", "", err
+	}
+
+	return saved.Location, string(raw), nil
+}
+
 func init() {
 	applyCmd.Flags().Bool("show-meta", false, "show metadata (params and modules)")
 	applyCmd.Flags().Bool("only-show-changes", false, "only show changes")
 	applyCmd.Flags().Bool("verify-modules", false, "verify module signatures")
+	applyCmd.Flags().String("plan", "", "apply only if the system still matches this saved plan")
 	registerRPCFlags(applyCmd.Flags())
 	registerLocalRPCFlags(applyCmd.Flags())
 	registerSSLFlags(applyCmd.Flags())
 	registerParamsFlags(applyCmd.Flags())
+	registerParallelismFlags(applyCmd.Flags())
+	registerRemoveFlags(applyCmd.Flags())
 
 	RootCmd.AddCommand(applyCmd)
 }
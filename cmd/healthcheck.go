@@ -63,6 +63,7 @@ not display healthy checks.`,
 		}
 
 		rpcParams := getParamsRPC(cmd)
+		parallelism, kindParallelism := getLimitsRPC()
 
 		verifyModules := viper.GetBool("verify-modules")
 		if !verifyModules {
@@ -78,9 +79,11 @@ not display healthy checks.`,
 			stream, err := client.HealthCheck(
 				ctx,
 				&pb.LoadRequest{
-					Location:   fname,
-					Parameters: rpcParams,
-					Verify:     verifyModules,
+					Location:        fname,
+					Parameters:      rpcParams,
+					Verify:          verifyModules,
+					Parallelism:     parallelism,
+					KindParallelism: kindParallelism,
 				},
 			)
 			if err != nil {
@@ -149,6 +152,7 @@ func init() {
 	registerLocalRPCFlags(healthcheckCmd.Flags())
 	registerSSLFlags(healthcheckCmd.Flags())
 	registerParamsFlags(healthcheckCmd.Flags())
+	registerParallelismFlags(healthcheckCmd.Flags())
 
 	RootCmd.AddCommand(healthcheckCmd)
 }
@@ -0,0 +1,90 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/asteris-llc/converge/graph"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	parallelismFlagName     = "parallelism"
+	kindParallelismFlagName = "kind-parallelism"
+)
+
+func registerParallelismFlags(flags *pflag.FlagSet) {
+	flags.Int(parallelismFlagName, 0, "maximum number of resources to check or apply at once (0 for no limit)")
+	flags.StringSlice(kindParallelismFlagName, []string{}, "maximum number of resources of a kind to check or apply at once, in kind=N format")
+}
+
+// parseLimits parses the overall limit and a list of "kind=N" limits
+func parseLimits(total int, kinds []string) (graph.Limits, error) {
+	limits := graph.Limits{
+		Total: total,
+		Kinds: map[string]int{},
+	}
+
+	if total < 0 {
+		return limits, fmt.Errorf("%s must not be negative, got %d", parallelismFlagName, total)
+	}
+
+	for _, raw := range kinds {
+		pair := strings.SplitN(raw, "=", 2)
+		if len(pair) < 2 || pair[0] == "" {
+			return limits, fmt.Errorf("malformed %s: %q, expected kind=N", kindParallelismFlagName, raw)
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(pair[1]))
+		if err != nil || limit < 1 {
+			return limits, fmt.Errorf("malformed %s: %q, limit must be a positive integer", kindParallelismFlagName, raw)
+		}
+
+		limits.Kinds[strings.TrimSpace(pair[0])] = limit
+	}
+
+	return limits, nil
+}
+
+// getLimits gets the parallelism limits from flags or config, logging and
+// exiting upon error
+func getLimits() graph.Limits {
+	limits, err := parseLimits(
+		viper.GetInt(parallelismFlagName),
+		viper.GetStringSlice(kindParallelismFlagName),
+	)
+	if err != nil {
+		log.WithError(err).Fatal("could not parse parallelism limits")
+	}
+
+	return limits
+}
+
+// getLimitsRPC gets the parallelism limits in the form used by LoadRequest
+func getLimitsRPC() (int32, map[string]int32) {
+	limits := getLimits()
+
+	kinds := map[string]int32{}
+	for kind, limit := range limits.Kinds {
+		kinds[kind] = int32(limit)
+	}
+
+	return int32(limits.Total), kinds
+}
@@ -0,0 +1,65 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterParallelismFlags(t *testing.T) {
+	flagSet := pflag.NewFlagSet("", pflag.PanicOnError)
+	registerParallelismFlags(flagSet)
+	assert.NotNil(t, flagSet.Lookup(parallelismFlagName))
+	assert.NotNil(t, flagSet.Lookup(kindParallelismFlagName))
+}
+
+func TestParseLimits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		limits, err := parseLimits(4, []string{"package.apt=1", " docker.image = 2"})
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, limits.Total)
+		assert.Equal(t, map[string]int{"package.apt": 1, "docker.image": 2}, limits.Kinds)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		limits, err := parseLimits(0, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, limits.Total)
+		assert.Empty(t, limits.Kinds)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, kinds := range [][]string{
+			{"package.apt"},
+			{"=1"},
+			{"package.apt=x"},
+			{"package.apt=0"},
+		} {
+			_, err := parseLimits(0, kinds)
+			assert.Error(t, err, "%v", kinds)
+		}
+
+		_, err := parseLimits(-1, nil)
+		assert.EqualError(t, err, "parallelism must not be negative, got -1")
+	})
+}
@@ -114,7 +114,18 @@ func getParamsRPC(cmd *cobra.Command) map[string]string {
 
 	clientParams := map[string]string{}
 	for k, v := range params {
-		clientParams[k] = fmt.Sprintf("%v", v)
+		if str, ok := v.(string); ok {
+			clientParams[k] = str
+			continue
+		}
+
+		// non-string values (from --paramsJSON) are sent as JSON so that typed
+		// params can coerce them back to lists and maps on the other side
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			log.WithError(err).WithField("param", k).Fatal("could not encode parameter")
+		}
+		clientParams[k] = string(encoded)
 	}
 
 	return clientParams
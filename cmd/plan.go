@@ -23,6 +23,7 @@ import (
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/graph/node"
 	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/plan/planfile"
 	"github.com/asteris-llc/converge/rpc/pb"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,6 +40,9 @@ can be done separately to see what needs to be changed before execution.`,
 		if len(args) == 0 {
 			return errors.New("Need at least one module filename as argument, got 0")
 		}
+		if viper.GetString("out") != "" && len(args) > 1 {
+			return fmt.Errorf("--out can only be used with a single module, got %d", len(args))
+		}
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -63,6 +67,7 @@ can be done separately to see what needs to be changed before execution.`,
 		}
 
 		rpcParams := getParamsRPC(cmd)
+		parallelism, kindParallelism := getLimitsRPC()
 
 		verifyModules := viper.GetBool("verify-modules")
 		if !verifyModules {
@@ -78,9 +83,11 @@ can be done separately to see what needs to be changed before execution.`,
 			stream, err := client.Plan(
 				ctx,
 				&pb.LoadRequest{
-					Location:   fname,
-					Parameters: rpcParams,
-					Verify:     verifyModules,
+					Location:        fname,
+					Parameters:      rpcParams,
+					Verify:          verifyModules,
+					Parallelism:     parallelism,
+					KindParallelism: kindParallelism,
 				},
 			)
 			if err != nil {
@@ -98,6 +105,15 @@ can be done separately to see what needs to be changed before execution.`,
 				g.Connect(edge.Source, edge.Dest)
 			}
 
+			modules, err := getModuleMeta(stream)
+			if err != nil {
+				flog.WithError(err).Fatal("error getting RPC metadata")
+			}
+			saved := planfile.New(fname, modules)
+			if saved.Salt, err = getSaltMeta(stream); err != nil {
+				flog.WithError(err).Fatal("error getting RPC metadata")
+			}
+
 			timer := new(TimerDisplay)
 			timer.Start()
 			oldOut := flog.Logger.Out
@@ -129,6 +145,7 @@ can be done separately to see what needs to be changed before execution.`,
 								planError = true
 							}
 							g.Add(node.New(resp.Id, printable))
+							saved.Add(resp)
 						}
 
 					default:
@@ -160,18 +177,42 @@ can be done separately to see what needs to be changed before execution.`,
 			if planError {
 				os.Exit(1)
 			}
+
+			if outFile := viper.GetString("out"); outFile != "" {
+				if err := writePlan(outFile, saved); err != nil {
+					flog.WithError(err).Fatal("could not save plan")
+				}
+				flog.WithField("out", outFile).Info("saved plan")
+			}
 		}
 	},
 }
 
+func writePlan(path string, saved *planfile.File) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := saved.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
 func init() {
 	planCmd.Flags().Bool("show-meta", false, "show metadata (params and modules)")
 	planCmd.Flags().Bool("only-show-changes", false, "only show changes")
 	planCmd.Flags().Bool("verify-modules", false, "verify module signatures")
+	planCmd.Flags().String("out", "", "save the plan to this file for use with apply --plan")
 	registerRPCFlags(planCmd.Flags())
 	registerLocalRPCFlags(planCmd.Flags())
 	registerSSLFlags(planCmd.Flags())
 	registerParamsFlags(planCmd.Flags())
+	registerParallelismFlags(planCmd.Flags())
+	registerRemoveFlags(planCmd.Flags())
 
 	RootCmd.AddCommand(planCmd)
 }
@@ -21,6 +21,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -67,8 +68,11 @@ see "converge graph --help" for more details.`,
 			return err
 		}
 
-		log.SetFormatter(&logging.Formatter{
-			DisableColors: nocolor,
+		// sensitive values are masked in all log output
+		log.SetFormatter(&redact.Formatter{
+			Formatter: &logging.Formatter{
+				DisableColors: nocolor,
+			},
 		})
 
 		// bind pflags for active commands
@@ -26,6 +26,7 @@ import (
 
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/plan/planfile"
 	"github.com/asteris-llc/converge/rpc"
 	"github.com/asteris-llc/converge/rpc/pb"
 	"github.com/pkg/errors"
@@ -88,6 +89,8 @@ func startRPC(ctx context.Context) error {
 		Security:             getSecurityConfig(),
 		ResourceRoot:         viper.GetString("root"),
 		EnableBinaryDownload: viper.GetBool("self-serve"),
+		Limits:               getLimits(),
+		RemoveAllowed:        viper.GetStringSlice(removeAllowedFlagName),
 	}
 
 	return server.Listen(ctx, loc)
@@ -151,6 +154,43 @@ func getMeta(stream headerer) ([]*graph.Edge, error) {
 	return edges, nil
 }
 
+func getModuleMeta(stream headerer) (map[string]*planfile.Module, error) {
+	meta, err := stream.Header()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting RPC header")
+	}
+
+	modules := map[string]*planfile.Module{}
+	for _, blob := range meta["modules"] {
+		var out map[string]*planfile.Module
+		err := json.Unmarshal([]byte(blob), &out)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not deserialize module metadata")
+		}
+
+		for id, module := range out {
+			modules[id] = module
+		}
+	}
+
+	return modules, nil
+}
+
+// getSaltMeta gets the salt of the fingerprints in a saved plan from the
+// header metadata
+func getSaltMeta(stream headerer) (string, error) {
+	meta, err := stream.Header()
+	if err != nil {
+		return "", errors.Wrap(err, "error getting RPC header")
+	}
+
+	salt := meta["salt"]
+	if len(salt) == 0 {
+		return "", errors.New("server did not send a salt for the plan")
+	}
+	return salt[0], nil
+}
+
 // More getters
 
 func setLocal(local bool)  { viper.Set(rpcEnableLocalName, local) }
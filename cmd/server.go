@@ -69,6 +69,8 @@ func init() {
 	// common
 	registerSSLFlags(serverCmd.Flags())
 	registerRPCFlags(serverCmd.Flags())
+	registerParallelismFlags(serverCmd.Flags())
+	registerRemoveFlags(serverCmd.Flags())
 
 	// API
 	serverCmd.Flags().String("root", ".", "location of modules to serve")
@@ -15,10 +15,17 @@
 package cmd
 
 import (
-	"errors"
+	"strings"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/load"
+	"github.com/asteris-llc/converge/parse"
+	"github.com/asteris-llc/converge/render"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/param"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/net/context"
@@ -44,20 +51,75 @@ var validateCmd = &cobra.Command{
 			log.WithField("component", "client").Warn("skipping module verification")
 		}
 
+		params := getParams(cmd)
+
 		for _, fname := range args {
 			flog := log.WithField("file", fname)
 
-			_, err := load.Load(ctx, fname, verifyModules)
+			loaded, err := load.Load(ctx, fname, verifyModules, params)
 			if err != nil {
 				flog.WithError(err).Fatal("could not parse file")
 			}
 
+			if err := validateParams(ctx, loaded, params); err != nil {
+				flog.WithError(err).Fatal("invalid params")
+			}
+
 			flog.Info("module valid")
 		}
 	},
 }
 
+// validateParams checks the values provided for the params of the top-level
+// module against their declared types and constraints. Params in nested
+// modules get their values from templates, so they can only be checked once
+// the graph is rendered.
+func validateParams(ctx context.Context, g *graph.Graph, values render.Values) error {
+	var errs error
+
+	for _, id := range g.Children("root") {
+		meta, ok := g.Get(id)
+		if !ok {
+			continue
+		}
+
+		prep, ok := meta.Value().(*resource.Preparer)
+		if !ok {
+			continue
+		}
+
+		if _, ok := prep.Destination.(*param.Preparer); !ok {
+			continue
+		}
+
+		r := &paramRenderer{id: id}
+		r.value, r.present = values[strings.TrimPrefix(graph.BaseID(id), "param.")]
+
+		if _, err := prep.Prepare(ctx, r); err != nil {
+			if pos, ok := meta.LookupMetadata(parse.MetaPosition); ok {
+				err = errors.Wrapf(err, "%v", pos)
+			}
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// paramRenderer provides values to params without doing any template
+// rendering, which is all that's needed to validate them
+type paramRenderer struct {
+	id      string
+	value   resource.Value
+	present bool
+}
+
+func (r *paramRenderer) GetID() string                               { return r.id }
+func (r *paramRenderer) Value() (resource.Value, bool)               { return r.value, r.present }
+func (r *paramRenderer) Render(name, content string) (string, error) { return content, nil }
+
 func init() {
 	validateCmd.Flags().Bool("verify-modules", false, "verify module signatures")
+	registerParamsFlags(validateCmd.Flags())
 	RootCmd.AddCommand(validateCmd)
 }
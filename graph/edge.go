@@ -26,6 +26,18 @@ func NewParentEdge(parent, child string) *ParentEdge {
 	return &ParentEdge{Edge: dag.BasicEdge(parent, child)}
 }
 
+// OrderEdge marks an edge that only orders two vertices, without the source
+// depending on the result of the target. Handlers use these to run after the
+// rest of their module.
+type OrderEdge struct {
+	dag.Edge
+}
+
+// NewOrderEdge constructs a new OrderEdge between the given vertices
+func NewOrderEdge(from, to string) *OrderEdge {
+	return &OrderEdge{Edge: dag.BasicEdge(from, to)}
+}
+
 // Sources gets the sources from slice of edges
 func Sources(edges []dag.Edge) (sources []string) {
 	for _, edge := range edges {
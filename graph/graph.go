@@ -161,6 +161,14 @@ func (g *Graph) Children(id string) (out []string) {
 	return
 }
 
+// ConnectOrder connects two vertices with an OrderEdge
+func (g *Graph) ConnectOrder(from, to string) {
+	g.innerLock.Lock()
+	defer g.innerLock.Unlock()
+
+	g.inner.Connect(NewOrderEdge(from, to))
+}
+
 // Connect two vertices together by ID
 func (g *Graph) Connect(from, to string) {
 	g.innerLock.Lock()
@@ -300,6 +308,34 @@ func (g *Graph) dependencies(id string, carry map[string]struct{}) map[string]st
 	return carry
 }
 
+// ResultDependencies gets the IDs of every vertex whose result id depends on,
+// directly or indirectly. Unlike Dependencies, vertices that are only ordered
+// before id with an OrderEdge are left out, since their result doesn't matter
+// to it.
+func (g *Graph) ResultDependencies(id string) []string {
+	var uniq []string
+	for key := range g.resultDependencies(id, make(map[string]struct{})) {
+		uniq = append(uniq, key)
+	}
+	return uniq
+}
+
+func (g *Graph) resultDependencies(id string, carry map[string]struct{}) map[string]struct{} {
+	for _, edge := range g.DownEdges(id) {
+		if _, isOrder := edge.(*OrderEdge); isOrder {
+			continue
+		}
+
+		elem := edge.Target().(string)
+		if _, seen := carry[elem]; seen {
+			continue
+		}
+		carry[elem] = struct{}{}
+		carry = g.resultDependencies(elem, carry)
+	}
+	return carry
+}
+
 // Walk the graph leaf-to-root
 func (g *Graph) Walk(ctx context.Context, cb WalkFunc) error {
 	return dependencyWalk(ctx, g, cb)
@@ -353,6 +389,10 @@ func dependencyWalk(rctx context.Context, g *Graph, cb WalkFunc) error {
 
 	wait := new(sync.WaitGroup)
 
+	// bound how many callbacks run at once. Workers waiting on their
+	// dependencies are cheap, so only execution is limited.
+	limits := newLimiter(LimitsFromContext(rctx))
+
 	// keep track of what we've scheduled so we don't schedule the same work
 	// twice
 	var worker func(id string)
@@ -459,8 +499,15 @@ func dependencyWalk(rctx context.Context, g *Graph, cb WalkFunc) error {
 			return
 		}
 
-		logger.WithField("id", id).Debug("executing")
 		val, _ := g.Get(id)
+
+		release, ok := limits.acquire(ctx, val)
+		if !ok {
+			return
+		}
+		defer release()
+
+		logger.WithField("id", id).Debug("executing")
 		if err := cb(val); err != nil {
 			setErr(id, err)
 		}
@@ -668,8 +715,11 @@ func (g *Graph) Edges() []Edge {
 			Dest:   srcEdge.Target().(string),
 		}
 
-		if _, ok := srcEdge.(*ParentEdge); ok {
+		switch srcEdge.(type) {
+		case *ParentEdge:
 			edge.Attributes = append(edge.Attributes, "parent")
+		case *OrderEdge:
+			edge.Attributes = append(edge.Attributes, "order")
 		}
 
 		edges[idx] = edge
@@ -276,6 +276,33 @@ func TestDescendents(t *testing.T) {
 	assert.Equal(t, []string{"one/two"}, g.Descendents("one"))
 }
 
+// TestResultDependencies tests that vertices which are only ordered before
+// another vertex are not counted as its dependencies
+func TestResultDependencies(t *testing.T) {
+	t.Parallel()
+
+	g := graph.New()
+	g.Add(node.New("root", nil))
+	g.Add(node.New("root/a", nil))
+	g.Add(node.New("root/b", nil))
+	g.Add(node.New("root/c", nil))
+	g.Add(node.New("root/handler", nil))
+
+	g.ConnectParent("root", "root/a")
+	g.ConnectParent("root", "root/b")
+	g.Connect("root/a", "root/b")
+	g.Connect("root/handler", "root/c")
+	g.ConnectOrder("root/handler", "root/a")
+
+	deps := g.ResultDependencies("root/handler")
+	sort.Strings(deps)
+	assert.Equal(t, []string{"root/c"}, deps)
+
+	deps = g.Dependencies("root/handler")
+	sort.Strings(deps)
+	assert.Equal(t, []string{"root/a", "root/b", "root/c"}, deps)
+}
+
 // TestChildren tests to ensure the correct behavior when getting children
 func TestChildren(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,151 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"github.com/asteris-llc/converge/graph/node"
+	"golang.org/x/net/context"
+)
+
+// MetaKind is the node metadata key for the kind of resource a node was
+// loaded from, for example "package.apt". It's used to apply per-kind limits
+// during walks.
+const MetaKind = "resource-kind"
+
+type limitsKey struct{}
+
+// Limits bound how many nodes are executed at once during a walk. Waiting for
+// dependencies doesn't count against the limits, only executing the callback
+// for a node does.
+type Limits struct {
+	// Total is the maximum number of nodes executed at once. Zero or less means
+	// there is no limit.
+	Total int
+
+	// Kinds limits how many nodes of a given resource kind are executed at
+	// once, for example only one "package.apt" at a time. Zero or less means
+	// there is no limit for that kind.
+	Kinds map[string]int
+}
+
+// Merge combines two sets of limits, keeping the stricter value for each
+func (l Limits) Merge(other Limits) Limits {
+	out := Limits{
+		Total: stricter(l.Total, other.Total),
+		Kinds: map[string]int{},
+	}
+
+	for kind, limit := range l.Kinds {
+		out.Kinds[kind] = limit
+	}
+	for kind, limit := range other.Kinds {
+		out.Kinds[kind] = stricter(out.Kinds[kind], limit)
+	}
+
+	return out
+}
+
+func stricter(a, b int) int {
+	switch {
+	case a <= 0:
+		return b
+	case b <= 0:
+		return a
+	case a < b:
+		return a
+	default:
+		return b
+	}
+}
+
+// WithLimits returns a context which will limit walks using it
+func WithLimits(ctx context.Context, limits Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, limits)
+}
+
+// LimitsFromContext gets the limits set with WithLimits. If none were set, the
+// returned limits are unbounded.
+func LimitsFromContext(ctx context.Context) Limits {
+	limits, _ := ctx.Value(limitsKey{}).(Limits)
+	return limits
+}
+
+// limiter hands out execution slots according to a set of Limits
+type limiter struct {
+	total chan struct{}
+	kinds map[string]chan struct{}
+}
+
+func newLimiter(limits Limits) *limiter {
+	l := &limiter{kinds: map[string]chan struct{}{}}
+
+	if limits.Total > 0 {
+		l.total = make(chan struct{}, limits.Total)
+	}
+
+	for kind, limit := range limits.Kinds {
+		if limit > 0 {
+			l.kinds[kind] = make(chan struct{}, limit)
+		}
+	}
+
+	return l
+}
+
+// acquire blocks until the node may be executed, returning a function to
+// release its slots. If the context is cancelled first, ok will be false and
+// no slots are held.
+func (l *limiter) acquire(ctx context.Context, meta *node.Node) (release func(), ok bool) {
+	var held []chan struct{}
+	release = func() {
+		for _, slot := range held {
+			<-slot
+		}
+	}
+
+	// the kind slot is taken first so nodes waiting on a busy kind don't hold
+	// up unrelated nodes
+	var slots []chan struct{}
+	if meta != nil {
+		kind, _ := meta.LookupMetadata(MetaKind)
+		if name, isString := kind.(string); isString {
+			if slot, limited := l.kinds[name]; limited {
+				slots = append(slots, slot)
+			}
+		}
+	}
+	if l.total != nil {
+		slots = append(slots, l.total)
+	}
+
+	for _, slot := range slots {
+		// select picks randomly when both cases are ready, so check for
+		// cancellation first
+		if ctx.Err() != nil {
+			release()
+			return func() {}, false
+		}
+
+		select {
+		case <-ctx.Done():
+			release()
+			return func() {}, false
+		case slot <- struct{}{}:
+			held = append(held, slot)
+		}
+	}
+
+	return release, true
+}
@@ -0,0 +1,152 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/graph/node"
+	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestLimitsMerge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stricter wins", func(t *testing.T) {
+		merged := graph.Limits{
+			Total: 4,
+			Kinds: map[string]int{"package.apt": 2, "task": 3},
+		}.Merge(graph.Limits{
+			Total: 8,
+			Kinds: map[string]int{"package.apt": 1, "docker.image": 2},
+		})
+
+		assert.Equal(t, 4, merged.Total)
+		assert.Equal(t, map[string]int{"package.apt": 1, "task": 3, "docker.image": 2}, merged.Kinds)
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		merged := graph.Limits{}.Merge(graph.Limits{Total: 2})
+		assert.Equal(t, 2, merged.Total)
+
+		merged = graph.Limits{Total: 2}.Merge(graph.Limits{})
+		assert.Equal(t, 2, merged.Total)
+	})
+}
+
+func TestLimitsFromContext(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, graph.Limits{}, graph.LimitsFromContext(context.Background()))
+
+	limits := graph.Limits{Total: 3}
+	assert.Equal(t, limits, graph.LimitsFromContext(graph.WithLimits(context.Background(), limits)))
+}
+
+// maxConcurrent walks a graph of independent children and returns the highest
+// number of callbacks running at once, overall and for nodes of kind "slow"
+func maxConcurrent(t *testing.T, limits graph.Limits) (total, slow int) {
+	g := graph.New()
+	g.Add(node.New("root", nil))
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("root/fast.%d", i)
+		g.Add(node.New(id, nil))
+		g.ConnectParent("root", id)
+
+		slowNode := node.New(fmt.Sprintf("root/slow.%d", i), nil)
+		slowNode.AddMetadata(graph.MetaKind, "slow")
+		g.Add(slowNode)
+		g.ConnectParent("root", slowNode.ID)
+	}
+
+	var (
+		lock                 sync.Mutex
+		running, runningSlow int
+	)
+
+	ctx := graph.WithLimits(context.Background(), limits)
+	err := g.Walk(ctx, func(meta *node.Node) error {
+		_, isSlow := meta.LookupMetadata(graph.MetaKind)
+
+		lock.Lock()
+		running++
+		if running > total {
+			total = running
+		}
+		if isSlow {
+			runningSlow++
+			if runningSlow > slow {
+				slow = runningSlow
+			}
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		if isSlow {
+			runningSlow--
+		}
+		lock.Unlock()
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	return total, slow
+}
+
+func TestWalkLimits(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	t.Run("total", func(t *testing.T) {
+		total, _ := maxConcurrent(t, graph.Limits{Total: 3})
+		assert.True(t, total <= 3, "%d nodes ran at once", total)
+		assert.True(t, total > 0)
+	})
+
+	t.Run("kind", func(t *testing.T) {
+		_, slow := maxConcurrent(t, graph.Limits{Kinds: map[string]int{"slow": 1}})
+		assert.Equal(t, 1, slow)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		total, _ := maxConcurrent(t, graph.Limits{})
+		assert.True(t, total > 3, "only %d nodes ran at once", total)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		g := graph.New()
+		g.Add(node.New("root", nil))
+
+		ctx, cancel := context.WithCancel(graph.WithLimits(context.Background(), graph.Limits{Total: 1}))
+		cancel()
+
+		var ran bool
+		assert.NoError(t, g.Walk(ctx, func(*node.Node) error {
+			ran = true
+			return nil
+		}))
+		assert.False(t, ran)
+	})
+}
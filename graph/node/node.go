@@ -65,6 +65,18 @@ func (n *Node) WithValue(value interface{}) *Node {
 	return copied
 }
 
+// Copy returns a copy of the node with a new ID and value. Unlike WithValue, the
+// metadata of the copy is independent of the original, so metadata can be
+// added to either without affecting the other.
+func (n *Node) Copy(id string, value interface{}) *Node {
+	copied := New(id, value)
+	for key, val := range n.metadata {
+		copied.metadata[key] = val
+	}
+
+	return copied
+}
+
 func (n *Node) setGroup() {
 	if groupable, ok := n.value.(Groupable); ok {
 		n.Group = groupable.Group()
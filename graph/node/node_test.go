@@ -61,6 +61,26 @@ func TestWithValue(t *testing.T) {
 	})
 }
 
+// TestCopy tests that copies have independent metadata
+func TestCopy(t *testing.T) {
+	t.Parallel()
+
+	fst := node.New("test", 1)
+	fst.AddMetadata("shared", "a")
+
+	snd := fst.Copy("copy", 2)
+	assert.Equal(t, "copy", snd.ID)
+	assert.Equal(t, 2, snd.Value())
+
+	shared, ok := snd.LookupMetadata("shared")
+	assert.True(t, ok)
+	assert.Equal(t, "a", shared)
+
+	snd.AddMetadata("own", "b")
+	_, ok = fst.LookupMetadata("own")
+	assert.False(t, ok)
+}
+
 // TestWithGroupable tests that group is set when the value is Groupable
 func TestWithGroupable(t *testing.T) {
 	t.Parallel()
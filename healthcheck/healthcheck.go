@@ -50,7 +50,7 @@ func WithNotify(ctx context.Context, in *graph.Graph, notify *graph.Notifier) (*
 				return nil
 			}
 
-			for _, dep := range out.Dependencies(meta.ID) {
+			for _, dep := range out.ResultDependencies(meta.ID) {
 				depmeta, ok := out.Get(dep)
 				if !ok {
 					continue
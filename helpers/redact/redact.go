@@ -0,0 +1,279 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redact masks sensitive values (like passwords and tokens) in output.
+// Values are registered as they are discovered during rendering. Each run or
+// request gets its own Redactor (see Scope and WithRedactor), which masks the
+// output produced for that run. The default Redactor masks the values of every
+// run in progress, and is used for output that isn't tied to a single run,
+// like logs.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// Mask is shown in place of sensitive values
+const Mask = "<sensitive>"
+
+// minScalarLength is the shortest number that will be registered as
+// sensitive. Masking shorter numbers (or booleans) would hide every occurrence
+// of values like "1" or "true" in the output. Those values are only masked
+// where they're known to be sensitive, like the value of a sensitive param.
+const minScalarLength = 8
+
+// Redactor masks registered sensitive values in strings. It is safe for
+// concurrent use.
+type Redactor struct {
+	lock     sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+
+	parent *Redactor
+	scopes map[*Redactor]struct{}
+}
+
+// New returns a Redactor with no sensitive values
+func New() *Redactor {
+	return &Redactor{
+		values: map[string]struct{}{},
+		scopes: map[*Redactor]struct{}{},
+	}
+}
+
+var defaultRedactor = New()
+
+// Default returns the process-wide Redactor. It masks the values of every
+// scope created from it until the scope is released.
+func Default() *Redactor {
+	return defaultRedactor
+}
+
+// Scope returns a new Redactor for a single run or request. Values added to
+// the scope are masked by the scope itself and, until Release is called, by r
+// as well.
+func (r *Redactor) Scope() *Redactor {
+	scope := New()
+	scope.parent = r
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.scopes[scope] = struct{}{}
+
+	return scope
+}
+
+// Release detaches a scope from the Redactor it was created from, so its
+// values are no longer masked there. It should be called when the run or
+// request is finished.
+func (r *Redactor) Release() {
+	if r.parent == nil {
+		return
+	}
+
+	r.parent.lock.Lock()
+	defer r.parent.lock.Unlock()
+	delete(r.parent.scopes, r)
+}
+
+type redactorKey struct{}
+
+// WithRedactor returns a context carrying the given Redactor
+func WithRedactor(ctx context.Context, r *Redactor) context.Context {
+	return context.WithValue(ctx, redactorKey{}, r)
+}
+
+// Scoped returns a context carrying a new scope of the Redactor in ctx (see
+// FromContext), and a function that releases the scope when the run or request
+// is finished
+func Scoped(ctx context.Context) (context.Context, func()) {
+	scope := FromContext(ctx).Scope()
+	return WithRedactor(ctx, scope), scope.Release
+}
+
+// FromContext returns the Redactor set with WithRedactor, or the default
+// Redactor if none was set
+func FromContext(ctx context.Context) *Redactor {
+	if r, ok := ctx.Value(redactorKey{}).(*Redactor); ok && r != nil {
+		return r
+	}
+	return Default()
+}
+
+// Add registers sensitive values. The JSON-encoded form of each value is
+// registered as well, so values are also masked inside serialized output.
+// Empty values are ignored.
+func (r *Redactor) Add(values ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		r.values[value] = struct{}{}
+
+		if encoded, err := json.Marshal(value); err == nil {
+			if inner := string(encoded[1 : len(encoded)-1]); inner != value {
+				r.values[inner] = struct{}{}
+			}
+		}
+	}
+
+	r.replacer = nil
+}
+
+// SensitiveFielder is implemented by tasks that know which of their exported
+// fields are sensitive. Those fields are masked by name, whatever their value.
+type SensitiveFielder interface {
+	SensitiveFields() []string
+}
+
+// AddValue registers a value of any type as sensitive. Lists and maps have
+// each of their elements registered. Booleans and short numbers are ignored.
+func (r *Redactor) AddValue(value interface{}) {
+	if value == nil {
+		return
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			r.AddValue(rv.Index(i).Interface())
+		}
+
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			r.AddValue(rv.MapIndex(key).Interface())
+		}
+
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			r.AddValue(rv.Elem().Interface())
+		}
+
+	case reflect.String:
+		r.Add(rv.String())
+
+	case reflect.Bool:
+		return
+
+	default:
+		if formatted := fmt.Sprint(value); len(formatted) >= minScalarLength {
+			r.Add(formatted)
+		}
+	}
+}
+
+// String masks all sensitive values in a string
+func (r *Redactor) String(in string) string {
+	if replacer := r.getReplacer(); replacer != nil {
+		in = replacer.Replace(in)
+	}
+
+	r.lock.RLock()
+	scopes := make([]*Redactor, 0, len(r.scopes))
+	for scope := range r.scopes {
+		scopes = append(scopes, scope)
+	}
+	r.lock.RUnlock()
+
+	for _, scope := range scopes {
+		in = scope.String(in)
+	}
+
+	return in
+}
+
+// Strings masks all sensitive values in a slice of strings. A new slice is
+// returned.
+func (r *Redactor) Strings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = r.String(s)
+	}
+
+	return out
+}
+
+func (r *Redactor) getReplacer() *strings.Replacer {
+	r.lock.RLock()
+	replacer, count := r.replacer, len(r.values)
+	r.lock.RUnlock()
+
+	if replacer != nil || count == 0 {
+		return replacer
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// the replacer prefers earlier arguments when values overlap, so longer
+	// values go first to make sure they're masked completely
+	values := make([]string, 0, len(r.values))
+	for value := range r.values {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+
+	pairs := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		pairs = append(pairs, value, Mask)
+	}
+
+	r.replacer = strings.NewReplacer(pairs...)
+	return r.replacer
+}
+
+// Formatter wraps a logrus formatter, masking sensitive values in formatted
+// log entries
+type Formatter struct {
+	logrus.Formatter
+
+	Redactor *Redactor
+}
+
+// Format an entry with the wrapped formatter and mask sensitive values
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	out, err := f.Formatter.Format(entry)
+	if err != nil {
+		return out, err
+	}
+
+	redactor := f.Redactor
+	if redactor == nil {
+		redactor = Default()
+	}
+
+	return []byte(redactor.String(string(out))), nil
+}
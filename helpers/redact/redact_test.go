@@ -0,0 +1,152 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redact_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/asteris-llc/converge/helpers/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestRedactorString tests masking values in strings
+func TestRedactorString(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		r := redact.New()
+		r.Add("")
+
+		assert.Equal(t, "nothing to hide", r.String("nothing to hide"))
+	})
+
+	t.Run("values", func(t *testing.T) {
+		r := redact.New()
+		r.Add("hunter2", "s3cret")
+
+		assert.Equal(
+			t,
+			"password is <sensitive>, token is <sensitive>",
+			r.String("password is hunter2, token is s3cret"),
+		)
+	})
+
+	t.Run("overlapping", func(t *testing.T) {
+		r := redact.New()
+		r.Add("abc", "abcdef")
+
+		assert.Equal(t, "<sensitive>!", r.String("abcdef!"))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		r := redact.New()
+		r.Add("line one\nline \"two\"")
+
+		assert.Equal(t, `{"value":"<sensitive>"}`, r.String(`{"value":"line one\nline \"two\""}`))
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		r := redact.New()
+		r.Add("hunter2")
+
+		in := []string{"a", "hunter2"}
+		assert.Equal(t, []string{"a", "<sensitive>"}, r.Strings(in))
+		assert.Equal(t, "hunter2", in[1], "input should not be modified")
+	})
+}
+
+// TestRedactorAddValue tests registering values of other types
+func TestRedactorAddValue(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New()
+	r.AddValue([]interface{}{"first", 1234567890})
+	r.AddValue(map[string]interface{}{"key": "mapvalue"})
+
+	assert.Equal(
+		t,
+		"<sensitive> <sensitive> <sensitive> key",
+		r.String("first 1234567890 mapvalue key"),
+	)
+
+	t.Run("short scalars", func(t *testing.T) {
+		r := redact.New()
+		r.AddValue(true)
+		r.AddValue(8080)
+		r.AddValue(1.5)
+
+		assert.Equal(t, "listen on 8080: true 1.5", r.String("listen on 8080: true 1.5"))
+	})
+}
+
+// TestRedactorScope tests masking values for a single run
+func TestRedactorScope(t *testing.T) {
+	t.Parallel()
+
+	parent := redact.New()
+	first, second := parent.Scope(), parent.Scope()
+	first.Add("first-secret")
+	second.Add("second-secret")
+
+	assert.Equal(t, "<sensitive> second-secret", first.String("first-secret second-secret"))
+	assert.Equal(t, "first-secret <sensitive>", second.String("first-secret second-secret"))
+	assert.Equal(t, "<sensitive> <sensitive>", parent.String("first-secret second-secret"))
+
+	first.Release()
+	assert.Equal(t, "first-secret <sensitive>", parent.String("first-secret second-secret"))
+
+	second.Release()
+	assert.Equal(t, "first-secret second-secret", parent.String("first-secret second-secret"))
+}
+
+// TestFromContext tests getting a Redactor from a context
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, redact.Default(), redact.FromContext(context.Background()))
+
+	r := redact.New()
+	assert.Equal(t, r, redact.FromContext(redact.WithRedactor(context.Background(), r)))
+
+	ctx, release := redact.Scoped(context.Background())
+	defer release()
+	assert.NotEqual(t, redact.Default(), redact.FromContext(ctx))
+}
+
+// TestFormatter tests masking values in logs
+func TestFormatter(t *testing.T) {
+	t.Parallel()
+
+	r := redact.New()
+	r.Add("hunter2")
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = &redact.Formatter{
+		Formatter: &logrus.TextFormatter{DisableColors: true},
+		Redactor:  r,
+	}
+
+	logger.WithField("password", "hunter2").Info("logging in with hunter2")
+
+	require.NotEmpty(t, buf.String())
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Contains(t, buf.String(), "<sensitive>")
+}
@@ -39,5 +39,5 @@ func LoadAndParseFromString(name, src string) (*graph.Graph, error) {
 	defer os.RemoveAll(tmpdir)
 	fileName := filepath.Join(tmpdir, name)
 	ioutil.WriteFile(fileName, []byte(src), 0777)
-	return load.Load(context.Background(), fileName, false)
+	return load.Load(context.Background(), fileName, false, nil)
 }
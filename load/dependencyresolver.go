@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"sort"
+	"strings"
 	"sync"
 	"text/template"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/asteris-llc/converge/parse"
 	"github.com/asteris-llc/converge/render/extensions"
 	"github.com/asteris-llc/converge/render/preprocessor"
+	"github.com/asteris-llc/converge/resource"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
@@ -42,6 +44,8 @@ func ResolveDependencies(ctx context.Context, g *graph.Graph) (*graph.Graph, err
 
 	groupLock := new(sync.Mutex)
 	groupMap := make(map[string]struct{})
+	handlerLock := new(sync.Mutex)
+	handlerMap := make(map[string]map[string]struct{})
 	g, err := g.Transform(ctx, func(meta *node.Node, out *graph.Graph) error {
 		if graph.IsRoot(meta.ID) { // skip root
 			return nil
@@ -69,6 +73,25 @@ func ResolveDependencies(ctx context.Context, g *graph.Graph) (*graph.Graph, err
 			}
 		}
 
+		// handlers depend on the resources that notify them
+		notifications, err := getNotifications(g, meta.ID, node)
+		if err != nil {
+			return err
+		}
+		for _, n := range notifications {
+			if err := out.SafeConnect(n.handler, n.notifier); err != nil {
+				logger.Error(err)
+				return err
+			}
+
+			handlerLock.Lock()
+			if _, ok := handlerMap[n.handler]; !ok {
+				handlerMap[n.handler] = make(map[string]struct{})
+			}
+			handlerMap[n.handler][n.notifier] = struct{}{}
+			handlerLock.Unlock()
+		}
+
 		// collect group information
 		if meta.Group != "" {
 			groupLock.Lock()
@@ -84,11 +107,172 @@ func ResolveDependencies(ctx context.Context, g *graph.Graph) (*graph.Graph, err
 			return depG, grpErr
 		}
 	}
+
+	if err == nil {
+		err = connectHandlers(g, handlerMap)
+	}
+	if err == nil {
+		err = connectRepositories(g)
+	}
 	return g, err
 }
 
 func getDepends(g *graph.Graph, id string, node *parse.Node) ([]string, error) {
-	deps, err := node.GetStringSlice("depends")
+	return getNearestAncestors(g, id, node, "depends")
+}
+
+// notification is a single notifies or subscribes relationship between a
+// resource and a handler
+type notification struct {
+	handler  string
+	notifier string
+}
+
+func getNotifications(g *graph.Graph, id string, node *parse.Node) ([]notification, error) {
+	var out []notification
+
+	handlers, err := getNearestAncestors(g, id, node, "notifies")
+	if err != nil {
+		return nil, err
+	}
+	for _, handler := range handlers {
+		out = append(out, notification{handler: handler, notifier: id})
+	}
+
+	notifiers, err := getNearestAncestors(g, id, node, "subscribes")
+	if err != nil {
+		return nil, err
+	}
+	for _, notifier := range notifiers {
+		out = append(out, notification{handler: id, notifier: notifier})
+	}
+
+	return out, nil
+}
+
+// connectHandlers marks each handler with the resources that notify it, and
+// orders it after every sibling that doesn't depend on it so that it runs once
+// at the end of its module
+func connectHandlers(g *graph.Graph, handlers map[string]map[string]struct{}) error {
+	for handler, notifiers := range handlers {
+		meta, ok := g.Get(handler)
+		if !ok {
+			return fmt.Errorf("nonexistent vertices in edges: %s", handler)
+		}
+
+		var ids []string
+		for id := range notifiers {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		if err := meta.AddMetadata(resource.MetaNotifiedBy, ids); err != nil {
+			return errors.Wrap(err, handler)
+		}
+
+		parent, ok := g.GetParentID(handler)
+		if !ok {
+			continue
+		}
+
+		for _, sibling := range g.Children(parent) {
+			if sibling == handler {
+				continue
+			}
+			if _, isHandler := handlers[sibling]; isHandler {
+				continue
+			}
+			if dependsOn(g, sibling, handler, map[string]struct{}{}) {
+				continue
+			}
+
+			g.ConnectOrder(handler, sibling)
+		}
+	}
+
+	return g.Validate()
+}
+
+// connectRepositories makes packages depend on the package repositories in
+// the same module that they may be installed from, so they aren't installed
+// when a repository fails. A repository of kind "package.<manager>.repository"
+// comes before its siblings of kind "package.<manager>" and "package".
+// Packages the repository itself depends on are left alone.
+func connectRepositories(g *graph.Graph) error {
+	for _, repo := range g.Vertices() {
+		manager, ok := repositoryManager(g, repo)
+		if !ok {
+			continue
+		}
+
+		parent, ok := g.GetParentID(repo)
+		if !ok {
+			continue
+		}
+
+		for _, sibling := range g.Children(parent) {
+			meta, ok := g.Get(sibling)
+			if !ok {
+				continue
+			}
+			node, ok := meta.Value().(*parse.Node)
+			if !ok {
+				continue
+			}
+			if kind := node.Kind(); kind != "package" && kind != "package."+manager {
+				continue
+			}
+			if dependsOn(g, repo, sibling, map[string]struct{}{}) {
+				continue
+			}
+
+			g.Connect(sibling, repo)
+		}
+	}
+
+	return g.Validate()
+}
+
+// repositoryManager returns the package manager of a package repository node
+func repositoryManager(g *graph.Graph, id string) (string, bool) {
+	meta, ok := g.Get(id)
+	if !ok {
+		return "", false
+	}
+	node, ok := meta.Value().(*parse.Node)
+	if !ok {
+		return "", false
+	}
+
+	parts := strings.Split(node.Kind(), ".")
+	if len(parts) != 3 || parts[0] != "package" || parts[2] != "repository" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// dependsOn checks whether src depends on dst, directly or indirectly
+func dependsOn(g *graph.Graph, src, dst string, seen map[string]struct{}) bool {
+	for _, dep := range graph.Targets(g.DownEdges(src)) {
+		if dep == dst {
+			return true
+		}
+		if _, ok := seen[dep]; ok {
+			continue
+		}
+		seen[dep] = struct{}{}
+
+		if dependsOn(g, dep, dst, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// getNearestAncestors resolves a list of IDs given in the named field of a
+// node, like "depends"
+func getNearestAncestors(g *graph.Graph, id string, node *parse.Node, field string) ([]string, error) {
+	deps, err := node.GetStringSlice(field)
 	switch err {
 	case parse.ErrNotFound:
 		return []string{}, nil
@@ -106,9 +290,10 @@ func getDepends(g *graph.Graph, id string, node *parse.Node) ([]string, error) {
 	}
 }
 
-func getParams(g *graph.Graph, id string, node *parse.Node) (out []string, err error) {
-	var nodeStrings []string
-	nodeStrings, err = node.GetStrings()
+// getNodeStrings returns all the strings in a node that may contain template
+// calls, including those stored in node metadata
+func getNodeStrings(g *graph.Graph, id string, node *parse.Node) ([]string, error) {
+	nodeStrings, err := node.GetStrings()
 	if err != nil {
 		return nil, err
 	}
@@ -118,12 +303,24 @@ func getParams(g *graph.Graph, id string, node *parse.Node) (out []string, err e
 		return nil, errors.New("error: node is not in the provided graph")
 	}
 
-	if metaIface, ok := meta.LookupMetadata("conditional-predicate-raw"); ok {
-		if str, ok := metaIface.(string); ok {
-			nodeStrings = append(nodeStrings, str)
+	for _, key := range []string{"conditional-predicate-raw", parse.MetaTemplate} {
+		if metaIface, ok := meta.LookupMetadata(key); ok {
+			if str, ok := metaIface.(string); ok {
+				nodeStrings = append(nodeStrings, str)
+			}
 		}
 	}
 
+	return nodeStrings, nil
+}
+
+func getParams(g *graph.Graph, id string, node *parse.Node) (out []string, err error) {
+	var nodeStrings []string
+	nodeStrings, err = getNodeStrings(g, id, node)
+	if err != nil {
+		return nil, err
+	}
+
 	type stub struct{}
 	language := extensions.MinimalLanguage()
 	language.On("param", extensions.RememberCalls(&out, ""))
@@ -152,22 +349,11 @@ func getXrefs(g *graph.Graph, id string, node *parse.Node) (out []string, err er
 	var nodeStrings []string
 	var calls []string
 	nodeRefs := make(map[string]struct{})
-	nodeStrings, err = node.GetStrings()
+	nodeStrings, err = getNodeStrings(g, id, node)
 	if err != nil {
 		return nil, err
 	}
 
-	meta, found := g.Get(id)
-	if !found {
-		return nil, errors.New("error: node is not in the provided graph")
-	}
-
-	if metaIface, ok := meta.LookupMetadata("conditional-predicate-raw"); ok {
-		if str, ok := metaIface.(string); ok {
-			nodeStrings = append(nodeStrings, str)
-		}
-	}
-
 	language := extensions.MinimalLanguage()
 	language.On(extensions.RefFuncName, extensions.RememberCalls(&calls, 0))
 	for _, s := range nodeStrings {
@@ -24,6 +24,7 @@ import (
 	"github.com/asteris-llc/converge/helpers/testing/hclutils"
 	"github.com/asteris-llc/converge/load"
 	"github.com/asteris-llc/converge/parse"
+	"github.com/asteris-llc/converge/resource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -229,3 +230,129 @@ func TestDependencyResolverResolvesGroupDependencies(t *testing.T) {
 		}
 	})
 }
+
+// TestDependencyResolverResolvesHandlers tests that notifies and subscribes
+// connect handlers to the resources that notify them
+func TestDependencyResolverResolvesHandlers(t *testing.T) {
+	t.Parallel()
+	defer logging.HideLogs(t)()
+
+	src := `
+file.content "config" {
+	destination = "/tmp/config"
+	notifies    = ["task.restart"]
+}
+
+file.content "other-config" {
+	destination = "/tmp/other-config"
+}
+
+task "restart" {
+	check      = "exit 0"
+	apply      = "echo restart"
+	subscribes = ["file.content.other-config"]
+}
+
+task "unrelated" {
+	check = "exit 0"
+	apply = "echo unrelated"
+}
+
+task "after-restart" {
+	check   = "exit 0"
+	apply   = "echo after"
+	depends = ["task.restart"]
+}
+`
+	gr, err := hclutils.LoadFromString("ResolverResolvesHandlers", src)
+	require.NoError(t, err)
+
+	g, err := load.ResolveDependencies(context.Background(), gr)
+	require.NoError(t, err)
+
+	t.Run("notifiers", func(t *testing.T) {
+		meta, ok := g.Get("root/task.restart")
+		require.True(t, ok)
+
+		notifiers, isHandler := resource.NotifiersFor(meta)
+		assert.True(t, isHandler)
+		assert.Equal(t, []string{"root/file.content.config", "root/file.content.other-config"}, notifiers)
+	})
+
+	t.Run("notifies", func(t *testing.T) {
+		assert.True(t, graphutils.DependsOn(g, "root/task.restart", "root/file.content.config"))
+	})
+
+	t.Run("subscribes", func(t *testing.T) {
+		assert.True(t, graphutils.DependsOn(g, "root/task.restart", "root/file.content.other-config"))
+	})
+
+	t.Run("end of module", func(t *testing.T) {
+		assert.True(t, graphutils.DependsOn(g, "root/task.restart", "root/task.unrelated"))
+		assert.False(t, graphutils.DependsOn(g, "root/task.restart", "root/task.after-restart"))
+	})
+
+	t.Run("not a handler", func(t *testing.T) {
+		meta, ok := g.Get("root/task.unrelated")
+		require.True(t, ok)
+
+		_, isHandler := resource.NotifiersFor(meta)
+		assert.False(t, isHandler)
+	})
+}
+
+func TestDependencyResolverResolvesRepositories(t *testing.T) {
+	t.Parallel()
+	defer logging.HideLogs(t)()
+
+	src := `
+package.apt "transport" {
+	name = "apt-transport-https"
+}
+
+package.apt.repository "docker" {
+	uri          = "https://download.docker.com/linux/ubuntu"
+	distribution = "xenial"
+	components   = ["stable"]
+	depends      = ["package.apt.transport"]
+}
+
+package.apt "docker" {
+	name = "docker-ce"
+}
+
+package "git" {
+	name = "git"
+}
+
+package.rpm "docker" {
+	name = "docker-ce"
+}
+`
+	gr, err := hclutils.LoadFromString("ResolverResolvesRepositories", src)
+	require.NoError(t, err)
+
+	g, err := load.ResolveDependencies(context.Background(), gr)
+	require.NoError(t, err)
+
+	t.Run("same manager", func(t *testing.T) {
+		assert.True(t, graphutils.DependsOn(g, "root/package.apt.docker", "root/package.apt.repository.docker"))
+	})
+
+	t.Run("any manager", func(t *testing.T) {
+		assert.True(t, graphutils.DependsOn(g, "root/package.git", "root/package.apt.repository.docker"))
+	})
+
+	t.Run("other manager", func(t *testing.T) {
+		assert.False(t, graphutils.DependsOn(g, "root/package.rpm.docker", "root/package.apt.repository.docker"))
+	})
+
+	t.Run("failing repository", func(t *testing.T) {
+		assert.Contains(t, g.ResultDependencies("root/package.apt.docker"), "root/package.apt.repository.docker")
+	})
+
+	t.Run("dependency of repository", func(t *testing.T) {
+		assert.True(t, graphutils.DependsOn(g, "root/package.apt.repository.docker", "root/package.apt.transport"))
+		assert.False(t, graphutils.DependsOn(g, "root/package.apt.transport", "root/package.apt.repository.docker"))
+	})
+}
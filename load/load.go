@@ -16,18 +16,25 @@ package load
 
 import (
 	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/resource"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
-// Load produces a fully-formed graph from the given root
-func Load(ctx context.Context, root string, verify bool) (*graph.Graph, error) {
+// Load produces a fully-formed graph from the given root. Values are the
+// values of the top-level params, which are needed to expand for_each fields.
+func Load(ctx context.Context, root string, verify bool, values map[string]resource.Value) (*graph.Graph, error) {
 	base, err := Nodes(ctx, root, verify)
 	if err != nil {
 		return nil, errors.Wrap(err, "loading failed")
 	}
 
-	resolved, err := ResolveDependencies(ctx, base)
+	expanded, err := ExpandLoops(ctx, base, values)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not expand loops")
+	}
+
+	resolved, err := ResolveDependencies(ctx, expanded)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "could not resolve dependencies")
@@ -0,0 +1,306 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/graph/node"
+	"github.com/asteris-llc/converge/graph/node/conditional"
+	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/parse"
+	"github.com/asteris-llc/converge/parse/preprocessor/loop"
+	"github.com/asteris-llc/converge/render/extensions"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/param"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// loopItem is a single element of a for_each value
+type loopItem struct {
+	key   string
+	value interface{}
+}
+
+// ExpandLoops replaces every node with a for_each field with one copy of the
+// node per element of its value. Each copy is named after the key of its
+// element, and the original node is replaced with a macro.for placeholder that
+// depends on all of them so the expanded set can be depended on as a whole.
+//
+// for_each values are needed to build the graph, so they must be literal, or
+// come from params through a single call to paramList or paramMap. The values
+// given for the top-level params are used if they are set.
+func ExpandLoops(ctx context.Context, g *graph.Graph, values map[string]resource.Value) (*graph.Graph, error) {
+	logger := logging.GetLogger(ctx).WithField("function", "ExpandLoops")
+
+	out := g.Copy()
+	for _, meta := range g.Nodes() {
+		n, ok := meta.Value().(*parse.Node)
+		if !ok || !loop.IsLoopNode(n) {
+			continue
+		}
+
+		logger.WithField("id", meta.ID).Debug("expanding")
+		if err := expandLoop(g, out, meta, n, values); err != nil {
+			return out, errors.Wrap(err, meta.ID)
+		}
+	}
+
+	return out, out.Validate()
+}
+
+func expandLoop(g, out *graph.Graph, meta *node.Node, n *parse.Node, values map[string]resource.Value) error {
+	switch n.Kind() {
+	case "module", "param", "macro.switch", "macro.case":
+		return fmt.Errorf("%s cannot be used with %s", loop.Field, n.Kind())
+	}
+
+	items, err := loopItems(g, meta.ID, n, values)
+	if err != nil {
+		return err
+	}
+
+	parentID, ok := g.GetParentID(meta.ID)
+	if !ok {
+		return errors.New("could not find parent")
+	}
+
+	var keys, ids []string
+	for _, item := range items {
+		expanded, err := loop.Expand(n, item.key)
+		if err != nil {
+			return err
+		}
+
+		id := graph.ID(parentID, expanded.ID())
+		if out.Contains(id) {
+			return fmt.Errorf("%s key %q: duplicate resource %s", loop.Field, item.key, id)
+		}
+
+		expandedMeta := meta.Copy(id, expanded)
+		expandedMeta.AddMetadata(parse.MetaEach, map[string]interface{}{
+			"key":   item.key,
+			"value": item.value,
+		})
+
+		out.Add(expandedMeta)
+		out.ConnectParent(parentID, id)
+		keys = append(keys, item.key)
+		ids = append(ids, id)
+	}
+
+	placeholder, err := loop.GenerateNode(n.Name(), keys)
+	if err != nil {
+		return err
+	}
+
+	placeholderMeta := node.New(meta.ID, placeholder)
+	placeholderMeta.AddMetadata(graph.MetaKind, placeholder.Kind())
+	for _, key := range []string{
+		parse.MetaPosition,
+		conditional.MetaSwitchName,
+		conditional.MetaUnrenderedPredicate,
+		conditional.MetaBranchName,
+		conditional.MetaPeers,
+		conditional.MetaType,
+	} {
+		if val, ok := meta.LookupMetadata(key); ok {
+			placeholderMeta.AddMetadata(key, val)
+		}
+	}
+	out.Add(placeholderMeta)
+
+	for _, id := range ids {
+		out.Connect(meta.ID, id)
+	}
+
+	return nil
+}
+
+// loopItems gets the elements to expand a node for. Lists are expanded for each
+// element, keyed by the element itself. Maps are expanded for each entry, in
+// order of their keys.
+func loopItems(g *graph.Graph, id string, n *parse.Node, values map[string]resource.Value) ([]loopItem, error) {
+	raw, err := n.Get(loop.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := loopValue(g, id, raw, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []loopItem
+	seen := map[string]struct{}{}
+
+	switch v := val.(type) {
+	case []interface{}:
+		for _, elem := range v {
+			switch elem.(type) {
+			case string, bool, int, int64, float64:
+			default:
+				return nil, fmt.Errorf("%s lists can only contain strings and numbers, use a map to loop over %T", loop.Field, elem)
+			}
+
+			key := fmt.Sprintf("%v", elem)
+			if _, ok := seen[key]; ok {
+				return nil, fmt.Errorf("%s key %q is not unique", loop.Field, key)
+			}
+			seen[key] = struct{}{}
+
+			items = append(items, loopItem{key: key, value: elem})
+		}
+
+	case map[string]interface{}:
+		var keys []string
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			items = append(items, loopItem{key: key, value: v[key]})
+		}
+
+	default:
+		return nil, fmt.Errorf("%s must be a list or a map, got %T", loop.Field, val)
+	}
+
+	return items, nil
+}
+
+// loopValue resolves the value of a for_each field, or of a module param that
+// a for_each field refers to
+func loopValue(g *graph.Graph, id string, raw interface{}, values map[string]resource.Value) (interface{}, error) {
+	str, ok := raw.(string)
+	if !ok {
+		// HCL decodes blocks as lists of maps
+		if blocks, ok := raw.([]map[string]interface{}); ok {
+			return param.TypeMap.Coerce(blocks)
+		}
+		return param.TypeList.Coerce(raw)
+	}
+
+	if !strings.Contains(str, "{{") {
+		return nil, fmt.Errorf("%s must be a list, a map, or a call to paramList or paramMap, got %q", loop.Field, str)
+	}
+
+	call, name, err := loopParamCall(str)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := loopParamValue(g, id, name, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if call == "paramMap" {
+		return param.TypeMap.Coerce(val)
+	}
+	return param.TypeList.Coerce(val)
+}
+
+// loopParamCall finds the single paramList or paramMap call in a template
+func loopParamCall(src string) (call string, name string, err error) {
+	type paramCall struct{ call, name string }
+	var calls []paramCall
+
+	language := extensions.MinimalLanguage()
+	language.On("paramList", func(name string) ([]interface{}, error) {
+		calls = append(calls, paramCall{"paramList", name})
+		return nil, nil
+	})
+	language.On("paramMap", func(name string) (map[string]interface{}, error) {
+		calls = append(calls, paramCall{"paramMap", name})
+		return nil, nil
+	})
+
+	tmpl, err := template.New(loop.Field).Funcs(language.Funcs).Parse(src)
+	if err != nil {
+		return "", "", err
+	}
+	if err := tmpl.Execute(ioutil.Discard, nil); err != nil {
+		return "", "", err
+	}
+
+	if len(calls) != 1 {
+		return "", "", fmt.Errorf("%s must contain a single call to paramList or paramMap, got %q", loop.Field, src)
+	}
+
+	return calls[0].call, calls[0].name, nil
+}
+
+// loopParamValue gets the value of a param before rendering. Top-level params
+// use the values given for them, params in modules use the values passed by
+// the module call, and both fall back to the param's default.
+func loopParamValue(g *graph.Graph, id, name string, values map[string]resource.Value) (interface{}, error) {
+	paramID, ok := getNearestAncestor(g, id, "param."+name)
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter: param.%s", name)
+	}
+
+	moduleID, _ := g.GetParentID(paramID)
+	if graph.IsRoot(moduleID) {
+		if val, ok := values[name]; ok {
+			return val, nil
+		}
+	} else if moduleMeta, ok := g.Get(moduleID); ok {
+		if module, ok := moduleMeta.Value().(*parse.Node); ok {
+			if val, ok, err := moduleParam(module, name); err != nil {
+				return nil, errors.Wrap(err, moduleID)
+			} else if ok {
+				return loopValue(g, moduleID, val, values)
+			}
+		}
+	}
+
+	paramMeta, _ := g.Get(paramID)
+	paramNode, ok := paramMeta.Value().(*parse.Node)
+	if !ok {
+		return nil, fmt.Errorf("expected param.%s to be a *parse.Node, got %T", name, paramMeta.Value())
+	}
+
+	def, err := paramNode.Get("default")
+	if err == parse.ErrNotFound {
+		return nil, fmt.Errorf("param.%s has no value", name)
+	}
+	return def, err
+}
+
+// moduleParam gets the value passed for a param in a module call
+func moduleParam(module *parse.Node, name string) (interface{}, bool, error) {
+	raw, err := module.Get("params")
+	if err == parse.ErrNotFound {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	params, err := param.TypeMap.Coerce(raw)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "params")
+	}
+
+	val, ok := params.(map[string]interface{})[name]
+	return val, ok, nil
+}
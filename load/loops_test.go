@@ -0,0 +1,218 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/helpers/testing/hclutils"
+	"github.com/asteris-llc/converge/load"
+	"github.com/asteris-llc/converge/parse"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExpandLoops tests expanding nodes with for_each
+func TestExpandLoops(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	expand := func(t *testing.T, src string, values map[string]resource.Value) (*graph.Graph, error) {
+		nodes, err := hclutils.LoadFromString("ExpandLoops", src)
+		require.NoError(t, err)
+
+		return load.ExpandLoops(context.Background(), nodes, values)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		expanded, err := expand(t, `
+file.content "greeting" {
+  for_each    = ["a", "b"]
+  destination = "/tmp/{{each.key}}"
+}
+`, nil)
+		require.NoError(t, err)
+
+		assert.True(t, expanded.Contains("root/file.content.a"))
+		assert.True(t, expanded.Contains("root/file.content.b"))
+
+		meta, ok := expanded.Get("root/file.content.a")
+		require.True(t, ok)
+		each, ok := meta.LookupMetadata(parse.MetaEach)
+		require.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"key": "a", "value": "a"}, each)
+
+		parentID, ok := expanded.GetParentID("root/file.content.a")
+		require.True(t, ok)
+		assert.Equal(t, "root", parentID)
+	})
+
+	t.Run("placeholder", func(t *testing.T) {
+		expanded, err := expand(t, `
+file.content "greeting" {
+  for_each    = ["a", "b"]
+  destination = "/tmp/{{each.key}}"
+}
+`, nil)
+		require.NoError(t, err)
+
+		meta, ok := expanded.Get("root/file.content.greeting")
+		require.True(t, ok)
+		n, ok := meta.Value().(*parse.Node)
+		require.True(t, ok)
+		assert.Equal(t, "macro.for", n.Kind())
+
+		assert.Equal(
+			t,
+			[]string{"root/file.content.a", "root/file.content.b"},
+			graph.Targets(expanded.DownEdgesInGroup("root/file.content.greeting", "depends")),
+		)
+	})
+
+	t.Run("param-map", func(t *testing.T) {
+		expanded, err := expand(t, `
+param "users" {
+  default {
+    alice = "/bin/zsh"
+    bob   = "/bin/bash"
+  }
+}
+
+user.user "users" {
+  for_each = "{{paramMap `+"`users`"+`}}"
+  username = "{{each.key}}"
+}
+`, nil)
+		require.NoError(t, err)
+
+		assert.True(t, expanded.Contains("root/user.user.alice"))
+		assert.True(t, expanded.Contains("root/user.user.bob"))
+
+		meta, ok := expanded.Get("root/user.user.bob")
+		require.True(t, ok)
+		each, ok := meta.LookupMetadata(parse.MetaEach)
+		require.True(t, ok)
+		assert.Equal(t, map[string]interface{}{"key": "bob", "value": "/bin/bash"}, each)
+	})
+
+	t.Run("param-values", func(t *testing.T) {
+		expanded, err := expand(t, `
+param "names" {
+  default = ["a"]
+}
+
+file.content "greeting" {
+  for_each    = "{{paramList `+"`names`"+`}}"
+  destination = "/tmp/{{each.key}}"
+}
+`, map[string]resource.Value{"names": `["x", "y"]`})
+		require.NoError(t, err)
+
+		assert.False(t, expanded.Contains("root/file.content.a"))
+		assert.True(t, expanded.Contains("root/file.content.x"))
+		assert.True(t, expanded.Contains("root/file.content.y"))
+	})
+
+	t.Run("module-params", func(t *testing.T) {
+		nodes, err := load.Nodes(context.Background(), "../samples/forEach.hcl", false)
+		require.NoError(t, err)
+
+		expanded, err := load.ExpandLoops(context.Background(), nodes, nil)
+		require.NoError(t, err)
+
+		assert.True(t, expanded.Contains("root/file.content.alice"))
+		assert.True(t, expanded.Contains("root/file.content.bob"))
+		assert.True(t, expanded.Contains("root/module.dirs/file.directory.first"))
+		assert.True(t, expanded.Contains("root/module.dirs/file.directory.second"))
+	})
+
+	t.Run("duplicate-keys", func(t *testing.T) {
+		_, err := expand(t, `
+file.content "greeting" {
+  for_each    = ["a", "a"]
+  destination = "/tmp/{{each.key}}"
+}
+`, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `for_each key "a" is not unique`)
+	})
+
+	t.Run("duplicate-ids", func(t *testing.T) {
+		_, err := expand(t, `
+file.content "a" {
+  destination = "/tmp/a"
+}
+
+file.content "greeting" {
+  for_each    = ["a"]
+  destination = "/tmp/{{each.key}}"
+}
+`, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate resource root/file.content.a")
+	})
+
+	t.Run("unresolvable", func(t *testing.T) {
+		_, err := expand(t, `
+file.content "greeting" {
+  for_each    = "{{lookup `+"`task.query.x.status`"+`}}"
+  destination = "/tmp/{{each.key}}"
+}
+`, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must contain a single call to paramList or paramMap")
+	})
+}
+
+// TestLoadResolvesLoopDependencies tests depending on an expanded set as a
+// whole
+func TestLoadResolvesLoopDependencies(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	src := `
+file.directory "dirs" {
+  for_each    = ["a", "b"]
+  destination = "/tmp/{{each.key}}"
+}
+
+file.content "done" {
+  destination = "/tmp/done"
+  depends     = ["file.directory.dirs"]
+}
+`
+
+	nodes, err := hclutils.LoadFromString("LoadResolvesLoopDependencies", src)
+	require.NoError(t, err)
+
+	expanded, err := load.ExpandLoops(context.Background(), nodes, nil)
+	require.NoError(t, err)
+
+	resolved, err := load.ResolveDependencies(context.Background(), expanded)
+	require.NoError(t, err)
+
+	assert.Contains(
+		t,
+		graph.Targets(resolved.DownEdges("root/file.content.done")),
+		"root/file.directory.dirs",
+	)
+	assert.Contains(
+		t,
+		graph.Targets(resolved.DownEdges("root/file.directory.dirs")),
+		"root/file.directory.a",
+	)
+}
@@ -16,7 +16,9 @@ package load
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
+	"strings"
 
 	"github.com/asteris-llc/converge/fetch"
 	"github.com/asteris-llc/converge/graph"
@@ -42,8 +44,6 @@ func (s *source) String() string {
 
 // Nodes loads and parses all resources referred to by the provided url
 func Nodes(ctx context.Context, root string, verify bool) (*graph.Graph, error) {
-	logger := logging.GetLogger(ctx).WithField("function", "Nodes")
-
 	toLoad := []*source{{"root", root, root}}
 
 	out := graph.New()
@@ -64,25 +64,15 @@ func Nodes(ctx context.Context, root string, verify bool) (*graph.Graph, error)
 			return nil, err
 		}
 
-		logger.WithField("url", url).Debug("fetching")
-		content, err := fetch.Any(ctx, url)
+		content, err := fetchAndVerify(ctx, url, verify)
 		if err != nil {
-			return nil, errors.Wrap(err, url)
+			return nil, err
 		}
 
-		if verify {
-			signatureURL := url + ".asc"
-
-			logger.WithField("signatureUrl", signatureURL).Debug("fetching")
-			signature, sigErr := fetch.Any(ctx, signatureURL)
-			if sigErr != nil {
-				return nil, errors.Wrap(sigErr, signatureURL)
-			}
-
-			err = keystore.Default().CheckSignature(bytes.NewBuffer(content), bytes.NewBuffer(signature))
-			if err != nil {
-				return nil, errors.Wrap(err, signatureURL)
-			}
+		// record where the module came from so saved plans can detect changes
+		if parent, ok := out.Get(current.Parent); ok {
+			parent.AddMetadata(parse.MetaSource, url)
+			parent.AddMetadata(parse.MetaSourceHash, fmt.Sprintf("sha256:%x", sha256.Sum256(content)))
 		}
 
 		resources, err := parse.Parse(content)
@@ -99,7 +89,19 @@ func Nodes(ctx context.Context, root string, verify bool) (*graph.Graph, error)
 				continue
 			}
 			newID := graph.ID(current.Parent, resource.ID())
-			out.Add(node.New(newID, resource))
+			newNode := node.New(newID, resource)
+			newNode.AddMetadata(parse.MetaPosition, fmt.Sprintf("%s:%s", url, resource.Pos()))
+			newNode.AddMetadata(graph.MetaKind, resource.Kind())
+
+			if resource.IsTemplate() {
+				tmpl, err := loadTemplate(ctx, url, resource, verify)
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s:%s", url, resource.Pos())
+				}
+				newNode.AddMetadata(parse.MetaTemplate, tmpl)
+			}
+
+			out.Add(newNode)
 			out.ConnectParent(current.Parent, newID)
 
 			if resource.IsModule() {
@@ -117,6 +119,66 @@ func Nodes(ctx context.Context, root string, verify bool) (*graph.Graph, error)
 	return out, out.Validate()
 }
 
+// fetchAndVerify fetches the content at the given URL, checking its signature
+// if verification is requested
+func fetchAndVerify(ctx context.Context, url string, verify bool) ([]byte, error) {
+	logger := logging.GetLogger(ctx).WithField("function", "fetchAndVerify")
+
+	logger.WithField("url", url).Debug("fetching")
+	content, err := fetch.Any(ctx, url)
+	if err != nil {
+		return nil, errors.Wrap(err, url)
+	}
+
+	if verify {
+		signatureURL := url + ".asc"
+
+		logger.WithField("signatureUrl", signatureURL).Debug("fetching")
+		signature, sigErr := fetch.Any(ctx, signatureURL)
+		if sigErr != nil {
+			return nil, errors.Wrap(sigErr, signatureURL)
+		}
+
+		err = keystore.Default().CheckSignature(bytes.NewBuffer(content), bytes.NewBuffer(signature))
+		if err != nil {
+			return nil, errors.Wrap(err, signatureURL)
+		}
+	}
+
+	return content, nil
+}
+
+// loadTemplate fetches the external template for a node, relative to the
+// module it was loaded from. The template is fetched during loading (instead
+// of when the resource is prepared) so that the params and lookups inside it
+// can be used to resolve dependencies.
+func loadTemplate(ctx context.Context, moduleURL string, n *parse.Node, verify bool) (string, error) {
+	if _, err := n.Get("content"); err == nil {
+		return "", fmt.Errorf("%s loads content from \"source\", use file.content for inline content", n.Kind())
+	}
+
+	source, err := n.GetString("source")
+	if err != nil {
+		return "", errors.Wrap(err, "could not get template source")
+	}
+
+	if strings.Contains(source, "{{") {
+		return "", fmt.Errorf("template source %q cannot contain template calls", source)
+	}
+
+	url, err := fetch.ResolveInContext(source, moduleURL)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := fetchAndVerify(ctx, url, verify)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
 // expandSwitchMacro is responsible for adding the generated switch nodes into
 // the graph.  Nodes inside of the switch macro are added as children to the
 // case statements, who are parents of the outer switch statement.  Actual node
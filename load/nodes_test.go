@@ -25,6 +25,7 @@ import (
 	"github.com/asteris-llc/converge/helpers/logging"
 	"github.com/asteris-llc/converge/helpers/testing/graphutils"
 	"github.com/asteris-llc/converge/load"
+	"github.com/asteris-llc/converge/parse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -151,6 +152,58 @@ func TestNodeWithConditionals(t *testing.T) {
 	})
 }
 
+// TestNodesTemplate tests loading external templates
+func TestNodesTemplate(t *testing.T) {
+	t.Parallel()
+	defer logging.HideLogs(t)()
+
+	t.Run("content", func(t *testing.T) {
+		g, err := load.Nodes(context.Background(), "../samples/fileTemplate.hcl", false)
+		require.NoError(t, err)
+
+		meta, found := g.Get("root/file.template.motd")
+		require.True(t, found)
+
+		content, ok := meta.LookupMetadata(parse.MetaTemplate)
+		require.True(t, ok)
+		assert.Contains(t, content, `Welcome to {{param "hostname"}}!`)
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		g, err := load.Load(context.Background(), "../samples/fileTemplate.hcl", false, nil)
+		require.NoError(t, err)
+
+		assert.True(t, graphutils.DependsOn(g, "root/file.template.motd", "root/param.hostname"))
+	})
+}
+
+// TestNodesModuleSource tests that module sources and hashes are recorded
+func TestNodesModuleSource(t *testing.T) {
+	t.Parallel()
+	defer logging.HideLogs(t)()
+
+	g, err := load.Nodes(context.Background(), "../samples/sourceFile.hcl", false)
+	require.NoError(t, err)
+
+	for _, id := range []string{"root", "root/module.basic"} {
+		meta, found := g.Get(id)
+		require.True(t, found, id)
+
+		source, ok := meta.LookupMetadata(parse.MetaSource)
+		require.True(t, ok, id)
+		assert.Contains(t, source, "samples/", id)
+
+		hash, ok := meta.LookupMetadata(parse.MetaSourceHash)
+		require.True(t, ok, id)
+		assert.Regexp(t, "^sha256:[0-9a-f]{64}$", hash, id)
+	}
+
+	meta, found := g.Get("root/module.basic/task.render")
+	require.True(t, found)
+	_, ok := meta.LookupMetadata(parse.MetaSourceHash)
+	assert.False(t, ok, "only modules should have a source hash")
+}
+
 func assertMetadataMatches(t *testing.T, node *node.Node, key string, expected interface{}) {
 	actual, ok := node.LookupMetadata(key)
 	assert.True(t, ok)
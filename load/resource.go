@@ -24,8 +24,10 @@ import (
 	"github.com/asteris-llc/converge/parse"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/hashicorp/hcl"
+	"github.com/pkg/errors"
 
 	// import empty to register types for SetResources
+	_ "github.com/asteris-llc/converge/resource/cron"
 	_ "github.com/asteris-llc/converge/resource/docker/container"
 	_ "github.com/asteris-llc/converge/resource/docker/image"
 	_ "github.com/asteris-llc/converge/resource/docker/network"
@@ -33,18 +35,29 @@ import (
 	_ "github.com/asteris-llc/converge/resource/file/content"
 	_ "github.com/asteris-llc/converge/resource/file/directory"
 	_ "github.com/asteris-llc/converge/resource/file/fetch"
+	_ "github.com/asteris-llc/converge/resource/file/line"
+	_ "github.com/asteris-llc/converge/resource/file/link"
 	_ "github.com/asteris-llc/converge/resource/file/mode"
 	_ "github.com/asteris-llc/converge/resource/file/owner"
+	_ "github.com/asteris-llc/converge/resource/file/template"
 	_ "github.com/asteris-llc/converge/resource/group"
 	_ "github.com/asteris-llc/converge/resource/lvm/fs"
 	_ "github.com/asteris-llc/converge/resource/lvm/lv"
 	_ "github.com/asteris-llc/converge/resource/lvm/vg"
 	_ "github.com/asteris-llc/converge/resource/module"
+	_ "github.com/asteris-llc/converge/resource/package/apk"
 	_ "github.com/asteris-llc/converge/resource/package/apt"
+	_ "github.com/asteris-llc/converge/resource/package/apt/repository"
+	_ "github.com/asteris-llc/converge/resource/package/auto"
+	_ "github.com/asteris-llc/converge/resource/package/pacman"
+	_ "github.com/asteris-llc/converge/resource/package/pip"
 	_ "github.com/asteris-llc/converge/resource/package/rpm"
+	_ "github.com/asteris-llc/converge/resource/package/rpm/repository"
+	_ "github.com/asteris-llc/converge/resource/package/zypper"
 	_ "github.com/asteris-llc/converge/resource/param"
 	_ "github.com/asteris-llc/converge/resource/shell"
 	_ "github.com/asteris-llc/converge/resource/shell/query"
+	_ "github.com/asteris-llc/converge/resource/sudo"
 	_ "github.com/asteris-llc/converge/resource/systemd/unit"
 	_ "github.com/asteris-llc/converge/resource/unarchive"
 	_ "github.com/asteris-llc/converge/resource/user"
@@ -85,6 +98,33 @@ func SetResources(ctx context.Context, g *graph.Graph) (*graph.Graph, error) {
 			return err
 		}
 
+		// templates loaded from an external source are rendered as content
+		if tmpl, ok := meta.LookupMetadata(parse.MetaTemplate); ok {
+			preparer.Source["content"] = tmpl
+		}
+
+		// retry and timeout are accepted by every resource. Resources with their
+		// own timeout field (like task) handle it themselves.
+		timeout := preparer.Source["timeout"]
+		if preparer.HasField("timeout") {
+			timeout = nil
+		}
+		policy, err := resource.NewPolicy(preparer.Source["retry"], timeout)
+		if err != nil {
+			return errors.Wrap(err, meta.ID)
+		}
+		if policy != nil {
+			meta.AddMetadata(resource.MetaPolicy, policy)
+		}
+
+		if raw, ok := preparer.Source["on_failure"]; ok {
+			mode, err := resource.ParseFailureMode(raw)
+			if err != nil {
+				return errors.Wrap(err, meta.ID)
+			}
+			meta.AddMetadata(resource.MetaOnFailure, mode)
+		}
+
 		out.Add(meta.WithValue(preparer))
 		return nil
 	})
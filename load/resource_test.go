@@ -17,6 +17,7 @@ package load_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/graph/node"
@@ -61,6 +62,99 @@ func TestSetResourcesBad(t *testing.T) {
 	}
 }
 
+func TestSetResourcesPolicy(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	t.Run("retry and timeout", func(t *testing.T) {
+		resourced, err := getResourcesGraph(
+			t,
+			[]byte(`
+file.content x {
+  destination = "x"
+  timeout     = "30s"
+
+  retry {
+    attempts = 3
+    interval = "1s"
+    backoff  = 2
+  }
+}`),
+		)
+		require.NoError(t, err)
+
+		meta, ok := resourced.Get("root/file.content.x")
+		require.True(t, ok)
+
+		assert.Equal(
+			t,
+			&resource.Policy{Attempts: 3, Interval: time.Second, Backoff: 2, Timeout: 30 * time.Second},
+			resource.PolicyFor(meta),
+		)
+	})
+
+	t.Run("own timeout", func(t *testing.T) {
+		resourced, err := getResourcesGraph(
+			t,
+			[]byte(`
+task x {
+  check   = "check"
+  apply   = "apply"
+  timeout = "{{param `+"`timeout`"+`}}"
+}`),
+		)
+		require.NoError(t, err)
+
+		meta, ok := resourced.Get("root/task.x")
+		require.True(t, ok)
+		assert.Nil(t, resource.PolicyFor(meta))
+	})
+
+	t.Run("none", func(t *testing.T) {
+		resourced, err := getResourcesGraph(t, []byte(`file.content x { destination = "x" }`))
+		require.NoError(t, err)
+
+		meta, ok := resourced.Get("root/file.content.x")
+		require.True(t, ok)
+		assert.Nil(t, resource.PolicyFor(meta))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := getResourcesGraph(t, []byte(`file.content x { retry { tries = 3 } }`))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "root/file.content.x: retry.tries: unknown field")
+		}
+	})
+}
+
+func TestSetResourcesOnFailure(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	t.Run("set", func(t *testing.T) {
+		resourced, err := getResourcesGraph(t, []byte(`file.content x { on_failure = "continue" }`))
+		require.NoError(t, err)
+
+		meta, ok := resourced.Get("root/file.content.x")
+		require.True(t, ok)
+		assert.Equal(t, resource.FailureContinue, resource.FailureModeFor(meta))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		resourced, err := getResourcesGraph(t, []byte(`file.content x { destination = "x" }`))
+		require.NoError(t, err)
+
+		meta, ok := resourced.Get("root/file.content.x")
+		require.True(t, ok)
+		assert.Equal(t, resource.FailureFail, resource.FailureModeFor(meta))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := getResourcesGraph(t, []byte(`file.content x { on_failure = "ignore" }`))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), `root/file.content.x: on_failure must be one of fail, continue, or warn, got "ignore"`)
+		}
+	})
+}
+
 func getResourcesGraph(t *testing.T, content []byte) (*graph.Graph, error) {
 	resources, err := parse.Parse(content)
 	require.NoError(t, err)
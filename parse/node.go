@@ -29,6 +29,27 @@ import (
 // ErrNotFound is returned from Get and friends when the key does not exist
 var ErrNotFound = errors.New("key does not exist")
 
+// MetaPosition is the graph node metadata key for the position of a node in
+// its source file, in the form "source:line:column"
+const MetaPosition = "source-position"
+
+// MetaTemplate is the graph node metadata key for the content of an external
+// template loaded for a node (see IsTemplate)
+const MetaTemplate = "template-content"
+
+// MetaSource is the graph node metadata key for the resolved URL of the
+// module loaded by a node. It is set on the root node and on module nodes.
+const MetaSource = "module-source"
+
+// MetaSourceHash is the graph node metadata key for the hash of the module
+// content loaded by a node, in the form "sha256:hex". It is set alongside
+// MetaSource.
+const MetaSourceHash = "module-source-hash"
+
+// MetaEach is the graph node metadata key for the element a node was expanded
+// for by for_each, as a map with "key" and "value" entries
+const MetaEach = "for-each"
+
 // Node represents a node in the parsed module
 type Node struct {
 	*ast.ObjectItem
@@ -129,6 +150,12 @@ func (n *Node) IsModule() bool {
 	return n.Kind() == "module"
 }
 
+// IsTemplate tests whether this node loads its content from an external
+// template
+func (n *Node) IsTemplate() bool {
+	return n.Kind() == "file.template"
+}
+
 // IsCase tests whether this node is a case statement
 func (n *Node) IsCase() bool {
 	return n.Kind() == "case"
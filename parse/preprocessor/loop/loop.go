@@ -0,0 +1,81 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loop
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/asteris-llc/converge/parse"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/pkg/errors"
+)
+
+// Field is the meta-argument that marks a node to be expanded
+const Field = "for_each"
+
+// IsLoopNode returns true if the parse node has a for_each field
+func IsLoopNode(n *parse.Node) bool {
+	_, err := n.Get(Field)
+	return err == nil
+}
+
+// Expand returns a copy of the node named after the given key. The copy shares
+// the fields of the original node.
+func Expand(n *parse.Node, key string) (*parse.Node, error) {
+	item := *n.ObjectItem
+
+	last := item.Keys[len(item.Keys)-1]
+	item.Keys = append(
+		append([]*ast.ObjectKey{}, item.Keys[:len(item.Keys)-1]...),
+		&ast.ObjectKey{Token: token.Token{
+			Type: token.STRING,
+			Pos:  last.Token.Pos,
+			Text: strconv.Quote(key),
+		}},
+	)
+
+	expanded := parse.NewNode(&item)
+	if err := expanded.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "%s key %q", Field, key)
+	}
+
+	return expanded, nil
+}
+
+// GenerateNode generates a parse.Node for the macro-expanded placeholder that
+// stands in for the whole expanded set
+func GenerateNode(name string, keys []string) (*parse.Node, error) {
+	var quotedKeys []string
+	for _, key := range keys {
+		quotedKeys = append(quotedKeys, fmt.Sprintf("%q", key))
+	}
+
+	loopHCL := fmt.Sprintf(
+		"macro.for %q {keys = [ %s ]}",
+		name,
+		strings.Join(quotedKeys, ","),
+	)
+	nodes, err := parse.Parse([]byte(loopHCL))
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) != 1 {
+		return nil, errors.New("expanded macro did not parse to a single node")
+	}
+	return nodes[0], nil
+}
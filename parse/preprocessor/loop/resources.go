@@ -0,0 +1,52 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loop
+
+import (
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"golang.org/x/net/context"
+)
+
+// Preparer represents the placeholder for a resource expanded with for_each;
+// the task it generates will not do anything during check or apply. It exists
+// so that the expanded set can be depended on as a whole.
+type Preparer struct {
+	Keys []string `hcl:"keys"`
+}
+
+// Prepare returns a task holding the expanded keys
+func (p *Preparer) Prepare(context.Context, resource.Renderer) (resource.Task, error) {
+	return &Task{Keys: p.Keys}, nil
+}
+
+// Task is the resource.Task for the placeholder of an expanded resource
+type Task struct {
+	Keys []string `export:"keys"`
+}
+
+// Check does nothing
+func (t *Task) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	return &resource.Status{}, nil
+}
+
+// Apply does nothing
+func (t *Task) Apply(context.Context) (resource.TaskStatus, error) {
+	return &resource.Status{}, nil
+}
+
+func init() {
+	registry.Register("macro.for", (*Preparer)(nil), (*Task)(nil))
+}
@@ -119,7 +119,14 @@ func (g *pipelineGen) DependencyCheck(ctx context.Context, taskI interface{}) (i
 	if !ok {
 		return nil, errors.New("input node is not a task wrapper")
 	}
-	for _, depID := range graph.Targets(g.Graph.DownEdges(g.ID)) {
+	for _, edge := range g.Graph.DownEdges(g.ID) {
+		// vertices that are only ordered before this one (like the siblings
+		// of a handler) don't affect it when they fail
+		if _, isOrder := edge.(*graph.OrderEdge); isOrder {
+			continue
+		}
+
+		depID := edge.Target().(string)
 		meta, ok := g.Graph.Get(depID)
 		if !ok {
 			return nil, nil
@@ -159,7 +166,9 @@ func (g *pipelineGen) PlanNode(ctx context.Context, taski interface{}) (interfac
 	if err != nil {
 		return nil, fmt.Errorf("unable to get renderer for %s", g.ID)
 	}
-	status, err := twrapper.Task.Check(ctx, renderer)
+	status, err := g.policy().Run(ctx, func(ctx context.Context) (resource.TaskStatus, error) {
+		return twrapper.Task.Check(ctx, renderer)
+	})
 
 	// create empty Status structure, if it not created in .Check()
 	if status == nil {
@@ -189,6 +198,15 @@ func (g *pipelineGen) PlanNode(ctx context.Context, taski interface{}) (interfac
 	}, nil
 }
 
+// policy gets the retry and timeout policy for the node, if there is one
+func (g *pipelineGen) policy() *resource.Policy {
+	meta, ok := g.Graph.Get(g.ID)
+	if !ok {
+		return nil
+	}
+	return resource.PolicyFor(meta)
+}
+
 func (g *pipelineGen) Renderer(id string) (*render.Renderer, error) {
 	return g.RenderingPlant.GetRenderer(id)
 }
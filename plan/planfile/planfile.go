@@ -0,0 +1,322 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package planfile reads and writes saved plans. A saved plan records the
+// checked state of every node in a graph, along with the modules the graph was
+// loaded from, so that a later apply can refuse to run if anything has changed
+// in the meantime.
+package planfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/parse"
+	"github.com/asteris-llc/converge/rpc/pb"
+	"github.com/pkg/errors"
+)
+
+// Version is the version of the plan file format written by this package
+const Version = 2
+
+// File is a saved plan
+type File struct {
+	Version  int                `json:"version"`
+	Location string             `json:"location"`
+	Modules  map[string]*Module `json:"modules"`
+	Nodes    []*Node            `json:"nodes"`
+
+	// Salt is the hex-encoded random key the fingerprints of the nodes are
+	// made with. It's chosen by the server for every plan.
+	Salt string `json:"salt"`
+}
+
+// Module is the source of a module loaded into the graph
+type Module struct {
+	Source string `json:"source"`
+	Hash   string `json:"hash"`
+}
+
+// Node is the checked state of a single node
+type Node struct {
+	ID         string           `json:"id"`
+	Kind       string           `json:"kind,omitempty"`
+	Level      string           `json:"level,omitempty"`
+	HasChanges bool             `json:"hasChanges"`
+	Error      string           `json:"error,omitempty"`
+	Warning    string           `json:"warning,omitempty"`
+	Changes    map[string]*Diff `json:"changes,omitempty"`
+	Fields     json.RawMessage  `json:"fields,omitempty"`
+
+	// Fingerprint is an HMAC of the real values behind any masked sensitive
+	// values, keyed with the salt of the plan, so a changed secret is detected
+	// even though it's masked the same way in both plans
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// Diff is a single planned change
+type Diff struct {
+	Original string   `json:"original"`
+	Current  string   `json:"current"`
+	Changes  bool     `json:"changes"`
+	Unified  []string `json:"unified,omitempty"`
+}
+
+// New creates an empty plan for the given location and modules
+func New(location string, modules map[string]*Module) *File {
+	if modules == nil {
+		modules = map[string]*Module{}
+	}
+
+	return &File{
+		Version:  Version,
+		Location: location,
+		Modules:  modules,
+	}
+}
+
+// ModulesFromGraph gets the source and hash of every module loaded into a
+// graph, keyed by the ID of the node that loaded it
+func ModulesFromGraph(g *graph.Graph) map[string]*Module {
+	modules := map[string]*Module{}
+
+	for _, meta := range g.Nodes() {
+		hash, ok := meta.LookupMetadata(parse.MetaSourceHash)
+		if !ok {
+			continue
+		}
+
+		module := &Module{Hash: fmt.Sprint(hash)}
+		if source, ok := meta.LookupMetadata(parse.MetaSource); ok {
+			module.Source = fmt.Sprint(source)
+		}
+
+		modules[meta.ID] = module
+	}
+
+	return modules
+}
+
+// Add records a status response in the plan. Only finished responses with
+// details are recorded. A later response for the same node replaces an
+// earlier one.
+func (f *File) Add(resp *pb.StatusResponse) {
+	details := resp.GetDetails()
+	if resp.Run != pb.StatusResponse_FINISHED || details == nil {
+		return
+	}
+
+	id := resp.GetMeta().GetId()
+	if id == "" {
+		id = resp.Id
+	}
+
+	node := &Node{
+		ID:         id,
+		Kind:       resp.GetMeta().GetKind(),
+		Level:      details.Level,
+		HasChanges: details.HasChanges,
+		Error:      details.Error,
+		Warning:    details.Warning,
+
+		Fingerprint: details.Fingerprint,
+	}
+
+	if details.Fields != "" {
+		node.Fields = json.RawMessage(details.Fields)
+	}
+
+	if len(details.Changes) > 0 {
+		node.Changes = map[string]*Diff{}
+		for key, diff := range details.Changes {
+			node.Changes[key] = &Diff{
+				Original: diff.Original,
+				Current:  diff.Current,
+				Changes:  diff.Changes,
+				Unified:  diff.Unified,
+			}
+		}
+	}
+
+	for i, existing := range f.Nodes {
+		if existing.ID == id {
+			f.Nodes[i] = node
+			return
+		}
+	}
+
+	f.Nodes = append(f.Nodes, node)
+}
+
+// Get returns the node with the given ID
+func (f *File) Get(id string) (*Node, bool) {
+	for _, node := range f.Nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+
+	return nil, false
+}
+
+// Write writes the plan as indented JSON, with nodes sorted by ID
+func (f *File) Write(w io.Writer) error {
+	sort.Slice(f.Nodes, func(i, j int) bool { return f.Nodes[i].ID < f.Nodes[j].ID })
+
+	out, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not serialize plan")
+	}
+
+	if _, err = w.Write(append(out, '\n')); err != nil {
+		return errors.Wrap(err, "could not write plan")
+	}
+
+	return nil
+}
+
+// Read reads a plan written by Write
+func Read(r io.Reader) (*File, error) {
+	f := new(File)
+	if err := json.NewDecoder(r).Decode(f); err != nil {
+		return nil, errors.Wrap(err, "could not read plan")
+	}
+
+	if f.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version %d, expected %d", f.Version, Version)
+	}
+
+	if f.Modules == nil {
+		f.Modules = map[string]*Module{}
+	}
+
+	return f, nil
+}
+
+// Drift compares this plan to another made later, and describes every
+// difference between them. An empty result means the plan is still valid.
+func (f *File) Drift(current *File) []string {
+	var drift []string
+
+	if f.Location != current.Location {
+		drift = append(drift, fmt.Sprintf("location changed from %q to %q", f.Location, current.Location))
+	}
+
+	for id, planned := range f.Modules {
+		found, ok := current.Modules[id]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("%s: module is no longer loaded", id))
+		case planned.Source != found.Source:
+			drift = append(drift, fmt.Sprintf("%s: module source changed from %q to %q", id, planned.Source, found.Source))
+		case planned.Hash != found.Hash:
+			drift = append(drift, fmt.Sprintf("%s: module %s has changed", id, found.Source))
+		}
+	}
+
+	for id := range current.Modules {
+		if _, ok := f.Modules[id]; !ok {
+			drift = append(drift, fmt.Sprintf("%s: module was not in the plan", id))
+		}
+	}
+
+	for _, planned := range f.Nodes {
+		found, ok := current.Get(planned.ID)
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s: node is no longer in the graph", planned.ID))
+			continue
+		}
+
+		drift = append(drift, planned.drift(found)...)
+	}
+
+	for _, found := range current.Nodes {
+		if _, ok := f.Get(found.ID); !ok {
+			drift = append(drift, fmt.Sprintf("%s: node was not in the plan", found.ID))
+		}
+	}
+
+	sort.Strings(drift)
+	return drift
+}
+
+func (n *Node) drift(current *Node) []string {
+	var drift []string
+
+	if n.Kind != current.Kind {
+		drift = append(drift, fmt.Sprintf("%s: kind changed from %q to %q", n.ID, n.Kind, current.Kind))
+	}
+
+	if n.Level != current.Level {
+		drift = append(drift, fmt.Sprintf("%s: status changed from %q to %q", n.ID, n.Level, current.Level))
+	}
+
+	if n.HasChanges != current.HasChanges {
+		drift = append(drift, fmt.Sprintf("%s: has changes changed from %t to %t", n.ID, n.HasChanges, current.HasChanges))
+	}
+
+	if n.Error != current.Error {
+		drift = append(drift, fmt.Sprintf("%s: error changed from %q to %q", n.ID, n.Error, current.Error))
+	}
+
+	keys := map[string]struct{}{}
+	for key := range n.Changes {
+		keys[key] = struct{}{}
+	}
+	for key := range current.Changes {
+		keys[key] = struct{}{}
+	}
+
+	for key := range keys {
+		planned, found := n.Changes[key], current.Changes[key]
+		if planned == nil || found == nil ||
+			planned.Original != found.Original ||
+			planned.Current != found.Current ||
+			planned.Changes != found.Changes {
+			drift = append(drift, fmt.Sprintf("%s: %s is different than planned", n.ID, key))
+		}
+	}
+
+	if !sameJSON(n.Fields, current.Fields) {
+		drift = append(drift, fmt.Sprintf("%s: rendered fields are different than planned", n.ID))
+	}
+
+	if n.Fingerprint != current.Fingerprint {
+		drift = append(drift, fmt.Sprintf("%s: sensitive values are different than planned", n.ID))
+	}
+
+	return drift
+}
+
+// sameJSON compares two JSON documents by value, so that formatting and key
+// order don't matter
+func sameJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	var aVal, bVal interface{}
+	if err := json.Unmarshal(a, &aVal); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &bVal); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aVal, bVal)
+}
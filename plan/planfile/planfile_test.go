@@ -0,0 +1,213 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planfile_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/asteris-llc/converge/plan/planfile"
+	"github.com/asteris-llc/converge/rpc/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func finished(id, level, fields string, changes map[string]*pb.DiffResponse) *pb.StatusResponse {
+	return &pb.StatusResponse{
+		Id:   id,
+		Run:  pb.StatusResponse_FINISHED,
+		Meta: &pb.StatusResponse_Meta{Id: id, Kind: "file.content"},
+		Details: &pb.StatusResponse_Details{
+			Level:      level,
+			HasChanges: len(changes) > 0,
+			Changes:    changes,
+			Fields:     fields,
+		},
+	}
+}
+
+func samplePlan() *planfile.File {
+	f := planfile.New("test.hcl", map[string]*planfile.Module{
+		"root": {Source: "file:///test.hcl", Hash: "sha256:abc"},
+	})
+	f.Add(finished(
+		"root/file.content.b",
+		"will change",
+		`{"destination":"b","content":"x"}`,
+		map[string]*pb.DiffResponse{"b": {Original: "<absent>", Current: "x", Changes: true}},
+	))
+	f.Add(finished("root/file.content.a", "no change", `{"destination":"a"}`, nil))
+	return f
+}
+
+// TestAdd tests recording responses
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finished", func(t *testing.T) {
+		f := samplePlan()
+		require.Len(t, f.Nodes, 2)
+
+		node, ok := f.Get("root/file.content.b")
+		require.True(t, ok)
+		assert.Equal(t, "file.content", node.Kind)
+		assert.Equal(t, "will change", node.Level)
+		assert.True(t, node.HasChanges)
+		assert.Equal(t, "x", node.Changes["b"].Current)
+	})
+
+	t.Run("started", func(t *testing.T) {
+		f := planfile.New("test.hcl", nil)
+		f.Add(&pb.StatusResponse{Run: pb.StatusResponse_STARTED, Meta: &pb.StatusResponse_Meta{Id: "root"}})
+		assert.Empty(t, f.Nodes)
+	})
+
+	t.Run("replaces", func(t *testing.T) {
+		f := samplePlan()
+		f.Add(finished("root/file.content.a", "will change", "", nil))
+		require.Len(t, f.Nodes, 2)
+
+		node, ok := f.Get("root/file.content.a")
+		require.True(t, ok)
+		assert.Equal(t, "will change", node.Level)
+	})
+}
+
+// TestReadWrite tests that plans survive a round trip
+func TestReadWrite(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, samplePlan().Write(&buf))
+
+		read, err := planfile.Read(&buf)
+		require.NoError(t, err)
+
+		assert.Equal(t, "test.hcl", read.Location)
+		assert.Equal(t, "sha256:abc", read.Modules["root"].Hash)
+		require.Len(t, read.Nodes, 2)
+		assert.Equal(t, "root/file.content.a", read.Nodes[0].ID, "nodes should be sorted")
+		assert.Empty(t, samplePlan().Drift(read))
+	})
+
+	t.Run("bad version", func(t *testing.T) {
+		_, err := planfile.Read(strings.NewReader(`{"version": 100}`))
+		assert.EqualError(t, err, "unsupported plan version 100, expected 2")
+	})
+
+	t.Run("bad json", func(t *testing.T) {
+		_, err := planfile.Read(strings.NewReader(`{`))
+		assert.Error(t, err)
+	})
+}
+
+// TestDrift tests comparing plans
+func TestDrift(t *testing.T) {
+	t.Parallel()
+
+	t.Run("none", func(t *testing.T) {
+		assert.Empty(t, samplePlan().Drift(samplePlan()))
+	})
+
+	t.Run("field order", func(t *testing.T) {
+		current := samplePlan()
+		current.Add(finished("root/file.content.a", "no change", `{ "destination": "a" }`, nil))
+		assert.Empty(t, samplePlan().Drift(current))
+	})
+
+	t.Run("module changed", func(t *testing.T) {
+		current := samplePlan()
+		current.Modules["root"].Hash = "sha256:def"
+		assert.Equal(t, []string{"root: module file:///test.hcl has changed"}, samplePlan().Drift(current))
+	})
+
+	t.Run("module added", func(t *testing.T) {
+		current := samplePlan()
+		current.Modules["root/module.x"] = &planfile.Module{Source: "x.hcl", Hash: "sha256:abc"}
+		assert.Equal(t, []string{"root/module.x: module was not in the plan"}, samplePlan().Drift(current))
+	})
+
+	t.Run("status changed", func(t *testing.T) {
+		current := samplePlan()
+		current.Add(finished(
+			"root/file.content.b",
+			"will change",
+			`{"destination":"b","content":"x"}`,
+			map[string]*pb.DiffResponse{"b": {Original: "y", Current: "x", Changes: true}},
+		))
+		assert.Equal(t, []string{"root/file.content.b: b is different than planned"}, samplePlan().Drift(current))
+	})
+
+	t.Run("level changed", func(t *testing.T) {
+		current := samplePlan()
+		current.Add(finished("root/file.content.a", "will change", `{"destination":"a"}`, nil))
+		assert.Equal(
+			t,
+			[]string{`root/file.content.a: status changed from "no change" to "will change"`},
+			samplePlan().Drift(current),
+		)
+	})
+
+	t.Run("fields changed", func(t *testing.T) {
+		current := samplePlan()
+		current.Add(finished("root/file.content.a", "no change", `{"destination":"c"}`, nil))
+		assert.Equal(
+			t,
+			[]string{"root/file.content.a: rendered fields are different than planned"},
+			samplePlan().Drift(current),
+		)
+	})
+
+	// sensitive values are masked the same way in both plans, so only the
+	// fingerprint shows they changed
+	t.Run("sensitive changed", func(t *testing.T) {
+		sensitive := func(fingerprint string) *planfile.File {
+			f := samplePlan()
+			resp := finished("root/file.content.a", "no change", `{"destination":"a","content":"<sensitive>"}`, nil)
+			resp.Details.Fingerprint = fingerprint
+			f.Add(resp)
+			return f
+		}
+
+		assert.Empty(t, sensitive("abc").Drift(sensitive("abc")))
+		assert.Equal(
+			t,
+			[]string{"root/file.content.a: sensitive values are different than planned"},
+			sensitive("abc").Drift(sensitive("def")),
+		)
+	})
+
+	t.Run("nodes", func(t *testing.T) {
+		current := planfile.New("test.hcl", samplePlan().Modules)
+		current.Add(finished("root/file.content.a", "no change", `{"destination":"a"}`, nil))
+		current.Add(finished("root/file.content.c", "no change", "", nil))
+		assert.Equal(
+			t,
+			[]string{
+				"root/file.content.b: node is no longer in the graph",
+				"root/file.content.c: node was not in the plan",
+			},
+			samplePlan().Drift(current),
+		)
+	})
+
+	t.Run("location", func(t *testing.T) {
+		current := samplePlan()
+		current.Location = "other.hcl"
+		assert.Equal(t, []string{`location changed from "test.hcl" to "other.hcl"`}, samplePlan().Drift(current))
+	})
+}
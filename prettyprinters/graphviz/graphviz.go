@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/helpers/redact"
 	pp "github.com/asteris-llc/converge/prettyprinters"
 	"github.com/asteris-llc/converge/rpc/pb"
 )
@@ -177,6 +178,9 @@ func (p *Printer) DrawNode(g *graph.Graph, id string) (pp.Renderable, error) {
 	if err != nil {
 		return pp.HiddenString(), err
 	}
+	if vertexLabel.Visible() {
+		vertexLabel = pp.VisibleString(redact.Default().String(vertexLabel.String()))
+	}
 
 	attributes := p.printProvider.VertexGetProperties(graphEntity)
 	attributes = maybeSetProperty(attributes, "label", escapeNewline(vertexLabel))
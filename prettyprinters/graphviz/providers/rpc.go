@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/asteris-llc/converge/helpers/redact"
 	pp "github.com/asteris-llc/converge/prettyprinters"
 	"github.com/asteris-llc/converge/prettyprinters/graphviz"
 	"github.com/asteris-llc/converge/resource/file/content"
@@ -99,8 +100,13 @@ func (p RPCProvider) VertexGetLabel(e graphviz.GraphEntity) (pp.VisibleRenderabl
 			return nil, errors.Wrap(err, "could not unmarshal param")
 		}
 
+		val := dest.Val
+		if dest.Sensitive {
+			val = redact.Mask
+		}
+
 		return pp.RenderableString(
-			fmt.Sprintf("%s: %s", name, dest.Val),
+			fmt.Sprintf("%s: %s", name, val),
 			p.ShowParams,
 		), nil
 
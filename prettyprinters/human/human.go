@@ -23,7 +23,9 @@ import (
 	"text/template"
 
 	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/helpers/redact"
 	pp "github.com/asteris-llc/converge/prettyprinters"
+	"github.com/asteris-llc/converge/resource"
 	"github.com/pkg/errors"
 )
 
@@ -78,11 +80,17 @@ func (p *Printer) FinishPP(g *graph.Graph) (pp.Renderable, error) {
 {{range .DependencyErrors}} * {{.}}
 {{end}}
 {{end}}
+{{- if .AllowedFailures}}Failed (allowed):
+{{range .AllowedFailures}} * {{.}}
+{{end}}
+{{end}}
 {{- if gt (len .Errors) 0}}{{red "Summary"}}
 {{- else}}{{green "Summary"}}
 {{- end}}: {{len .Errors}} errors, {{.ChangesCount}} changes
 {{- if .DependencyErrors}}, {{len .DependencyErrors}} dependency errors
 {{- end}}
+{{- if .AllowedFailures}}, {{len .AllowedFailures}} allowed failures
+{{- end}}
 `)
 	if err != nil {
 		return pp.HiddenString(), err
@@ -92,6 +100,7 @@ func (p *Printer) FinishPP(g *graph.Graph) (pp.Renderable, error) {
 		ChangesCount     int
 		Errors           []error
 		DependencyErrors []error
+		AllowedFailures  []error
 	}{}
 
 	for _, id := range g.Vertices() {
@@ -119,6 +128,13 @@ func (p *Printer) FinishPP(g *graph.Graph) (pp.Renderable, error) {
 					)
 				}
 			}
+		} else if failer, ok := printable.(AllowedFailer); ok && failer.AllowedFailure() != nil {
+			if id != "root" {
+				counts.AllowedFailures = append(
+					counts.AllowedFailures,
+					errors.Wrap(failer.AllowedFailure(), id),
+				)
+			}
 		} else if printable.HasChanges() && id != "root" {
 			counts.ChangesCount++
 		}
@@ -127,7 +143,7 @@ func (p *Printer) FinishPP(g *graph.Graph) (pp.Renderable, error) {
 	var buf bytes.Buffer
 	err = tmpl.Execute(&buf, counts)
 
-	return &buf, err
+	return pp.VisibleString(redact.Default().String(buf.String())), err
 }
 
 // DrawNode containing a result
@@ -146,10 +162,13 @@ func (p *Printer) DrawNode(g *graph.Graph, id string) (pp.Renderable, error) {
 		return pp.HiddenString(), nil
 	}
 
-	tmpl, err := p.template(`{{if .Error}}{{red .ID}}{{else if .HasChanges}}{{yellow .ID}}{{else}}{{.ID}}{{end}}:
+	tmpl, err := p.template(`{{if .Error}}{{red .ID}}{{else if .AllowedFailure}}{{magenta .ID}}{{else if .HasChanges}}{{yellow .ID}}{{else}}{{.ID}}{{end}}:
 	{{- if .Error}}
 	{{red "Error"}}: {{.Error}}
 	{{- end}}
+	{{- if .AllowedFailure}}
+	{{magenta "Failed (allowed)"}}: {{.AllowedFailure}}
+	{{- end}}
 	{{- if .Warning}}
 	{{yellow "Warning"}}: {{.Warning}}
 	{{- end}}
@@ -160,7 +179,7 @@ func (p *Printer) DrawNode(g *graph.Graph, id string) (pp.Renderable, error) {
 	Has Changes: {{if .HasChanges}}{{yellow "yes"}}{{else}}no{{end}}
 	Changes:
 		{{- range $key, $values := .Changes}}
-		{{cyan $key}}:	{{diff ($values.Original) ($values.Current)}}
+		{{cyan $key}}:	{{showDiff $values}}
 		{{- else}} No changes {{- end}}
 
 `)
@@ -177,7 +196,7 @@ func (p *Printer) DrawNode(g *graph.Graph, id string) (pp.Renderable, error) {
 	tabWriter := tabwriter.NewWriter(&out, 1, 1, 1, ' ', 0)
 	_, err = tabWriter.Write(intermediate.Bytes())
 
-	return &out, err
+	return pp.VisibleString(redact.Default().String(out.String())), err
 }
 
 func (p *Printer) getFunc(key string) func(string) string {
@@ -194,6 +213,7 @@ func (p *Printer) funcsMapWrite(key string, value interface{}) {
 
 func (p *Printer) template(source string) (*template.Template, error) {
 	p.funcsMapWrite("diff", p.diff)
+	p.funcsMapWrite("showDiff", p.showDiff)
 	p.funcsMapWrite("indent", p.indent)
 	p.funcsMapWrite("empty", p.empty)
 
@@ -233,6 +253,36 @@ after:
 	return "\n" + p.indent(p.indent(buf.String())), err
 }
 
+// showDiff shows a unified diff if the diff supports it, and falls back to
+// showing the values before and after otherwise
+func (p *Printer) showDiff(d resource.Diff) (string, error) {
+	unified, ok := d.(resource.UnifiedDiffer)
+	if !ok {
+		return p.diff(d.Original(), d.Current())
+	}
+
+	lines := unified.Unified()
+	if len(lines) == 0 {
+		return p.diff(d.Original(), d.Current())
+	}
+
+	styled := make([]string, len(lines))
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			styled[i] = p.getFunc("cyan")(line)
+		case strings.HasPrefix(line, "+"):
+			styled[i] = p.getFunc("green")(line)
+		case strings.HasPrefix(line, "-"):
+			styled[i] = p.getFunc("red")(line)
+		default:
+			styled[i] = line
+		}
+	}
+
+	return "\n" + p.indent(p.indent(strings.Join(styled, "\n"))), nil
+}
+
 func (p *Printer) indent(in string) string {
 	return "\t" + strings.Replace(in, "\n", "\n\t", -1)
 }
@@ -20,6 +20,7 @@ import (
 
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/graph/node"
+	"github.com/asteris-llc/converge/helpers/redact"
 	pp "github.com/asteris-llc/converge/prettyprinters"
 	"github.com/asteris-llc/converge/prettyprinters/human"
 	"github.com/asteris-llc/converge/resource"
@@ -118,6 +119,14 @@ func TestFinishPPError(t *testing.T) {
 			)
 		})
 	})
+
+	t.Run("allowed failures", func(t *testing.T) {
+		testFinishPP(
+			t,
+			Printable{"allowed": "test"},
+			"Failed (allowed):\n * root/task: test\n\nSummary: 0 errors, 0 changes, 1 allowed failures\n",
+		)
+	})
 }
 
 func testDrawNodes(t *testing.T, in Printable, out string) {
@@ -249,6 +258,40 @@ func TestDrawNodeError(t *testing.T) {
 	)
 }
 
+// TestDrawNodeUnifiedDiff tests that unified diffs are shown line by line
+func TestDrawNodeUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	g := graph.New()
+	g.Add(node.New("root", diffPrintable{
+		"file": resource.NewUnifiedDiff("a\nb\nc\n", "a\nx\nc\n"),
+	}))
+
+	printer := human.New()
+	printer.InitColors()
+	str, err := printer.DrawNode(g, "root")
+
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		"root:\n Messages:\n Has Changes: yes\n Changes:\n  file: \n  @@ -1,3 +1,3 @@\n   a\n  -b\n  +x\n   c\n\n",
+		str.String(),
+	)
+}
+
+// TestDrawNodeSensitive tests that sensitive values are masked
+func TestDrawNodeSensitive(t *testing.T) {
+	t.Parallel()
+
+	redact.Default().Add("human-sensitive-value")
+
+	testDrawNodes(
+		t,
+		Printable{"password": "human-sensitive-value"},
+		"root:\n Messages:\n Has Changes: yes\n Changes:\n  password: \"\" => \"<sensitive>\"\n\n",
+	)
+}
+
 // TestDrawNodeWarning tests to ensure that warnings work correctly
 func TestDrawNodeWarning(t *testing.T) {
 	t.Parallel()
@@ -260,6 +303,18 @@ func TestDrawNodeWarning(t *testing.T) {
 	)
 }
 
+// TestDrawNodeAllowedFailure tests that allowed failures are shown as their own
+// state
+func TestDrawNodeAllowedFailure(t *testing.T) {
+	t.Parallel()
+
+	testDrawNodes(
+		t,
+		Printable{"allowed": "x"},
+		"root:\n Failed (allowed): x\n Messages:\n Has Changes: yes\n Changes:\n  allowed: \"\" => \"x\"\n\n",
+	)
+}
+
 func BenchmarkDrawNodeError(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		benchmarkDrawNodes(
@@ -306,3 +361,21 @@ func (p Printable) Error() error {
 func (p Printable) Warning() string {
 	return p["warning"]
 }
+
+// AllowedFailure generates an allowed failure
+func (p Printable) AllowedFailure() error {
+	err, ok := p["allowed"]
+	if !ok {
+		return nil
+	}
+
+	return errors.New(err)
+}
+
+type diffPrintable map[string]resource.Diff
+
+func (p diffPrintable) Messages() []string                { return []string{} }
+func (p diffPrintable) Changes() map[string]resource.Diff { return p }
+func (p diffPrintable) HasChanges() bool                  { return len(p) > 0 }
+func (p diffPrintable) Error() error                      { return nil }
+func (p diffPrintable) Warning() string                   { return "" }
@@ -30,3 +30,18 @@ type Printable interface {
 	Error() error
 	Warning() string
 }
+
+// AllowedFailer is implemented by printables that can fail without failing
+// the whole run, as set by a resource's on_failure policy
+type AllowedFailer interface {
+	AllowedFailure() error
+}
+
+// AllowedFailure returns the allowed failure of the node, if the underlying
+// printable supports them
+func (p *printerNode) AllowedFailure() error {
+	if failer, ok := p.Printable.(AllowedFailer); ok {
+		return failer.AllowedFailure()
+	}
+	return nil
+}
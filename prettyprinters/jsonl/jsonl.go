@@ -19,6 +19,7 @@ import (
 
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/graph/node"
+	"github.com/asteris-llc/converge/helpers/redact"
 	pp "github.com/asteris-llc/converge/prettyprinters"
 )
 
@@ -53,7 +54,7 @@ func (j *Printer) DrawNode(graph *graph.Graph, nodeID string) (pp.Renderable, er
 		Meta:  meta,
 		Value: meta.Value(),
 	})
-	return pp.VisibleString(string(out) + "\n"), err
+	return pp.VisibleString(redact.Default().String(string(out)) + "\n"), err
 }
 
 // DrawEdge returns an edge in JSONL format
@@ -22,6 +22,7 @@ import (
 	"github.com/asteris-llc/converge/graph/node"
 	pp "github.com/asteris-llc/converge/prettyprinters"
 	"github.com/asteris-llc/converge/prettyprinters/jsonl"
+	"github.com/asteris-llc/converge/resource"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,6 +45,21 @@ func TestDrawNode(t *testing.T) {
 	assert.Equal(t, `{"kind":"node","id":"x","meta":{"id":"x","group":""},"value":1}`+"\n", fmt.Sprint(out))
 }
 
+func TestDrawNodeUnifiedDiff(t *testing.T) {
+	g := graph.New()
+	g.Add(node.New("x", map[string]resource.Diff{"file": resource.NewUnifiedDiff("a\n", "b\n")}))
+
+	printer := new(jsonl.Printer)
+	out, err := printer.DrawNode(g, "x")
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"kind":"node","id":"x","meta":{"id":"x","group":""},"value":{"file":{"original":"a\n","current":"b\n","changes":true,"unified":["@@ -1 +1 @@","-a","+b"]}}}`+"\n",
+		fmt.Sprint(out),
+	)
+}
+
 func TestDrawEdge(t *testing.T) {
 	g := graph.New()
 
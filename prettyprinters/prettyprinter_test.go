@@ -65,7 +65,7 @@ func Example_usingTheIDProvider() {
 }
 
 func Example_generateAGraphFromAFileOnDisk() {
-	g, err := load.Load(context.Background(), os.Args[1], false)
+	g, err := load.Load(context.Background(), os.Args[1], false, nil)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -17,6 +17,7 @@ package extensions
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"sync"
 	"text/template"
 
@@ -45,6 +46,58 @@ var languageKeywords = map[string]struct{}{
 	"param":     {},
 	"paramList": {},
 	"paramMap":  {},
+
+	// the current element of a for_each loop
+	"each": {},
+
+	// string functions
+	"upper":    {},
+	"lower":    {},
+	"trim":     {},
+	"replace":  {},
+	"indent":   {},
+	"contains": {},
+	"default":  {},
+
+	// encoding and hashing functions
+	"base64encode": {},
+	"base64decode": {},
+	"sha256":       {},
+	"toYaml":       {},
+	"fromJson":     {},
+
+	// math functions
+	"add": {},
+	"sub": {},
+	"mul": {},
+	"div": {},
+	"mod": {},
+}
+
+// pureFunctions are the context-free functions that DefaultLanguage implements
+// without any dependencies. MinimalLanguage uses lenient stubs of them instead,
+// returning these values.
+var pureFunctions = map[string]struct {
+	impl interface{}
+	stub interface{}
+}{
+	"upper":        {strings.ToUpper, ""},
+	"lower":        {strings.ToLower, ""},
+	"trim":         {DefaultTrim, ""},
+	"replace":      {DefaultReplace, ""},
+	"indent":       {DefaultIndent, ""},
+	"contains":     {DefaultContains, false},
+	"default":      {DefaultDefault, ""},
+	"base64encode": {DefaultBase64Encode, ""},
+	"base64decode": {DefaultBase64Decode, ""},
+	"sha256":       {DefaultSha256, ""},
+	"toYaml":       {DefaultToYaml, ""},
+	"fromJson":     {DefaultFromJSON, nil},
+	"add":          {DefaultAdd, 0},
+	"sub":          {DefaultSub, 0},
+	"mul":          {DefaultMul, 0},
+	"div":          {DefaultDiv, 0},
+	"mod":          {DefaultMod, 0},
 }
 
 // LanguageExtension is a type wrapper around a template.FuncMap to allow us to
@@ -85,6 +138,15 @@ func MinimalLanguage() *LanguageExtension {
 	language.On("param", newStub(""))
 	language.On("paramList", newStub([]interface{}{}))
 	language.On("paramMap", newStub(map[string]interface{}{}))
+
+	// loops
+	language.On("each", newStub(map[string]interface{}{"key": "", "value": map[string]interface{}{}}))
+
+	// pure functions may be called with arguments of any type, including the
+	// results of the stubs above
+	for keyword, fn := range pureFunctions {
+		language.On(keyword, newLenientStub(fn.stub))
+	}
 	return language
 }
 
@@ -105,6 +167,13 @@ func DefaultLanguage() *LanguageExtension {
 	language.On("param", Unimplemented("param"))
 	language.On("paramList", Unimplemented("paramList"))
 	language.On("paramMap", Unimplemented("paramMap"))
+
+	// loops
+	language.On("each", Unimplemented("each"))
+
+	for keyword, fn := range pureFunctions {
+		language.On(keyword, fn.impl)
+	}
 	language.Validate()
 	return language
 }
@@ -199,6 +268,13 @@ func newStub(returnVal interface{}) func(...string) (interface{}, error) {
 	}
 }
 
+// newLenientStub works like newStub, but accepts arguments of any type
+func newLenientStub(returnVal interface{}) func(...interface{}) (interface{}, error) {
+	return func(...interface{}) (interface{}, error) {
+		return returnVal, nil
+	}
+}
+
 // RememberCalls is a utility function to instert calls into a list.
 // RememberCalls takes a pointer to a list of strings, and a default. It returns
 // a variadic function that when called from gotemplate will take the indexed
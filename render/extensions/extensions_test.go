@@ -39,6 +39,32 @@ var keywords = map[string]struct{}{
 	"param":     {},
 	"paramList": {},
 	"paramMap":  {},
+
+	// loops
+	"each": {},
+
+	// strings
+	"upper":    {},
+	"lower":    {},
+	"trim":     {},
+	"replace":  {},
+	"indent":   {},
+	"contains": {},
+	"default":  {},
+
+	// encoding and hashing
+	"base64encode": {},
+	"base64decode": {},
+	"sha256":       {},
+	"toYaml":       {},
+	"fromJson":     {},
+
+	// math
+	"add": {},
+	"sub": {},
+	"mul": {},
+	"div": {},
+	"mod": {},
 }
 
 var contextualFunctions = map[string]string{
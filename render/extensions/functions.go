@@ -0,0 +1,264 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extensions
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// The functions in this file are pure transformations. Where it makes sense
+// the value being transformed is the last argument, so they can be used at the
+// end of a pipeline, e.g. `{{param "name" | replace "-" "_" | upper}}`
+
+// DefaultTrim removes leading and trailing whitespace
+func DefaultTrim(str string) string {
+	return strings.TrimSpace(str)
+}
+
+// DefaultReplace replaces all instances of old with new in str
+func DefaultReplace(old, new, str string) string {
+	return strings.Replace(str, old, new, -1)
+}
+
+// DefaultIndent indents every non-empty line of str by the given number of
+// spaces. This is useful for embedding values in indented formats like YAML.
+func DefaultIndent(spaces int, str string) string {
+	pad := strings.Repeat(" ", spaces)
+
+	lines := strings.Split(str, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DefaultContains checks whether the given value contains needle. Strings are
+// checked for a substring, and lists for an element that formats the same as
+// needle.
+func DefaultContains(needle string, haystack interface{}) (bool, error) {
+	if str, ok := haystack.(string); ok {
+		return strings.Contains(str, needle), nil
+	}
+
+	values := reflect.ValueOf(haystack)
+	if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+		return false, fmt.Errorf("contains: cannot search in %T", haystack)
+	}
+
+	for i := 0; i < values.Len(); i++ {
+		if fmt.Sprintf("%v", values.Index(i).Interface()) == needle {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// DefaultDefault returns val, or def if val is empty. Values are empty if they
+// are nil, the zero value of their type, or an empty list or map.
+func DefaultDefault(def, val interface{}) interface{} {
+	if val == nil {
+		return def
+	}
+
+	value := reflect.ValueOf(val)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		if value.Len() == 0 {
+			return def
+		}
+	default:
+		if reflect.DeepEqual(val, reflect.Zero(value.Type()).Interface()) {
+			return def
+		}
+	}
+
+	return val
+}
+
+// DefaultBase64Encode encodes a string as standard base64
+func DefaultBase64Encode(str string) string {
+	return base64.StdEncoding.EncodeToString([]byte(str))
+}
+
+// DefaultBase64Decode decodes a standard base64 string
+func DefaultBase64Decode(str string) (string, error) {
+	out, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return "", errors.Wrap(err, "base64decode")
+	}
+
+	return string(out), nil
+}
+
+// DefaultSha256 returns the hex-encoded SHA256 checksum of a string
+func DefaultSha256(str string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(str)))
+}
+
+// DefaultToYaml marshals a value to YAML
+func DefaultToYaml(val interface{}) (string, error) {
+	out, err := yaml.Marshal(val)
+	if err != nil {
+		return "", errors.Wrap(err, "toYaml")
+	}
+
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// DefaultFromJSON unmarshals a JSON string, so that its fields and elements can
+// be used with `index` and `range`
+func DefaultFromJSON(str string) (interface{}, error) {
+	var out interface{}
+	if err := json.Unmarshal([]byte(str), &out); err != nil {
+		return nil, errors.Wrap(err, "fromJson")
+	}
+
+	return out, nil
+}
+
+// DefaultAdd adds two numbers
+func DefaultAdd(a, b interface{}) (interface{}, error) {
+	return arithmetic("add", a, b, func(x, y int64) (int64, error) { return x + y, nil }, func(x, y float64) (float64, error) { return x + y, nil })
+}
+
+// DefaultSub subtracts b from a
+func DefaultSub(a, b interface{}) (interface{}, error) {
+	return arithmetic("sub", a, b, func(x, y int64) (int64, error) { return x - y, nil }, func(x, y float64) (float64, error) { return x - y, nil })
+}
+
+// DefaultMul multiplies two numbers
+func DefaultMul(a, b interface{}) (interface{}, error) {
+	return arithmetic("mul", a, b, func(x, y int64) (int64, error) { return x * y, nil }, func(x, y float64) (float64, error) { return x * y, nil })
+}
+
+// DefaultDiv divides a by b. Dividing two integers results in an integer.
+func DefaultDiv(a, b interface{}) (interface{}, error) {
+	return arithmetic(
+		"div", a, b,
+		func(x, y int64) (int64, error) {
+			if y == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return x / y, nil
+		},
+		func(x, y float64) (float64, error) {
+			if y == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return x / y, nil
+		},
+	)
+}
+
+// DefaultMod returns the remainder of dividing a by b
+func DefaultMod(a, b interface{}) (interface{}, error) {
+	return arithmetic(
+		"mod", a, b,
+		func(x, y int64) (int64, error) {
+			if y == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return x % y, nil
+		},
+		func(x, y float64) (float64, error) {
+			if y == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return math.Mod(x, y), nil
+		},
+	)
+}
+
+// arithmetic applies an operation to two numbers. If both can be represented as
+// integers the integer operation is used, otherwise the float operation is.
+// Numeric strings are accepted, since param values are often strings.
+func arithmetic(name string, a, b interface{}, ints func(int64, int64) (int64, error), floats func(float64, float64) (float64, error)) (interface{}, error) {
+	intA, aOK := toInt(a)
+	intB, bOK := toInt(b)
+	if aOK && bOK {
+		out, err := ints(intA, intB)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		return out, nil
+	}
+
+	floatA, err := toFloat(a)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+	floatB, err := toFloat(b)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+
+	out, err := floats(floatA, floatB)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+	return out, nil
+}
+
+func toInt(val interface{}) (int64, bool) {
+	switch v := reflect.ValueOf(val); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); f == math.Trunc(f) {
+			return int64(f), true
+		}
+	case reflect.String:
+		if i, err := strconv.ParseInt(strings.TrimSpace(v.String()), 10, 64); err == nil {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func toFloat(val interface{}) (float64, error) {
+	switch v := reflect.ValueOf(val); v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v.String())
+		}
+		return f, nil
+	}
+
+	if i, ok := toInt(val); ok {
+		return float64(i), nil
+	}
+
+	return 0, fmt.Errorf("%v (%T) is not a number", val, val)
+}
@@ -0,0 +1,119 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extensions_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/render/extensions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDefaultLanguageFunctions tests the pure functions in the default language
+// by rendering them in templates
+func TestDefaultLanguageFunctions(t *testing.T) {
+	t.Parallel()
+
+	language := extensions.DefaultLanguage()
+
+	cases := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{"upper", `{{"abc" | upper}}`, "ABC"},
+		{"lower", `{{"ABC" | lower}}`, "abc"},
+		{"trim", `{{" abc " | trim}}`, "abc"},
+		{"replace", `{{"a-b-c" | replace "-" "_"}}`, "a_b_c"},
+		{"indent", `{{"a\n\nb" | indent 2}}`, "  a\n\n  b"},
+		{"contains string", `{{"abc" | contains "b"}}`, "true"},
+		{"contains list", `{{split "," "a,b" | contains "c"}}`, "false"},
+		{"default empty", `{{"" | default "x"}}`, "x"},
+		{"default set", `{{"y" | default "x"}}`, "y"},
+		{"base64encode", `{{"abc" | base64encode}}`, "YWJj"},
+		{"base64decode", `{{"YWJj" | base64decode}}`, "abc"},
+		{"sha256", `{{"abc" | sha256}}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"toYaml", `{{split "," "a,b" | toYaml}}`, "- a\n- b"},
+		{"fromJson", `{{with fromJson "{\"a\": [1, 2]}"}}{{index .a 1}}{{end}}`, "2"},
+		{"add", `{{add 1 2}}`, "3"},
+		{"add strings", `{{"2" | add 1}}`, "3"},
+		{"add floats", `{{add 1.5 2}}`, "3.5"},
+		{"sub", `{{sub 5 2}}`, "3"},
+		{"mul", `{{mul 2 3}}`, "6"},
+		{"div", `{{div 7 2}}`, "3"},
+		{"mod", `{{mod 7 2}}`, "1"},
+		{"printf", `{{printf "%03d" 7}}`, "007"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out, err := renderTemplate(language, c.template)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, out)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tmpl := range []string{
+			`{{div 1 0}}`,
+			`{{div 1.5 0}}`,
+			`{{mod 1 0}}`,
+			`{{mod 1.5 0}}`,
+			`{{add 1 "x"}}`,
+			`{{"!" | base64decode}}`,
+			`{{fromJson "{"}}`,
+			`{{1 | contains "x"}}`,
+		} {
+			_, err := renderTemplate(language, tmpl)
+			assert.Error(t, err, tmpl)
+		}
+	})
+}
+
+// TestDivisionByZero tests that integer and float division by zero fail the
+// same way
+func TestDivisionByZero(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		name string
+		fn   func(a, b interface{}) (interface{}, error)
+		a, b interface{}
+	}{
+		{"div", extensions.DefaultDiv, 1, 0},
+		{"div", extensions.DefaultDiv, 1.5, 0},
+		{"div", extensions.DefaultDiv, "1.5", "0.0"},
+		{"mod", extensions.DefaultMod, 1, 0},
+		{"mod", extensions.DefaultMod, 1.5, 0.0},
+	} {
+		_, err := c.fn(c.a, c.b)
+		assert.EqualError(t, err, c.name+": division by zero", "%s %v %v", c.name, c.a, c.b)
+	}
+}
+
+// TestMinimalLanguageFunctions tests that the stubs in the minimal language
+// accept any arguments, so that dependency generation doesn't stop early
+func TestMinimalLanguageFunctions(t *testing.T) {
+	t.Parallel()
+
+	language := extensions.MinimalLanguage()
+
+	_, err := renderTemplate(language, `{{param "x" | add 1 | printf "%d"}}{{fromJson (param "y") | toYaml | indent 2}}`)
+	assert.NoError(t, err)
+
+	_, err = renderTemplate(language, `{{param "x" | uper}}`)
+	assert.Error(t, err)
+}
@@ -15,7 +15,10 @@
 // Package platform queries the underlying operating system
 package platform
 
-import "runtime"
+import (
+	"os"
+	"runtime"
+)
 
 // Platform is a struct containing version information for the
 // underlying operating system
@@ -27,6 +30,17 @@ type Platform struct {
 	Name              string
 	PrettyName        string
 	Version           string
+	Cron              bool
+}
+
+// cronDaemons are the locations cron daemons are installed at
+var cronDaemons = []string{
+	"/usr/sbin/cron",
+	"/usr/sbin/crond",
+	"/usr/bin/crond",
+	"/sbin/crond",
+	"/usr/sbin/fcron",
+	"/usr/bin/fcron",
 }
 
 // DefaultPlatform Queries the runtime and then attempts to
@@ -41,5 +55,16 @@ func DefaultPlatform() (*Platform, error) {
 	case "linux":
 		err = platform.LinuxLSB()
 	}
+	platform.DetectCron()
 	return &platform, err
 }
+
+// DetectCron sets Cron if a cron daemon is installed
+func (platform *Platform) DetectCron() {
+	for _, path := range cronDaemons {
+		if _, err := os.Stat(path); err == nil {
+			platform.Cron = true
+			return
+		}
+	}
+}
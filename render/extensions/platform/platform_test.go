@@ -0,0 +1,47 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package platform
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectCron(t *testing.T) {
+	dir, err := ioutil.TempDir("", "converge-platform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(orig []string) { cronDaemons = orig }(cronDaemons)
+	cronDaemons = []string{filepath.Join(dir, "cron")}
+
+	var platform Platform
+	platform.DetectCron()
+	if platform.Cron {
+		t.Error("Cron should be false when no cron daemon is installed")
+	}
+
+	if err := ioutil.WriteFile(cronDaemons[0], nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	platform.DetectCron()
+	if !platform.Cron {
+		t.Error("Cron should be true when a cron daemon is installed")
+	}
+}
@@ -50,7 +50,7 @@ func (v *LazyValue) Value() (resource.Value, bool, error) {
 		if result[2] != nil {
 			resultErr = result[2].(error)
 		}
-		return result[0].(string), result[1].(bool), resultErr
+		return result[0], result[1].(bool), resultErr
 	case ValueThunk:
 		val, found, err := result()
 		v.val = [3]interface{}{val, found, err}
@@ -22,6 +22,7 @@ import (
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/graph/node"
 	"github.com/asteris-llc/converge/graph/node/conditional"
+	"github.com/asteris-llc/converge/parse"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/resource/module"
 	multierror "github.com/hashicorp/go-multierror"
@@ -115,14 +116,32 @@ func (p pipelineGen) prepareNode(ctx context.Context, idi interface{}) (interfac
 						return nil, rendErr
 					}
 				}
-				return res.Prepare(ctx, dynamicRenderer)
+				prepared, prepErr := res.Prepare(ctx, dynamicRenderer)
+				if prepErr != nil {
+					return nil, p.withPosition(prepErr)
+				}
+				return prepared, nil
 			}), nil
 		}
-		return nil, merged
+		return nil, p.withPosition(merged)
 	}
 	return prepared, nil
 }
 
+// withPosition annotates an error with the position of the node in its source
+// file, if it is known
+func (p pipelineGen) withPosition(err error) error {
+	meta, ok := p.Graph.Get(p.ID)
+	if !ok {
+		return err
+	}
+	pos, ok := meta.LookupMetadata(parse.MetaPosition)
+	if !ok {
+		return err
+	}
+	return errors.Wrapf(err, "%v", pos)
+}
+
 func mergeMaybeUnresolvables(err1, err2 error) error {
 	if err1 == nil {
 		return err2
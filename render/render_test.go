@@ -24,6 +24,7 @@ import (
 	"github.com/asteris-llc/converge/helpers/logging"
 	"github.com/asteris-llc/converge/helpers/testing/graphutils"
 	"github.com/asteris-llc/converge/helpers/testing/hclutils"
+	"github.com/asteris-llc/converge/parse"
 	"github.com/asteris-llc/converge/render"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/resource/file/content"
@@ -105,6 +106,64 @@ func TestRenderParam(t *testing.T) {
 	assert.Equal(t, "1", fileContent.Destination)
 }
 
+func TestRenderEach(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	g := graph.New()
+	g.Add(node.New("root", nil))
+
+	alice := node.New(
+		"root/file.content.alice",
+		resource.NewPreparerWithSource(
+			new(content.Preparer),
+			map[string]interface{}{
+				"destination": "{{each.key}}",
+				"content":     "{{each.value.shell}}",
+			},
+		),
+	)
+	alice.AddMetadata(parse.MetaEach, map[string]interface{}{
+		"key":   "alice",
+		"value": map[string]interface{}{"shell": "/bin/zsh"},
+	})
+	g.Add(alice)
+	g.ConnectParent("root", "root/file.content.alice")
+
+	t.Run("with-for-each", func(t *testing.T) {
+		rendered, err := render.Render(context.Background(), g, render.Values{})
+		require.NoError(t, err)
+
+		meta, ok := rendered.Get("root/file.content.alice")
+		require.True(t, ok, `"root/file.content.alice" was missing from the graph`)
+
+		wrapper, ok := meta.Value().(*resource.TaskWrapper)
+		require.True(t, ok, fmt.Sprintf("expected a %T, but got %T", wrapper, meta.Value()))
+
+		fileContent, ok := wrapper.Task.(*content.Content)
+		require.True(t, ok, fmt.Sprintf("expected a %T, but got %T", fileContent, wrapper.Task))
+
+		assert.Equal(t, "alice", fileContent.Destination)
+		assert.Equal(t, "/bin/zsh", fileContent.Content)
+	})
+
+	t.Run("without-for-each", func(t *testing.T) {
+		g := graph.New()
+		g.Add(node.New("root", nil))
+		g.Add(node.New(
+			"root/file.content.x",
+			resource.NewPreparerWithSource(
+				new(content.Preparer),
+				map[string]interface{}{"destination": "{{each.key}}"},
+			),
+		))
+		g.ConnectParent("root", "root/file.content.x")
+
+		_, err := render.Render(context.Background(), g, render.Values{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "each can only be used in resources with for_each")
+	})
+}
+
 func TestRenderValues(t *testing.T) {
 	defer logging.HideLogs(t)()
 
@@ -70,6 +70,7 @@ func (r *Renderer) Render(name, src string) (string, error) {
 	r.Language = r.Language.On("param", r.param)
 	r.Language = r.Language.On("paramList", r.paramList)
 	r.Language = r.Language.On("paramMap", r.paramMap)
+	r.Language = r.Language.On("each", r.each)
 
 	r.Language = r.Language.On(extensions.RefFuncName, r.lookup)
 	out, err := r.Language.Render(r.DotValue, name, src)
@@ -170,6 +171,27 @@ func (r *Renderer) paramRawValue(name string) (interface{}, error) {
 	return param.Val, nil
 }
 
+// each returns the element of a for_each loop that this node was expanded for,
+// as a map with "key" and "value" entries
+func (r *Renderer) each() (map[string]interface{}, error) {
+	meta, ok := r.Graph().Get(r.ID)
+	if !ok {
+		return nil, fmt.Errorf("%s is not in the graph", r.ID)
+	}
+
+	each, ok := meta.LookupMetadata(parse.MetaEach)
+	if !ok {
+		return nil, errors.New("each can only be used in resources with for_each")
+	}
+
+	elem, ok := each.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected for_each element to be a map, got %T", each)
+	}
+
+	return elem, nil
+}
+
 func (r *Renderer) lookup(name string) (string, error) {
 	g := r.Graph()
 	// fully-qualified graph name
@@ -0,0 +1,441 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/systemd/unit"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// State type for Job
+type State string
+
+const (
+	// StatePresent indicates the job should be scheduled
+	StatePresent State = "present"
+
+	// StateAbsent indicates the job should be removed
+	StateAbsent State = "absent"
+)
+
+// Target type for Job
+type Target string
+
+const (
+	// TargetCronD writes the job to a file in /etc/cron.d
+	TargetCronD Target = "cron.d"
+
+	// TargetCrontab adds the job to the crontab of its user
+	TargetCrontab Target = "crontab"
+
+	// TargetTimer writes a systemd timer and service for the job
+	TargetTimer Target = "timer"
+)
+
+// Job manages a scheduled job
+type Job struct {
+	// the name of the job
+	Name string `export:"name"`
+
+	// the cron schedule of the job
+	Schedule string `export:"schedule"`
+
+	// the user the job runs as
+	User string `export:"user"`
+
+	// the command the job runs
+	Command string `export:"command"`
+
+	// the environment of the command
+	Env map[string]string `export:"env"`
+
+	// where the job is scheduled: cron.d, crontab or timer
+	Target Target `export:"target"`
+
+	// whether the job should be present or absent
+	State State `export:"state"`
+
+	// the directory cron.d files are written to
+	CronDir string
+
+	// the directory systemd units are written to
+	UnitDir string
+
+	system System
+}
+
+// jobFile is a file written for a job
+type jobFile struct {
+	Path    string
+	Content string
+}
+
+// plan is the set of changes to a job
+type plan struct {
+	files   []*jobFile
+	crontab *string
+	enable  bool
+}
+
+// NewJob constructs and returns a new Job
+func NewJob(system System) *Job {
+	return &Job{
+		system: system,
+	}
+}
+
+// Check if the job has to be scheduled or removed
+func (j *Job) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	if _, err := j.plan(status); err != nil {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, err
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// Apply schedules or removes the job
+func (j *Job) Apply(context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	p, err := j.plan(status)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if j.Target == TargetTimer && j.State == StateAbsent && len(p.files) > 0 {
+		// the timer may already be stopped, so a failure here is not fatal
+		if err := j.system.DisableTimer(j.unitName() + ".timer"); err == nil {
+			status.AddMessage("disabled " + j.unitName() + ".timer")
+		}
+	}
+
+	for _, f := range p.files {
+		if err := f.write(j.State == StateAbsent); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+	}
+
+	if p.crontab != nil {
+		if err := j.system.WriteCrontab(j.User, *p.crontab); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, errors.Wrapf(err, "cannot write crontab for %s", j.User)
+		}
+		status.AddMessage("updated crontab for " + j.User)
+	}
+
+	if j.Target == TargetTimer && (len(p.files) > 0 || p.enable) {
+		if err := j.system.DaemonReload(); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		if j.State != StateAbsent {
+			if err := j.system.EnableTimer(j.unitName() + ".timer"); err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, err
+			}
+			status.AddMessage("enabled " + j.unitName() + ".timer")
+		}
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// plan adds the differences between the current and desired state of the job
+// to the status, and returns the changes that have to be made
+func (j *Job) plan(status *resource.Status) (*plan, error) {
+	p := &plan{}
+
+	if j.Target == TargetCrontab {
+		current, err := j.system.ReadCrontab(j.User)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read crontab for %s", j.User)
+		}
+
+		block := ""
+		if j.State != StateAbsent {
+			block = j.crontabBlock()
+		}
+		updated, err := replaceBlock(current, j.blockMarker("BEGIN"), j.blockMarker("END"), block)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot update crontab for %s", j.User)
+		}
+		if updated != current {
+			status.Differences["crontab for "+j.User] = resource.NewUnifiedDiff(current, updated)
+			p.crontab = &updated
+		}
+		return p, nil
+	}
+
+	files, err := j.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		changed, err := f.check(status, j.State == StateAbsent)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			p.files = append(p.files, f)
+		}
+	}
+
+	if j.Target == TargetTimer && j.State != StateAbsent && len(p.files) == 0 {
+		active, err := j.system.TimerActive(j.unitName() + ".timer")
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot check %s.timer", j.unitName())
+		}
+		if !active {
+			status.AddDifference(j.unitName()+".timer", "inactive", "active", "")
+			p.enable = true
+		}
+	}
+
+	return p, nil
+}
+
+// files returns the files written for the cron.d and timer targets
+func (j *Job) files() ([]*jobFile, error) {
+	if j.Target == TargetCronD {
+		return []*jobFile{{Path: filepath.Join(j.CronDir, j.Name), Content: j.cronDContent()}}, nil
+	}
+
+	service := &jobFile{Path: filepath.Join(j.UnitDir, j.unitName()+".service")}
+	timer := &jobFile{Path: filepath.Join(j.UnitDir, j.unitName()+".timer")}
+	if j.State == StateAbsent {
+		return []*jobFile{timer, service}, nil
+	}
+
+	schedule, err := ParseSchedule(j.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	timerOptions, err := schedule.TimerOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	description := map[string]string{"Description": "cron.job " + j.Name}
+	serviceOptions := map[string]string{
+		"Type":      "oneshot",
+		"User":      j.User,
+		"ExecStart": `/bin/sh -c "` + unitEscaper.Replace(j.Command) + `"`,
+	}
+	if env := j.sortedEnv(); len(env) > 0 {
+		var lines []string
+		for _, pair := range env {
+			lines = append(lines, `"`+unitEscaper.Replace(pair[0]+"="+pair[1])+`"`)
+		}
+		serviceOptions["Environment"] = strings.Join(lines, "\n")
+	}
+
+	service.Content = unit.RenderSections([]unit.Section{
+		{Name: "Unit", Options: description},
+		{Name: "Service", Options: serviceOptions},
+	})
+	timer.Content = unit.RenderSections([]unit.Section{
+		{Name: "Unit", Options: description},
+		{Name: "Timer", Options: timerOptions},
+		{Name: "Install", Options: map[string]string{"WantedBy": "timers.target"}},
+	})
+
+	return []*jobFile{service, timer}, nil
+}
+
+// unitEscaper escapes a value for a double-quoted string in a unit file.
+// Percent signs start specifiers and dollar signs start variables.
+var unitEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$")
+
+// cronEscaper escapes a command for cron, where an unescaped percent sign
+// starts the input of the command
+var cronEscaper = strings.NewReplacer("%", `\%`)
+
+// cronDContent renders the job as a file in /etc/cron.d
+func (j *Job) cronDContent() string {
+	lines := []string{fmt.Sprintf("# %s is managed by converge", j.Name)}
+	for _, pair := range j.sortedEnv() {
+		lines = append(lines, pair[0]+"="+pair[1])
+	}
+	lines = append(lines, fmt.Sprintf("%s %s %s", j.Schedule, j.User, cronEscaper.Replace(j.Command)))
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// crontabBlock renders the job as a block in a user crontab. Variables set in
+// a crontab apply to every job after them, so the environment is set on the
+// command instead.
+func (j *Job) crontabBlock() string {
+	command := j.Command
+	if env := j.sortedEnv(); len(env) > 0 {
+		var assignments []string
+		for _, pair := range env {
+			assignments = append(assignments, pair[0]+"="+shellQuote(pair[1]))
+		}
+		command = strings.Join(assignments, " ") + " " + command
+	}
+
+	return strings.Join([]string{
+		j.blockMarker("BEGIN"),
+		j.Schedule + " " + cronEscaper.Replace(command),
+		j.blockMarker("END"),
+	}, "\n") + "\n"
+}
+
+// blockMarker returns the comment around the job in a user crontab
+func (j *Job) blockMarker(which string) string {
+	return fmt.Sprintf("# %s converge cron.job %s", which, j.Name)
+}
+
+// unitName returns the name of the systemd units for the job
+func (j *Job) unitName() string {
+	return "cron-" + j.Name
+}
+
+// sortedEnv returns the environment as sorted pairs
+func (j *Job) sortedEnv() [][2]string {
+	var keys []string
+	for key := range j.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs [][2]string
+	for _, key := range keys {
+		pairs = append(pairs, [2]string{key, j.Env[key]})
+	}
+	return pairs
+}
+
+// replaceBlock replaces the lines from begin to end in content with block, or
+// appends block if content doesn't have those lines. An empty block removes
+// the lines. It is an error for content to have begin without end, since the
+// rest of the crontab would be taken as part of the block.
+func replaceBlock(content, begin, end, block string) (string, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var out []string
+	inserted, inBlock := false, false
+	for _, line := range lines {
+		switch {
+		case strings.TrimRight(line, "\n") == begin:
+			inBlock = true
+		case inBlock && strings.TrimRight(line, "\n") == end:
+			inBlock = false
+			if !inserted && block != "" {
+				out = append(out, block)
+			}
+			inserted = true
+		case !inBlock:
+			out = append(out, line)
+		}
+	}
+	if inBlock {
+		return "", fmt.Errorf("found %q but not %q", begin, end)
+	}
+
+	if !inserted && block != "" {
+		if len(out) > 0 && !strings.HasSuffix(out[len(out)-1], "\n") {
+			out = append(out, "\n")
+		}
+		out = append(out, block)
+	}
+	return strings.Join(out, ""), nil
+}
+
+// shellQuote quotes a value for sh
+func shellQuote(val string) string {
+	return "'" + strings.Replace(val, "'", `'\''`, -1) + "'"
+}
+
+// check adds the difference between the file on disk and the wanted content to
+// the status, or between the file and no file if absent is true. It returns
+// true if the file has to be changed.
+func (f *jobFile) check(status *resource.Status, absent bool) (bool, error) {
+	actual, err := ioutil.ReadFile(f.Path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return false, errors.Wrapf(err, "cannot read %q", f.Path)
+	}
+
+	switch {
+	case absent && !exists:
+		return false, nil
+	case absent:
+		status.AddDifference(f.Path, "<present>", "<absent>", "")
+	case exists && string(actual) == f.Content:
+		return false, nil
+	default:
+		diff := resource.NewUnifiedDiff(string(actual), f.Content)
+		if !exists {
+			diff.Placeholder = "<file-missing>"
+		}
+		status.Differences[f.Path] = diff
+	}
+	return true, nil
+}
+
+// write writes the file, or removes it if absent is true
+func (f *jobFile) write(absent bool) error {
+	if absent {
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "cannot remove %q", f.Path)
+		}
+		return nil
+	}
+
+	// write to a temporary file next to the destination and rename it, so cron
+	// and systemd never read a partially written file. cron skips files whose
+	// names start with a dot, and systemd doesn't load a name without a unit
+	// suffix.
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), "."+filepath.Base(f.Path)+".")
+	if err != nil {
+		return errors.Wrapf(err, "cannot write %q", f.Path)
+	}
+	name := tmp.Name()
+
+	_, err = tmp.WriteString(f.Content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(name, 0644)
+	}
+	if err == nil {
+		err = os.Rename(name, f.Path)
+	}
+	if err != nil {
+		os.Remove(name)
+		return errors.Wrapf(err, "cannot write %q", f.Path)
+	}
+	return nil
+}
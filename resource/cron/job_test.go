@@ -0,0 +1,283 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/cron"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestJobInterface tests that Job is properly implemented
+func TestJobInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Task)(nil), new(cron.Job))
+}
+
+// TestJobCronD tests jobs in /etc/cron.d
+func TestJobCronD(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-cron")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	job, m := newJob(dir, cron.TargetCronD)
+	path := filepath.Join(dir, "backup")
+
+	status, err := job.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, status.HasChanges())
+	assert.Equal(t, "<file-missing>", status.Diffs()[path].Original())
+
+	_, err = job.Apply(context.Background())
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"# backup is managed by converge\nBUCKET=backups\nPATH=/usr/local/bin:/usr/bin:/bin\n0 4 * * * backup /usr/local/bin/backup --date $(date +\\%F)\n",
+		string(content),
+	)
+
+	status, err = job.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, status.HasChanges())
+
+	job.State = cron.StateAbsent
+	status, err = job.Apply(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "<absent>", status.Diffs()[path].Current())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	m.AssertNotCalled(t, "ReadCrontab", mock.Anything)
+}
+
+// TestJobCrontab tests jobs in a user crontab
+func TestJobCrontab(t *testing.T) {
+	t.Parallel()
+
+	block := "# BEGIN converge cron.job backup\n" +
+		"0 4 * * * BUCKET='backups' PATH='/usr/local/bin:/usr/bin:/bin' /usr/local/bin/backup --date $(date +\\%F)\n" +
+		"# END converge cron.job backup\n"
+	other := "MAILTO=ops@example.com\n*/5 * * * * /usr/bin/other\n"
+
+	t.Run("add", func(t *testing.T) {
+		job, m := newJob("", cron.TargetCrontab)
+		m.On("ReadCrontab", "backup").Return(other, nil)
+		m.On("WriteCrontab", "backup", mock.Anything).Return(nil)
+
+		status, err := job.Apply(context.Background())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		m.AssertCalled(t, "WriteCrontab", "backup", other+block)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		job, m := newJob("", cron.TargetCrontab)
+		old := "# BEGIN converge cron.job backup\n0 3 * * * /bin/old\n# END converge cron.job backup\n"
+		m.On("ReadCrontab", "backup").Return(old+other, nil)
+		m.On("WriteCrontab", "backup", mock.Anything).Return(nil)
+
+		_, err := job.Apply(context.Background())
+		require.NoError(t, err)
+		m.AssertCalled(t, "WriteCrontab", "backup", block+other)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		job, m := newJob("", cron.TargetCrontab)
+		m.On("ReadCrontab", "backup").Return(other+block, nil)
+
+		status, err := job.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		job, m := newJob("", cron.TargetCrontab)
+		job.State = cron.StateAbsent
+		m.On("ReadCrontab", "backup").Return(block+other, nil)
+		m.On("WriteCrontab", "backup", mock.Anything).Return(nil)
+
+		_, err := job.Apply(context.Background())
+		require.NoError(t, err)
+		m.AssertCalled(t, "WriteCrontab", "backup", other)
+	})
+
+	t.Run("missing end marker", func(t *testing.T) {
+		job, m := newJob("", cron.TargetCrontab)
+		m.On("ReadCrontab", "backup").Return("# BEGIN converge cron.job backup\n0 3 * * * /bin/old\n"+other, nil)
+
+		status, err := job.Check(context.Background(), nil)
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+		m.AssertNotCalled(t, "WriteCrontab", mock.Anything, mock.Anything)
+	})
+
+	t.Run("error reading crontab", func(t *testing.T) {
+		job, m := newJob("", cron.TargetCrontab)
+		m.On("ReadCrontab", "backup").Return("", errors.New("crontab: permission denied"))
+
+		status, err := job.Check(context.Background(), nil)
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+	})
+}
+
+// TestJobTimer tests jobs scheduled with systemd timers
+func TestJobTimer(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-cron")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	job, m := newJob(dir, cron.TargetTimer)
+	m.On("DaemonReload").Return(nil)
+	m.On("EnableTimer", "cron-backup.timer").Return(nil)
+	m.On("DisableTimer", "cron-backup.timer").Return(nil)
+	m.On("TimerActive", "cron-backup.timer").Return(true, nil)
+
+	_, err = job.Apply(context.Background())
+	require.NoError(t, err)
+	m.AssertCalled(t, "DaemonReload")
+	m.AssertCalled(t, "EnableTimer", "cron-backup.timer")
+
+	service, err := ioutil.ReadFile(filepath.Join(dir, "cron-backup.service"))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"[Unit]\nDescription=cron.job backup\n\n[Service]\n"+
+			"Environment=\"BUCKET=backups\"\nEnvironment=\"PATH=/usr/local/bin:/usr/bin:/bin\"\n"+
+			"ExecStart=/bin/sh -c \"/usr/local/bin/backup --date $$(date +%%F)\"\nType=oneshot\nUser=backup\n",
+		string(service),
+	)
+
+	timer, err := ioutil.ReadFile(filepath.Join(dir, "cron-backup.timer"))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"[Unit]\nDescription=cron.job backup\n\n[Timer]\nOnCalendar=*-*-* 04:00:00\n\n[Install]\nWantedBy=timers.target\n",
+		string(timer),
+	)
+
+	status, err := job.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, status.HasChanges())
+
+	job.State = cron.StateAbsent
+	_, err = job.Apply(context.Background())
+	require.NoError(t, err)
+	m.AssertCalled(t, "DisableTimer", "cron-backup.timer")
+	_, err = os.Stat(filepath.Join(dir, "cron-backup.timer"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestJobTimerInactive tests that an inactive timer is started
+func TestJobTimerInactive(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-cron")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	setup, sm := newJob(dir, cron.TargetTimer)
+	sm.On("DaemonReload").Return(nil)
+	sm.On("EnableTimer", mock.Anything).Return(nil)
+	_, err = setup.Apply(context.Background())
+	require.NoError(t, err)
+
+	job, m := newJob(dir, cron.TargetTimer)
+	m.On("TimerActive", "cron-backup.timer").Return(false, nil)
+	m.On("DaemonReload").Return(nil)
+	m.On("EnableTimer", "cron-backup.timer").Return(nil)
+
+	status, err := job.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", status.Diffs()["cron-backup.timer"].Original())
+
+	_, err = job.Apply(context.Background())
+	require.NoError(t, err)
+	m.AssertCalled(t, "EnableTimer", "cron-backup.timer")
+}
+
+// newJob creates a job writing to dir
+func newJob(dir string, target cron.Target) (*cron.Job, *MockSystem) {
+	m := &MockSystem{}
+	job := cron.NewJob(m)
+	job.Name = "backup"
+	job.Schedule = "0 4 * * *"
+	job.User = "backup"
+	job.Command = "/usr/local/bin/backup --date $(date +%F)"
+	job.Env = map[string]string{"PATH": "/usr/local/bin:/usr/bin:/bin", "BUCKET": "backups"}
+	job.Target = target
+	job.State = cron.StatePresent
+	job.CronDir = dir
+	job.UnitDir = dir
+	return job, m
+}
+
+// MockSystem is a mock implementation of cron.System
+type MockSystem struct {
+	mock.Mock
+}
+
+// ReadCrontab reads a crontab
+func (m *MockSystem) ReadCrontab(user string) (string, error) {
+	args := m.Called(user)
+	return args.String(0), args.Error(1)
+}
+
+// WriteCrontab writes a crontab
+func (m *MockSystem) WriteCrontab(user, content string) error {
+	args := m.Called(user, content)
+	return args.Error(0)
+}
+
+// TimerActive checks a timer
+func (m *MockSystem) TimerActive(name string) (bool, error) {
+	args := m.Called(name)
+	return args.Bool(0), args.Error(1)
+}
+
+// EnableTimer enables a timer
+func (m *MockSystem) EnableTimer(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+// DisableTimer disables a timer
+func (m *MockSystem) DisableTimer(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+// DaemonReload reloads systemd
+func (m *MockSystem) DaemonReload() error {
+	args := m.Called()
+	return args.Error(0)
+}
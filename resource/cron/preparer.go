@@ -0,0 +1,182 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/render/extensions/platform"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/systemd/unit"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// DefaultCronDir is where jobs are written unless they are added to a crontab
+const DefaultCronDir = "/etc/cron.d"
+
+var (
+	// cron ignores files in /etc/cron.d whose names contain a dot, so job names
+	// are limited to characters it reads
+	validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	validEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// detectPlatform is used to find out whether cron is installed
+var detectPlatform = platform.DefaultPlatform
+
+// newSystemdExecutor connects to systemd for jobs scheduled with a timer
+var newSystemdExecutor = unit.NewExecutor
+
+// Preparer for Cron Job
+//
+// Cron Job schedules a command with cron. By default the job is written to
+// /etc/cron.d/<name>; with `crontab` it is added to the crontab of its user
+// instead, between comments that mark it as managed by converge. With
+// `systemd_timer`, hosts without a cron daemon get an equivalent systemd timer
+// and service named `cron-<name>` instead. Whether cron is installed is
+// detected through `platform` when the job is prepared.
+type Preparer struct {
+	// Name of the job. It may only contain letters, digits, dashes and
+	// underscores, since cron ignores other files in /etc/cron.d.
+	Name string `hcl:"name" required:"true" nonempty:"true"`
+
+	// Schedule of the job, either the five fields minute, hour, day of month,
+	// month and day of week (like "*/15 * * * *" or "0 4 * * mon-fri"), or a
+	// shorthand like "@daily" or "@reboot". Required unless state is absent.
+	Schedule string `hcl:"schedule" nonempty:"true"`
+
+	// User the job runs as. It cannot contain whitespace.
+	// The default value is root.
+	User string `hcl:"user" nonempty:"true"`
+
+	// Command the job runs with /bin/sh. Percent signs are escaped, so they are
+	// passed to the command instead of starting its input. Required unless
+	// state is absent.
+	Command string `hcl:"command" nonempty:"true"`
+
+	// Env sets environment variables for the command.
+	Env map[string]string `hcl:"env"`
+
+	// Crontab adds the job to the crontab of the user instead of writing a
+	// file to /etc/cron.d.
+	Crontab bool `hcl:"crontab"`
+
+	// SystemdTimer schedules the job with a systemd timer on hosts where cron
+	// is not installed. Schedules that restrict both the day of month and the
+	// day of week can't be expressed as a timer.
+	SystemdTimer bool `hcl:"systemd_timer"`
+
+	// State of the job. Present means the job will be scheduled; Absent means
+	// it will be removed.
+	// The default value is present.
+	State State `hcl:"state" valid_values:"present,absent"`
+}
+
+// Prepare a new task
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if !validName.MatchString(p.Name) {
+		return nil, fmt.Errorf("cron job \"name\" may only contain letters, digits, dashes and underscores")
+	}
+
+	if p.User == "" {
+		p.User = "root"
+	}
+	if strings.ContainsAny(p.User, " \t\r\n") {
+		return nil, fmt.Errorf("cron job \"user\" parameter cannot contain whitespace")
+	}
+
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
+	system := &ExecSystem{}
+	job := NewJob(system)
+	job.Name = p.Name
+	job.User = p.User
+	job.Command = p.Command
+	job.Env = p.Env
+	job.State = p.State
+	job.CronDir = DefaultCronDir
+	job.UnitDir = unit.DefaultUnitDirectory
+
+	job.Target = TargetCronD
+	if p.Crontab {
+		job.Target = TargetCrontab
+	}
+
+	if p.SystemdTimer {
+		plat, err := detectPlatform()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot detect platform")
+		}
+		if !plat.Cron {
+			job.Target = TargetTimer
+		}
+	}
+
+	if job.Target == TargetTimer {
+		executor, err := newSystemdExecutor()
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot connect to systemd")
+		}
+		system.Systemd = executor
+	}
+
+	if p.State == StateAbsent {
+		return job, nil
+	}
+
+	if p.Schedule == "" {
+		return nil, fmt.Errorf("cron job \"schedule\" parameter is required")
+	}
+	schedule, err := ParseSchedule(p.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	job.Schedule = schedule.Expression
+
+	if job.Target == TargetTimer {
+		if _, err := schedule.TimerOptions(); err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(p.Command) == "" {
+		return nil, fmt.Errorf("cron job \"command\" parameter is required")
+	}
+	if strings.Contains(p.Command, "\n") {
+		return nil, fmt.Errorf("cron job \"command\" parameter cannot contain newlines")
+	}
+
+	for key, val := range p.Env {
+		if !validEnvName.MatchString(key) {
+			return nil, fmt.Errorf("cron job has invalid environment variable name %q", key)
+		}
+		if strings.Contains(val, "\n") {
+			return nil, fmt.Errorf("cron job environment variable %q cannot contain newlines", key)
+		}
+	}
+
+	return job, nil
+}
+
+func init() {
+	registry.Register("cron.job", (*Preparer)(nil), (*Job)(nil))
+}
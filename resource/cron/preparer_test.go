@@ -0,0 +1,119 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/render/extensions/platform"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/systemd/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPreparerInterface tests that the Preparer interface is properly
+// implemented
+func TestPreparerInterface(t *testing.T) {
+	assert.Implements(t, (*resource.Resource)(nil), new(Preparer))
+}
+
+// TestPrepare tests preparing jobs for each target
+func TestPrepare(t *testing.T) {
+	defer func(orig func() (*platform.Platform, error)) { detectPlatform = orig }(detectPlatform)
+	defer func(orig func() (unit.SystemdExecutor, error)) { newSystemdExecutor = orig }(newSystemdExecutor)
+	newSystemdExecutor = func() (unit.SystemdExecutor, error) { return nil, nil }
+
+	withCron := func(installed bool) {
+		detectPlatform = func() (*platform.Platform, error) {
+			return &platform.Platform{OS: "linux", Cron: installed}, nil
+		}
+	}
+
+	t.Run("cron.d", func(t *testing.T) {
+		p := &Preparer{Name: "backup", Schedule: "0  4 * * *", Command: "/usr/local/bin/backup"}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		job := task.(*Job)
+		assert.Equal(t, TargetCronD, job.Target)
+		assert.Equal(t, "root", job.User)
+		assert.Equal(t, StatePresent, job.State)
+		assert.Equal(t, "0 4 * * *", job.Schedule)
+		assert.Equal(t, "/etc/cron.d", job.CronDir)
+	})
+
+	t.Run("crontab", func(t *testing.T) {
+		p := &Preparer{Name: "backup", Schedule: "@daily", Command: "true", User: "backup", Crontab: true}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		assert.Equal(t, TargetCrontab, task.(*Job).Target)
+	})
+
+	t.Run("timer when cron is missing", func(t *testing.T) {
+		withCron(false)
+		p := &Preparer{Name: "backup", Schedule: "@daily", Command: "true", SystemdTimer: true}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		assert.Equal(t, TargetTimer, task.(*Job).Target)
+		assert.Equal(t, "/etc/systemd/system", task.(*Job).UnitDir)
+	})
+
+	t.Run("cron when cron is installed", func(t *testing.T) {
+		withCron(true)
+		p := &Preparer{Name: "backup", Schedule: "@daily", Command: "true", SystemdTimer: true}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		assert.Equal(t, TargetCronD, task.(*Job).Target)
+	})
+
+	t.Run("schedule not expressible as timer", func(t *testing.T) {
+		withCron(false)
+		p := &Preparer{Name: "backup", Schedule: "0 0 1 * mon", Command: "true", SystemdTimer: true}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		assert.Error(t, err)
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		p := &Preparer{Name: "backup", State: StateAbsent}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		assert.Equal(t, StateAbsent, task.(*Job).State)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, p := range map[string]*Preparer{
+			"name with dot":    {Name: "backup.sh", Schedule: "@daily", Command: "true"},
+			"missing schedule": {Name: "backup", Command: "true"},
+			"invalid schedule": {Name: "backup", Schedule: "61 * * * *", Command: "true"},
+			"missing command":  {Name: "backup", Schedule: "@daily"},
+			"multiline":        {Name: "backup", Schedule: "@daily", Command: "true\nfalse"},
+			"env name":         {Name: "backup", Schedule: "@daily", Command: "true", Env: map[string]string{"A-B": "c"}},
+			"user with space":  {Name: "backup", Schedule: "@daily", Command: "true", User: "root true"},
+			"user newline":     {Name: "backup", Schedule: "@daily", Command: "true", User: "root\n* * * * * root id"},
+			"absent user":      {Name: "backup", State: StateAbsent, User: "a b"},
+		} {
+			_, err := p.Prepare(context.Background(), fakerenderer.New())
+			assert.Error(t, err, name)
+		}
+	})
+}
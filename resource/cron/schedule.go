@@ -0,0 +1,223 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// macros are the shorthand schedules cron understands, with the equivalent
+// systemd calendar event
+var macros = map[string]string{
+	"@yearly":   "yearly",
+	"@annually": "yearly",
+	"@monthly":  "monthly",
+	"@weekly":   "weekly",
+	"@daily":    "daily",
+	"@midnight": "daily",
+	"@hourly":   "hourly",
+	"@reboot":   "",
+}
+
+// field describes one of the five fields of a schedule
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	months = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	days   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+	fields = []field{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31},
+		{name: "month", min: 1, max: 12, names: months},
+		{name: "day of week", min: 0, max: 7, names: days},
+	}
+)
+
+// Schedule is a parsed cron schedule
+type Schedule struct {
+	// Expression is the schedule as it is written to cron
+	Expression string
+
+	// macro is set if the schedule is a shorthand like @daily
+	macro string
+
+	// values are the expanded values of each field. A field given as "*" is
+	// nil.
+	values [][]int
+}
+
+// ParseSchedule parses and validates a cron schedule, either five fields
+// (minute, hour, day of month, month, and day of week) or a shorthand like
+// @daily
+func ParseSchedule(expr string) (*Schedule, error) {
+	expr = strings.Join(strings.Fields(expr), " ")
+
+	if strings.HasPrefix(expr, "@") {
+		if _, ok := macros[expr]; !ok {
+			return nil, fmt.Errorf("unknown schedule %q", expr)
+		}
+		return &Schedule{Expression: expr, macro: expr}, nil
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("schedule %q must have 5 fields, but has %d", expr, len(parts))
+	}
+
+	schedule := &Schedule{Expression: expr, values: make([][]int, len(fields))}
+	for i, part := range parts {
+		if part == "*" {
+			continue
+		}
+		values, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %s", expr, err)
+		}
+		schedule.values[i] = values
+	}
+
+	return schedule, nil
+}
+
+// parseField expands a field like "1-10/2,15" into its values
+func parseField(text string, f field) ([]int, error) {
+	set := map[int]struct{}{}
+
+	for _, part := range strings.Split(text, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[idx+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q in %s", part[idx+1:], f.name)
+			}
+			part = part[:idx]
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0], f); err != nil {
+				return nil, err
+			}
+			if high, err = parseValue(bounds[1], f); err != nil {
+				return nil, err
+			}
+			if low > high {
+				return nil, fmt.Errorf("invalid range %q in %s", part, f.name)
+			}
+		default:
+			value, err := parseValue(part, f)
+			if err != nil {
+				return nil, err
+			}
+			low = value
+			if step == 1 {
+				// a single value without a step
+				high = value
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			if f.max == 7 && value == 7 {
+				// sunday can be given as 0 or 7
+				set[0] = struct{}{}
+				continue
+			}
+			set[value] = struct{}{}
+		}
+	}
+
+	values := make([]int, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// parseValue parses a single number or name in a field
+func parseValue(text string, f field) (int, error) {
+	for i, name := range f.names {
+		if strings.ToLower(text) == name {
+			return i + f.min, nil
+		}
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid %s %q, must be between %d and %d", f.name, text, f.min, f.max)
+	}
+	return value, nil
+}
+
+// TimerOptions returns the [Timer] options of a systemd timer that runs at the
+// same times as the schedule
+func (s *Schedule) TimerOptions() (map[string]string, error) {
+	if s.macro == "@reboot" {
+		return map[string]string{"OnBootSec": "1min"}, nil
+	}
+	if s.macro != "" {
+		return map[string]string{"OnCalendar": macros[s.macro]}, nil
+	}
+
+	// cron runs a job when either the day of month or the day of week match,
+	// if both are restricted, while systemd requires both to match
+	if s.values[2] != nil && s.values[4] != nil {
+		return nil, fmt.Errorf("schedule %q restricts both the day of month and the day of week, which a systemd timer can't express", s.Expression)
+	}
+
+	var calendar string
+	if s.values[4] != nil {
+		var names []string
+		for _, day := range s.values[4] {
+			names = append(names, strings.Title(days[day]))
+		}
+		calendar = strings.Join(names, ",") + " "
+	}
+	calendar += fmt.Sprintf(
+		"*-%s-%s %s:%s:00",
+		calendarField(s.values[3]),
+		calendarField(s.values[2]),
+		calendarField(s.values[1]),
+		calendarField(s.values[0]),
+	)
+
+	return map[string]string{"OnCalendar": calendar}, nil
+}
+
+// calendarField formats the values of a field as a systemd calendar component
+func calendarField(values []int) string {
+	if values == nil {
+		return "*"
+	}
+	var out []string
+	for _, value := range values {
+		out = append(out, fmt.Sprintf("%02d", value))
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,94 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/cron"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSchedule tests parsing valid and invalid schedules
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0 4 * * mon-fri",
+		"30 2 1,15 * *",
+		"0 0 1 jan,jul *",
+		"5-55/10 8-18 * * 7",
+		"@daily",
+		"@reboot",
+	} {
+		_, err := cron.ParseSchedule(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	for _, expr := range []string{
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@sometimes",
+	} {
+		_, err := cron.ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+
+	t.Run("normalizes whitespace", func(t *testing.T) {
+		schedule, err := cron.ParseSchedule("  0  4 * *   *")
+		require.NoError(t, err)
+		assert.Equal(t, "0 4 * * *", schedule.Expression)
+	})
+}
+
+// TestTimerOptions tests converting schedules to systemd timers
+func TestTimerOptions(t *testing.T) {
+	t.Parallel()
+
+	for expr, expected := range map[string]map[string]string{
+		"@daily":          {"OnCalendar": "daily"},
+		"@annually":       {"OnCalendar": "yearly"},
+		"@reboot":         {"OnBootSec": "1min"},
+		"* * * * *":       {"OnCalendar": "*-*-* *:*:00"},
+		"*/15 * * * *":    {"OnCalendar": "*-*-* *:00,15,30,45:00"},
+		"0 4 * * mon-fri": {"OnCalendar": "Mon,Tue,Wed,Thu,Fri *-*-* 04:00:00"},
+		"30 2 1,15 jan *": {"OnCalendar": "*-01-01,15 02:30:00"},
+		"0 12 * * 7":      {"OnCalendar": "Sun *-*-* 12:00:00"},
+	} {
+		schedule, err := cron.ParseSchedule(expr)
+		require.NoError(t, err, expr)
+		options, err := schedule.TimerOptions()
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, options, expr)
+	}
+
+	t.Run("day of month and day of week", func(t *testing.T) {
+		schedule, err := cron.ParseSchedule("0 0 1 * mon")
+		require.NoError(t, err)
+		_, err = schedule.TimerOptions()
+		assert.Error(t, err)
+	})
+}
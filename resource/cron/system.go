@@ -0,0 +1,119 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource/systemd/unit"
+)
+
+var errNoSystemd = errors.New("systemd timers are not available")
+
+// System manages crontabs and systemd timers
+type System interface {
+	ReadCrontab(user string) (string, error)
+	WriteCrontab(user, content string) error
+	TimerActive(name string) (bool, error)
+	EnableTimer(name string) error
+	DisableTimer(name string) error
+	DaemonReload() error
+}
+
+// ExecSystem implements System with the crontab command and the systemd D-Bus
+// API
+type ExecSystem struct {
+	// Systemd manages timers. It is only connected for jobs that use a timer.
+	Systemd unit.SystemdExecutor
+}
+
+// ReadCrontab reads the crontab of a user. A user without a crontab has an
+// empty one.
+func (ExecSystem) ReadCrontab(user string) (string, error) {
+	out, err := exec.Command("crontab", "-u", user, "-l").CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "no crontab for") {
+			return "", nil
+		}
+		return "", fmt.Errorf("crontab: %s", strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// WriteCrontab replaces the crontab of a user
+func (ExecSystem) WriteCrontab(user, content string) error {
+	cmd := exec.Command("crontab", "-u", user, "-")
+	cmd.Stdin = strings.NewReader(content)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("crontab: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// TimerActive checks whether a timer is both enabled and started
+func (s ExecSystem) TimerActive(name string) (bool, error) {
+	if s.Systemd == nil {
+		return false, errNoSystemd
+	}
+
+	state, err := s.Systemd.UnitFileState(name)
+	if err != nil {
+		return false, err
+	}
+	if state != "enabled" {
+		return false, nil
+	}
+
+	u, err := s.Systemd.QueryUnit(name, false)
+	if err != nil {
+		return false, err
+	}
+	return u.ActiveState == "active", nil
+}
+
+// EnableTimer enables and starts a timer
+func (s ExecSystem) EnableTimer(name string) error {
+	if s.Systemd == nil {
+		return errNoSystemd
+	}
+
+	if err := s.Systemd.EnableUnitFile(name); err != nil {
+		return err
+	}
+	return s.Systemd.StartUnit(&unit.Unit{Name: name})
+}
+
+// DisableTimer disables and stops a timer
+func (s ExecSystem) DisableTimer(name string) error {
+	if s.Systemd == nil {
+		return errNoSystemd
+	}
+
+	if err := s.Systemd.StopUnit(&unit.Unit{Name: name}); err != nil {
+		return err
+	}
+	return s.Systemd.DisableUnitFile(name)
+}
+
+// DaemonReload reloads the systemd configuration
+func (s ExecSystem) DaemonReload() error {
+	if s.Systemd == nil {
+		return errNoSystemd
+	}
+	return s.Systemd.DaemonReload()
+}
@@ -0,0 +1,67 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import "fmt"
+
+// MetaOnFailure is the graph node metadata key for the failure mode of a
+// node, set from the "on_failure" field that every resource accepts
+const MetaOnFailure = "on-failure"
+
+// FailureMode controls what happens when a resource fails
+type FailureMode string
+
+const (
+	// FailureFail fails the run and skips everything that depends on the
+	// failed resource. This is the default.
+	FailureFail FailureMode = "fail"
+
+	// FailureContinue skips everything that depends on the failed resource,
+	// but does not fail the run or the modules containing it
+	FailureContinue FailureMode = "continue"
+
+	// FailureWarn reports the failure, but lets everything that depends on the
+	// failed resource run as if it had succeeded
+	FailureWarn FailureMode = "warn"
+)
+
+// ParseFailureMode parses the raw value of the "on_failure" field
+func ParseFailureMode(val interface{}) (FailureMode, error) {
+	str, ok := val.(string)
+	if !ok {
+		return FailureFail, fmt.Errorf("on_failure must be a string, got %T", val)
+	}
+
+	switch mode := FailureMode(str); mode {
+	case FailureFail, FailureContinue, FailureWarn:
+		return mode, nil
+	}
+
+	return FailureFail, fmt.Errorf("on_failure must be one of fail, continue, or warn, got %q", str)
+}
+
+// FailureModeFor gets the failure mode stored in the metadata of a graph node,
+// defaulting to FailureFail
+func FailureModeFor(meta metadataLookup) FailureMode {
+	raw, ok := meta.LookupMetadata(MetaOnFailure)
+	if !ok {
+		return FailureFail
+	}
+
+	if mode, ok := raw.(FailureMode); ok {
+		return mode
+	}
+	return FailureFail
+}
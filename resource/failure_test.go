@@ -0,0 +1,60 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/graph/node"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFailureMode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		for _, mode := range []resource.FailureMode{resource.FailureFail, resource.FailureContinue, resource.FailureWarn} {
+			parsed, err := resource.ParseFailureMode(string(mode))
+			assert.NoError(t, err)
+			assert.Equal(t, mode, parsed)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := resource.ParseFailureMode("ignore")
+		assert.EqualError(t, err, `on_failure must be one of fail, continue, or warn, got "ignore"`)
+	})
+
+	t.Run("not a string", func(t *testing.T) {
+		_, err := resource.ParseFailureMode(1)
+		assert.EqualError(t, err, "on_failure must be a string, got int")
+	})
+}
+
+func TestFailureModeFor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default", func(t *testing.T) {
+		assert.Equal(t, resource.FailureFail, resource.FailureModeFor(node.New("x", nil)))
+	})
+
+	t.Run("set", func(t *testing.T) {
+		meta := node.New("x", nil)
+		meta.AddMetadata(resource.MetaOnFailure, resource.FailureWarn)
+
+		assert.Equal(t, resource.FailureWarn, resource.FailureModeFor(meta))
+	})
+}
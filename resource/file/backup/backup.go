@@ -0,0 +1,237 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// timestampFormat is used in the names of backups. It sorts in the order the
+// backups were made.
+const timestampFormat = "20060102T150405.000000000"
+
+// Options control where backups are made and how many are kept
+type Options struct {
+	// Dir is the directory backups are made in. If empty, backups are made
+	// next to the original. Backups in Dir are named after the full path of
+	// the original, with slashes escaped as %2F.
+	Dir string
+
+	// Keep is the number of backups of the same original that are kept. Older
+	// backups are removed after a new one is made. Zero or less keeps all of
+	// them.
+	Keep int
+}
+
+// Create copies the file at path to a timestamped backup next to it, keeping
+// its permissions. The path of the backup is returned. If the file does not
+// exist no backup is made and the returned path is empty.
+func Create(path string) (string, error) {
+	return Options{}.Create(path)
+}
+
+// Create copies the file at path to a timestamped backup, keeping its
+// permissions. The path of the backup is returned. If the file does not exist
+// no backup is made and the returned path is empty.
+func (o Options) Create(path string) (string, error) {
+	stat, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Wrap(err, "could not stat file for backup")
+	}
+
+	if stat.IsDir() {
+		return "", fmt.Errorf("cannot back up %q, it is a directory", path)
+	}
+
+	dest, err := o.name(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := copyFile(path, dest); err != nil {
+		return "", err
+	}
+
+	return dest, o.prune(path)
+}
+
+// CreateTree copies the files at the given paths inside root to a single
+// timestamped backup directory, keeping their paths relative to root. The path
+// of the backup directory is returned. Files that do not exist are skipped,
+// and if none of them exist no backup is made and the returned path is empty.
+func (o Options) CreateTree(root string, paths []string) (string, error) {
+	var existing []string
+	for _, path := range paths {
+		stat, err := os.Stat(filepath.Join(root, path))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", errors.Wrap(err, "could not stat file for backup")
+		}
+
+		if !stat.IsDir() {
+			existing = append(existing, path)
+		}
+	}
+
+	if len(existing) == 0 {
+		return "", nil
+	}
+
+	dest, err := o.name(root)
+	if err != nil {
+		return "", err
+	}
+
+	for _, path := range existing {
+		target := filepath.Join(dest, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return "", errors.Wrap(err, "could not create backup")
+		}
+
+		if err := copyFile(filepath.Join(root, path), target); err != nil {
+			return "", err
+		}
+	}
+
+	return dest, o.prune(root)
+}
+
+// name returns the path of a new backup of the given original
+func (o Options) name(original string) (string, error) {
+	dir, base, err := o.location(original)
+	if err != nil {
+		return "", err
+	}
+
+	if o.Dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", errors.Wrap(err, "could not create backup directory")
+		}
+	}
+
+	name := fmt.Sprintf("%s.%s.bak", base, time.Now().UTC().Format(timestampFormat))
+	return filepath.Join(dir, name), nil
+}
+
+// pathEscaper escapes the slashes in a path so it can be used as a file name
+var pathEscaper = strings.NewReplacer("%", "%25", "/", "%2F")
+
+// location returns the directory backups of the given original are made in,
+// and the base of their names. Backups in Dir are named after the full path
+// of the original, so originals with the same name in different directories
+// don't share backups.
+func (o Options) location(original string) (dir, base string, err error) {
+	abs, err := filepath.Abs(original)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "could not resolve %q", original)
+	}
+
+	if o.Dir == "" {
+		return filepath.Dir(abs), filepath.Base(abs), nil
+	}
+	return o.Dir, pathEscaper.Replace(abs), nil
+}
+
+// prune removes the oldest backups of the given original until only Keep
+// remain
+func (o Options) prune(original string) error {
+	if o.Keep <= 0 {
+		return nil
+	}
+
+	backups, err := o.List(original)
+	if err != nil {
+		return err
+	}
+
+	for len(backups) > o.Keep {
+		if err := os.RemoveAll(backups[0]); err != nil {
+			return errors.Wrap(err, "could not remove old backup")
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// List returns the paths of the backups of the given original, oldest first
+func (o Options) List(original string) ([]string, error) {
+	dir, base, err := o.location(original)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not list backups")
+	}
+
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(base) + `\.\d{8}T\d{6}\.\d{9}\.bak$`)
+
+	var backups []string
+	for _, entry := range entries {
+		if pattern.MatchString(entry.Name()) {
+			backups = append(backups, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(backups)
+
+	return backups, nil
+}
+
+// copyFile copies the file at src to dest, keeping its permissions
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrap(err, "could not open file for backup")
+	}
+	defer in.Close()
+
+	stat, err := in.Stat()
+	if err != nil {
+		return errors.Wrap(err, "could not stat file for backup")
+	}
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, stat.Mode().Perm())
+	if err != nil {
+		return errors.Wrap(err, "could not create backup")
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return errors.Wrap(err, "could not write backup")
+	}
+
+	if err := out.Close(); err != nil {
+		return errors.Wrap(err, "could not write backup")
+	}
+
+	return nil
+}
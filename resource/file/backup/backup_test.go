@@ -0,0 +1,162 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/file/backup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreate tests backing up files
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("missing", func(t *testing.T) {
+		dest, err := backup.Create(filepath.Join(dir, "missing"))
+		assert.NoError(t, err)
+		assert.Equal(t, "", dest)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		src := filepath.Join(dir, "file")
+		require.NoError(t, ioutil.WriteFile(src, []byte("original"), 0640))
+
+		dest, err := backup.Create(src)
+		require.NoError(t, err)
+		assert.NotEqual(t, src, dest)
+
+		content, err := ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(content))
+
+		stat, err := os.Stat(dest)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), stat.Mode().Perm())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		_, err := backup.Create(dir)
+		assert.Error(t, err)
+	})
+}
+
+// TestOptionsCreate tests backing up files to a directory and keeping a number
+// of generations
+func TestOptionsCreate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-backup-options")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "file")
+	backupDir := filepath.Join(dir, "backups")
+	opts := backup.Options{Dir: backupDir, Keep: 2}
+
+	var made []string
+	for _, content := range []string{"1", "2", "3"} {
+		require.NoError(t, ioutil.WriteFile(src, []byte(content), 0600))
+
+		dest, err := opts.Create(src)
+		require.NoError(t, err)
+		assert.Equal(t, backupDir, filepath.Dir(dest))
+		made = append(made, dest)
+	}
+
+	backups, err := opts.List(src)
+	require.NoError(t, err)
+	assert.Equal(t, made[1:], backups)
+
+	content, err := ioutil.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(content))
+}
+
+// TestOptionsCreateSameName tests that files with the same name in different
+// directories keep separate backups in a shared directory
+func TestOptionsCreateSameName(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-backup-same-name")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	a := filepath.Join(dir, "a", "app.conf")
+	b := filepath.Join(dir, "b", "app.conf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(a), 0700))
+	require.NoError(t, os.MkdirAll(filepath.Dir(b), 0700))
+	require.NoError(t, ioutil.WriteFile(a, []byte("a"), 0600))
+	require.NoError(t, ioutil.WriteFile(b, []byte("b"), 0600))
+
+	opts := backup.Options{Dir: filepath.Join(dir, "backups"), Keep: 1}
+
+	_, err = opts.Create(a)
+	require.NoError(t, err)
+	_, err = opts.Create(b)
+	require.NoError(t, err)
+
+	for path, want := range map[string]string{a: "a", b: "b"} {
+		backups, err := opts.List(path)
+		require.NoError(t, err)
+		require.Len(t, backups, 1, "backups of %s", path)
+
+		content, err := ioutil.ReadFile(backups[0])
+		require.NoError(t, err)
+		assert.Equal(t, want, string(content))
+	}
+}
+
+// TestOptionsCreateTree tests backing up several files in a directory
+func TestOptionsCreateTree(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-backup-tree")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "a"), []byte("a"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "sub", "b"), []byte("b"), 0600))
+
+	t.Run("none-exist", func(t *testing.T) {
+		dest, err := backup.Options{}.CreateTree(root, []string{"missing"})
+		require.NoError(t, err)
+		assert.Equal(t, "", dest)
+	})
+
+	t.Run("files", func(t *testing.T) {
+		dest, err := backup.Options{}.CreateTree(root, []string{"a", "sub/b", "missing"})
+		require.NoError(t, err)
+		assert.Equal(t, dir, filepath.Dir(dest))
+
+		content, err := ioutil.ReadFile(filepath.Join(dest, "sub", "b"))
+		require.NoError(t, err)
+		assert.Equal(t, "b", string(content))
+
+		_, err = os.Stat(filepath.Join(dest, "missing"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -20,9 +20,23 @@ import (
 	"os"
 
 	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/backup"
+	"github.com/asteris-llc/converge/resource/file/remove"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
+// State type for Content
+type State string
+
+const (
+	// StatePresent indicates the file should be present
+	StatePresent State = "present"
+
+	// StateAbsent indicates the file should be absent
+	StateAbsent State = "absent"
+)
+
 // Content renders content to disk
 type Content struct {
 	// configured content of the file
@@ -30,16 +44,33 @@ type Content struct {
 
 	// configured destination of the file
 	Destination string `export:"destination"`
+
+	// whether the file should be present or absent
+	State State `export:"state"`
+
+	// whether an existing file will be backed up before it is overwritten
+	Backup bool `export:"backup"`
+
+	// the directory backups are made in, if not next to the file
+	BackupDir string `export:"backupdir"`
+
+	// the number of backups that are kept, if limited
+	BackupKeep int `export:"backupkeep"`
+
+	// the location of the backup made during apply, if any
+	BackupPath string `export:"backuppath"`
 }
 
 // Check if the content needs to be rendered
-func (t *Content) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+func (t *Content) Check(ctx context.Context, _ resource.Renderer) (resource.TaskStatus, error) {
+	if t.State == StateAbsent {
+		return t.checkAbsent(ctx)
+	}
+
 	diffs := make(map[string]resource.Diff)
-	contentDiff := resource.TextDiff{Values: [2]string{"", t.Content}}
 	stat, err := os.Stat(t.Destination)
 	if os.IsNotExist(err) {
-		contentDiff.Values[0] = "<file-missing>"
-		diffs[t.Destination] = contentDiff
+		diffs[t.Destination] = missingFileDiff(t.Content)
 		return &resource.Status{
 			Level:       resource.StatusWillChange,
 			Differences: diffs,
@@ -66,7 +97,7 @@ func (t *Content) Check(context.Context, resource.Renderer) (resource.TaskStatus
 
 	if string(actual) != t.Content {
 		statusMessage = "contents differ"
-		diffs[t.Destination] = resource.TextDiff{Values: [2]string{string(actual), t.Content}}
+		diffs[t.Destination] = resource.NewUnifiedDiff(string(actual), t.Content)
 	}
 
 	return &resource.Status{
@@ -76,9 +107,12 @@ func (t *Content) Check(context.Context, resource.Renderer) (resource.TaskStatus
 }
 
 // Apply writes the content to disk
-func (t *Content) Apply(context.Context) (resource.TaskStatus, error) {
+func (t *Content) Apply(ctx context.Context) (resource.TaskStatus, error) {
+	if t.State == StateAbsent {
+		return t.applyAbsent(ctx)
+	}
+
 	var perm os.FileMode
-	var preChange string
 	diffs := make(map[string]resource.Diff)
 
 	stat, err := os.Stat(t.Destination)
@@ -95,12 +129,25 @@ func (t *Content) Apply(context.Context) (resource.TaskStatus, error) {
 	}
 
 	if rawData, readErr := ioutil.ReadFile(t.Destination); readErr != nil {
-		preChange = "<file-missing>"
+		diffs[t.Destination] = missingFileDiff(t.Content)
 	} else {
-		preChange = string(rawData)
+		diffs[t.Destination] = resource.NewUnifiedDiff(string(rawData), t.Content)
 	}
 
-	diffs[t.Destination] = resource.TextDiff{Values: [2]string{preChange, t.Content}}
+	var output []string
+	if t.Backup && diffs[t.Destination].Changes() {
+		opts := backup.Options{Dir: t.BackupDir, Keep: t.BackupKeep}
+		if t.BackupPath, err = opts.Create(t.Destination); err != nil {
+			return &resource.Status{
+				Output:      []string{err.Error()},
+				Level:       resource.StatusFatal,
+				Differences: diffs,
+			}, err
+		}
+		if t.BackupPath != "" {
+			output = append(output, "backed up to "+t.BackupPath)
+		}
+	}
 
 	if err = ioutil.WriteFile(t.Destination, []byte(t.Content), perm); err != nil {
 		return &resource.Status{
@@ -110,5 +157,73 @@ func (t *Content) Apply(context.Context) (resource.TaskStatus, error) {
 		}, err
 	}
 
-	return &resource.Status{Differences: diffs}, nil
+	return &resource.Status{Differences: diffs, Output: output}, nil
+}
+
+// checkAbsent checks if the file needs to be removed
+func (t *Content) checkAbsent(ctx context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	stat, err := os.Lstat(t.Destination)
+	if os.IsNotExist(err) {
+		status.AddMessage(t.Destination + " is already absent")
+		return status, nil
+	} else if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot read %q", t.Destination)
+	} else if stat.IsDir() {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, fmt.Errorf("cannot remove %q, it is a directory", t.Destination)
+	}
+
+	if err := remove.Guard(ctx, t.Destination); err != nil {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, err
+	}
+
+	status.AddMessage(fmt.Sprintf("will remove %s (%d bytes)", t.Destination, stat.Size()))
+	status.AddDifference(t.Destination, "<present>", "<absent>", "")
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// applyAbsent removes the file
+func (t *Content) applyAbsent(ctx context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	// os.Remove also removes empty directories, so check again here in case
+	// Apply runs after Check found a directory
+	stat, err := os.Lstat(t.Destination)
+	if os.IsNotExist(err) {
+		return status, nil
+	} else if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot read %q", t.Destination)
+	} else if stat.IsDir() {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, fmt.Errorf("cannot remove %q, it is a directory", t.Destination)
+	}
+
+	if err := remove.Guard(ctx, t.Destination); err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if err := os.Remove(t.Destination); os.IsNotExist(err) {
+		return status, nil
+	} else if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot remove %q", t.Destination)
+	}
+
+	status.AddDifference(t.Destination, "<present>", "<absent>", "")
+	return status, nil
+}
+
+// missingFileDiff returns the diff of content that will be written to a file
+// that doesn't exist yet
+func missingFileDiff(content string) *resource.UnifiedDiff {
+	diff := resource.NewUnifiedDiff("", content)
+	diff.Placeholder = "<file-missing>"
+	return diff
 }
@@ -18,11 +18,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/resource/file/content"
+	"github.com/asteris-llc/converge/resource/file/remove"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -198,3 +200,112 @@ func TestContentApplyKeepPermission(t *testing.T) {
 
 	assert.Equal(t, perm, stat.Mode().Perm())
 }
+
+func TestContentAbsent(t *testing.T) {
+	t.Run("check", func(t *testing.T) {
+		tmpfile, err := ioutil.TempFile("", "test-content-absent")
+		require.NoError(t, err)
+		defer os.Remove(tmpfile.Name())
+
+		_, err = tmpfile.Write([]byte("1234"))
+		require.NoError(t, err)
+		require.NoError(t, tmpfile.Close())
+
+		tmpl := content.Content{Destination: tmpfile.Name(), State: content.StateAbsent}
+
+		status, err := tmpl.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Contains(t, status.Messages(), fmt.Sprintf("will remove %s (4 bytes)", tmpfile.Name()))
+		assert.Equal(t, "<absent>", status.Diffs()[tmpfile.Name()].Current())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		tmpl := content.Content{Destination: "missing-file", State: content.StateAbsent}
+
+		status, err := tmpl.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		tmpdir, err := ioutil.TempDir("", "test-content-absent-dir")
+		require.NoError(t, err)
+		defer os.RemoveAll(tmpdir)
+
+		tmpl := content.Content{Destination: tmpdir, State: content.StateAbsent}
+
+		_, err = tmpl.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+
+		_, err = tmpl.Apply(context.Background())
+		assert.Error(t, err)
+
+		_, err = os.Stat(tmpdir)
+		assert.NoError(t, err)
+	})
+
+	t.Run("outside-allowed", func(t *testing.T) {
+		tmpfile, err := ioutil.TempFile("", "test-content-absent")
+		require.NoError(t, err)
+		defer os.Remove(tmpfile.Name())
+
+		ctx := remove.WithAllowed(context.Background(), []string{"/nonexistent"})
+		tmpl := content.Content{Destination: tmpfile.Name(), State: content.StateAbsent}
+
+		_, err = tmpl.Check(ctx, fakerenderer.New())
+		assert.Error(t, err)
+
+		_, err = tmpl.Apply(ctx)
+		assert.Error(t, err)
+
+		_, err = os.Stat(tmpfile.Name())
+		assert.NoError(t, err)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		tmpfile, err := ioutil.TempFile("", "test-content-absent")
+		require.NoError(t, err)
+		defer os.Remove(tmpfile.Name())
+
+		tmpl := content.Content{Destination: tmpfile.Name(), State: content.StateAbsent}
+
+		status, err := tmpl.Apply(context.Background())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+
+		_, err = os.Stat(tmpfile.Name())
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestContentApplyBackup(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "test-content-backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	dest := filepath.Join(tmpdir, "file")
+	require.NoError(t, ioutil.WriteFile(dest, []byte("old"), 0600))
+
+	tmpl := content.Content{
+		Destination: dest,
+		Content:     "new",
+		Backup:      true,
+		BackupDir:   filepath.Join(tmpdir, "backups"),
+	}
+
+	status, err := tmpl.Apply(context.Background())
+	require.NoError(t, err)
+	require.NotEqual(t, "", tmpl.BackupPath)
+	assert.Contains(t, status.Messages(), "backed up to "+tmpl.BackupPath)
+
+	backedUp, err := ioutil.ReadFile(tmpl.BackupPath)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(backedUp))
+
+	// unchanged content is not backed up again
+	tmpl.BackupPath = ""
+	_, err = tmpl.Apply(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "", tmpl.BackupPath)
+}
@@ -29,13 +29,39 @@ type Preparer struct {
 
 	// Destination is the location on disk where the content will be rendered.
 	Destination string `hcl:"destination" required:"true" nonempty:"true"`
+
+	// State is whether the file should be present. When it is absent, the
+	// file is removed and content is ignored.
+	// The default value is present.
+	State State `hcl:"state" valid_values:"present,absent"`
+
+	// Backup controls whether an existing file is copied to a timestamped
+	// backup before it is overwritten. The location of the backup is exported
+	// as `backuppath`.
+	Backup bool `hcl:"backup"`
+
+	// BackupDir is the directory backups are made in. If not set, backups are
+	// made next to the destination.
+	BackupDir string `hcl:"backup_dir" nonempty:"true"`
+
+	// BackupKeep is the number of backups of the destination that are kept.
+	// Older backups are removed. If not set, all backups are kept.
+	BackupKeep int `hcl:"backup_keep"`
 }
 
 // Prepare a new task
 func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
 	return &Content{
 		Destination: p.Destination,
 		Content:     p.Content,
+		State:       p.State,
+		Backup:      p.Backup,
+		BackupDir:   p.BackupDir,
+		BackupKeep:  p.BackupKeep,
 	}, nil
 }
 
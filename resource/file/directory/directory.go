@@ -16,14 +16,27 @@ package directory
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 
 	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/remove"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
+// State type for Directory
+type State string
+
+const (
+	// StatePresent indicates the directory should be present
+	StatePresent State = "present"
+
+	// StateAbsent indicates the directory should be absent
+	StateAbsent State = "absent"
+)
+
 // Directory makes sure a directory is present on disk
 type Directory struct {
 	resource.TaskStatus
@@ -33,10 +46,20 @@ type Directory struct {
 
 	// if true, directories will be created recursively
 	CreateAll bool `export:"createall"`
+
+	// whether the directory should be present or absent
+	State State `export:"state"`
+
+	// if true, directories that are not empty will be removed
+	Recursive bool `export:"recursive"`
 }
 
 // Check if the directory exists
-func (d *Directory) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+func (d *Directory) Check(ctx context.Context, _ resource.Renderer) (resource.TaskStatus, error) {
+	if d.State == StateAbsent {
+		return d.checkAbsent(ctx)
+	}
+
 	status := resource.NewStatus()
 
 	dest := d.Destination
@@ -45,7 +68,7 @@ func (d *Directory) Check(context.Context, resource.Renderer) (resource.TaskStat
 
 		switch {
 		case err != nil && !os.IsNotExist(err):
-			return status, errors.Wrapf(err, "could not stat %q")
+			return status, errors.Wrapf(err, "could not stat %q", dest)
 
 		case os.IsNotExist(err):
 			// if we aren't told to create everything, we should fail early
@@ -82,7 +105,11 @@ func (d *Directory) Check(context.Context, resource.Renderer) (resource.TaskStat
 }
 
 // Apply creates the directory
-func (d *Directory) Apply(context.Context) (resource.TaskStatus, error) {
+func (d *Directory) Apply(ctx context.Context) (resource.TaskStatus, error) {
+	if d.State == StateAbsent {
+		return d.applyAbsent(ctx)
+	}
+
 	var err error
 
 	if d.CreateAll {
@@ -102,3 +129,108 @@ func (d *Directory) Apply(context.Context) (resource.TaskStatus, error) {
 
 	return d, err
 }
+
+// checkAbsent checks if the directory needs to be removed
+func (d *Directory) checkAbsent(ctx context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	exists, count, problem, err := d.removable(ctx)
+	if err != nil {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, err
+	}
+
+	switch {
+	case !exists:
+		status.AddMessage(fmt.Sprintf("%q is already absent", d.Destination))
+	case problem != "":
+		status.RaiseLevel(resource.StatusCantChange)
+		status.AddMessage(problem)
+	default:
+		status.RaiseLevel(resource.StatusWillChange)
+		status.AddMessage(fmt.Sprintf("will remove %q and %d files", d.Destination, count))
+		status.AddDifference(d.Destination, "<present>", "<absent>", "<absent>")
+	}
+
+	d.TaskStatus = status
+	return d, nil
+}
+
+// applyAbsent removes the directory. The checks from checkAbsent are repeated
+// since Apply also runs when Check found the directory can't be removed.
+func (d *Directory) applyAbsent(ctx context.Context) (resource.TaskStatus, error) {
+	exists, _, problem, err := d.removable(ctx)
+	if err != nil {
+		return nil, err
+	} else if problem != "" {
+		return nil, errors.New(problem)
+	}
+
+	if exists {
+		if d.Recursive {
+			err = os.RemoveAll(d.Destination)
+		} else {
+			err = os.Remove(d.Destination)
+		}
+
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	status := resource.NewStatus()
+	status.RaiseLevel(resource.StatusWillChange)
+	status.AddMessage(fmt.Sprintf("%q is absent", d.Destination))
+	d.TaskStatus = status
+
+	return d, nil
+}
+
+// removable checks whether the destination exists and can be removed. If it
+// can't, problem says why. count is the number of files in the directory.
+func (d *Directory) removable(ctx context.Context) (exists bool, count int, problem string, err error) {
+	stat, err := os.Lstat(d.Destination)
+	if os.IsNotExist(err) {
+		return false, 0, "", nil
+	} else if err != nil {
+		return false, 0, "", errors.Wrapf(err, "could not stat %q", d.Destination)
+	} else if !stat.IsDir() {
+		return true, 0, fmt.Sprintf("%q is not a directory and will not be removed", d.Destination), nil
+	}
+
+	if err := remove.Guard(ctx, d.Destination); err != nil {
+		return true, 0, "", err
+	}
+
+	count, err = remove.Count(d.Destination)
+	if err != nil {
+		return true, 0, "", err
+	}
+
+	empty, err := isEmpty(d.Destination)
+	if err != nil {
+		return true, 0, "", err
+	}
+
+	if !empty && !d.Recursive {
+		return true, count, fmt.Sprintf("%q is not empty (%d files) and will not be removed (enable recursive to do this)", d.Destination, count), nil
+	}
+	return true, count, "", nil
+}
+
+// isEmpty returns true if the directory has no entries
+func isEmpty(dir string) (bool, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not open %q", dir)
+	}
+	defer f.Close()
+
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "could not read %q", dir)
+	}
+	return false, nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/resource/file/directory"
+	"github.com/asteris-llc/converge/resource/file/remove"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -184,3 +185,155 @@ func TestDirectoryApply(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestDirectoryCheckAbsent(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "converge-directory-check-absent")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	full := path.Join(tmpDir, "full")
+	require.NoError(t, os.MkdirAll(path.Join(full, "sub"), 0700))
+	require.NoError(t, ioutil.WriteFile(path.Join(full, "a"), []byte("a"), 0600))
+	require.NoError(t, ioutil.WriteFile(path.Join(full, "sub", "b"), []byte("b"), 0600))
+
+	t.Run("missing", func(t *testing.T) {
+		dest := path.Join(tmpDir, "missing")
+		dir := directory.Directory{Destination: dest, State: directory.StateAbsent}
+
+		plan, err := dir.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.False(t, plan.HasChanges())
+		assert.Equal(t, []string{fmt.Sprintf("%q is already absent", dest)}, plan.Messages())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		dest := path.Join(tmpDir, "empty")
+		require.NoError(t, os.Mkdir(dest, 0700))
+
+		dir := directory.Directory{Destination: dest, State: directory.StateAbsent}
+
+		plan, err := dir.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.True(t, plan.HasChanges())
+		assert.Equal(t, resource.StatusWillChange, plan.StatusCode())
+		assert.Equal(t, []string{fmt.Sprintf("will remove %q and 0 files", dest)}, plan.Messages())
+	})
+
+	t.Run("not-empty", func(t *testing.T) {
+		dir := directory.Directory{Destination: full, State: directory.StateAbsent}
+
+		plan, err := dir.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.Equal(t, resource.StatusCantChange, plan.StatusCode())
+		assert.Equal(
+			t,
+			[]string{fmt.Sprintf("%q is not empty (2 files) and will not be removed (enable recursive to do this)", full)},
+			plan.Messages(),
+		)
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		dir := directory.Directory{Destination: full, State: directory.StateAbsent, Recursive: true}
+
+		plan, err := dir.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.True(t, plan.HasChanges())
+		assert.Equal(t, []string{fmt.Sprintf("will remove %q and 2 files", full)}, plan.Messages())
+	})
+
+	t.Run("not-a-directory", func(t *testing.T) {
+		dest := path.Join(full, "a")
+		dir := directory.Directory{Destination: dest, State: directory.StateAbsent}
+
+		plan, err := dir.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.Equal(t, resource.StatusCantChange, plan.StatusCode())
+	})
+
+	t.Run("root", func(t *testing.T) {
+		dir := directory.Directory{Destination: "/", State: directory.StateAbsent, Recursive: true}
+
+		_, err := dir.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("outside-allowed", func(t *testing.T) {
+		ctx := remove.WithAllowed(context.Background(), []string{path.Join(tmpDir, "allowed")})
+		dir := directory.Directory{Destination: full, State: directory.StateAbsent, Recursive: true}
+
+		_, err := dir.Check(ctx, fakerenderer.New())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "not inside an allowed path")
+		}
+	})
+}
+
+func TestDirectoryApplyAbsent(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "converge-directory-apply-absent")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	t.Run("empty", func(t *testing.T) {
+		dest := path.Join(tmpDir, "empty")
+		require.NoError(t, os.Mkdir(dest, 0700))
+
+		dir := directory.Directory{Destination: dest, State: directory.StateAbsent}
+
+		_, err := dir.Apply(context.Background())
+		require.NoError(t, err)
+
+		_, err = os.Stat(dest)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		dest := path.Join(tmpDir, "full")
+		require.NoError(t, os.MkdirAll(path.Join(dest, "sub"), 0700))
+		require.NoError(t, ioutil.WriteFile(path.Join(dest, "sub", "a"), []byte("a"), 0600))
+
+		dir := directory.Directory{Destination: dest, State: directory.StateAbsent, Recursive: true}
+
+		_, err := dir.Apply(context.Background())
+		require.NoError(t, err)
+
+		_, err = os.Stat(dest)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("not-empty", func(t *testing.T) {
+		dest := path.Join(tmpDir, "not-empty")
+		require.NoError(t, os.Mkdir(dest, 0700))
+		require.NoError(t, ioutil.WriteFile(path.Join(dest, "a"), []byte("a"), 0600))
+
+		dir := directory.Directory{Destination: dest, State: directory.StateAbsent}
+
+		_, err := dir.Apply(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("not-a-directory", func(t *testing.T) {
+		dest := path.Join(tmpDir, "file")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("a"), 0600))
+		link := path.Join(tmpDir, "link")
+		require.NoError(t, os.Symlink(tmpDir, link))
+
+		for _, dest := range []string{dest, link} {
+			dir := directory.Directory{Destination: dest, State: directory.StateAbsent, Recursive: true}
+
+			plan, err := dir.Check(context.Background(), fakerenderer.New())
+			require.NoError(t, err)
+			assert.Equal(t, resource.StatusCantChange, plan.StatusCode())
+
+			_, err = dir.Apply(context.Background())
+			assert.Error(t, err)
+
+			_, err = os.Lstat(dest)
+			assert.NoError(t, err)
+		}
+	})
+}
@@ -29,13 +29,26 @@ type Preparer struct {
 
 	// whether or not to create all parent directories on the way up
 	CreateAll bool `hcl:"create_all"`
+
+	// whether the directory should be present or absent. The default value is
+	// present.
+	State State `hcl:"state" valid_values:"present,absent"`
+
+	// whether to remove a directory that is not empty when state is absent
+	Recursive bool `hcl:"recursive"`
 }
 
 // Prepare the new directory
 func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
 	return &Directory{
 		Destination: p.Destination,
 		CreateAll:   p.CreateAll,
+		State:       p.State,
+		Recursive:   p.Recursive,
 	}, nil
 }
 
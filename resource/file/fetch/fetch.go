@@ -27,6 +27,7 @@ import (
 	"os"
 
 	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/backup"
 	"github.com/hashicorp/go-getter"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -67,6 +68,18 @@ type Fetch struct {
 	// whether the file will be fetched if it already exists
 	Force bool `export:"force"`
 
+	// whether an existing file will be backed up before it is replaced
+	Backup bool `export:"backup"`
+
+	// the directory backups are made in, if not next to the file
+	BackupDir string `export:"backupdir"`
+
+	// the number of backups that are kept, if limited
+	BackupKeep int `export:"backupkeep"`
+
+	// the location of the backup made during apply, if any
+	BackupPath string `export:"backuppath"`
+
 	// whether the fetched file will be unarchived
 	Unarchive bool
 
@@ -187,6 +200,17 @@ func (f *Fetch) applyWithContext(context.Context) (resource.TaskStatus, error) {
 		return status, errors.Wrap(err, "failed to get working directory")
 	}
 
+	if f.Backup && !f.Unarchive {
+		opts := backup.Options{Dir: f.BackupDir, Keep: f.BackupKeep}
+		if f.BackupPath, err = opts.Create(f.Destination); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		if f.BackupPath != "" {
+			status.AddMessage("backed up to " + f.BackupPath)
+		}
+	}
+
 	client := &getter.Client{
 		Src:  source,
 		Dst:  f.Destination,
@@ -47,6 +47,19 @@ type Preparer struct {
 	// 1. no checksum is provided
 	// 2. the checksum of the existing file differs from the checksum provided
 	Force bool `hcl:"force"`
+
+	// Backup controls whether an existing file is copied to a timestamped
+	// backup before it is replaced. The location of the backup is exported as
+	// `backuppath`.
+	Backup bool `hcl:"backup"`
+
+	// BackupDir is the directory backups are made in. If not set, backups are
+	// made next to the destination.
+	BackupDir string `hcl:"backup_dir" nonempty:"true"`
+
+	// BackupKeep is the number of backups of the destination that are kept.
+	// Older backups are removed. If not set, all backups are kept.
+	BackupKeep int `hcl:"backup_keep"`
 }
 
 // Prepare a new fetch task
@@ -89,6 +102,9 @@ func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resou
 		Source:      p.Source,
 		Destination: p.Destination,
 		Force:       p.Force,
+		Backup:      p.Backup,
+		BackupDir:   p.BackupDir,
+		BackupKeep:  p.BackupKeep,
 	}
 
 	if p.HashType != nil {
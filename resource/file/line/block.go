@@ -0,0 +1,115 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package line
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource"
+	"golang.org/x/net/context"
+)
+
+// DefaultMarker is the marker used for blocks that don't set one
+const DefaultMarker = "# {mark} CONVERGE MANAGED BLOCK"
+
+// Block manages a block of lines between markers in a file
+type Block struct {
+	// the file to edit
+	Destination string `export:"destination"`
+
+	// the content between the markers
+	Content string `export:"content"`
+
+	// the template of the marker lines, "{mark}" is replaced with BEGIN or END
+	Marker string `export:"marker"`
+
+	// the regular expression matching the line to insert a new block after
+	InsertAfter string `export:"insert_after"`
+
+	// whether the file will be created if it does not exist
+	Create bool `export:"create"`
+
+	// whether the block should be present or absent
+	State State `export:"state"`
+}
+
+// Check if the block needs to be changed
+func (b *Block) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	return check(b.Destination, b.Create, b.State, b.edit)
+}
+
+// Apply changes to the block
+func (b *Block) Apply(context.Context) (resource.TaskStatus, error) {
+	return apply(b.Destination, b.Create, b.State, b.edit)
+}
+
+// edit returns the content with the block present or absent. An existing
+// block is replaced in place; a new one is inserted after the last line
+// matching InsertAfter, or at the end of the file.
+func (b *Block) edit(original string) (string, error) {
+	begin, end := b.markers()
+	content := splitText(original)
+
+	start, stop := -1, -1
+	for i, line := range content.lines {
+		if start < 0 && line == begin {
+			start = i
+		} else if start >= 0 && line == end {
+			stop = i
+			break
+		}
+	}
+	if start >= 0 && stop < 0 {
+		return "", fmt.Errorf("found %q but not %q", begin, end)
+	}
+
+	var block []string
+	if b.State != StateAbsent {
+		block = append(block, begin)
+		if b.Content != "" {
+			block = append(block, strings.Split(strings.TrimSuffix(b.Content, "\n"), "\n")...)
+		}
+		block = append(block, end)
+	}
+
+	if start >= 0 {
+		out := append([]string{}, content.lines[:start]...)
+		out = append(out, block...)
+		content.lines = append(out, content.lines[stop+1:]...)
+		return content.String(), nil
+	}
+
+	if b.State == StateAbsent {
+		return original, nil
+	}
+
+	after, err := compile("insert_after", b.InsertAfter)
+	if err != nil {
+		return "", err
+	}
+
+	content.insert(after, block)
+	return content.String(), nil
+}
+
+// markers returns the lines that begin and end the block
+func (b *Block) markers() (begin, end string) {
+	marker := b.Marker
+	if marker == "" {
+		marker = DefaultMarker
+	}
+	return strings.Replace(marker, "{mark}", "BEGIN", -1), strings.Replace(marker, "{mark}", "END", -1)
+}
@@ -0,0 +1,137 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package line_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/line"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestBlockInterface tests that Block is properly implemented
+func TestBlockInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Task)(nil), new(line.Block))
+}
+
+// TestBlockApply tests editing blocks
+func TestBlockApply(t *testing.T) {
+	t.Parallel()
+
+	const (
+		begin = "# BEGIN CONVERGE MANAGED BLOCK"
+		end   = "# END CONVERGE MANAGED BLOCK"
+	)
+
+	for _, tc := range []struct {
+		name     string
+		original string
+		block    *line.Block
+		expected string
+	}{
+		{
+			"append",
+			"a\n",
+			&line.Block{Content: "x\ny\n", State: line.StatePresent},
+			"a\n" + begin + "\nx\ny\n" + end + "\n",
+		},
+		{
+			"replace",
+			"a\n" + begin + "\nold\n" + end + "\nb\n",
+			&line.Block{Content: "new", State: line.StatePresent},
+			"a\n" + begin + "\nnew\n" + end + "\nb\n",
+		},
+		{
+			"insert-after",
+			"a\nb\n",
+			&line.Block{Content: "x", InsertAfter: "^a$", State: line.StatePresent},
+			"a\n" + begin + "\nx\n" + end + "\nb\n",
+		},
+		{
+			"custom-marker",
+			"a\n",
+			&line.Block{Content: "x", Marker: "// {mark} hosts", State: line.StatePresent},
+			"a\n// BEGIN hosts\nx\n// END hosts\n",
+		},
+		{
+			"absent",
+			"a\n" + begin + "\nold\n" + end + "\nb\n",
+			&line.Block{State: line.StateAbsent},
+			"a\nb\n",
+		},
+		{
+			"absent-missing",
+			"a\n",
+			&line.Block{State: line.StateAbsent},
+			"a\n",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := ioutil.TempDir("", "test-block-apply")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			tc.block.Destination = filepath.Join(dir, "file")
+			require.NoError(t, ioutil.WriteFile(tc.block.Destination, []byte(tc.original), 0644))
+
+			status, err := tc.block.Check(context.Background(), fakerenderer.New())
+			require.NoError(t, err)
+			assert.Equal(t, tc.original != tc.expected, status.HasChanges())
+
+			_, err = tc.block.Apply(context.Background())
+			require.NoError(t, err)
+
+			actual, err := ioutil.ReadFile(tc.block.Destination)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+
+			// applying again changes nothing
+			status, err = tc.block.Check(context.Background(), fakerenderer.New())
+			require.NoError(t, err)
+			assert.False(t, status.HasChanges())
+		})
+	}
+}
+
+// TestBlockCheckUnterminated tests that a block without an end marker is an
+// error
+func TestBlockCheckUnterminated(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-block-unterminated")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dest := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(dest, []byte("# BEGIN CONVERGE MANAGED BLOCK\nx\n"), 0644))
+
+	b := &line.Block{Destination: dest, Content: "x", State: line.StatePresent}
+	_, err = b.Check(context.Background(), fakerenderer.New())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `but not "# END CONVERGE MANAGED BLOCK"`)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package line
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/pkg/errors"
+)
+
+// State type for Line and Block
+type State string
+
+const (
+	// StatePresent indicates the line or block should be present
+	StatePresent State = "present"
+
+	// StateAbsent indicates the line or block should be absent
+	StateAbsent State = "absent"
+)
+
+// editFunc computes the desired content of a file from its current content
+type editFunc func(original string) (string, error)
+
+// text is the content of a file split into lines
+type text struct {
+	lines           []string
+	trailingNewline bool
+}
+
+func splitText(content string) *text {
+	if content == "" {
+		return &text{}
+	}
+
+	trailingNewline := strings.HasSuffix(content, "\n")
+	return &text{
+		lines:           strings.Split(strings.TrimSuffix(content, "\n"), "\n"),
+		trailingNewline: trailingNewline,
+	}
+}
+
+func (t *text) String() string {
+	if len(t.lines) == 0 {
+		return ""
+	}
+
+	out := strings.Join(t.lines, "\n")
+	if t.trailingNewline {
+		out += "\n"
+	}
+	return out
+}
+
+// insert adds lines after the last line matching after, or at the end of the
+// text if after is nil or matches nothing. Inserted lines are always
+// terminated, so a missing newline at the end of the file is added.
+func (t *text) insert(after *regexp.Regexp, lines []string) {
+	pos := len(t.lines)
+	if after != nil {
+		if idx := t.lastMatch(after); idx >= 0 {
+			pos = idx + 1
+		}
+	}
+
+	if pos == len(t.lines) {
+		t.trailingNewline = true
+	}
+
+	out := append([]string{}, t.lines[:pos]...)
+	out = append(out, lines...)
+	t.lines = append(out, t.lines[pos:]...)
+}
+
+// lastMatch returns the index of the last line matching re, or -1
+func (t *text) lastMatch(re *regexp.Regexp) int {
+	for i := len(t.lines) - 1; i >= 0; i-- {
+		if re.MatchString(t.lines[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// compile compiles an optional regular expression
+func compile(name, expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", name)
+	}
+	return re, nil
+}
+
+// check reports the changes edit would make to the file at dest
+func check(dest string, create bool, state State, edit editFunc) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	original, exists, err := read(dest)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if !exists {
+		if state == StateAbsent {
+			status.AddMessage(dest + ": File is missing")
+			return status, nil
+		}
+		if !create {
+			status.RaiseLevel(resource.StatusCantChange)
+			return status, fmt.Errorf("%q does not exist, set create to create it", dest)
+		}
+	}
+
+	desired, err := edit(original)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot edit %q", dest)
+	}
+
+	if !exists || desired != original {
+		status.AddMessage("contents differ")
+		status.Differences[dest] = contentDiff(original, desired, exists)
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// apply writes the changes edit makes to the file at dest
+func apply(dest string, create bool, state State, edit editFunc) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	original, exists, err := read(dest)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if !exists {
+		if state == StateAbsent {
+			return status, nil
+		}
+		if !create {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, fmt.Errorf("%q does not exist, set create to create it", dest)
+		}
+	}
+
+	desired, err := edit(original)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot edit %q", dest)
+	}
+
+	if exists && desired == original {
+		return status, nil
+	}
+
+	var perm os.FileMode = 0600
+	if stat, err := os.Stat(dest); err == nil {
+		perm = stat.Mode().Perm()
+	}
+
+	if err := ioutil.WriteFile(dest, []byte(desired), perm); err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot write %q", dest)
+	}
+	status.Differences[dest] = contentDiff(original, desired, exists)
+
+	return status, nil
+}
+
+// read returns the content of the file at dest, and whether it exists
+func read(dest string) (string, bool, error) {
+	stat, err := os.Stat(dest)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, errors.Wrapf(err, "cannot read %q", dest)
+	} else if stat.IsDir() {
+		return "", false, fmt.Errorf("cannot edit %q, it is a directory", dest)
+	}
+
+	content, err := ioutil.ReadFile(dest)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "cannot read %q", dest)
+	}
+	return string(content), true, nil
+}
+
+func contentDiff(original, desired string, exists bool) *resource.UnifiedDiff {
+	diff := resource.NewUnifiedDiff(original, desired)
+	if !exists {
+		diff.Placeholder = "<file-missing>"
+	}
+	return diff
+}
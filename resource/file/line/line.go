@@ -0,0 +1,100 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package line
+
+import (
+	"github.com/asteris-llc/converge/resource"
+	"golang.org/x/net/context"
+)
+
+// Line manages a single line in a file
+type Line struct {
+	// the file to edit
+	Destination string `export:"destination"`
+
+	// the line that should be present or absent
+	Line string `export:"line"`
+
+	// the regular expression matching the line to replace or remove
+	Match string `export:"match"`
+
+	// the regular expression matching the line to insert after
+	InsertAfter string `export:"insert_after"`
+
+	// whether the file will be created if it does not exist
+	Create bool `export:"create"`
+
+	// whether the line should be present or absent
+	State State `export:"state"`
+}
+
+// Check if the line needs to be changed
+func (l *Line) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	return check(l.Destination, l.Create, l.State, l.edit)
+}
+
+// Apply changes to the line
+func (l *Line) Apply(context.Context) (resource.TaskStatus, error) {
+	return apply(l.Destination, l.Create, l.State, l.edit)
+}
+
+// edit returns the content with the line present or absent.
+//
+// When the line should be present and is not already, the last line matching
+// Match is replaced with it. If nothing matches, it is inserted after the last
+// line matching InsertAfter, or at the end of the file. When the line should be
+// absent, every line matching Match is removed, or every line equal to Line if
+// Match is not set.
+func (l *Line) edit(original string) (string, error) {
+	match, err := compile("match", l.Match)
+	if err != nil {
+		return "", err
+	}
+
+	content := splitText(original)
+
+	if l.State == StateAbsent {
+		var kept []string
+		for _, line := range content.lines {
+			if (match != nil && match.MatchString(line)) || (match == nil && line == l.Line) {
+				continue
+			}
+			kept = append(kept, line)
+		}
+		content.lines = kept
+		return content.String(), nil
+	}
+
+	for _, line := range content.lines {
+		if line == l.Line {
+			return original, nil
+		}
+	}
+
+	if match != nil {
+		if idx := content.lastMatch(match); idx >= 0 {
+			content.lines[idx] = l.Line
+			return content.String(), nil
+		}
+	}
+
+	after, err := compile("insert_after", l.InsertAfter)
+	if err != nil {
+		return "", err
+	}
+
+	content.insert(after, []string{l.Line})
+	return content.String(), nil
+}
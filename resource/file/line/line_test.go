@@ -0,0 +1,183 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package line_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/line"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestLineInterface tests that Line is properly implemented
+func TestLineInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Task)(nil), new(line.Line))
+}
+
+// TestLineApply tests editing lines
+func TestLineApply(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		original string
+		line     *line.Line
+		expected string
+	}{
+		{
+			"append",
+			"a\nb\n",
+			&line.Line{Line: "c", State: line.StatePresent},
+			"a\nb\nc\n",
+		},
+		{
+			"append-without-newline",
+			"a\nb",
+			&line.Line{Line: "c", State: line.StatePresent},
+			"a\nb\nc\n",
+		},
+		{
+			"present",
+			"a\nc\nb",
+			&line.Line{Line: "c", Match: "^c", State: line.StatePresent},
+			"a\nc\nb",
+		},
+		{
+			"replace-last-match",
+			"x=1\nx=2\ny=3\n",
+			&line.Line{Line: "x=5", Match: "^x=", State: line.StatePresent},
+			"x=1\nx=5\ny=3\n",
+		},
+		{
+			"insert-after",
+			"[a]\nk=v\n[b]\n",
+			&line.Line{Line: "n=m", Match: "^n=", InsertAfter: `^\[a\]$`, State: line.StatePresent},
+			"[a]\nn=m\nk=v\n[b]\n",
+		},
+		{
+			"insert-after-no-match",
+			"a\n",
+			&line.Line{Line: "c", InsertAfter: "^z", State: line.StatePresent},
+			"a\nc\n",
+		},
+		{
+			"absent",
+			"a\nc\nb\nc\n",
+			&line.Line{Line: "c", State: line.StateAbsent},
+			"a\nb\n",
+		},
+		{
+			"absent-match",
+			"x=1\ny=2\nx=3\n",
+			&line.Line{Match: "^x=", State: line.StateAbsent},
+			"y=2\n",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := ioutil.TempDir("", "test-line-apply")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			tc.line.Destination = filepath.Join(dir, "file")
+			require.NoError(t, ioutil.WriteFile(tc.line.Destination, []byte(tc.original), 0644))
+
+			status, err := tc.line.Check(context.Background(), fakerenderer.New())
+			require.NoError(t, err)
+			assert.Equal(t, tc.original != tc.expected, status.HasChanges())
+
+			_, err = tc.line.Apply(context.Background())
+			require.NoError(t, err)
+
+			actual, err := ioutil.ReadFile(tc.line.Destination)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+
+			// applying again changes nothing
+			status, err = tc.line.Check(context.Background(), fakerenderer.New())
+			require.NoError(t, err)
+			assert.False(t, status.HasChanges())
+		})
+	}
+}
+
+// TestLineCheck tests the status reported by Check
+func TestLineCheck(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-line-check")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("diff", func(t *testing.T) {
+		dest := filepath.Join(dir, "diff")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("a\n"), 0644))
+
+		l := &line.Line{Destination: dest, Line: "b", State: line.StatePresent}
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		diff, ok := status.Diffs()[dest]
+		require.True(t, ok)
+		assert.Equal(t, "a\n", diff.Original())
+		assert.Equal(t, "a\nb\n", diff.Current())
+		assert.Equal(t, resource.StatusWillChange, status.StatusCode())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		l := &line.Line{Destination: filepath.Join(dir, "missing"), Line: "b", State: line.StatePresent}
+		_, err := l.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("missing-absent", func(t *testing.T) {
+		l := &line.Line{Destination: filepath.Join(dir, "missing"), Line: "b", State: line.StateAbsent}
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("create", func(t *testing.T) {
+		l := &line.Line{Destination: filepath.Join(dir, "create"), Line: "b", Create: true, State: line.StatePresent}
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Equal(t, "<file-missing>", status.Diffs()[l.Destination].Original())
+
+		_, err = l.Apply(context.Background())
+		require.NoError(t, err)
+
+		actual, err := ioutil.ReadFile(l.Destination)
+		require.NoError(t, err)
+		assert.Equal(t, "b\n", string(actual))
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		l := &line.Line{Destination: dir, Line: "b", State: line.StatePresent}
+		_, err := l.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package line
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"golang.org/x/net/context"
+)
+
+// LinePreparer for Line
+//
+// Line ensures that a single line is present in or absent from a file, without
+// managing the rest of its content. An existing line can be found with a
+// regular expression and replaced.
+type LinePreparer struct {
+	// Destination is the file to edit.
+	Destination string `hcl:"destination" required:"true" nonempty:"true"`
+
+	// Line is the full text of the line. It is required when state is
+	// present.
+	Line string `hcl:"line"`
+
+	// Match is a regular expression. When the line should be present and is
+	// not, the last line matching it is replaced. When the line should be
+	// absent, every line matching it is removed. If not set, only lines equal
+	// to `line` are considered.
+	Match string `hcl:"match"`
+
+	// InsertAfter is a regular expression. If nothing matches `match`, the line
+	// is inserted after the last line matching it, or at the end of the file
+	// if nothing does.
+	InsertAfter string `hcl:"insert_after"`
+
+	// Create controls whether the file is created if it does not exist.
+	Create bool `hcl:"create"`
+
+	// State is whether the line should be present.
+	// The default value is present.
+	State State `hcl:"state" valid_values:"present,absent"`
+}
+
+// Prepare a new task
+func (p *LinePreparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
+	if p.State == StatePresent && p.Line == "" {
+		return nil, errors.New("\"line\" is required when state is present")
+	}
+
+	if p.State == StateAbsent && p.Line == "" && p.Match == "" {
+		return nil, errors.New("\"line\" or \"match\" is required when state is absent")
+	}
+
+	if _, err := compile("match", p.Match); err != nil {
+		return nil, err
+	}
+
+	if _, err := compile("insert_after", p.InsertAfter); err != nil {
+		return nil, err
+	}
+
+	return &Line{
+		Destination: p.Destination,
+		Line:        p.Line,
+		Match:       p.Match,
+		InsertAfter: p.InsertAfter,
+		Create:      p.Create,
+		State:       p.State,
+	}, nil
+}
+
+// BlockPreparer for Block
+//
+// Block ensures that a block of lines between two marker lines is present in or
+// absent from a file, without managing the rest of its content.
+type BlockPreparer struct {
+	// Destination is the file to edit.
+	Destination string `hcl:"destination" required:"true" nonempty:"true"`
+
+	// Content is the text between the markers.
+	Content string `hcl:"content"`
+
+	// Marker is the template of the lines around the block. It must contain
+	// "{mark}", which is replaced with BEGIN and END. The default value is
+	// "# {mark} CONVERGE MANAGED BLOCK". Use a different marker for each block
+	// in the same file.
+	Marker string `hcl:"marker"`
+
+	// InsertAfter is a regular expression. If the block is not in the file
+	// yet, it is inserted after the last line matching it, or at the end of
+	// the file if nothing does.
+	InsertAfter string `hcl:"insert_after"`
+
+	// Create controls whether the file is created if it does not exist.
+	Create bool `hcl:"create"`
+
+	// State is whether the block should be present.
+	// The default value is present.
+	State State `hcl:"state" valid_values:"present,absent"`
+}
+
+// Prepare a new task
+func (p *BlockPreparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
+	if p.Marker == "" {
+		p.Marker = DefaultMarker
+	}
+
+	if !strings.Contains(p.Marker, "{mark}") {
+		return nil, errors.New("\"marker\" must contain {mark}")
+	}
+
+	if _, err := compile("insert_after", p.InsertAfter); err != nil {
+		return nil, err
+	}
+
+	return &Block{
+		Destination: p.Destination,
+		Content:     p.Content,
+		Marker:      p.Marker,
+		InsertAfter: p.InsertAfter,
+		Create:      p.Create,
+		State:       p.State,
+	}, nil
+}
+
+func init() {
+	registry.Register("file.line", (*LinePreparer)(nil), (*Line)(nil))
+	registry.Register("file.block", (*BlockPreparer)(nil), (*Block)(nil))
+}
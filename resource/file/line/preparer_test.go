@@ -0,0 +1,92 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package line_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/line"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPreparerInterface tests that the preparers are properly implemented
+func TestPreparerInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Resource)(nil), new(line.LinePreparer))
+	assert.Implements(t, (*resource.Resource)(nil), new(line.BlockPreparer))
+}
+
+// TestLinePreparer tests preparing lines
+func TestLinePreparer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		prep := &line.LinePreparer{Destination: "/tmp/test", Line: "a"}
+		task, err := prep.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		l, ok := task.(*line.Line)
+		require.True(t, ok)
+		assert.Equal(t, line.StatePresent, l.State)
+		assert.Equal(t, "a", l.Line)
+	})
+
+	t.Run("line-required", func(t *testing.T) {
+		prep := &line.LinePreparer{Destination: "/tmp/test", Match: "^a"}
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, `"line" is required when state is present`)
+	})
+
+	t.Run("absent-requires-line-or-match", func(t *testing.T) {
+		prep := &line.LinePreparer{Destination: "/tmp/test", State: line.StateAbsent}
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, `"line" or "match" is required when state is absent`)
+	})
+
+	t.Run("invalid-match", func(t *testing.T) {
+		prep := &line.LinePreparer{Destination: "/tmp/test", Line: "a", Match: "("}
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "invalid match")
+		}
+	})
+}
+
+// TestBlockPreparer tests preparing blocks
+func TestBlockPreparer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		prep := &line.BlockPreparer{Destination: "/tmp/test", Content: "a"}
+		task, err := prep.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		b, ok := task.(*line.Block)
+		require.True(t, ok)
+		assert.Equal(t, line.StatePresent, b.State)
+		assert.Equal(t, line.DefaultMarker, b.Marker)
+	})
+
+	t.Run("invalid-marker", func(t *testing.T) {
+		prep := &line.BlockPreparer{Destination: "/tmp/test", Marker: "# managed"}
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, `"marker" must contain {mark}`)
+	})
+}
@@ -0,0 +1,304 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package link
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/remove"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// Type of link
+type Type string
+
+const (
+	// TypeSymbolic indicates a symbolic link
+	TypeSymbolic Type = "symbolic"
+
+	// TypeHard indicates a hard link
+	TypeHard Type = "hard"
+)
+
+// State type for Link
+type State string
+
+const (
+	// StatePresent indicates the link should be present
+	StatePresent State = "present"
+
+	// StateAbsent indicates the link should be absent
+	StateAbsent State = "absent"
+)
+
+// Link manages symbolic and hard links
+type Link struct {
+	// the location of the link
+	Destination string `export:"destination"`
+
+	// the file the link points to
+	Target string `export:"target"`
+
+	// the type of the link
+	Type Type `export:"type"`
+
+	// whether existing files at the destination will be replaced
+	Force bool `export:"force"`
+
+	// whether the link should be present or absent
+	State State `export:"state"`
+}
+
+// current describes what is at the destination of a link
+type current struct {
+	exists bool
+	isDir  bool
+
+	// isLink is true if the destination is a link of the configured type. Any
+	// regular file is a hard link.
+	isLink bool
+
+	// target is what a symbolic link points to
+	target string
+
+	// correct is true if the destination is a link to the configured target
+	correct bool
+}
+
+// describe the destination for diffs
+func (c *current) describe() string {
+	switch {
+	case !c.exists:
+		return "<absent>"
+	case c.isDir:
+		return "<directory>"
+	case c.target != "":
+		return c.target
+	default:
+		return "<file>"
+	}
+}
+
+// Check if the link needs to be changed
+func (l *Link) Check(ctx context.Context, _ resource.Renderer) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	cur, err := l.inspect()
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if err := l.blocked(cur); err != nil {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, err
+	}
+
+	if l.State == StateAbsent {
+		if !cur.exists {
+			status.AddMessage(fmt.Sprintf("%q does not exist", l.Destination))
+		} else {
+			if err := remove.Guard(ctx, l.Destination); err != nil {
+				status.RaiseLevel(resource.StatusCantChange)
+				return status, err
+			}
+			status.AddDifference(l.Destination, cur.describe(), "<absent>", "")
+		}
+
+		status.RaiseLevelForDiffs()
+		return status, nil
+	}
+
+	switch {
+	case cur.correct:
+		status.AddMessage(fmt.Sprintf("%q links to %q", l.Destination, l.Target))
+		if dangling, err := l.dangling(); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		} else if dangling {
+			status.SetWarning(fmt.Sprintf("%q is dangling, %q does not exist", l.Destination, l.Target))
+		}
+
+	default:
+		status.AddDifference(l.Destination, cur.describe(), l.Target, "<absent>")
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// Apply changes to the link
+func (l *Link) Apply(ctx context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	cur, err := l.inspect()
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	// Apply also runs when Check found the destination can't be changed, so
+	// the same checks are made again here
+	if err := l.blocked(cur); err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if l.State == StateAbsent {
+		if cur.exists {
+			if err := remove.Guard(ctx, l.Destination); err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, err
+			}
+			if err := os.Remove(l.Destination); err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, errors.Wrapf(err, "could not remove %q", l.Destination)
+			}
+			status.AddDifference(l.Destination, cur.describe(), "<absent>", "")
+		}
+		return status, nil
+	}
+
+	if !cur.correct {
+		if err := l.replace(); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+
+		status.AddDifference(l.Destination, cur.describe(), l.Target, "<absent>")
+	}
+
+	return status, nil
+}
+
+// blocked returns an error if what is at the destination can't be replaced
+// or removed, either because it is a directory or because it isn't our link
+// and force is not set
+func (l *Link) blocked(cur *current) error {
+	if l.State == StateAbsent {
+		switch {
+		case !cur.exists:
+			return nil
+		case cur.isDir:
+			return fmt.Errorf("%q is a directory and will not be removed", l.Destination)
+		case !l.ours(cur) && !l.Force:
+			return fmt.Errorf("%q is not a %s link to %q, set force to remove it", l.Destination, l.Type, l.Target)
+		}
+		return nil
+	}
+
+	switch {
+	case cur.correct:
+		return nil
+	case cur.isDir:
+		return fmt.Errorf("%q is a directory and will not be replaced", l.Destination)
+	case cur.exists && (l.Type == TypeHard || !cur.isLink) && !l.Force:
+		return fmt.Errorf("%q already exists, set force to replace it", l.Destination)
+	}
+	return nil
+}
+
+// replace creates the link in a temporary directory next to the destination
+// and renames it into place, so whatever is at the destination is only
+// replaced once the link has been created
+func (l *Link) replace() error {
+	tmpDir, err := ioutil.TempDir(filepath.Dir(l.Destination), ".converge-link")
+	if err != nil {
+		return errors.Wrapf(err, "could not create temporary directory for %q", l.Destination)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	link := os.Symlink
+	if l.Type == TypeHard {
+		link = os.Link
+	}
+
+	tmp := filepath.Join(tmpDir, filepath.Base(l.Destination))
+	if err := link(l.Target, tmp); err != nil {
+		return errors.Wrapf(err, "could not link %q to %q", l.Destination, l.Target)
+	}
+
+	if err := os.Rename(tmp, l.Destination); err != nil {
+		return errors.Wrapf(err, "could not move link into place at %q", l.Destination)
+	}
+
+	return nil
+}
+
+// inspect the destination of the link
+func (l *Link) inspect() (*current, error) {
+	info, err := os.Lstat(l.Destination)
+	if os.IsNotExist(err) {
+		return &current{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "could not stat %q", l.Destination)
+	}
+
+	cur := &current{exists: true, isDir: info.IsDir()}
+
+	if l.Type == TypeHard {
+		cur.isLink = info.Mode().IsRegular()
+		if cur.isLink && l.Target != "" {
+			targetInfo, err := os.Stat(l.Target)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "could not stat %q", l.Target)
+			}
+			cur.correct = err == nil && os.SameFile(info, targetInfo)
+		}
+		return cur, nil
+	}
+
+	cur.isLink = info.Mode()&os.ModeSymlink != 0
+	if cur.isLink {
+		if cur.target, err = os.Readlink(l.Destination); err != nil {
+			return nil, errors.Wrapf(err, "could not read link %q", l.Destination)
+		}
+		cur.correct = cur.target == l.Target
+	}
+
+	return cur, nil
+}
+
+// ours returns true if the destination is a link that can be removed without
+// force: a link of the configured type, to the target if one is set. Any
+// regular file is a hard link, so hard links are only ours if they link to the
+// target.
+func (l *Link) ours(cur *current) bool {
+	if l.Type == TypeHard {
+		return cur.correct
+	}
+	return cur.isLink && (l.Target == "" || cur.correct)
+}
+
+// dangling returns true if the destination is a symbolic link to a file that
+// does not exist
+func (l *Link) dangling() (bool, error) {
+	if l.Type != TypeSymbolic {
+		return false, nil
+	}
+
+	_, err := os.Stat(l.Destination)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "could not stat %q", l.Destination)
+	}
+	return false, nil
+}
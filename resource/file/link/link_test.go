@@ -0,0 +1,325 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package link_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/link"
+	"github.com/asteris-llc/converge/resource/file/remove"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestLinkInterface tests that Link is properly implemented
+func TestLinkInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Task)(nil), new(link.Link))
+}
+
+// setup creates a temporary directory with a target file in it
+func setup(t *testing.T) (dir, target string) {
+	dir, err := ioutil.TempDir("", "test-link")
+	require.NoError(t, err)
+
+	target = filepath.Join(dir, "target")
+	require.NoError(t, ioutil.WriteFile(target, []byte("target"), 0600))
+
+	return dir, target
+}
+
+// TestLinkSymbolic tests managing symbolic links
+func TestLinkSymbolic(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		l := &link.Link{Destination: filepath.Join(dir, "link"), Target: target, Type: link.TypeSymbolic, State: link.StatePresent}
+
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Equal(t, "<absent>", status.Diffs()[l.Destination].Original())
+		assert.Equal(t, target, status.Diffs()[l.Destination].Current())
+
+		_, err = l.Apply(context.Background())
+		require.NoError(t, err)
+
+		actual, err := os.Readlink(l.Destination)
+		require.NoError(t, err)
+		assert.Equal(t, target, actual)
+
+		status, err = l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("wrong-target", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "link")
+		require.NoError(t, os.Symlink("/wrong", dest))
+
+		l := &link.Link{Destination: dest, Target: target, Type: link.TypeSymbolic, State: link.StatePresent}
+
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Equal(t, "/wrong", status.Diffs()[dest].Original())
+
+		_, err = l.Apply(context.Background())
+		require.NoError(t, err)
+
+		actual, err := os.Readlink(dest)
+		require.NoError(t, err)
+		assert.Equal(t, target, actual)
+	})
+
+	t.Run("dangling", func(t *testing.T) {
+		dir, _ := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "link")
+		missing := filepath.Join(dir, "missing")
+		require.NoError(t, os.Symlink(missing, dest))
+
+		l := &link.Link{Destination: dest, Target: missing, Type: link.TypeSymbolic, State: link.StatePresent}
+
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+		assert.Contains(t, status.Warning(), "is dangling")
+	})
+
+	t.Run("existing-file", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "file")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("file"), 0600))
+
+		l := &link.Link{Destination: dest, Target: target, Type: link.TypeSymbolic, State: link.StatePresent}
+
+		_, err := l.Check(context.Background(), fakerenderer.New())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "set force to replace it")
+		}
+
+		// Apply refuses to replace the file without force as well
+		_, err = l.Apply(context.Background())
+		assert.Error(t, err)
+		content, err := ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "file", string(content))
+
+		l.Force = true
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, "<file>", status.Diffs()[dest].Original())
+
+		_, err = l.Apply(context.Background())
+		require.NoError(t, err)
+
+		actual, err := os.Readlink(dest)
+		require.NoError(t, err)
+		assert.Equal(t, target, actual)
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		l := &link.Link{Destination: dir, Target: target, Type: link.TypeSymbolic, Force: true, State: link.StatePresent}
+
+		_, err := l.Check(context.Background(), fakerenderer.New())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "is a directory")
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "link")
+		require.NoError(t, os.Symlink(target, dest))
+
+		l := &link.Link{Destination: dest, Type: link.TypeSymbolic, State: link.StateAbsent}
+
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+
+		_, err = l.Apply(context.Background())
+		require.NoError(t, err)
+
+		_, err = os.Lstat(dest)
+		assert.True(t, os.IsNotExist(err))
+
+		// the target is left alone
+		_, err = os.Stat(target)
+		assert.NoError(t, err)
+
+		status, err = l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("absent-not-allowed", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "link")
+		require.NoError(t, os.Symlink(target, dest))
+
+		ctx := remove.WithAllowed(context.Background(), []string{filepath.Join(dir, "other")})
+		l := &link.Link{Destination: dest, Target: target, Type: link.TypeSymbolic, State: link.StateAbsent}
+
+		_, err := l.Check(ctx, fakerenderer.New())
+		assert.Error(t, err)
+
+		_, err = l.Apply(ctx)
+		assert.Error(t, err)
+		_, err = os.Lstat(dest)
+		assert.NoError(t, err, "the link should not have been removed")
+	})
+
+	t.Run("absent-other-target", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "link")
+		require.NoError(t, os.Symlink("/other", dest))
+
+		l := &link.Link{Destination: dest, Target: target, Type: link.TypeSymbolic, State: link.StateAbsent}
+
+		_, err := l.Check(context.Background(), fakerenderer.New())
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "set force to remove it")
+		}
+
+		_, err = l.Apply(context.Background())
+		assert.Error(t, err)
+		actual, err := os.Readlink(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "/other", actual)
+	})
+}
+
+// TestLinkHard tests managing hard links
+func TestLinkHard(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		l := &link.Link{Destination: filepath.Join(dir, "link"), Target: target, Type: link.TypeHard, State: link.StatePresent}
+
+		status, err := l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+
+		_, err = l.Apply(context.Background())
+		require.NoError(t, err)
+
+		linkInfo, err := os.Stat(l.Destination)
+		require.NoError(t, err)
+		targetInfo, err := os.Stat(target)
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(linkInfo, targetInfo))
+
+		status, err = l.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("other-file", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "file")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("file"), 0600))
+
+		l := &link.Link{Destination: dest, Target: target, Type: link.TypeHard, State: link.StatePresent}
+
+		_, err := l.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+
+		_, err = l.Apply(context.Background())
+		assert.Error(t, err)
+		content, err := ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "file", string(content))
+
+		l.Force = true
+		_, err = l.Apply(context.Background())
+		require.NoError(t, err)
+
+		content, err = ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "target", string(content))
+	})
+
+	t.Run("absent-other-file", func(t *testing.T) {
+		dir, target := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "file")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("file"), 0600))
+
+		l := &link.Link{Destination: dest, Target: target, Type: link.TypeHard, State: link.StateAbsent}
+
+		_, err := l.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err, "a file that isn't linked to the target needs force")
+
+		_, err = l.Apply(context.Background())
+		assert.Error(t, err)
+		_, err = os.Stat(dest)
+		assert.NoError(t, err, "the file should not have been removed")
+	})
+
+	// a failed link leaves whatever was at the destination alone
+	t.Run("missing-target", func(t *testing.T) {
+		dir, _ := setup(t)
+		defer os.RemoveAll(dir)
+
+		dest := filepath.Join(dir, "file")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("file"), 0600))
+
+		l := &link.Link{Destination: dest, Target: filepath.Join(dir, "missing"), Type: link.TypeHard, State: link.StatePresent, Force: true}
+
+		_, err := l.Apply(context.Background())
+		assert.Error(t, err)
+
+		content, err := ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "file", string(content))
+
+		entries, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, "the temporary directory should be removed")
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package link
+
+import (
+	"errors"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"golang.org/x/net/context"
+)
+
+// Preparer for Link
+//
+// Link manages symbolic and hard links. Wrong links are replaced, but other
+// files at the destination are only replaced if `force` is set. Directories are
+// never replaced.
+type Preparer struct {
+	// Destination is the location of the link.
+	Destination string `hcl:"destination" required:"true" nonempty:"true"`
+
+	// Target is the file the link points to. It is required when state is
+	// present. When state is absent, it limits removal to links to it, and is
+	// required for hard links unless force is set, since any file is a hard
+	// link.
+	// Symbolic links store the target as written, so relative targets are
+	// resolved from the directory of the link.
+	Target string `hcl:"target"`
+
+	// Type is the type of the link.
+	// The default value is symbolic.
+	Type Type `hcl:"type" valid_values:"symbolic,hard"`
+
+	// Force controls whether a file that is not a link to the target is
+	// replaced or removed. Any regular file counts as a hard link, so replacing a file
+	// with a hard link always requires force.
+	Force bool `hcl:"force"`
+
+	// State is whether the link should be present.
+	// The default value is present.
+	State State `hcl:"state" valid_values:"present,absent"`
+}
+
+// Prepare a new task
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.Type == "" {
+		p.Type = TypeSymbolic
+	}
+
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
+	if p.State == StatePresent && p.Target == "" {
+		return nil, errors.New("\"target\" is required when state is present")
+	}
+
+	if p.State == StateAbsent && p.Type == TypeHard && p.Target == "" && !p.Force {
+		return nil, errors.New("\"target\" or \"force\" is required to remove a hard link, since any file is a hard link")
+	}
+
+	return &Link{
+		Destination: p.Destination,
+		Target:      p.Target,
+		Type:        p.Type,
+		Force:       p.Force,
+		State:       p.State,
+	}, nil
+}
+
+func init() {
+	registry.Register("file.link", (*Preparer)(nil), (*Link)(nil))
+}
@@ -0,0 +1,72 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package link_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/link"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPreparerInterface tests that the Preparer interface is properly implemented
+func TestPreparerInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Resource)(nil), new(link.Preparer))
+}
+
+// TestPreparerPrepare tests preparing links
+func TestPreparerPrepare(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		prep := &link.Preparer{Destination: "/tmp/link", Target: "/tmp/target"}
+		task, err := prep.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		l, ok := task.(*link.Link)
+		require.True(t, ok)
+		assert.Equal(t, link.TypeSymbolic, l.Type)
+		assert.Equal(t, link.StatePresent, l.State)
+		assert.Equal(t, "/tmp/target", l.Target)
+	})
+
+	t.Run("target-required", func(t *testing.T) {
+		prep := &link.Preparer{Destination: "/tmp/link"}
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, `"target" is required when state is present`)
+	})
+
+	t.Run("absent-without-target", func(t *testing.T) {
+		prep := &link.Preparer{Destination: "/tmp/link", State: link.StateAbsent}
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		assert.NoError(t, err)
+	})
+
+	t.Run("absent-hard-without-target", func(t *testing.T) {
+		prep := &link.Preparer{Destination: "/tmp/link", Type: link.TypeHard, State: link.StateAbsent}
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, `"target" or "force" is required to remove a hard link, since any file is a hard link`)
+
+		prep.Force = true
+		_, err = prep.Prepare(context.Background(), fakerenderer.New())
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remove
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+type allowedKey struct{}
+
+// WithAllowed returns a context which only allows removing paths inside the
+// given directories. If none are given, any path but the root may be removed.
+func WithAllowed(ctx context.Context, paths []string) context.Context {
+	return context.WithValue(ctx, allowedKey{}, paths)
+}
+
+// AllowedFromContext gets the directories set with WithAllowed
+func AllowedFromContext(ctx context.Context) []string {
+	paths, _ := ctx.Value(allowedKey{}).([]string)
+	return paths
+}
+
+// Guard returns an error if the path may not be removed. The root directory
+// can never be removed, and if directories were set with WithAllowed the path
+// must be one of them or inside one of them. Symbolic links in the directories
+// above the path are followed, so a link inside an allowed directory can't be
+// used to remove files outside of it.
+func Guard(ctx context.Context, path string) error {
+	abs, err := resolve(path)
+	if err != nil {
+		return err
+	}
+
+	if abs == string(filepath.Separator) {
+		return fmt.Errorf("refusing to remove %q, it is the root directory", path)
+	}
+
+	allowed := AllowedFromContext(ctx)
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, dir := range allowed {
+		dirAbs, err := resolveDir(dir)
+		if err != nil {
+			return err
+		}
+
+		if abs == dirAbs || strings.HasPrefix(abs, strings.TrimSuffix(dirAbs, string(filepath.Separator))+string(filepath.Separator)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("refusing to remove %q, it is not inside an allowed path (%s)", path, strings.Join(allowed, ", "))
+}
+
+// resolve returns the absolute path of a file with symbolic links in its
+// parent directories evaluated. The file itself is not followed, since
+// removing a link removes the link rather than what it points to. Parent
+// directories that don't exist are left as they are.
+func resolve(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not resolve %q", path)
+	}
+
+	dir, base := filepath.Split(abs)
+	if base == "" {
+		return abs, nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(dir)
+	if os.IsNotExist(err) {
+		if resolved, err = resolve(filepath.Clean(dir)); err != nil {
+			return "", err
+		}
+	} else if err != nil {
+		return "", errors.Wrapf(err, "could not resolve %q", path)
+	}
+
+	return filepath.Join(resolved, base), nil
+}
+
+// resolveDir returns the absolute path of a directory with all symbolic links
+// evaluated, including the directory itself
+func resolveDir(dir string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(dir)
+	if os.IsNotExist(err) {
+		return resolve(dir)
+	} else if err != nil {
+		return "", errors.Wrapf(err, "could not resolve %q", dir)
+	}
+
+	return filepath.Abs(resolved)
+}
+
+// Count returns the number of files in a directory tree, not counting the
+// directories themselves
+func Count(dir string) (int, error) {
+	var count int
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, errors.Wrapf(err, "could not count files in %q", dir)
+	}
+
+	return count, nil
+}
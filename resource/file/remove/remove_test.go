@@ -0,0 +1,108 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remove_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/file/remove"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestGuard tests which paths may be removed
+func TestGuard(t *testing.T) {
+	t.Parallel()
+
+	t.Run("root", func(t *testing.T) {
+		err := remove.Guard(context.Background(), "/")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "it is the root directory")
+		}
+
+		assert.Error(t, remove.Guard(context.Background(), "/tmp/.."))
+	})
+
+	t.Run("no-allowlist", func(t *testing.T) {
+		assert.NoError(t, remove.Guard(context.Background(), "/etc/old"))
+	})
+
+	t.Run("allowlist", func(t *testing.T) {
+		ctx := remove.WithAllowed(context.Background(), []string{"/srv/app", "/var/tmp/"})
+
+		assert.NoError(t, remove.Guard(ctx, "/srv/app"))
+		assert.NoError(t, remove.Guard(ctx, "/srv/app/releases/1"))
+		assert.NoError(t, remove.Guard(ctx, "/var/tmp/cache"))
+
+		err := remove.Guard(ctx, "/srv/application")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "not inside an allowed path")
+		}
+		assert.Error(t, remove.Guard(ctx, "/srv/app/../other"))
+		assert.Error(t, remove.Guard(ctx, "/etc/passwd"))
+	})
+
+	t.Run("symlinked-parent", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "test-remove-guard")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		allowed := filepath.Join(dir, "allowed")
+		outside := filepath.Join(dir, "outside")
+		require.NoError(t, os.Mkdir(allowed, 0700))
+		require.NoError(t, os.Mkdir(outside, 0700))
+		require.NoError(t, os.Symlink(outside, filepath.Join(allowed, "escape")))
+		require.NoError(t, os.Symlink(allowed, filepath.Join(dir, "alias")))
+
+		ctx := remove.WithAllowed(context.Background(), []string{allowed})
+
+		err = remove.Guard(ctx, filepath.Join(allowed, "escape", "file"))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "not inside an allowed path")
+		}
+
+		// the link itself is inside the allowed directory
+		assert.NoError(t, remove.Guard(ctx, filepath.Join(allowed, "escape")))
+		assert.NoError(t, remove.Guard(ctx, filepath.Join(allowed, "missing", "file")))
+		assert.NoError(t, remove.Guard(ctx, filepath.Join(dir, "alias", "file")))
+
+		// allowed directories are resolved too
+		ctx = remove.WithAllowed(context.Background(), []string{filepath.Join(dir, "alias")})
+		assert.NoError(t, remove.Guard(ctx, filepath.Join(allowed, "file")))
+		assert.Error(t, remove.Guard(ctx, filepath.Join(outside, "file")))
+	})
+}
+
+// TestCount tests counting files in a directory tree
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "test-remove-count")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "one"), nil, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", "two"), nil, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", "b", "three"), nil, 0600))
+
+	count, err := remove.Count(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,134 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/owner"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// Preparer for Template
+//
+// Template renders a template file to disk. The template is loaded from
+// `source`, relative to the module it is used in, and rendered with the same
+// template language as any other field (including `param` and `lookup`.)
+type Preparer struct {
+	// Source is the location of the template. It is resolved relative to the
+	// module that contains this resource, and is fetched when the module is
+	// loaded. It cannot contain template calls.
+	Source string `hcl:"source" required:"true" nonempty:"true"`
+
+	// Content is set from the template at `source` when the module is loaded,
+	// and should not be set directly.
+	Content string `hcl:"content"`
+
+	// Destination is the location on disk where the template will be rendered.
+	Destination string `hcl:"destination" required:"true" nonempty:"true"`
+
+	// Mode is the mode of the rendered file, specified in octal. If not set,
+	// the mode of an existing file is kept and new files are created with
+	// 0600.
+	Mode *uint32 `hcl:"mode" base:"8"`
+
+	// User is the name of the user that should own the rendered file
+	User string `hcl:"user"`
+
+	// Group is the name of the group that should own the rendered file
+	Group string `hcl:"group"`
+
+	// Backup controls whether an existing file is copied to a timestamped
+	// backup next to the destination before it is overwritten.
+	Backup bool `hcl:"backup"`
+
+	// BackupDir is the directory backups are made in. If not set, backups are
+	// made next to the destination.
+	BackupDir string `hcl:"backup_dir" nonempty:"true"`
+
+	// BackupKeep is the number of backups of the destination that are kept.
+	// Older backups are removed. If not set, all backups are kept.
+	BackupKeep int `hcl:"backup_keep"`
+
+	osProxy owner.OSProxy
+}
+
+// Prepare a new task
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.osProxy == nil {
+		p.osProxy = &owner.OSExecutor{}
+	}
+
+	tmpl := &Template{
+		Source:      p.Source,
+		Content:     p.Content,
+		Destination: p.Destination,
+		Backup:      p.Backup,
+		BackupDir:   p.BackupDir,
+		BackupKeep:  p.BackupKeep,
+		osProxy:     p.osProxy,
+	}
+
+	if p.Mode != nil {
+		mode := os.FileMode(*p.Mode)
+		tmpl.Mode = &mode
+	}
+
+	if p.User != "" {
+		u, err := p.osProxy.Lookup(p.User)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find user %q", p.User)
+		}
+
+		uid, err := strconv.Atoi(u.Uid)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid uid for user %q", p.User)
+		}
+
+		tmpl.User = p.User
+		tmpl.UID = &uid
+	}
+
+	if p.Group != "" {
+		g, err := p.osProxy.LookupGroup(p.Group)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find group %q", p.Group)
+		}
+
+		gid, err := strconv.Atoi(g.Gid)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid gid for group %q", p.Group)
+		}
+
+		tmpl.Group = p.Group
+		tmpl.GID = &gid
+	}
+
+	return tmpl, nil
+}
+
+// SetOSProxy sets the private os proxy for mocking in tests
+func (p *Preparer) SetOSProxy(o owner.OSProxy) *Preparer {
+	p.osProxy = o
+	return p
+}
+
+func init() {
+	registry.Register("file.template", (*Preparer)(nil), (*Template)(nil))
+}
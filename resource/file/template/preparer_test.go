@@ -0,0 +1,93 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template_test
+
+import (
+	"fmt"
+	"os/user"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPreparerInterface tests that the Preparer interface is properly implemented
+func TestPreparerInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Resource)(nil), new(template.Preparer))
+}
+
+// TestPreparerPrepare tests preparing templates
+func TestPreparerPrepare(t *testing.T) {
+	t.Parallel()
+
+	t.Run("basic", func(t *testing.T) {
+		var mode uint32 = 0640
+		prep := &template.Preparer{
+			Source:      "test.tmpl",
+			Content:     "content",
+			Destination: "/tmp/test",
+			Mode:        &mode,
+			Backup:      true,
+		}
+
+		task, err := prep.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		tmpl, ok := task.(*template.Template)
+		require.True(t, ok)
+		assert.Equal(t, "content", tmpl.Content)
+		assert.Equal(t, "/tmp/test", tmpl.Destination)
+		assert.True(t, tmpl.Backup)
+		require.NotNil(t, tmpl.Mode)
+		assert.EqualValues(t, 0640, *tmpl.Mode)
+		assert.Nil(t, tmpl.UID)
+		assert.Nil(t, tmpl.GID)
+	})
+
+	t.Run("user", func(t *testing.T) {
+		current, err := user.Current()
+		require.NoError(t, err)
+
+		prep := &template.Preparer{
+			Source:      "test.tmpl",
+			Destination: "/tmp/test",
+			User:        current.Username,
+		}
+
+		task, err := prep.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		tmpl := task.(*template.Template)
+		require.NotNil(t, tmpl.UID)
+		assert.Equal(t, current.Uid, fmt.Sprint(*tmpl.UID))
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		prep := &template.Preparer{
+			Source:      "test.tmpl",
+			Destination: "/tmp/test",
+			User:        "converge-no-such-user",
+		}
+
+		_, err := prep.Prepare(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,230 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/backup"
+	"github.com/asteris-llc/converge/resource/file/mode"
+	"github.com/asteris-llc/converge/resource/file/owner"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// Template renders a template file to disk
+type Template struct {
+	// the location the template was loaded from
+	Source string `export:"source"`
+
+	// the rendered content of the template
+	Content string `export:"content"`
+
+	// the location on disk where the template will be rendered
+	Destination string `export:"destination"`
+
+	// the mode of the rendered file, if set
+	Mode *os.FileMode `export:"mode"`
+
+	// the name of the user that should own the rendered file, if set
+	User string `export:"user"`
+
+	// the uid of the user that should own the rendered file, if set
+	UID *int `export:"uid"`
+
+	// the name of the group that should own the rendered file, if set
+	Group string `export:"group"`
+
+	// the gid of the group that should own the rendered file, if set
+	GID *int `export:"gid"`
+
+	// whether an existing file will be backed up before it is overwritten
+	Backup bool `export:"backup"`
+
+	// the directory backups are made in, if not next to the file
+	BackupDir string `export:"backupdir"`
+
+	// the number of backups that are kept, if limited
+	BackupKeep int `export:"backupkeep"`
+
+	// the location of the backup made during apply, if any
+	BackupPath string `export:"backuppath"`
+
+	osProxy owner.OSProxy
+}
+
+// Check if the template needs to be rendered
+func (t *Template) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	stat, err := os.Stat(t.Destination)
+	if os.IsNotExist(err) {
+		status.AddMessage(t.Destination + ": File is missing")
+		status.Differences[t.Destination] = t.missingFileDiff()
+		if t.Mode != nil {
+			status.Differences["mode"] = &mode.FileModeDiff{Expected: *t.Mode}
+		}
+		if t.UID != nil || t.GID != nil {
+			status.AddDifference("owner", "", t.ownerString(), "")
+		}
+		status.RaiseLevel(resource.StatusWillChange)
+		return status, nil
+	} else if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot read %q", t.Destination)
+	} else if stat.IsDir() {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, fmt.Errorf("cannot render template to %q, it is a directory", t.Destination)
+	}
+
+	actual, err := ioutil.ReadFile(t.Destination)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot read %q", t.Destination)
+	}
+
+	if string(actual) != t.Content {
+		status.AddMessage("contents differ")
+		status.Differences[t.Destination] = resource.NewUnifiedDiff(string(actual), t.Content)
+	}
+
+	if t.Mode != nil {
+		modeDiff := &mode.FileModeDiff{Actual: stat.Mode().Perm(), Expected: *t.Mode}
+		if modeDiff.Changes() {
+			status.Differences["mode"] = modeDiff
+		}
+	}
+
+	ownerDiff, err := t.ownershipDiff()
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+	if ownerDiff != nil && ownerDiff.Changes() {
+		status.Differences["owner"] = ownerDiff
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// Apply renders the template to disk
+func (t *Template) Apply(context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	var perm os.FileMode = 0600
+	contentDiff := t.missingFileDiff()
+
+	stat, err := os.Stat(t.Destination)
+	if err == nil {
+		perm = stat.Mode().Perm()
+
+		actual, readErr := ioutil.ReadFile(t.Destination)
+		if readErr != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, errors.Wrapf(readErr, "cannot read %q", t.Destination)
+		}
+		contentDiff = resource.NewUnifiedDiff(string(actual), t.Content)
+	} else if !os.IsNotExist(err) {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, errors.Wrapf(err, "cannot read %q", t.Destination)
+	}
+
+	if t.Mode != nil {
+		perm = *t.Mode
+	}
+
+	if stat == nil || contentDiff.Changes() {
+		if t.Backup && stat != nil {
+			opts := backup.Options{Dir: t.BackupDir, Keep: t.BackupKeep}
+			t.BackupPath, err = opts.Create(t.Destination)
+			if err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, err
+			}
+			status.AddMessage("backed up to " + t.BackupPath)
+		}
+
+		if err := ioutil.WriteFile(t.Destination, []byte(t.Content), perm); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, errors.Wrapf(err, "cannot write %q", t.Destination)
+		}
+		status.Differences[t.Destination] = contentDiff
+	}
+
+	// WriteFile only uses perm when creating the file, so existing files
+	// still need to have their mode set
+	if t.Mode != nil {
+		if err := os.Chmod(t.Destination, *t.Mode); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, errors.Wrapf(err, "failed to set mode on %q", t.Destination)
+		}
+	}
+
+	ownerDiff, err := t.ownershipDiff()
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+	if ownerDiff != nil && ownerDiff.Changes() {
+		if err := ownerDiff.Apply(); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, errors.Wrapf(err, "failed to set owner on %q", t.Destination)
+		}
+		status.Differences["owner"] = ownerDiff
+	}
+
+	return status, nil
+}
+
+// ownershipDiff returns the difference in ownership of the destination, or nil
+// if no ownership was requested
+func (t *Template) ownershipDiff() (*owner.OwnershipDiff, error) {
+	if t.UID == nil && t.GID == nil {
+		return nil, nil
+	}
+
+	diff, err := owner.NewOwnershipDiff(t.osProxy, t.Destination, &owner.Ownership{UID: t.UID, GID: t.GID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get ownership of %q", t.Destination)
+	}
+
+	return diff, nil
+}
+
+// missingFileDiff returns the diff of the template content against a file
+// that doesn't exist yet
+func (t *Template) missingFileDiff() *resource.UnifiedDiff {
+	diff := resource.NewUnifiedDiff("", t.Content)
+	diff.Placeholder = "<file-missing>"
+	return diff
+}
+
+func (t *Template) ownerString() string {
+	var out string
+	if t.UID != nil {
+		out = fmt.Sprintf("user: %s (%d)", t.User, *t.UID)
+	}
+	if t.GID != nil {
+		if out != "" {
+			out += "; "
+		}
+		out += fmt.Sprintf("group: %s (%d)", t.Group, *t.GID)
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestTemplateInterface tests that Template is properly implemented
+func TestTemplateInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Task)(nil), new(template.Template))
+}
+
+// TestTemplateCheck tests checking templates
+func TestTemplateCheck(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-template")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("missing", func(t *testing.T) {
+		tmpl := prepare(t, filepath.Join(dir, "missing"), "content", nil)
+
+		status, err := tmpl.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.Equal(t, resource.StatusWillChange, status.StatusCode())
+		assert.True(t, status.HasChanges())
+	})
+
+	t.Run("same", func(t *testing.T) {
+		dest := filepath.Join(dir, "same")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("content"), 0600))
+		tmpl := prepare(t, dest, "content", nil)
+
+		status, err := tmpl.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("different content", func(t *testing.T) {
+		dest := filepath.Join(dir, "different")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("old"), 0600))
+		tmpl := prepare(t, dest, "new", nil)
+
+		status, err := tmpl.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.True(t, status.HasChanges())
+		assert.Equal(t, "old", status.Diffs()[dest].Original())
+		assert.Equal(t, "new", status.Diffs()[dest].Current())
+	})
+
+	t.Run("different mode", func(t *testing.T) {
+		dest := filepath.Join(dir, "mode")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("content"), 0600))
+		var mode uint32 = 0640
+		tmpl := prepare(t, dest, "content", &mode)
+
+		status, err := tmpl.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.True(t, status.HasChanges())
+		assert.Contains(t, status.Diffs(), "mode")
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		tmpl := prepare(t, dir, "content", nil)
+
+		_, err := tmpl.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+}
+
+// TestTemplateApply tests applying templates
+func TestTemplateApply(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-template")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("new file", func(t *testing.T) {
+		dest := filepath.Join(dir, "new")
+		var mode uint32 = 0640
+		tmpl := prepare(t, dest, "content", &mode)
+
+		_, err := tmpl.Apply(context.Background())
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+
+		stat, err := os.Stat(dest)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), stat.Mode().Perm())
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		dest := filepath.Join(dir, "backup")
+		require.NoError(t, ioutil.WriteFile(dest, []byte("old"), 0600))
+		tmpl := prepare(t, dest, "new", nil)
+		tmpl.Backup = true
+
+		_, err := tmpl.Apply(context.Background())
+		require.NoError(t, err)
+
+		require.NotEqual(t, "", tmpl.BackupPath)
+		backedUp, err := ioutil.ReadFile(tmpl.BackupPath)
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(backedUp))
+
+		content, err := ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(content))
+	})
+}
+
+func prepare(t *testing.T, dest, content string, mode *uint32) *template.Template {
+	prep := &template.Preparer{
+		Source:      "test.tmpl",
+		Content:     content,
+		Destination: dest,
+		Mode:        mode,
+	}
+
+	task, err := prep.Prepare(context.Background(), fakerenderer.New())
+	require.NoError(t, err)
+
+	return task.(*template.Template)
+}
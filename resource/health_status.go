@@ -43,6 +43,10 @@ type HealthStatus struct {
 	WarningLevel HealthStatusCode
 	DisplayLevel *HealthStatusCode
 	FailingDeps  map[string]string
+
+	// Details are messages from the check explaining the health level, like
+	// the reason a service failed
+	Details []string
 }
 
 // HasFailingDeps returns true of FailingDeps is not empty
@@ -91,7 +95,7 @@ func (h *HealthStatus) Messages() []string {
 	if depCount := len(h.FailingDeps); depCount > 0 {
 		messages = append(messages, fmt.Sprintf("%d failing dependencies", depCount))
 	}
-	return messages
+	return append(messages, h.Details...)
 }
 
 // Changes returns changes from the underlying TaskStatus diffs
@@ -26,3 +26,12 @@ func Test_HeathStatus_ImplementsPrinterInterface(t *testing.T) {
 	t.Parallel()
 	assert.Implements(t, (*human.Printable)(nil), new(resource.HealthStatus))
 }
+
+func Test_HealthStatus_MessagesIncludeDetails(t *testing.T) {
+	t.Parallel()
+	status := &resource.HealthStatus{
+		TaskStatus: resource.NewStatus(),
+		Details:    []string{"unit has failed"},
+	}
+	assert.Equal(t, []string{"unit has failed"}, status.Messages())
+}
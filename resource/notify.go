@@ -0,0 +1,32 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+// MetaNotifiedBy is the graph node metadata key for the IDs of the resources
+// that notify a handler, set from the "notifies" and "subscribes" fields that
+// every resource accepts
+const MetaNotifiedBy = "notified-by"
+
+// NotifiersFor gets the IDs of the resources that notify the handler at a
+// graph node. The second return value is false if the node is not a handler.
+func NotifiersFor(meta metadataLookup) ([]string, bool) {
+	raw, ok := meta.LookupMetadata(MetaNotifiedBy)
+	if !ok {
+		return nil, false
+	}
+
+	ids, ok := raw.([]string)
+	return ids, ok
+}
@@ -0,0 +1,160 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
+)
+
+// WorldFile lists the packages apk keeps installed, along with their version
+// constraints
+const WorldFile = "/etc/apk/world"
+
+// Manager provides a concrete implementation of PackageManager for Alpine
+// packages.
+type Manager struct {
+	Sys pkg.SysCaller
+}
+
+// InstalledVersion gets the installed version of package, if available
+func (a *Manager) InstalledVersion(p string) (pkg.PackageVersion, bool) {
+	result, err := a.Sys.Run(fmt.Sprintf("apk info -ve %s", p))
+	if exitCode, _ := pkg.GetExitCode(err); exitCode != 0 {
+		return "", false
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(result)), "\n") {
+		if strings.HasPrefix(line, p+"-") {
+			return pkg.PackageVersion(strings.TrimPrefix(line, p+"-")), true
+		}
+	}
+	return "", false
+}
+
+// LatestVersion gets the newest version of a package in the configured
+// repositories
+func (a *Manager) LatestVersion(p string) (pkg.PackageVersion, error) {
+	versions, err := a.availableVersions(p)
+	if err != nil {
+		return "", err
+	}
+
+	var latest pkg.PackageVersion
+	for _, version := range versions {
+		if pkg.CompareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no installable version of %s", p)
+	}
+	return latest, nil
+}
+
+// ResolveVersion finds the newest available version of a package that a
+// version without a package release refers to
+func (a *Manager) ResolveVersion(p string, wanted pkg.PackageVersion) (pkg.PackageVersion, error) {
+	versions, err := a.availableVersions(p)
+	if err != nil {
+		return "", err
+	}
+
+	var resolved pkg.PackageVersion
+	for _, version := range versions {
+		if pkg.VersionMatches(version, wanted) && pkg.CompareVersions(version, resolved) > 0 {
+			resolved = version
+		}
+	}
+	if resolved == "" {
+		return "", fmt.Errorf("no available version of %s matches %q", p, wanted)
+	}
+	return resolved, nil
+}
+
+// availableVersions lists the versions of a package in the configured
+// repositories, including the installed one
+func (a *Manager) availableVersions(p string) ([]pkg.PackageVersion, error) {
+	result, err := a.Sys.Run(fmt.Sprintf("apk policy %s", p))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not query available versions of %s", p)
+	}
+
+	// versions are listed indented by two spaces, followed by the
+	// repositories they are in
+	var versions []pkg.PackageVersion
+	for _, line := range strings.Split(string(result), "\n") {
+		if !strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "   ") || !strings.HasSuffix(line, ":") {
+			continue
+		}
+		versions = append(versions, pkg.PackageVersion(strings.TrimSuffix(strings.TrimSpace(line), ":")))
+	}
+	return versions, nil
+}
+
+// InstallPackages installs several packages with a single apk call. Packages
+// with a version are pinned to it in the world file, so they also stay at
+// that version.
+func (a *Manager) InstallPackages(specs []pkg.PackageSpec) (string, error) {
+	args := make([]string, len(specs))
+	for i, spec := range specs {
+		args[i] = spec.Name
+		if spec.Version != "" {
+			args[i] = fmt.Sprintf("%s=%s", spec.Name, spec.Version)
+		}
+	}
+	res, err := a.Sys.Run(fmt.Sprintf("apk add %s", strings.Join(args, " ")))
+	return string(res), err
+}
+
+// RemovePackages removes several packages with a single apk call
+func (a *Manager) RemovePackages(names []string) (string, error) {
+	res, err := a.Sys.Run(fmt.Sprintf("apk del %s", strings.Join(names, " ")))
+	return string(res), err
+}
+
+// IsHeld returns true if the package is pinned to a version in the world
+// file
+func (a *Manager) IsHeld(p string) (bool, error) {
+	result, err := a.Sys.Run("cat " + WorldFile)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not read %s", WorldFile)
+	}
+	for _, line := range strings.Split(string(result), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, p) && strings.IndexAny(line, "=<>~") == len(p) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HoldPackage pins a package to its installed version in the world file
+func (a *Manager) HoldPackage(p string) (string, error) {
+	version, isInstalled := a.InstalledVersion(p)
+	if !isInstalled {
+		return "", fmt.Errorf("cannot hold %s, it is not installed", p)
+	}
+	res, err := a.Sys.Run(fmt.Sprintf("apk add %s=%s", p, version))
+	return string(res), err
+}
+
+// UnholdPackage removes the version pin of a package from the world file
+func (a *Manager) UnholdPackage(p string) (string, error) {
+	res, err := a.Sys.Run(fmt.Sprintf("apk add %s", p))
+	return string(res), err
+}
@@ -0,0 +1,142 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk_test
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/asteris-llc/converge/resource/package/apk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestApkInstalledVersion validates that the installed version is read from
+// apk info
+func TestApkInstalledVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when installed", func(t *testing.T) {
+		a := &apk.Manager{Sys: newRunner("foo-bar-1.2.3-r0\n", nil)}
+		result, found := a.InstalledVersion("foo-bar")
+		assert.True(t, found)
+		assert.Equal(t, "1.2.3-r0", string(result))
+	})
+
+	t.Run("when not installed", func(t *testing.T) {
+		a := &apk.Manager{Sys: newRunner("", makeExitError("", 1))}
+		_, found := a.InstalledVersion("foo")
+		assert.False(t, found)
+	})
+}
+
+// TestApkLatestVersion validates that the newest version is read from apk
+// policy
+func TestApkLatestVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when available", func(t *testing.T) {
+		out := "foo policy:\n  1.2.3-r0:\n    lib/apk/db/installed\n  1.2.4-r1:\n    http://dl-cdn.alpinelinux.org/alpine/v3.5/main\n"
+		a := &apk.Manager{Sys: newRunner(out, nil)}
+		result, err := a.LatestVersion("foo")
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.4-r1", string(result))
+	})
+
+	t.Run("when not available", func(t *testing.T) {
+		a := &apk.Manager{Sys: newRunner("", nil)}
+		_, err := a.LatestVersion("foo")
+		assert.Error(t, err)
+	})
+}
+
+// TestApkResolveVersion validates that a version without a release is
+// resolved to the newest available release of it
+func TestApkResolveVersion(t *testing.T) {
+	t.Parallel()
+
+	out := "foo policy:\n  1.2.3-r0:\n    lib/apk/db/installed\n  1.2.3-r2:\n    http://dl-cdn.alpinelinux.org/alpine/v3.5/main\n  1.2.4-r1:\n    http://dl-cdn.alpinelinux.org/alpine/v3.5/main\n"
+
+	t.Run("when available", func(t *testing.T) {
+		a := &apk.Manager{Sys: newRunner(out, nil)}
+		result, err := a.ResolveVersion("foo", "1.2.3")
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.3-r2", string(result))
+	})
+
+	t.Run("when not available", func(t *testing.T) {
+		a := &apk.Manager{Sys: newRunner(out, nil)}
+		_, err := a.ResolveVersion("foo", "1.3")
+		assert.Error(t, err)
+	})
+}
+
+// TestApkInstallPackages validates that several packages are installed with a
+// single call
+func TestApkInstallPackages(t *testing.T) {
+	t.Parallel()
+
+	runner := newRunner("", nil)
+	a := &apk.Manager{Sys: runner}
+	_, err := a.InstallPackages([]pkg.PackageSpec{{Name: "foo"}, {Name: "bar", Version: "1.0-r0"}})
+	assert.NoError(t, err)
+	runner.AssertNumberOfCalls(t, "Run", 1)
+}
+
+// TestApkIsHeld validates that pins are read from the world file
+func TestApkIsHeld(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when pinned", func(t *testing.T) {
+		a := &apk.Manager{Sys: newRunner("alpine-base\nfoo=1.2.3-r0\nfoo-doc\n", nil)}
+		held, err := a.IsHeld("foo")
+		assert.NoError(t, err)
+		assert.True(t, held)
+	})
+
+	t.Run("when not pinned", func(t *testing.T) {
+		a := &apk.Manager{Sys: newRunner("alpine-base\nfoo\nfoo-doc=1.0-r0\n", nil)}
+		held, err := a.IsHeld("foo")
+		assert.NoError(t, err)
+		assert.False(t, held)
+	})
+}
+
+// MockRunner mocks out SysCaller
+type MockRunner struct {
+	mock.Mock
+}
+
+// Run mocks out Run
+func (m *MockRunner) Run(cmd string) ([]byte, error) {
+	args := m.Called(1)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// newRunner creates a new MockRunner that returns the output string and error
+func newRunner(output string, err error) *MockRunner {
+	m := &MockRunner{}
+	m.On("Run", mock.Anything).Return([]byte(output), err)
+	return m
+}
+
+// makeExitError generates a new ExitError
+func makeExitError(stderr string, exitCode uint32) error {
+	cmd := fmt.Sprintf("echo %q 1>&2; exit %d", stderr, exitCode)
+	_, err := exec.Command("/bin/bash", "-c", cmd).Output()
+	return err
+}
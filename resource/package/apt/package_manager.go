@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
 )
 
 // Outputs from dpkg-query
@@ -56,14 +57,14 @@ func (a *Manager) InstalledVersion(p string) (pkg.PackageVersion, bool) {
 	for _, line := range strings.Split(strings.TrimSpace(string(result)), "\n") {
 		l := strings.Split(line, ",")
 		if len(l) == 3 {
-			name, status, ver := l[0], l[1], l[2]
+			status, ver := l[1], l[2]
 
 			if strings.Contains(status, PkgRemoved) || strings.Contains(status, PkgUninstalled) {
 				return "", false
 			}
 
 			if strings.Contains(status, PkgInstalled) || strings.Contains(status, PkgHold) {
-				version = fmt.Sprintf("%s-%s", name, ver)
+				version = ver
 				installed = true
 			}
 		}
@@ -71,23 +72,97 @@ func (a *Manager) InstalledVersion(p string) (pkg.PackageVersion, bool) {
 	return (pkg.PackageVersion)(version), installed
 }
 
-// InstallPackage installs a package, returning an error if something went wrong
-func (a *Manager) InstallPackage(p string) (string, error) {
-	if _, isInstalled := a.InstalledVersion(p); isInstalled {
-		return "already installed", nil
+// LatestVersion gets the version apt would install, taking pinning into
+// account
+func (a *Manager) LatestVersion(p string) (pkg.PackageVersion, error) {
+	result, err := a.Sys.Run(fmt.Sprintf("apt-cache policy %s", p))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not query available versions of %s", p)
 	}
-	res, err := a.Sys.Run(fmt.Sprintf("apt-get install -y %s", p))
+	for _, line := range strings.Split(string(result), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "Candidate:") {
+			continue
+		}
+		candidate := strings.TrimSpace(strings.TrimPrefix(line, "Candidate:"))
+		if candidate == "" || candidate == "(none)" {
+			break
+		}
+		return pkg.PackageVersion(candidate), nil
+	}
+	return "", fmt.Errorf("no installable version of %s", p)
+}
+
+// ResolveVersion finds the full version of a package that a version without a
+// Debian revision refers to. The candidate version is used if it matches, and
+// otherwise the newest matching version listed by `apt-cache madison`.
+func (a *Manager) ResolveVersion(p string, wanted pkg.PackageVersion) (pkg.PackageVersion, error) {
+	if candidate, err := a.LatestVersion(p); err == nil && pkg.VersionMatches(candidate, wanted) {
+		return candidate, nil
+	}
+
+	result, err := a.Sys.Run(fmt.Sprintf("apt-cache madison %s", p))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not query available versions of %s", p)
+	}
+
+	// each line is "name | version | source", newest first
+	for _, line := range strings.Split(string(result), "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) < 3 {
+			continue
+		}
+		version := pkg.PackageVersion(strings.TrimSpace(fields[1]))
+		if pkg.VersionMatches(version, wanted) {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("no available version of %s matches %q", p, wanted)
+}
+
+// InstallPackages installs several packages with a single apt-get call.
+// Packages with a version are pinned to it, which may downgrade them.
+// Downgrading needs `--allow-downgrades`, which was added in apt 1.1.
+func (a *Manager) InstallPackages(specs []pkg.PackageSpec) (string, error) {
+	args := make([]string, len(specs))
+	for i, spec := range specs {
+		args[i] = spec.Name
+		if spec.Version != "" {
+			args[i] = fmt.Sprintf("%s=%s", spec.Name, spec.Version)
+		}
+	}
+	res, err := a.Sys.Run(fmt.Sprintf("apt-get install -y --allow-downgrades %s", strings.Join(args, " ")))
 	return string(res), err
 }
 
-// RemovePackage removes a package, returning an error if something went wrong
-func (a *Manager) RemovePackage(p string) (string, error) {
-	switch _, isInstalled := a.InstalledVersion(p); isInstalled {
-	case true:
-		res, err := a.Sys.Run(fmt.Sprintf("apt-get purge -y %s", p))
-		return string(res), err
-	default:
-		return "package is not installed ", nil
+// RemovePackages removes several packages with a single apt-get call
+func (a *Manager) RemovePackages(names []string) (string, error) {
+	res, err := a.Sys.Run(fmt.Sprintf("apt-get purge -y %s", strings.Join(names, " ")))
+	return string(res), err
+}
+
+// IsHeld returns true if the package is held with `apt-mark hold`
+func (a *Manager) IsHeld(p string) (bool, error) {
+	result, err := a.Sys.Run(fmt.Sprintf("dpkg-query -W -f'${Status}\n' %s", p))
+	if exitCode, _ := pkg.GetExitCode(err); exitCode != 0 {
+		return false, nil
 	}
+	for _, line := range strings.Split(strings.TrimSpace(string(result)), "\n") {
+		if strings.HasPrefix(line, "hold ") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HoldPackage holds a package at its installed version
+func (a *Manager) HoldPackage(p string) (string, error) {
+	res, err := a.Sys.Run(fmt.Sprintf("apt-mark hold %s", p))
+	return string(res), err
+}
 
+// UnholdPackage releases the hold on a package
+func (a *Manager) UnholdPackage(p string) (string, error) {
+	res, err := a.Sys.Run(fmt.Sprintf("apt-mark unhold %s", p))
+	return string(res), err
 }
@@ -19,6 +19,7 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/asteris-llc/converge/resource/package"
 	"github.com/asteris-llc/converge/resource/package/apt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,7 +31,7 @@ func TestAptInstalledVersion(t *testing.T) {
 	t.Parallel()
 
 	t.Run("when installed", func(t *testing.T) {
-		expected := "foo-0.1.2.3"
+		expected := "0.1.2.3"
 		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgInstalled)
 		runner := newRunner(out, nil)
 		a := &apt.Manager{Sys: runner}
@@ -48,7 +49,7 @@ func TestAptInstalledVersion(t *testing.T) {
 	})
 
 	t.Run("when held", func(t *testing.T) {
-		expected := "foo-0.1.2.3"
+		expected := "0.1.2.3"
 		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgHold)
 		runner := newRunner(out, nil)
 		a := &apt.Manager{Sys: runner}
@@ -79,123 +80,126 @@ func TestAptInstalledVersion(t *testing.T) {
 
 }
 
-// TestAptInstallPackage validates that we successfully ask Apt to install a
-// package
-func TestAptInstallPackage(t *testing.T) {
+// TestAptInstallPackages validates that several packages are installed with
+// a single call
+func TestAptInstallPackages(t *testing.T) {
 	t.Parallel()
 
-	t.Run("when installed", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgInstalled)
-		runner := newRunner(out, nil)
+	runner := newRunner("", nil)
+	a := &apt.Manager{Sys: runner}
+	_, err := a.InstallPackages([]pkg.PackageSpec{{Name: "foo"}, {Name: "bar", Version: "1.0-1"}})
+	assert.NoError(t, err)
+	runner.AssertNumberOfCalls(t, "Run", 1)
+}
+
+// TestAptRemovePackages validates that several packages are removed with a
+// single call
+func TestAptRemovePackages(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when removing", func(t *testing.T) {
+		runner := newRunner("", nil)
 		a := &apt.Manager{Sys: runner}
-		_, err := a.InstallPackage(pkg)
+		_, err := a.RemovePackages([]string{"foo", "bar"})
 		assert.NoError(t, err)
 		runner.AssertNumberOfCalls(t, "Run", 1)
 	})
 
-	t.Run("when not installed", func(t *testing.T) {
-		pkg := "foo1"
+	t.Run("when removal error", func(t *testing.T) {
 		runner := newRunner("", makeExitError("", 1))
 		a := &apt.Manager{Sys: runner}
-		a.InstallPackage(pkg)
-		runner.AssertNumberOfCalls(t, "Run", 2)
-	})
-
-	t.Run("when removed", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgRemoved)
-		runner := newRunner(out, nil)
-		a := &apt.Manager{Sys: runner}
-		_, err := a.InstallPackage(pkg)
-		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 2)
+		_, err := a.RemovePackages([]string{"foo"})
+		assert.Error(t, err)
 	})
+}
 
-	t.Run("when uninstalled", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgUninstalled)
-		runner := newRunner(out, nil)
-		a := &apt.Manager{Sys: runner}
-		_, err := a.InstallPackage(pkg)
-		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 2)
-	})
+// TestAptLatestVersion validates that the candidate version is read from
+// apt-cache
+func TestAptLatestVersion(t *testing.T) {
+	t.Parallel()
 
-	t.Run("when held", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgHold)
-		runner := newRunner(out, nil)
-		a := &apt.Manager{Sys: runner}
-		_, err := a.InstallPackage(pkg)
+	t.Run("when available", func(t *testing.T) {
+		out := "foo:\n  Installed: 1.0-1\n  Candidate: 1.2-1ubuntu1\n  Version table:\n"
+		a := &apt.Manager{Sys: newRunner(out, nil)}
+		result, err := a.LatestVersion("foo")
 		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 1)
+		assert.Equal(t, "1.2-1ubuntu1", string(result))
 	})
 
-	t.Run("when installation error", func(t *testing.T) {
-		pkg := "foo1"
-		runner := newRunner("", makeExitError("", 1))
-		a := &apt.Manager{Sys: runner}
-		_, err := a.InstallPackage(pkg)
+	t.Run("when not available", func(t *testing.T) {
+		out := "foo:\n  Installed: (none)\n  Candidate: (none)\n"
+		a := &apt.Manager{Sys: newRunner(out, nil)}
+		_, err := a.LatestVersion("foo")
 		assert.Error(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 2)
 	})
 }
 
-// TestAptRemovePackage validates that we successfully ask Apt to uninstall a
-// package
-func TestAptRemovePackage(t *testing.T) {
+// TestAptResolveVersion validates that a version without a revision is
+// resolved to the candidate or to a version listed by apt-cache madison
+func TestAptResolveVersion(t *testing.T) {
 	t.Parallel()
 
-	t.Run("when installed", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgInstalled)
-		runner := newRunner(out, nil)
-		a := &apt.Manager{Sys: runner}
-		_, err := a.RemovePackage(pkg)
+	policy := "foo:\n  Installed: 1.0-1\n  Candidate: 1.2-1ubuntu1\n  Version table:\n"
+	madison := "       foo | 1.2-1ubuntu1 | http://archive.ubuntu.com/ubuntu xenial/main amd64 Packages\n       foo | 1.1-2 | http://archive.ubuntu.com/ubuntu xenial/main amd64 Packages\n"
+
+	t.Run("when candidate", func(t *testing.T) {
+		a := &apt.Manager{Sys: newRunner(policy, nil)}
+		result, err := a.ResolveVersion("foo", "1.2")
 		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 2)
+		assert.Equal(t, "1.2-1ubuntu1", string(result))
 	})
 
-	t.Run("when held", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgHold)
-		runner := newRunner(out, nil)
-		a := &apt.Manager{Sys: runner}
-		_, err := a.RemovePackage(pkg)
+	t.Run("when candidate has an epoch", func(t *testing.T) {
+		a := &apt.Manager{Sys: newRunner("openssh-server:\n  Installed: (none)\n  Candidate: 1:8.9p1-3ubuntu0.1\n  Version table:\n", nil)}
+		result, err := a.ResolveVersion("openssh-server", "8.9p1")
 		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 2)
+		assert.Equal(t, "1:8.9p1-3ubuntu0.1", string(result))
 	})
 
-	t.Run("when uninstalled", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgUninstalled)
-		runner := newRunner(out, nil)
+	t.Run("when older", func(t *testing.T) {
+		runner := &MockRunner{}
+		runner.On("Run", mock.Anything).Return([]byte(policy), nil).Once()
+		runner.On("Run", mock.Anything).Return([]byte(madison), nil)
 		a := &apt.Manager{Sys: runner}
-		_, err := a.RemovePackage(pkg)
+		result, err := a.ResolveVersion("foo", "1.1")
 		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 1)
+		assert.Equal(t, "1.1-2", string(result))
 	})
 
-	t.Run("when removed", func(t *testing.T) {
-		pkg := "foo1"
-		out := fmt.Sprintf("foo,%s,0.1.2.3", apt.PkgRemoved)
-		runner := newRunner(out, nil)
+	t.Run("when not available", func(t *testing.T) {
+		runner := &MockRunner{}
+		runner.On("Run", mock.Anything).Return([]byte(policy), nil).Once()
+		runner.On("Run", mock.Anything).Return([]byte(madison), nil)
 		a := &apt.Manager{Sys: runner}
-		_, err := a.RemovePackage(pkg)
+		_, err := a.ResolveVersion("foo", "1.3")
+		assert.Error(t, err)
+	})
+}
+
+// TestAptIsHeld validates that holds are read from the dpkg status
+func TestAptIsHeld(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when held", func(t *testing.T) {
+		a := &apt.Manager{Sys: newRunner(apt.PkgHold, nil)}
+		held, err := a.IsHeld("foo")
 		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 1)
+		assert.True(t, held)
 	})
 
-	t.Run("when package never installed", func(t *testing.T) {
-		pkg := "foo1"
-		runner := newRunner("", makeExitError("", 1))
-		a := &apt.Manager{Sys: runner}
-		_, err := a.RemovePackage(pkg)
+	t.Run("when installed", func(t *testing.T) {
+		a := &apt.Manager{Sys: newRunner(apt.PkgInstalled, nil)}
+		held, err := a.IsHeld("foo")
 		assert.NoError(t, err)
-		runner.AssertNumberOfCalls(t, "Run", 1)
+		assert.False(t, held)
 	})
 
+	t.Run("when not installed", func(t *testing.T) {
+		a := &apt.Manager{Sys: newRunner("", makeExitError("", 1))}
+		held, err := a.IsHeld("foo")
+		assert.NoError(t, err)
+		assert.False(t, held)
+	})
 }
 
 // MockRunner mocks out SysCaller
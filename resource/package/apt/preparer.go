@@ -15,7 +15,6 @@
 package apt
 
 import (
-	"errors"
 	"strings"
 
 	"github.com/asteris-llc/converge/load/registry"
@@ -30,30 +29,60 @@ import (
 // both `apt` and `dpkg` are installed on the system, and that the user has
 // permissions to install, remove, and query packages.
 type Preparer struct {
-	// Name of the package or package group.
-	Name string `hcl:"name" required:"true" nonempty:"true"`
+	// Name of the package or package group. Either name or packages is
+	// required.
+	Name string `hcl:"name" mutually_exclusive:"name,packages"`
+
+	// Packages to manage together. They are installed or removed with a
+	// single `apt-get` call, which is much faster than one resource per package.
+	// State and hold apply to all of them, and the plan shows each package
+	// that will change.
+	Packages []string `hcl:"packages" mutually_exclusive:"name,packages"`
 
 	// State of the package. Present means the package will be installed if
-	// missing; Absent means the package will be uninstalled if present.
-	State pkg.State `hcl:"state" valid_values:"present,absent"`
+	// missing; Absent means the package will be uninstalled if present; Latest
+	// means the package will be installed or upgraded to the newest available
+	// version.
+	State pkg.State `hcl:"state" valid_values:"present,absent,latest"`
+
+	// Version the package should be at. Only valid when state is present, and
+	// not with packages. Either a specific version, like "1.2.3-1ubuntu1", or
+	// a constraint like ">= 1.2, < 2.0". A version without a Debian revision,
+	// like "1.2.3", matches any revision of it, and if none is installed the
+	// candidate revision (or else the newest available one) is installed. A
+	// trailing "*" matches any version starting with the rest, like "1.2.*".
+	// A version without an epoch, like "8.9p1", matches one with any epoch,
+	// like "1:8.9p1-3ubuntu0.1". When the installed version doesn't match a constraint, the newest
+	// available version is installed if it does. Installing an older version
+	// than the one installed needs apt 1.1 or newer.
+	Version string `hcl:"version"`
+
+	// Hold the package at its installed version, so it isn't changed by other
+	// upgrades. The package is held with `apt-mark hold`. If hold is false, an
+	// existing hold is released. If unset, holds are left alone.
+	Hold *bool `hcl:"hold"`
 }
 
 // Prepare a new package
 func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
 
-	if strings.TrimSpace(p.Name) == "" {
-		return &pkg.Package{}, errors.New("package name cannot be empty")
-	}
-
 	if p.State == "" {
 		p.State = "present"
 	}
 
-	return &pkg.Package{
-		Name:   p.Name,
-		State:  p.State,
-		PkgMgr: &Manager{Sys: pkg.ExecCaller{}},
-	}, nil
+	task := &pkg.Package{
+		Name:     p.Name,
+		Packages: p.Packages,
+		State:    p.State,
+		Version:  strings.TrimSpace(p.Version),
+		Hold:     p.Hold,
+		PkgMgr:   &Manager{Sys: pkg.ExecCaller{}},
+	}
+	if err := task.Validate(); err != nil {
+		return &pkg.Package{}, err
+	}
+
+	return task, nil
 }
 
 func init() {
@@ -0,0 +1,174 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"golang.org/x/net/context"
+)
+
+// fingerprintPattern matches a normalized key fingerprint
+var fingerprintPattern = regexp.MustCompile(`^[0-9A-F]{40}$`)
+
+const (
+	// DefaultSourcesDir is where source lists are written
+	DefaultSourcesDir = "/etc/apt/sources.list.d"
+
+	// DefaultKeyDir is where signing keys are written
+	DefaultKeyDir = "/etc/apt/keyrings"
+)
+
+// Preparer for apt Repository
+//
+// Apt Repository manages a source list in /etc/apt/sources.list.d and the key
+// its packages are signed with. The package lists for the repository are
+// updated only when the source list or key change. `package.apt` and
+// `package` resources in the same module depend on every apt repository, so
+// packages from the repository can be installed without listing it in
+// `depends`, and they aren't installed if the repository fails. Keys are
+// written to /etc/apt/keyrings as binary keyrings and only trusted for the
+// repository with `signed-by`, which needs apt 1.1 or newer.
+type Preparer struct {
+	// Name of the repository. The source list is written to
+	// /etc/apt/sources.list.d/<name>.list.
+	Name string `hcl:"name" required:"true" nonempty:"true"`
+
+	// URI of the repository, like "https://download.docker.com/linux/ubuntu".
+	// Required unless state is absent.
+	URI string `hcl:"uri"`
+
+	// Distribution to use, like "xenial". For flat repositories, a path ending
+	// with a slash, like "./".
+	Distribution string `hcl:"distribution"`
+
+	// Components to use, like "main" or "stable". Required unless the
+	// distribution is a flat repository.
+	Components []string `hcl:"components"`
+
+	// Architectures to fetch packages for, if not all of the ones configured
+	// for dpkg.
+	Architectures []string `hcl:"architectures"`
+
+	// Source adds a deb-src line for the repository as well.
+	Source bool `hcl:"source"`
+
+	// Key the repository is signed with, either ASCII armored or binary.
+	Key string `hcl:"key" mutually_exclusive:"key,key_url"`
+
+	// KeyURL to fetch the key the repository is signed with. The key is fetched
+	// whenever the repository is checked.
+	KeyURL string `hcl:"key_url" mutually_exclusive:"key,key_url"`
+
+	// KeyFingerprint the key must have, like
+	// "9DC8 5822 9FC7 DD38 854A E2D8 8D81 803C 0EBF CD88". The repository is not
+	// changed if the key has another fingerprint or contains other keys.
+	// Strongly recommended with key_url.
+	KeyFingerprint string `hcl:"key_fingerprint"`
+
+	// State of the repository. Present means the source list and key will be
+	// written; Absent means they will be removed.
+	State State `hcl:"state" valid_values:"present,absent"`
+}
+
+// Prepare a new repository
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if strings.ContainsAny(p.Name, "/ ") {
+		return nil, fmt.Errorf("repository name %q cannot contain slashes or spaces", p.Name)
+	}
+
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
+	repo := &Repository{
+		Name:           p.Name,
+		State:          p.State,
+		Path:           filepath.Join(DefaultSourcesDir, p.Name+".list"),
+		Key:            p.Key,
+		KeyURL:         p.KeyURL,
+		KeyFingerprint: pkg.NormalizeFingerprint(p.KeyFingerprint),
+		KeyDir:         DefaultKeyDir,
+		Sys:            pkg.ExecCaller{},
+	}
+
+	if p.State == StateAbsent {
+		return repo, nil
+	}
+
+	if p.KeyFingerprint != "" {
+		if p.Key == "" && p.KeyURL == "" {
+			return nil, errors.New("key_fingerprint needs key or key_url")
+		}
+		if !fingerprintPattern.MatchString(repo.KeyFingerprint) {
+			return nil, fmt.Errorf("key_fingerprint %q is not a 40 character hex fingerprint", p.KeyFingerprint)
+		}
+	}
+	if p.Key != "" || p.KeyURL != "" {
+		repo.KeyPath = filepath.Join(DefaultKeyDir, p.Name+".gpg")
+	}
+
+	if p.URI == "" {
+		return nil, errors.New("uri is required")
+	}
+	if p.Distribution == "" {
+		return nil, errors.New("distribution is required")
+	}
+
+	flat := strings.HasSuffix(p.Distribution, "/")
+	if flat && len(p.Components) > 0 {
+		return nil, errors.New("components cannot be set for a flat repository")
+	} else if !flat && len(p.Components) == 0 {
+		return nil, errors.New("components are required unless the distribution ends with a slash")
+	}
+
+	repo.Sources = p.sources(repo.KeyPath)
+	return repo, nil
+}
+
+// sources renders the source list, limiting the repository to the key at
+// keyPath if there is one
+func (p *Preparer) sources(keyPath string) string {
+	var options []string
+	if len(p.Architectures) > 0 {
+		options = append(options, "arch="+strings.Join(p.Architectures, ","))
+	}
+	if keyPath != "" {
+		options = append(options, "signed-by="+keyPath)
+	}
+
+	line := []string{p.URI, p.Distribution}
+	line = append(line, p.Components...)
+	if len(options) > 0 {
+		line = append([]string{"[" + strings.Join(options, " ") + "]"}, line...)
+	}
+
+	out := "deb " + strings.Join(line, " ") + "\n"
+	if p.Source {
+		out += "deb-src " + strings.Join(line, " ") + "\n"
+	}
+	return out
+}
+
+func init() {
+	registry.Register("package.apt.repository", (*Preparer)(nil), (*Repository)(nil))
+}
@@ -0,0 +1,113 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package/apt/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreparerInterfaces ensures that the correct interfaces are implemented by
+// the preparer
+func TestPreparerInterfaces(t *testing.T) {
+	t.Parallel()
+	assert.Implements(t, (*resource.Resource)(nil), new(repository.Preparer))
+}
+
+// TestPreparerCreatesRepository tests that the preparer renders the source list
+func TestPreparerCreatesRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with components", func(t *testing.T) {
+		p := &repository.Preparer{
+			Name:          "docker",
+			URI:           "https://download.docker.com/linux/ubuntu",
+			Distribution:  "xenial",
+			Components:    []string{"stable", "edge"},
+			Architectures: []string{"amd64", "arm64"},
+		}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		repo, ok := task.(*repository.Repository)
+		require.True(t, ok)
+		assert.Equal(t, repository.StatePresent, repo.State)
+		assert.Equal(t, "/etc/apt/sources.list.d/docker.list", repo.Path)
+		assert.Equal(t, "deb [arch=amd64,arm64] https://download.docker.com/linux/ubuntu xenial stable edge\n", repo.Sources)
+	})
+
+	t.Run("with key", func(t *testing.T) {
+		p := &repository.Preparer{
+			Name:           "docker",
+			URI:            "https://download.docker.com/linux/ubuntu",
+			Distribution:   "xenial",
+			Components:     []string{"stable"},
+			Architectures:  []string{"amd64"},
+			KeyURL:         "https://download.docker.com/linux/ubuntu/gpg",
+			KeyFingerprint: "0x9dc8 5822 9fc7 dd38 854a e2d8 8d81 803c 0ebf cd88",
+		}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		repo := task.(*repository.Repository)
+		assert.Equal(t, "/etc/apt/keyrings/docker.gpg", repo.KeyPath)
+		assert.Equal(t, "9DC858229FC7DD38854AE2D88D81803C0EBFCD88", repo.KeyFingerprint)
+		assert.Equal(t, "deb [arch=amd64 signed-by=/etc/apt/keyrings/docker.gpg] https://download.docker.com/linux/ubuntu xenial stable\n", repo.Sources)
+	})
+
+	t.Run("flat with source", func(t *testing.T) {
+		p := &repository.Preparer{
+			Name:         "local",
+			URI:          "file:/srv/debs",
+			Distribution: "./",
+			Source:       true,
+		}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, "deb file:/srv/debs ./\ndeb-src file:/srv/debs ./\n", task.(*repository.Repository).Sources)
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		p := &repository.Preparer{Name: "docker", State: repository.StateAbsent}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, "", task.(*repository.Repository).Sources)
+	})
+}
+
+// TestPreparerValidates tests that invalid repositories are rejected
+func TestPreparerValidates(t *testing.T) {
+	t.Parallel()
+
+	for name, p := range map[string]*repository.Preparer{
+		"name with slash":      {Name: "a/b", URI: "http://x", Distribution: "xenial", Components: []string{"main"}},
+		"missing uri":          {Name: "a", Distribution: "xenial", Components: []string{"main"}},
+		"missing distribution": {Name: "a", URI: "http://x", Components: []string{"main"}},
+		"missing components":   {Name: "a", URI: "http://x", Distribution: "xenial"},
+		"components in flat":   {Name: "a", URI: "http://x", Distribution: "./", Components: []string{"main"}},
+		"fingerprint no key":   {Name: "a", URI: "http://x", Distribution: "xenial", Components: []string{"main"}, KeyFingerprint: "9DC858229FC7DD38854AE2D88D81803C0EBFCD88"},
+		"short fingerprint":    {Name: "a", URI: "http://x", Distribution: "xenial", Components: []string{"main"}, KeyURL: "http://x/key", KeyFingerprint: "0EBFCD88"},
+	} {
+		p := p
+		t.Run(name, func(t *testing.T) {
+			_, err := p.Prepare(context.Background(), fakerenderer.New())
+			assert.Error(t, err)
+		})
+	}
+}
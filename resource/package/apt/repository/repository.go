@@ -0,0 +1,216 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/asteris-llc/converge/fetch"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/net/context"
+)
+
+// State type for Repository
+type State string
+
+const (
+	// StatePresent indicates the repository should be configured
+	StatePresent State = "present"
+
+	// StateAbsent indicates the repository should be removed
+	StateAbsent State = "absent"
+)
+
+// Repository manages an apt source list and its signing key
+type Repository struct {
+	// name of the repository
+	Name string `export:"name"`
+
+	// whether the repository should be present or absent
+	State State `export:"state"`
+
+	// content of the source list
+	Sources string `export:"sources"`
+
+	// location of the source list
+	Path string `export:"path"`
+
+	// the signing key, if given directly
+	Key string
+
+	// where the signing key is fetched from, if any
+	KeyURL string `export:"keyurl"`
+
+	// fingerprint the signing key must have, if any
+	KeyFingerprint string `export:"keyfingerprint"`
+
+	// location of the signing key, if any. The source list refers to it with
+	// signed-by, so apt only trusts it for this repository.
+	KeyPath string `export:"keypath"`
+
+	// directory signing keys are written to
+	KeyDir string
+
+	// whether the package lists were updated during apply
+	Updated bool `export:"updated"`
+
+	Sys pkg.SysCaller
+}
+
+// Check if the source list or key have to change
+func (r *Repository) Check(ctx context.Context, _ resource.Renderer) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	files, err := r.files(ctx)
+	if err != nil {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, err
+	}
+
+	var changed bool
+	for _, f := range files {
+		fileChanged, err := f.Check(status, r.State == StateAbsent)
+		if err != nil {
+			status.RaiseLevel(resource.StatusCantChange)
+			return status, err
+		}
+		changed = changed || fileChanged
+	}
+
+	if changed {
+		status.AddMessage("package lists will be updated")
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// Apply writes the source list and key, and updates the package lists if
+// either of them changed
+func (r *Repository) Apply(ctx context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	files, err := r.files(ctx)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	var changed bool
+	for _, f := range files {
+		fileChanged, err := f.Check(status, r.State == StateAbsent)
+		if err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		if !fileChanged {
+			continue
+		}
+		if err := f.Write(r.State == StateAbsent); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		changed = true
+	}
+
+	if changed {
+		out, err := r.Sys.Run(r.updateCommand())
+		status.AddMessage("updated package lists")
+		status.AddMessage(string(out))
+		if err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, errors.Wrap(err, "could not update package lists")
+		}
+		r.Updated = true
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// files returns the files making up the repository. When the repository
+// should be absent, any key it may have is included. Keys are always written
+// as binary keyrings, and a key left in ASCII armored form is removed.
+func (r *Repository) files(ctx context.Context) ([]*pkg.RepositoryFile, error) {
+	files := []*pkg.RepositoryFile{
+		{Path: r.Path, Content: r.Sources},
+		{Path: filepath.Join(r.KeyDir, r.Name+".asc"), Opaque: true, Stale: true},
+	}
+
+	if r.State == StateAbsent || r.KeyPath == "" {
+		return append(files, &pkg.RepositoryFile{Path: filepath.Join(r.KeyDir, r.Name+".gpg"), Opaque: true, Stale: true}), nil
+	}
+
+	if r.Key == "" && r.KeyURL != "" {
+		key, err := fetch.Any(ctx, r.KeyURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not fetch key from %s", r.KeyURL)
+		}
+		r.Key = string(key)
+	}
+
+	keys, err := pkg.ReadKeys(r.Key)
+	if err != nil {
+		return nil, err
+	}
+	if r.KeyFingerprint != "" {
+		if err := pkg.CheckFingerprint(keys, r.KeyFingerprint); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := dearmor(r.Key)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, &pkg.RepositoryFile{Path: r.KeyPath, Content: key, Opaque: true}), nil
+}
+
+// dearmor converts an ASCII armored key to a binary keyring, which every
+// version of apt can read with signed-by
+func dearmor(key string) (string, error) {
+	if !pkg.IsArmored(key) {
+		return key, nil
+	}
+
+	block, err := armor.Decode(strings.NewReader(key))
+	if err != nil {
+		return "", errors.Wrap(err, "cannot read key")
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(block.Body); err != nil {
+		return "", errors.Wrap(err, "cannot read key")
+	}
+	return buf.String(), nil
+}
+
+// updateCommand returns the command that updates the package lists. Only
+// this repository is updated when it is added or changed, but every list has
+// to be updated to forget a removed one.
+func (r *Repository) updateCommand() string {
+	if r.State == StateAbsent {
+		return "apt-get update"
+	}
+	return fmt.Sprintf(
+		"apt-get update -o Dir::Etc::sourcelist=%s -o Dir::Etc::sourceparts=- -o APT::Get::List-Cleanup=0",
+		r.Path,
+	)
+}
@@ -0,0 +1,231 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package/apt/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fingerprint is the fingerprint of armoredKey
+const fingerprint = "74FD F669 F18D 59F9 2B0A ACCD 7203 51FF 475C C928"
+
+const armoredKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+Version: GnuPG v1
+
+mQENBFengzoBCADLJvQwXJtUfY3+vgCSj7x+X7yvgg/YT/5BfG5XhS+aR5foaTqM
+DUEQb2gDY3pcWTi1TbGYJ9bedleTyn5F0Vh92nEdIIVG/y9RrV5vRsr5zRVGUj69
+Gk2lOviZ5wWth9JI6rBy0aTtpeoDlQNofWWu4ml77LqxySu/uxZFCZXnkdqeOPw6
+NWS1+nY4p96k3xmcZY68fR1+jgnoIw+xd1B2SOZZrQEZCIx1qfcOJYUFBY0OCDIr
+n0kc3IAfT9HGHMAd0Y3t7Y2fizE3MEIb3Z0uaaQg/JOuqPgVPLgEyE/pAThCWYbF
++Hp9z2PB+DvavBRAI/3evG9cX+GJ2Px7I0K/ABEBAAG0L1Rlc3QgQXN0ZXJpcyAo
+VGVzdCBzaWduaW5nIGtleSkgPHRlc3RAYXN0ZXIuaXM+iQE4BBMBAgAiBQJXp4M6
+AhsvBgsJCAcDAgYVCAIJCgsEFgIDAQIeAQIXgAAKCRByA1H/R1zJKJxlB/9eej/S
+5Nh8NZoT6rPrfxhqoCf+53T2j+JbBnEZRoWE61dnJjMDuDw+3UK7EiObAxY+iZPi
+2K/AeHlN8cRsLdth6ohPlVlpfgSZq5TNJigzNuItoYLB0hDQjW6T30DvvJ8dgpGy
+GUVlKKKK6Tcoc9IIHuKd9G+cF5oTY91GLjRDt1tf+33Ot8Wqd9ZWcfCZuihpwa7C
+L3fui/zbU//xBzBsubv1Wa2rjRMqjM6lK3yM4l8vcxk/jW0DGuhFhuGlEMBHbEnZ
+pDSoCw4XKdy+gzQQYjJMR8niocW0xoQQ2BVSNYvSPmsXlb9grwda0BSuKx1ovykX
+bigYyuHg+BBoS3Y0uQENBFengzoBCACgr5BprdYM95RJT1PRjV5caGiLutX6nY+F
+leGMB76zDJ7UDBAhytx9+eT9qBCbtngz+LES4y78544KrrpWJmI9eEZDc+T8r8rZ
+ycZI2N7WstbcbvMLNzXqMmnOnpC3LgeQz9VMrAKufg7rdYIFWJSw7p5Q2TGSfpCE
+Wsqut0LguuOEc/GtqoPqDMej0t94a88IB9lK8lHudSsTy9jlUwNC2Aa51DuRXaCM
+jNVC9YG7v15weapa1tRm16wsBzplajHn5coFcd2fDGfxAZR5YwtQja+x8vqXMDPc
+9pYtFi0PNjHBZ8T5TV4bFqWLL9inyM2O8+ncco3ghDzJ3dsLOJ6fABEBAAGJAj4E
+GAECAAkFAlengzoCGy4BKQkQcgNR/0dcySjAXSAEGQECAAYFAlengzoACgkQH7zZ
+vhMn2Jyiggf/SZsX0YwbZvQmrpzSQ1gbs805csQfxHvJ/e1dLzdOvNaotK54DDhp
+S9nCRStwBgClMsFS2vhcPGjnJsFob3d62PghZOTabmG49/TnK5trlPYkYmAwklAy
+6sXDMWtvMAm/kDhbpLYpR/yFRHo+1OEuHkiXltEbRJFg/Drzrbg8Muf+qXdNzunh
+KN/vDfu+uOooPwTfvMH/MdwHSEuw/QLJ9is5CYCDsbK9bB2aMri4lt/791Zyaf8j
+9WASF2n0aX30/jCCzsLTUjQS2W3OTrc8jt43bdUqPl+Ce6xM5py2g0C3jk9M97bt
+FL7ucS9c7dIMYY2hsHOMunx9V6D0wJboLE29B/4mNEf6C1+gjemcpoDb3g8K4dcl
+Ttjb6PcOuqS1N2bl6NUFLY5YER1klWcVTAwbrXEfzgnadAxqjx0zwIeZwiAMoG4z
+JN7pxR/g4G0B7cTtb4hXQK/BJAJAgnL1PQ1yUDLskrE+j/f1katW8WPp/MPPVr+o
+olzg/b5Pkk3hS3ApuP0d4BHEuB/vYoKGWY66HclrAZyQJFyfFeDDu6QSYaqxznFp
+ej83qcoSoffR18y3lr53ehuZoYGAWyJSaYXTarclB4nGKZk430OwX6ldnvtmyG1a
+PBuKW5ogwQXtoeFRdX3LjG4J3Wy/VgHzfQVQHxEHaY0qFbPn542+byp46WGJ
+=6XW+
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+// TestRepositoryInterface ensures that the repository implements Task
+func TestRepositoryInterface(t *testing.T) {
+	t.Parallel()
+	assert.Implements(t, (*resource.Task)(nil), new(repository.Repository))
+}
+
+// TestRepositoryPresent tests adding a repository
+func TestRepositoryPresent(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-apt-repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, runner := newRepository(dir, repository.StatePresent)
+	listPath := filepath.Join(dir, "sources.list.d", "docker.list")
+	keyPath := filepath.Join(dir, "keyrings", "docker.gpg")
+	stalePath := filepath.Join(dir, "keyrings", "docker.asc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(stalePath), 0755))
+	require.NoError(t, ioutil.WriteFile(stalePath, []byte(armoredKey), 0644))
+
+	t.Run("check when missing", func(t *testing.T) {
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Contains(t, status.Diffs(), listPath)
+		assert.Equal(t, "<absent>", status.Diffs()[keyPath].Original())
+		assert.Equal(t, "<absent>", status.Diffs()[stalePath].Current())
+		assert.Contains(t, status.Messages(), "package lists will be updated")
+		runner.AssertNotCalled(t, "Run", mock.Anything)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		status, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.True(t, r.Updated)
+		runner.AssertCalled(t, "Run", "apt-get update -o Dir::Etc::sourcelist="+listPath+" -o Dir::Etc::sourceparts=- -o APT::Get::List-Cleanup=0")
+
+		sources, err := ioutil.ReadFile(listPath)
+		require.NoError(t, err)
+		assert.Equal(t, r.Sources, string(sources))
+
+		key, err := ioutil.ReadFile(keyPath)
+		require.NoError(t, err)
+		require.NotEmpty(t, key)
+		assert.Equal(t, byte(0x99), key[0], "key should be a binary public key packet")
+
+		_, err = os.Stat(stalePath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("check when present", func(t *testing.T) {
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+		assert.NotContains(t, status.Messages(), "package lists will be updated")
+	})
+}
+
+// TestRepositoryKeyFingerprint tests that the key has to have the fingerprint
+func TestRepositoryKeyFingerprint(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-apt-repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("matching", func(t *testing.T) {
+		r, _ := newRepository(dir, repository.StatePresent)
+		r.KeyFingerprint = fingerprint
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+	})
+
+	t.Run("different", func(t *testing.T) {
+		r, _ := newRepository(dir, repository.StatePresent)
+		r.KeyFingerprint = "0000000000000000000000000000000000000000"
+		status, err := r.Check(context.Background(), nil)
+		assert.EqualError(t, err, "key has fingerprint 74FDF669F18D59F92B0AACCD720351FF475CC928, expected 0000000000000000000000000000000000000000")
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+
+		_, err = r.Apply(context.Background())
+		assert.Error(t, err)
+		_, err = os.Stat(r.KeyPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+// TestRepositoryAbsent tests removing a repository
+func TestRepositoryAbsent(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-apt-repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	present, _ := newRepository(dir, repository.StatePresent)
+	_, err = present.Apply(context.Background())
+	require.NoError(t, err)
+
+	r, runner := newRepository(dir, repository.StateAbsent)
+	r.Key = ""
+	r.KeyPath = ""
+	listPath := filepath.Join(dir, "sources.list.d", "docker.list")
+	keyPath := filepath.Join(dir, "keyrings", "docker.gpg")
+
+	t.Run("check", func(t *testing.T) {
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Equal(t, "<absent>", status.Diffs()[listPath].Current())
+		assert.Equal(t, "<absent>", status.Diffs()[keyPath].Current())
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		_, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		runner.AssertCalled(t, "Run", "apt-get update")
+
+		_, err = os.Stat(listPath)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(keyPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("check when removed", func(t *testing.T) {
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+}
+
+// newRepository creates a repository writing to dir
+func newRepository(dir string, state repository.State) (*repository.Repository, *MockRunner) {
+	runner := &MockRunner{}
+	runner.On("Run", mock.Anything).Return([]byte{}, nil)
+	return &repository.Repository{
+		Name:    "docker",
+		State:   state,
+		Sources: "deb https://download.docker.com/linux/ubuntu xenial stable\n",
+		Path:    filepath.Join(dir, "sources.list.d", "docker.list"),
+		Key:     armoredKey,
+		KeyPath: filepath.Join(dir, "keyrings", "docker.gpg"),
+		KeyDir:  filepath.Join(dir, "keyrings"),
+		Sys:     runner,
+	}, runner
+}
+
+// MockRunner mocks out SysCaller
+type MockRunner struct {
+	mock.Mock
+}
+
+// Run mocks out Run
+func (m *MockRunner) Run(cmd string) ([]byte, error) {
+	args := m.Called(cmd)
+	return args.Get(0).([]byte), args.Error(1)
+}
@@ -0,0 +1,161 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/render/extensions/platform"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/asteris-llc/converge/resource/package/apk"
+	"github.com/asteris-llc/converge/resource/package/apt"
+	"github.com/asteris-llc/converge/resource/package/pacman"
+	"github.com/asteris-llc/converge/resource/package/rpm"
+	"github.com/asteris-llc/converge/resource/package/zypper"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// managers creates the package manager for each supported manager name
+var managers = map[string]func() pkg.PackageManager{
+	"apt":    func() pkg.PackageManager { return &apt.Manager{Sys: pkg.ExecCaller{}} },
+	"rpm":    func() pkg.PackageManager { return &rpm.YumManager{Sys: pkg.ExecCaller{}} },
+	"apk":    func() pkg.PackageManager { return &apk.Manager{Sys: pkg.ExecCaller{}} },
+	"zypper": func() pkg.PackageManager { return &zypper.Manager{Sys: pkg.ExecCaller{}} },
+	"pacman": func() pkg.PackageManager { return &pacman.Manager{Sys: pkg.ExecCaller{}} },
+}
+
+// noHold lists the package managers that can't hold packages
+var noHold = map[string]bool{
+	"pacman": true,
+}
+
+// distributions maps the IDs in /etc/os-release to the name of the package
+// manager they use
+var distributions = map[string]string{
+	"debian":   "apt",
+	"ubuntu":   "apt",
+	"rhel":     "rpm",
+	"centos":   "rpm",
+	"fedora":   "rpm",
+	"alpine":   "apk",
+	"suse":     "zypper",
+	"opensuse": "zypper",
+	"sles":     "zypper",
+	"arch":     "pacman",
+}
+
+// detectPlatform is used to find out which platform converge is running on
+var detectPlatform = platform.DefaultPlatform
+
+// Preparer for Package
+//
+// Package manages system packages with the package manager of the platform
+// it runs on: `apt` on Debian and Ubuntu, `yum` on Red Hat, CentOS and
+// Fedora, `apk` on Alpine, `zypper` on SUSE and openSUSE, and `pacman` on
+// Arch Linux. The distribution is read from /etc/os-release, and derivatives
+// are recognized by the distributions they are like. It works the same as
+// the resource for that package manager, so the same module can install
+// packages across distributions.
+type Preparer struct {
+	// Name of the package. Either name or packages is required.
+	Name string `hcl:"name" mutually_exclusive:"name,packages"`
+
+	// Packages to manage together. They are installed or removed in a single
+	// call to the package manager. State and hold apply to all of them, and
+	// the plan shows each package that will change.
+	Packages []string `hcl:"packages" mutually_exclusive:"name,packages"`
+
+	// State of the package. Present means the package will be installed if
+	// missing; Absent means the package will be uninstalled if present; Latest
+	// means the package will be installed or upgraded to the newest available
+	// version.
+	State pkg.State `hcl:"state" valid_values:"present,absent,latest"`
+
+	// Version the package should be at. Only valid when state is present, and
+	// not with packages. Either a specific version or a constraint like
+	// ">= 1.2, < 2.0". Since version numbers differ between distributions,
+	// this is mostly useful together with manager.
+	Version string `hcl:"version"`
+
+	// Hold the package at its installed version, so it isn't changed by other
+	// upgrades. Not supported by pacman.
+	Hold *bool `hcl:"hold"`
+
+	// Manager to use instead of detecting it from the platform.
+	Manager string `hcl:"manager" valid_values:"apt,rpm,apk,zypper,pacman"`
+}
+
+// Prepare a new package
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.State == "" {
+		p.State = pkg.StatePresent
+	}
+
+	if p.Manager == "" {
+		plat, err := detectPlatform()
+		if err != nil {
+			return &pkg.Package{}, errors.Wrap(err, "could not detect the platform")
+		}
+		if p.Manager, err = managerFor(plat); err != nil {
+			return &pkg.Package{}, err
+		}
+	}
+
+	newManager, ok := managers[p.Manager]
+	if !ok {
+		return &pkg.Package{}, fmt.Errorf("unknown package manager %q", p.Manager)
+	}
+	if p.Hold != nil && noHold[p.Manager] {
+		return &pkg.Package{}, fmt.Errorf("hold is not supported by %s", p.Manager)
+	}
+
+	task := &pkg.Package{
+		Name:     p.Name,
+		Packages: p.Packages,
+		State:    p.State,
+		Version:  strings.TrimSpace(p.Version),
+		Hold:     p.Hold,
+		PkgMgr:   newManager(),
+	}
+	if err := task.Validate(); err != nil {
+		return &pkg.Package{}, err
+	}
+
+	return task, nil
+}
+
+// managerFor picks the package manager for a platform, looking at the
+// distribution first and then at the distributions it is like
+func managerFor(plat *platform.Platform) (string, error) {
+	if plat.OS != "linux" {
+		return "", fmt.Errorf("no supported package manager for %s, set manager to choose one", plat.OS)
+	}
+
+	for _, id := range append([]string{plat.LinuxDistribution}, plat.LinuxLSBLike...) {
+		if manager, ok := distributions[id]; ok {
+			return manager, nil
+		}
+	}
+
+	return "", fmt.Errorf("no supported package manager for distribution %q, set manager to choose one", plat.LinuxDistribution)
+}
+
+func init() {
+	registry.Register("package", (*Preparer)(nil), (*pkg.Package)(nil))
+}
@@ -0,0 +1,128 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/render/extensions/platform"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/asteris-llc/converge/resource/package/apk"
+	"github.com/asteris-llc/converge/resource/package/apt"
+	"github.com/asteris-llc/converge/resource/package/zypper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPreparerInterfaces ensures that the correct interfaces are implemented by
+// the preparer
+func TestPreparerInterfaces(t *testing.T) {
+	t.Parallel()
+	assert.Implements(t, (*resource.Resource)(nil), new(Preparer))
+}
+
+// TestManagerFor tests picking a package manager for a platform
+func TestManagerFor(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		platform platform.Platform
+		expected string
+	}{
+		{platform.Platform{OS: "linux", LinuxDistribution: "ubuntu", LinuxLSBLike: []string{"debian"}}, "apt"},
+		{platform.Platform{OS: "linux", LinuxDistribution: "centos", LinuxLSBLike: []string{"rhel", "fedora"}}, "rpm"},
+		{platform.Platform{OS: "linux", LinuxDistribution: "alpine"}, "apk"},
+		{platform.Platform{OS: "linux", LinuxDistribution: "opensuse-leap", LinuxLSBLike: []string{"suse", "opensuse"}}, "zypper"},
+		{platform.Platform{OS: "linux", LinuxDistribution: "manjaro", LinuxLSBLike: []string{"arch"}}, "pacman"},
+	} {
+		manager, err := managerFor(&tc.platform)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, manager, tc.platform.LinuxDistribution)
+	}
+
+	t.Run("unknown distribution", func(t *testing.T) {
+		_, err := managerFor(&platform.Platform{OS: "linux", LinuxDistribution: "gentoo"})
+		assert.EqualError(t, err, `no supported package manager for distribution "gentoo", set manager to choose one`)
+	})
+
+	t.Run("not linux", func(t *testing.T) {
+		_, err := managerFor(&platform.Platform{OS: "darwin"})
+		assert.Error(t, err)
+	})
+}
+
+// TestPreparerCreatesPackage tests that the package uses the right manager
+func TestPreparerCreatesPackage(t *testing.T) {
+	defer func(orig func() (*platform.Platform, error)) { detectPlatform = orig }(detectPlatform)
+
+	t.Run("detected", func(t *testing.T) {
+		detectPlatform = func() (*platform.Platform, error) {
+			return &platform.Platform{OS: "linux", LinuxDistribution: "alpine"}, nil
+		}
+
+		p := &Preparer{Name: "test1"}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		asPkg, ok := task.(*pkg.Package)
+		require.True(t, ok)
+		assert.IsType(t, &apk.Manager{}, asPkg.PkgMgr)
+		assert.Equal(t, pkg.StatePresent, asPkg.State)
+	})
+
+	t.Run("manager", func(t *testing.T) {
+		detectPlatform = func() (*platform.Platform, error) {
+			return nil, errors.New("should not be called")
+		}
+
+		p := &Preparer{Name: "test1", Manager: "zypper"}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.IsType(t, &zypper.Manager{}, task.(*pkg.Package).PkgMgr)
+	})
+
+	t.Run("detection failed", func(t *testing.T) {
+		detectPlatform = func() (*platform.Platform, error) {
+			return &platform.Platform{OS: "linux"}, errors.New("no os-release")
+		}
+
+		p := &Preparer{Name: "test1"}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		p := &Preparer{Packages: []string{"test1"}, Version: "1.0", Manager: "apt"}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("hold with pacman", func(t *testing.T) {
+		hold := true
+		p := &Preparer{Name: "test1", Hold: &hold, Manager: "pacman"}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, "hold is not supported by pacman")
+	})
+
+	t.Run("apt", func(t *testing.T) {
+		p := &Preparer{Name: "test1", Manager: "apt"}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.IsType(t, &apt.Manager{}, task.(*pkg.Package).PkgMgr)
+	})
+}
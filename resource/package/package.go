@@ -15,7 +15,10 @@
 package pkg
 
 import (
+	"fmt"
 	"os/exec"
+	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/asteris-llc/converge/resource"
@@ -36,6 +39,10 @@ const (
 
 	// StateAbsent indicates the package should be absent
 	StateAbsent State = "absent"
+
+	// StateLatest indicates the package should be at the newest available
+	// version
+	StateLatest State = "latest"
 )
 
 // PackageManager describes an interface for managing packages and helps make
@@ -45,11 +52,46 @@ type PackageManager interface {
 	// returns an empty string and false.
 	InstalledVersion(string) (PackageVersion, bool)
 
-	// Installs a package, returning an error if something went wrong
-	InstallPackage(string) (string, error)
+	// Returns the newest version of a package that can be installed
+	LatestVersion(string) (PackageVersion, error)
+
+	// Installs several packages in a single transaction, upgrading or
+	// downgrading those with a version if another version is installed
+	InstallPackages([]PackageSpec) (string, error)
+
+	// Removes several packages in a single transaction
+	RemovePackages([]string) (string, error)
+
+	// Returns true if the package is held at its installed version
+	IsHeld(string) (bool, error)
+
+	// Holds a package at its installed version
+	HoldPackage(string) (string, error)
+
+	// Releases the hold on a package
+	UnholdPackage(string) (string, error)
+}
+
+// VersionResolver is implemented by package managers that can only install a
+// version given in full. ResolveVersion returns the available version that a
+// partial version (like "1.2.3" or "1.2.*") refers to, preferring the one that
+// would be installed anyway.
+type VersionResolver interface {
+	ResolveVersion(string, PackageVersion) (PackageVersion, error)
+}
 
-	// Removes a package, returning an error if something went wrong
-	RemovePackage(string) (string, error)
+// VersionComparer is implemented by package managers whose versions aren't
+// ordered the way dpkg and rpm order them. CompareVersions returns -1 if the
+// first version is older, 1 if it is newer, and 0 if they are the same.
+type VersionComparer interface {
+	CompareVersions(PackageVersion, PackageVersion) int
+}
+
+// PackageSpec names a package to install, and the version to install if a
+// specific one is needed
+type PackageSpec struct {
+	Name    string
+	Version PackageVersion
 }
 
 // Package is an API for package state
@@ -57,11 +99,90 @@ type Package struct {
 	// name of the package
 	Name string `export:"name"`
 
-	// package state; one of "present" or "absent"
-	State  State `export:"state"`
+	// names of the packages, when several are managed together
+	Packages []string `export:"packages"`
+
+	// package state; one of "present", "absent" or "latest"
+	State State `export:"state"`
+
+	// the version or version constraint the package should meet, if any
+	Version string `export:"version"`
+
+	// whether the package should be held at its installed version. When nil,
+	// holds are left as they are.
+	Hold *bool
+
+	// the version that was installed when the package was last checked
+	InstalledVersion PackageVersion `export:"installedversion"`
+
+	// the versions of each package that were installed when they were last
+	// checked, leaving out those that were not installed
+	InstalledVersions map[string]PackageVersion `export:"installedversions"`
+
 	PkgMgr PackageManager
 }
 
+// unsafeChars can't appear in package names or versions, since they are
+// passed to the package manager through the shell
+const unsafeChars = "`$;&|<>()'\"\\ \t\r\n"
+
+// checkSafe returns an error if the value contains a character in unsafeChars
+func checkSafe(field, value string) error {
+	if idx := strings.IndexAny(value, unsafeChars); idx >= 0 {
+		return fmt.Errorf("%s %q cannot contain %q", field, value, value[idx:idx+1])
+	}
+	return nil
+}
+
+// Validate checks that the package names are set and safe to pass to the
+// shell, and that the version and hold make sense for the state
+func (p *Package) Validate() error {
+	if len(p.Packages) == 0 && strings.TrimSpace(p.Name) == "" {
+		return errors.New("package name cannot be empty")
+	}
+	if len(p.Packages) == 0 {
+		if err := checkSafe("package name", p.Name); err != nil {
+			return err
+		}
+	}
+	for _, name := range p.Packages {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("package name cannot be empty")
+		}
+		if err := checkSafe("package name", name); err != nil {
+			return err
+		}
+	}
+
+	if p.Version != "" {
+		if len(p.Packages) > 0 {
+			return errors.New("version cannot be set for a list of packages")
+		}
+		if p.State != StatePresent {
+			return fmt.Errorf("version cannot be set when state is %q", p.State)
+		}
+		if IsConstraint(p.Version) {
+			constraint, err := ParseConstraint(p.Version)
+			if err != nil {
+				return err
+			}
+			for _, req := range constraint {
+				if err := checkSafe("version", string(req.Version)); err != nil {
+					return err
+				}
+			}
+		} else if err := checkSafe("version", p.Version); err != nil {
+			return err
+		}
+	}
+
+	if p.Hold != nil && *p.Hold && p.State == StateAbsent {
+		return errors.New("cannot hold a package that should be absent")
+	}
+
+	return nil
+}
+
 // SysCaller allows us to mock exec.Command
 type SysCaller interface {
 	Run(string) ([]byte, error)
@@ -92,40 +213,128 @@ func GetExitCode(err error) (uint32, error) {
 	return uint32(status.ExitStatus()), nil
 }
 
-// Check if the package has to be 'present', or 'absent'
+// Check if the packages have to be installed, removed, changed to another
+// version, or held
 func (p *Package) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
 	status := resource.NewStatus()
-	if p.State == p.PackageState() {
-		return status, nil
+
+	changes, err := p.plan()
+	if err != nil {
+		status.RaiseLevel(resource.StatusCantChange)
+		return status, err
 	}
-	status.AddDifference(p.Name, string(p.PackageState()), string(p.State), "")
-	status.RaiseLevel(resource.StatusWillChange)
+	for _, c := range changes {
+		status.AddDifference(c.name, c.from, c.to, "")
+	}
+
+	if p.manageHold() {
+		for _, name := range p.names() {
+			held, err := p.PkgMgr.IsHeld(name)
+			if err != nil {
+				status.RaiseLevel(resource.StatusCantChange)
+				return status, err
+			}
+			status.AddDifference(p.holdKey(name), strconv.FormatBool(held), strconv.FormatBool(*p.Hold), "")
+		}
+	}
+
+	status.RaiseLevelForDiffs()
 	return status, nil
 }
 
-// Apply desired package state
+// Apply desired package state. All packages that have to be installed or
+// removed are handed to the package manager at once.
 func (p *Package) Apply(context.Context) (resource.TaskStatus, error) {
-	var err error
 	status := resource.NewStatus()
-	if p.State == p.PackageState() {
-		return status, nil
+
+	changes, err := p.plan()
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
 	}
 
-	var results string
-	if p.State == StatePresent {
-		results, err = p.PkgMgr.InstallPackage(p.Name)
-		status.AddMessage("installed " + p.Name)
-	} else {
-		results, err = p.PkgMgr.RemovePackage(p.Name)
-		status.AddMessage("removed  " + p.Name)
+	changed := make(map[string]bool)
+	for _, c := range changes {
+		changed[c.name] = true
 	}
 
-	status.AddMessage(results)
-	if err != nil {
-		return status, err
+	wasHeld := make(map[string]bool)
+	if p.manageHold() {
+		for _, name := range p.names() {
+			if wasHeld[name], err = p.PkgMgr.IsHeld(name); err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, err
+			}
+		}
+	}
+
+	// held packages have to be released before they can be changed
+	held := make(map[string]bool)
+	for _, name := range p.names() {
+		if !wasHeld[name] {
+			continue
+		}
+		if changed[name] || !*p.Hold {
+			results, err := p.PkgMgr.UnholdPackage(name)
+			status.AddMessage("released hold on " + name)
+			status.AddMessage(results)
+			if err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, err
+			}
+		} else {
+			held[name] = true
+		}
 	}
-	status.AddDifference(p.Name, string(p.PackageState()), string(p.State), "")
-	status.RaiseLevel(resource.StatusWillChange)
+
+	if len(changes) > 0 {
+		var results string
+		if p.State == StateAbsent {
+			var names []string
+			for _, c := range changes {
+				names = append(names, c.name)
+				status.AddMessage("removed  " + c.name)
+			}
+			results, err = p.PkgMgr.RemovePackages(names)
+		} else {
+			var specs []PackageSpec
+			for _, c := range changes {
+				specs = append(specs, PackageSpec{Name: c.name, Version: c.version})
+				if c.version != "" {
+					status.AddMessage(fmt.Sprintf("installed %s %s", c.name, c.version))
+				} else {
+					status.AddMessage("installed " + c.name)
+				}
+			}
+			results, err = p.PkgMgr.InstallPackages(specs)
+		}
+
+		status.AddMessage(results)
+		if err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		for _, c := range changes {
+			status.AddDifference(c.name, c.from, c.to, "")
+		}
+	}
+
+	if p.manageHold() {
+		for _, name := range p.names() {
+			if *p.Hold && !held[name] {
+				results, err := p.PkgMgr.HoldPackage(name)
+				status.AddMessage("held " + name)
+				status.AddMessage(results)
+				if err != nil {
+					status.RaiseLevel(resource.StatusFatal)
+					return status, err
+				}
+			}
+			status.AddDifference(p.holdKey(name), strconv.FormatBool(wasHeld[name]), strconv.FormatBool(*p.Hold), "")
+		}
+	}
+
+	status.RaiseLevelForDiffs()
 	return status, nil
 }
 
@@ -137,3 +346,141 @@ func (p *Package) PackageState() State {
 	}
 	return StateAbsent
 }
+
+// change describes how a package will be changed. from and to are shown in
+// the plan, and version is the version to install, if a specific one is
+// needed.
+type change struct {
+	name     string
+	from, to string
+	version  PackageVersion
+}
+
+// plan works out the changes needed to bring every package to the desired
+// state and version, leaving out packages that are already there
+func (p *Package) plan() ([]*change, error) {
+	p.InstalledVersions = make(map[string]PackageVersion)
+
+	var changes []*change
+	for _, name := range p.names() {
+		c, err := p.planPackage(name)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			changes = append(changes, c)
+		}
+	}
+
+	p.InstalledVersion = p.InstalledVersions[p.Name]
+	return changes, nil
+}
+
+// planPackage works out the change needed for a single package. It returns
+// nil if the package is already where it should be.
+func (p *Package) planPackage(name string) (*change, error) {
+	installed, isInstalled := p.PkgMgr.InstalledVersion(name)
+
+	from := string(StateAbsent)
+	if isInstalled {
+		p.InstalledVersions[name] = installed
+		from = string(installed)
+		if from == "" {
+			from = string(StatePresent)
+		}
+	}
+
+	switch {
+	case p.State == StateAbsent:
+		if !isInstalled {
+			return nil, nil
+		}
+		return &change{name: name, from: from, to: string(StateAbsent)}, nil
+
+	case p.State == StateLatest:
+		latest, err := p.PkgMgr.LatestVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		if isInstalled && p.compareVersions(installed, latest) >= 0 {
+			return nil, nil
+		}
+		return &change{name: name, from: from, to: string(latest), version: latest}, nil
+
+	case p.Version == "":
+		if isInstalled {
+			return nil, nil
+		}
+		return &change{name: name, from: from, to: string(StatePresent)}, nil
+
+	case !IsConstraint(p.Version):
+		wanted := PackageVersion(p.Version)
+		if isInstalled && p.versionMatches(installed, wanted) {
+			return nil, nil
+		}
+		if resolver, ok := p.PkgMgr.(VersionResolver); ok {
+			resolved, err := resolver.ResolveVersion(name, wanted)
+			if err != nil {
+				return nil, err
+			}
+			wanted = resolved
+		}
+		return &change{name: name, from: from, to: string(wanted), version: wanted}, nil
+	}
+
+	constraint, err := ParseConstraint(p.Version)
+	if err != nil {
+		return nil, err
+	}
+	if isInstalled && constraint.MatchWith(installed, p.versionMatches, p.compareVersions) {
+		return nil, nil
+	}
+
+	latest, err := p.PkgMgr.LatestVersion(name)
+	if err != nil {
+		return nil, err
+	}
+	if !constraint.MatchWith(latest, p.versionMatches, p.compareVersions) {
+		return nil, fmt.Errorf("no available version of %s matches %q (latest is %s)", name, p.Version, latest)
+	}
+	return &change{name: name, from: from, to: string(latest), version: latest}, nil
+}
+
+// versionMatches returns true if the installed version is the wanted version.
+// Package managers with their own ordering match versions that it considers
+// the same, like "1.2" and "1.2.0", unless the wanted version ends with "*".
+func (p *Package) versionMatches(installed, wanted PackageVersion) bool {
+	if comparer, ok := p.PkgMgr.(VersionComparer); ok && !strings.HasSuffix(string(wanted), "*") {
+		return comparer.CompareVersions(installed, wanted) == 0
+	}
+	return VersionMatches(installed, wanted)
+}
+
+// compareVersions orders versions the way the package manager does
+func (p *Package) compareVersions(a, b PackageVersion) int {
+	if comparer, ok := p.PkgMgr.(VersionComparer); ok {
+		return comparer.CompareVersions(a, b)
+	}
+	return CompareVersions(a, b)
+}
+
+// names returns the names of all managed packages
+func (p *Package) names() []string {
+	if len(p.Packages) > 0 {
+		return p.Packages
+	}
+	return []string{p.Name}
+}
+
+// holdKey returns the name of the difference for the hold on a package
+func (p *Package) holdKey(name string) string {
+	if len(p.Packages) > 0 {
+		return name + " hold"
+	}
+	return "hold"
+}
+
+// manageHold returns true if the hold on the packages should be changed
+func (p *Package) manageHold() bool {
+	return p.Hold != nil && p.State != StateAbsent
+}
@@ -15,8 +15,10 @@
 package pkg_test
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
+	"strings"
 	"testing"
 
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
@@ -111,6 +113,291 @@ func TestApply(t *testing.T) {
 	})
 }
 
+// TestCheckVersion ensures Check shows version changes
+func TestCheckVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when version matches", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2.3"}
+		p.PkgMgr = &fakeManager{installed: "1.2.3-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+		assert.Equal(t, pkg.PackageVersion("1.2.3-1"), p.InstalledVersion)
+	})
+
+	t.Run("when version differs", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2.3-1"}
+		p.PkgMgr = &fakeManager{installed: "1.0-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assertDiff(t, status, "foo", "1.0-1", "1.2.3-1")
+	})
+
+	t.Run("when version missing", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2.3-1"}
+		p.PkgMgr = &fakeManager{}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assertDiff(t, status, "foo", "absent", "1.2.3-1")
+	})
+
+	t.Run("when version is resolved", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2.3"}
+		p.PkgMgr = &resolvingManager{fakeManager{installed: "1.0-1", latest: "1.2.3-2"}}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assertDiff(t, status, "foo", "1.0-1", "1.2.3-2")
+	})
+
+	t.Run("when version cannot be resolved", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2.3"}
+		p.PkgMgr = &resolvingManager{fakeManager{installed: "1.0-1", latest: "1.4-1"}}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+	})
+
+	t.Run("when constraint matches", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: ">= 1.0, < 2.0"}
+		p.PkgMgr = &fakeManager{installed: "1.0-1", latest: "2.1-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("when constraint does not match", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: ">= 1.2"}
+		p.PkgMgr = &fakeManager{installed: "1.0-1", latest: "1.4-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assertDiff(t, status, "foo", "1.0-1", "1.4-1")
+	})
+
+	t.Run("when no version matches constraint", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: ">= 2.0"}
+		p.PkgMgr = &fakeManager{installed: "1.0-1", latest: "1.4-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+	})
+
+	t.Run("when latest", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StateLatest}
+		p.PkgMgr = &fakeManager{installed: "1.0-1", latest: "1.4-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assertDiff(t, status, "foo", "1.0-1", "1.4-1")
+	})
+
+	t.Run("when already latest", func(t *testing.T) {
+		p := &pkg.Package{Name: "foo", State: pkg.StateLatest}
+		p.PkgMgr = &fakeManager{installed: "1.4-1", latest: "1.4-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("when should be held", func(t *testing.T) {
+		hold := true
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Hold: &hold}
+		p.PkgMgr = &fakeManager{installed: "1.0-1"}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assertDiff(t, status, "hold", "false", "true")
+	})
+}
+
+// TestApplyVersion ensures Apply installs versions and manages holds
+func TestApplyVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when version differs", func(t *testing.T) {
+		m := &fakeManager{installed: "1.0-1"}
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2-1", PkgMgr: m}
+		status, err := p.Apply(context.Background())
+		require.NoError(t, err)
+		assertDiff(t, status, "foo", "1.0-1", "1.2-1")
+		assert.Equal(t, []string{"install foo 1.2-1"}, m.calls)
+	})
+
+	t.Run("when latest", func(t *testing.T) {
+		m := &fakeManager{latest: "1.4-1"}
+		p := &pkg.Package{Name: "foo", State: pkg.StateLatest, PkgMgr: m}
+		_, err := p.Apply(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"install foo 1.4-1"}, m.calls)
+	})
+
+	t.Run("when held and version differs", func(t *testing.T) {
+		hold := true
+		m := &fakeManager{installed: "1.0-1", held: true}
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2-1", Hold: &hold, PkgMgr: m}
+		status, err := p.Apply(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"unhold", "install foo 1.2-1", "hold"}, m.calls)
+		assert.False(t, status.Diffs()["hold"].Changes())
+	})
+
+	t.Run("when hold should be released", func(t *testing.T) {
+		hold := false
+		m := &fakeManager{installed: "1.0-1", held: true}
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Hold: &hold, PkgMgr: m}
+		status, err := p.Apply(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"unhold"}, m.calls)
+		assertDiff(t, status, "hold", "true", "false")
+	})
+
+	t.Run("when install fails", func(t *testing.T) {
+		m := &fakeManager{installed: "1.0-1", err: errors.New("failed")}
+		p := &pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.2-1", PkgMgr: m}
+		status, err := p.Apply(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusFatal, status.StatusCode())
+	})
+}
+
+// TestPackages ensures lists of packages are changed together
+func TestPackages(t *testing.T) {
+	t.Parallel()
+
+	versions := map[string]pkg.PackageVersion{"a": "1.0-1", "c": "2.0-1"}
+
+	t.Run("check", func(t *testing.T) {
+		p := &pkg.Package{Packages: []string{"a", "b", "c", "d"}, State: pkg.StatePresent}
+		p.PkgMgr = &fakeManager{versions: versions}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Len(t, status.Diffs(), 2)
+		assertDiff(t, status, "b", "absent", "present")
+		assertDiff(t, status, "d", "absent", "present")
+		assert.Equal(t, versions, p.InstalledVersions)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		m := &fakeManager{versions: versions}
+		p := &pkg.Package{Packages: []string{"a", "b", "c", "d"}, State: pkg.StatePresent, PkgMgr: m}
+		status, err := p.Apply(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"install b, d"}, m.calls)
+		assert.Equal(t, []string{"installed b", "installed d", ""}, status.Messages())
+	})
+
+	t.Run("apply absent", func(t *testing.T) {
+		m := &fakeManager{versions: versions}
+		p := &pkg.Package{Packages: []string{"a", "b", "c"}, State: pkg.StateAbsent, PkgMgr: m}
+		status, err := p.Apply(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"remove a, c"}, m.calls)
+		assertDiff(t, status, "a", "1.0-1", "absent")
+		assertDiff(t, status, "c", "2.0-1", "absent")
+	})
+
+	t.Run("hold", func(t *testing.T) {
+		hold := true
+		p := &pkg.Package{Packages: []string{"a", "c"}, State: pkg.StatePresent, Hold: &hold}
+		p.PkgMgr = &fakeManager{versions: versions}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assertDiff(t, status, "a hold", "false", "true")
+		assertDiff(t, status, "c hold", "false", "true")
+	})
+}
+
+// TestValidate ensures invalid combinations of settings are rejected
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	hold := true
+	assert.NoError(t, (&pkg.Package{Name: "foo", State: pkg.StatePresent, Version: ">= 1.0"}).Validate())
+	assert.Error(t, (&pkg.Package{Name: "foo", State: pkg.StateLatest, Version: "1.0"}).Validate())
+	assert.Error(t, (&pkg.Package{Name: "foo", State: pkg.StatePresent, Version: ">= "}).Validate())
+	assert.Error(t, (&pkg.Package{Name: "foo", State: pkg.StateAbsent, Hold: &hold}).Validate())
+	assert.Error(t, (&pkg.Package{State: pkg.StatePresent, Packages: []string{"a"}, Version: "1.0"}).Validate())
+	assert.EqualError(t, (&pkg.Package{Name: " ", State: pkg.StatePresent}).Validate(), "package name cannot be empty")
+	assert.EqualError(t, (&pkg.Package{Packages: []string{"a", ""}, State: pkg.StatePresent}).Validate(), "package name cannot be empty")
+	assert.EqualError(t, (&pkg.Package{Name: "foo;reboot", State: pkg.StatePresent}).Validate(), `package name "foo;reboot" cannot contain ";"`)
+	assert.EqualError(t, (&pkg.Package{Packages: []string{"a", "$(id)"}, State: pkg.StatePresent}).Validate(), `package name "$(id)" cannot contain "$"`)
+	assert.EqualError(t, (&pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1.0 && reboot"}).Validate(), `version "1.0 && reboot" cannot contain " "`)
+	assert.EqualError(t, (&pkg.Package{Name: "foo", State: pkg.StatePresent, Version: ">= 1.0, < `id`"}).Validate(), "version \"`id`\" cannot contain \"`\"")
+	assert.NoError(t, (&pkg.Package{Name: "foo", State: pkg.StatePresent, Version: "1:2.0~rc1-1"}).Validate())
+}
+
+// assertDiff asserts that the status has a difference with the given values
+func assertDiff(t *testing.T, status resource.TaskStatus, name, original, current string) {
+	diff, ok := status.Diffs()[name]
+	if assert.True(t, ok, "missing diff for %s", name) {
+		assert.Equal(t, original, diff.Original())
+		assert.Equal(t, current, diff.Current())
+	}
+}
+
+// fakeManager is a PackageManager that records the changes made to it
+type fakeManager struct {
+	installed pkg.PackageVersion
+	latest    pkg.PackageVersion
+	held      bool
+	err       error
+	calls     []string
+
+	// versions installed per package, overriding installed
+	versions map[string]pkg.PackageVersion
+}
+
+func (f *fakeManager) InstalledVersion(name string) (pkg.PackageVersion, bool) {
+	if f.versions != nil {
+		version, ok := f.versions[name]
+		return version, ok
+	}
+	return f.installed, f.installed != ""
+}
+
+func (f *fakeManager) LatestVersion(string) (pkg.PackageVersion, error) {
+	return f.latest, nil
+}
+
+func (f *fakeManager) InstallPackages(specs []pkg.PackageSpec) (string, error) {
+	args := make([]string, len(specs))
+	for i, spec := range specs {
+		args[i] = strings.TrimSpace(spec.Name + " " + string(spec.Version))
+	}
+	f.calls = append(f.calls, "install "+strings.Join(args, ", "))
+	return "", f.err
+}
+
+func (f *fakeManager) RemovePackages(names []string) (string, error) {
+	f.calls = append(f.calls, "remove "+strings.Join(names, ", "))
+	return "", f.err
+}
+
+func (f *fakeManager) IsHeld(string) (bool, error) {
+	return f.held, nil
+}
+
+func (f *fakeManager) HoldPackage(string) (string, error) {
+	f.calls = append(f.calls, "hold")
+	return "", f.err
+}
+
+func (f *fakeManager) UnholdPackage(string) (string, error) {
+	f.calls = append(f.calls, "unhold")
+	return "", f.err
+}
+
+// resolvingManager resolves partial versions to the latest version
+type resolvingManager struct {
+	fakeManager
+}
+
+func (f *resolvingManager) ResolveVersion(name string, wanted pkg.PackageVersion) (pkg.PackageVersion, error) {
+	if pkg.VersionMatches(f.latest, wanted) {
+		return f.latest, nil
+	}
+	return "", fmt.Errorf("no available version of %s matches %q", name, wanted)
+}
+
 // MockRunner mocks out SysCaller
 type MockRunner struct {
 	mock.Mock
@@ -0,0 +1,107 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pacman
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
+)
+
+// errNoHold is returned when asked to hold a package. Pacman can only ignore
+// packages in pacman.conf.
+var errNoHold = errors.New("pacman does not support holding packages")
+
+// Manager provides a concrete implementation of PackageManager for Arch Linux
+// packages.
+type Manager struct {
+	Sys pkg.SysCaller
+}
+
+// InstalledVersion gets the installed version of package, if available
+func (m *Manager) InstalledVersion(p string) (pkg.PackageVersion, bool) {
+	result, err := m.Sys.Run(fmt.Sprintf("pacman -Q %s", p))
+	if exitCode, _ := pkg.GetExitCode(err); exitCode != 0 {
+		return "", false
+	}
+
+	// installed packages are listed as "name version"
+	fields := strings.Fields(string(result))
+	if len(fields) < 2 || fields[0] != p {
+		return "", false
+	}
+	return pkg.PackageVersion(fields[1]), true
+}
+
+// LatestVersion gets the newest version of a package in the sync databases
+func (m *Manager) LatestVersion(p string) (pkg.PackageVersion, error) {
+	result, err := m.Sys.Run(fmt.Sprintf("pacman -Si %s", p))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not query available versions of %s", p)
+	}
+
+	var latest pkg.PackageVersion
+	for _, line := range strings.Split(string(result), "\n") {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) != "Version" {
+			continue
+		}
+		version := pkg.PackageVersion(strings.TrimSpace(kv[1]))
+		if pkg.CompareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no installable version of %s", p)
+	}
+	return latest, nil
+}
+
+// InstallPackages installs several packages with a single pacman call.
+// Packages with a version have to be at that version in the sync databases.
+func (m *Manager) InstallPackages(specs []pkg.PackageSpec) (string, error) {
+	args := make([]string, len(specs))
+	for i, spec := range specs {
+		args[i] = spec.Name
+		if spec.Version != "" {
+			args[i] = fmt.Sprintf("'%s=%s'", spec.Name, spec.Version)
+		}
+	}
+	res, err := m.Sys.Run(fmt.Sprintf("pacman -S --noconfirm %s", strings.Join(args, " ")))
+	return string(res), err
+}
+
+// RemovePackages removes several packages with a single pacman call
+func (m *Manager) RemovePackages(names []string) (string, error) {
+	res, err := m.Sys.Run(fmt.Sprintf("pacman -R --noconfirm %s", strings.Join(names, " ")))
+	return string(res), err
+}
+
+// IsHeld returns an error, since holds are not supported
+func (m *Manager) IsHeld(p string) (bool, error) {
+	return false, errNoHold
+}
+
+// HoldPackage returns an error, since holds are not supported
+func (m *Manager) HoldPackage(p string) (string, error) {
+	return "", errNoHold
+}
+
+// UnholdPackage returns an error, since holds are not supported
+func (m *Manager) UnholdPackage(p string) (string, error) {
+	return "", errNoHold
+}
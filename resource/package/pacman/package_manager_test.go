@@ -0,0 +1,111 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pacman_test
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/asteris-llc/converge/resource/package/pacman"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestPacmanInstalledVersion validates that the installed version is read
+// from pacman -Q
+func TestPacmanInstalledVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when installed", func(t *testing.T) {
+		m := &pacman.Manager{Sys: newRunner("foo 1.2.3-1\n", nil)}
+		result, found := m.InstalledVersion("foo")
+		assert.True(t, found)
+		assert.Equal(t, "1.2.3-1", string(result))
+	})
+
+	t.Run("when not installed", func(t *testing.T) {
+		m := &pacman.Manager{Sys: newRunner("error: package 'foo' was not found\n", makeExitError("", 1))}
+		_, found := m.InstalledVersion("foo")
+		assert.False(t, found)
+	})
+}
+
+// TestPacmanLatestVersion validates that the newest version is read from the
+// sync databases
+func TestPacmanLatestVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when available", func(t *testing.T) {
+		out := "Repository      : extra\nName            : foo\nVersion         : 1:1.2.4-1\n\nRepository      : community\nName            : foo\nVersion         : 1.3-1\n"
+		m := &pacman.Manager{Sys: newRunner(out, nil)}
+		result, err := m.LatestVersion("foo")
+		assert.NoError(t, err)
+		assert.Equal(t, "1:1.2.4-1", string(result))
+	})
+
+	t.Run("when not available", func(t *testing.T) {
+		m := &pacman.Manager{Sys: newRunner("", makeExitError("", 1))}
+		_, err := m.LatestVersion("foo")
+		assert.Error(t, err)
+	})
+}
+
+// TestPacmanInstallPackages validates that several packages are installed
+// with a single call
+func TestPacmanInstallPackages(t *testing.T) {
+	t.Parallel()
+
+	runner := newRunner("", nil)
+	m := &pacman.Manager{Sys: runner}
+	_, err := m.InstallPackages([]pkg.PackageSpec{{Name: "foo"}, {Name: "bar", Version: "1.0-1"}})
+	assert.NoError(t, err)
+	runner.AssertNumberOfCalls(t, "Run", 1)
+}
+
+// TestPacmanHold validates that holds are reported as unsupported
+func TestPacmanHold(t *testing.T) {
+	t.Parallel()
+
+	m := &pacman.Manager{Sys: newRunner("", nil)}
+	_, err := m.IsHeld("foo")
+	assert.EqualError(t, err, "pacman does not support holding packages")
+}
+
+// MockRunner mocks out SysCaller
+type MockRunner struct {
+	mock.Mock
+}
+
+// Run mocks out Run
+func (m *MockRunner) Run(cmd string) ([]byte, error) {
+	args := m.Called(1)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// newRunner creates a new MockRunner that returns the output string and error
+func newRunner(output string, err error) *MockRunner {
+	m := &MockRunner{}
+	m.On("Run", mock.Anything).Return([]byte(output), err)
+	return m
+}
+
+// makeExitError generates a new ExitError
+func makeExitError(stderr string, exitCode uint32) error {
+	cmd := fmt.Sprintf("echo %q 1>&2; exit %d", stderr, exitCode)
+	_, err := exec.Command("/bin/bash", "-c", cmd).Output()
+	return err
+}
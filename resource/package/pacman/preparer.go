@@ -0,0 +1,79 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pacman
+
+import (
+	"strings"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"golang.org/x/net/context"
+)
+
+// Preparer for Pacman Package
+//
+// Pacman Package manages system packages on Arch Linux with `pacman`. It
+// assumes that `pacman` is installed on the system, and that the user has
+// permissions to install, remove, and query packages. The package databases
+// are not refreshed, so run `pacman -Sy` first if they may be out of date.
+type Preparer struct {
+	// Name of the package. Either name or packages is required.
+	Name string `hcl:"name" mutually_exclusive:"name,packages"`
+
+	// Packages to manage together. They are installed or removed with a
+	// single `pacman` call, which is much faster than one resource per package.
+	// State applies to all of them, and the plan shows each package that
+	// will change.
+	Packages []string `hcl:"packages" mutually_exclusive:"name,packages"`
+
+	// State of the package. Present means the package will be installed if
+	// missing; Absent means the package will be uninstalled if present; Latest
+	// means the package will be installed or upgraded to the newest available
+	// version.
+	State pkg.State `hcl:"state" valid_values:"present,absent,latest"`
+
+	// Version the package should be at. Only valid when state is present, and
+	// not with packages. Either a specific version, like "1.2.3-1", or a
+	// constraint like ">= 1.2, < 2.0". A trailing "*" matches any version
+	// starting with the rest, like "1.2.*". Pacman can only install the
+	// version in the sync databases, so a version that isn't there will fail
+	// to install.
+	Version string `hcl:"version"`
+}
+
+// Prepare a new package
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.State == "" {
+		p.State = pkg.StatePresent
+	}
+
+	task := &pkg.Package{
+		Name:     p.Name,
+		Packages: p.Packages,
+		State:    p.State,
+		Version:  strings.TrimSpace(p.Version),
+		PkgMgr:   &Manager{Sys: pkg.ExecCaller{}},
+	}
+	if err := task.Validate(); err != nil {
+		return &pkg.Package{}, err
+	}
+
+	return task, nil
+}
+
+func init() {
+	registry.Register("package.pacman", (*Preparer)(nil), (*pkg.Package)(nil))
+}
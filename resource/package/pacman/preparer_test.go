@@ -0,0 +1,65 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pacman_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/asteris-llc/converge/resource/package/pacman"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPreparerInterfaces ensures that the correct interfaces are implemented by
+// the preparer
+func TestPreparerInterfaces(t *testing.T) {
+	t.Parallel()
+	assert.Implements(t, (*resource.Resource)(nil), new(pacman.Preparer))
+}
+
+// TestPreparerCreatesPackage tests pkg.Package creation from the preparer
+func TestPreparerCreatesPackage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when-state-missing", func(t *testing.T) {
+		p := &pacman.Preparer{Name: "test1"}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		asPkg, ok := task.(*pkg.Package)
+		require.True(t, ok)
+		assert.Equal(t, pkg.StatePresent, asPkg.State)
+		assert.IsType(t, &pacman.Manager{}, asPkg.PkgMgr)
+	})
+
+	t.Run("when-packages", func(t *testing.T) {
+		p := &pacman.Preparer{Packages: []string{"test1", "test2"}, State: "latest"}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		asPkg, ok := task.(*pkg.Package)
+		require.True(t, ok)
+		assert.Equal(t, []string{"test1", "test2"}, asPkg.Packages)
+		assert.Equal(t, pkg.StateLatest, asPkg.State)
+	})
+
+	t.Run("when-name-space", func(t *testing.T) {
+		p := &pacman.Preparer{Name: " "}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, "package name cannot be empty")
+	})
+}
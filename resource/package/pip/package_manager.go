@@ -0,0 +1,161 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pip
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
+)
+
+// DefaultPip is the pip executable used if none is given
+const DefaultPip = "pip"
+
+// errNoHold is returned when asked to hold a package
+var errNoHold = errors.New("pip does not support holding packages")
+
+// Manager provides a concrete implementation of PackageManager for Python
+// packages.
+type Manager struct {
+	Sys pkg.SysCaller
+
+	// Pip is the pip executable to run
+	Pip string
+}
+
+// InstalledVersion gets the installed version of package, if available
+func (m *Manager) InstalledVersion(p string) (pkg.PackageVersion, bool) {
+	result, err := m.Sys.Run(fmt.Sprintf("%s show %s", m.pip(), p))
+	if exitCode, _ := pkg.GetExitCode(err); exitCode != 0 {
+		return "", false
+	}
+	for _, line := range strings.Split(string(result), "\n") {
+		if strings.HasPrefix(line, "Version:") {
+			return pkg.PackageVersion(strings.TrimSpace(strings.TrimPrefix(line, "Version:"))), true
+		}
+	}
+	return "", false
+}
+
+// LatestVersion gets the newest version of a package in the package index.
+// It uses `pip index versions`, which was added in pip 21.2 and is still
+// experimental. When that fails, the versions are read from the error pip
+// gives when asked to install a version that doesn't exist.
+func (m *Manager) LatestVersion(p string) (pkg.PackageVersion, error) {
+	result, err := m.Sys.Run(fmt.Sprintf("%s index versions %s", m.pip(), p))
+	if err != nil {
+		return m.latestFromInstall(p)
+	}
+
+	// the first line is the name and the latest version, like "foo (1.2.3)"
+	line := strings.TrimSpace(strings.SplitN(string(result), "\n", 2)[0])
+	start, end := strings.Index(line, "("), strings.LastIndex(line, ")")
+	if start < 0 || end <= start+1 {
+		return "", fmt.Errorf("no installable version of %s", p)
+	}
+	return pkg.PackageVersion(line[start+1 : end]), nil
+}
+
+// latestFromInstall gets the newest version of a package from the versions
+// pip lists when it can't find the one asked for, like "(from versions: 1.0,
+// 1.1)". Pre-releases are only used if there is nothing else, as pip does.
+func (m *Manager) latestFromInstall(p string) (pkg.PackageVersion, error) {
+	// this always fails, so only the output matters
+	result, _ := m.Sys.Run(fmt.Sprintf("%s install --disable-pip-version-check '%s==' 2>&1", m.pip(), p))
+
+	out := string(result)
+	start := strings.Index(out, "(from versions:")
+	if start < 0 {
+		return "", fmt.Errorf("could not query available versions of %s: %s", p, strings.TrimSpace(out))
+	}
+	end := strings.Index(out[start:], ")")
+	if end < 0 {
+		return "", fmt.Errorf("could not query available versions of %s: %s", p, strings.TrimSpace(out))
+	}
+
+	var latest, latestPre pkg.PackageVersion
+	for _, field := range strings.Split(out[start+len("(from versions:"):start+end], ",") {
+		v := pkg.PackageVersion(strings.TrimSpace(field))
+		parsed, ok := parseVersion(string(v))
+		if !ok {
+			continue
+		}
+		if parsed.isPreRelease() {
+			if latestPre == "" || compareVersions(v, latestPre) > 0 {
+				latestPre = v
+			}
+		} else if latest == "" || compareVersions(v, latest) > 0 {
+			latest = v
+		}
+	}
+
+	switch {
+	case latest != "":
+		return latest, nil
+	case latestPre != "":
+		return latestPre, nil
+	}
+	return "", fmt.Errorf("no installable version of %s", p)
+}
+
+// CompareVersions orders versions by the rules of PEP 440, the way pip does
+func (m *Manager) CompareVersions(a, b pkg.PackageVersion) int {
+	return compareVersions(a, b)
+}
+
+// InstallPackages installs several packages with a single pip call. Packages
+// with a version are pinned to it, which may downgrade them.
+func (m *Manager) InstallPackages(specs []pkg.PackageSpec) (string, error) {
+	args := make([]string, len(specs))
+	for i, spec := range specs {
+		args[i] = spec.Name
+		if spec.Version != "" {
+			args[i] = fmt.Sprintf("'%s==%s'", spec.Name, spec.Version)
+		}
+	}
+	res, err := m.Sys.Run(fmt.Sprintf("%s install %s", m.pip(), strings.Join(args, " ")))
+	return string(res), err
+}
+
+// RemovePackages removes several packages with a single pip call
+func (m *Manager) RemovePackages(names []string) (string, error) {
+	res, err := m.Sys.Run(fmt.Sprintf("%s uninstall -y %s", m.pip(), strings.Join(names, " ")))
+	return string(res), err
+}
+
+// IsHeld returns an error, since holds are not supported
+func (m *Manager) IsHeld(p string) (bool, error) {
+	return false, errNoHold
+}
+
+// HoldPackage returns an error, since holds are not supported
+func (m *Manager) HoldPackage(p string) (string, error) {
+	return "", errNoHold
+}
+
+// UnholdPackage returns an error, since holds are not supported
+func (m *Manager) UnholdPackage(p string) (string, error) {
+	return "", errNoHold
+}
+
+// pip returns the pip executable to run
+func (m *Manager) pip() string {
+	if m.Pip == "" {
+		return DefaultPip
+	}
+	return m.Pip
+}
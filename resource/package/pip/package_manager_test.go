@@ -0,0 +1,142 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pip_test
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/asteris-llc/converge/resource/package/pip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPipInstalledVersion validates that the installed version is read from
+// pip show
+func TestPipInstalledVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when installed", func(t *testing.T) {
+		m := &pip.Manager{Sys: newRunner("Name: requests\nVersion: 2.12.4\nSummary: Python HTTP for Humans.\n", nil)}
+		result, found := m.InstalledVersion("requests")
+		assert.True(t, found)
+		assert.Equal(t, "2.12.4", string(result))
+	})
+
+	t.Run("when not installed", func(t *testing.T) {
+		m := &pip.Manager{Sys: newRunner("", makeExitError("", 1))}
+		_, found := m.InstalledVersion("requests")
+		assert.False(t, found)
+	})
+}
+
+// TestPipLatestVersion validates that the newest version is read from pip
+// index
+func TestPipLatestVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when available", func(t *testing.T) {
+		m := &pip.Manager{Sys: newRunner("requests (2.13.0)\nAvailable versions: 2.13.0, 2.12.4\n", nil)}
+		result, err := m.LatestVersion("requests")
+		assert.NoError(t, err)
+		assert.Equal(t, "2.13.0", string(result))
+	})
+
+	t.Run("when pip index is missing", func(t *testing.T) {
+		runner := &MockRunner{}
+		runner.On("Run", mock.Anything).Return([]byte(""), makeExitError(`ERROR: unknown command "index"`, 1)).Once()
+		runner.On("Run", mock.Anything).Return([]byte("ERROR: Could not find a version that satisfies the requirement requests== (from versions: 2.12.4, 2.13.0rc1, 2.13.0, 2.14.0b1)\nERROR: No matching distribution found for requests==\n"), makeExitError("", 1))
+		m := &pip.Manager{Sys: runner}
+		result, err := m.LatestVersion("requests")
+		assert.NoError(t, err)
+		assert.Equal(t, "2.13.0", string(result))
+	})
+
+	t.Run("when not available", func(t *testing.T) {
+		runner := &MockRunner{}
+		runner.On("Run", mock.Anything).Return([]byte(""), makeExitError("", 1)).Once()
+		runner.On("Run", mock.Anything).Return([]byte("ERROR: Could not find a version that satisfies the requirement requests== (from versions: none)\n"), makeExitError("", 1))
+		m := &pip.Manager{Sys: runner}
+		_, err := m.LatestVersion("requests")
+		assert.Error(t, err)
+	})
+}
+
+// TestPipInstallPackages validates that several packages are installed with a
+// single call
+func TestPipInstallPackages(t *testing.T) {
+	t.Parallel()
+
+	runner := newRunner("", nil)
+	m := &pip.Manager{Sys: runner}
+	_, err := m.InstallPackages([]pkg.PackageSpec{{Name: "requests"}, {Name: "six", Version: "1.10.0"}})
+	assert.NoError(t, err)
+	runner.AssertNumberOfCalls(t, "Run", 1)
+}
+
+// TestPipCheckVersion validates that installed versions are matched by the
+// rules of PEP 440
+func TestPipCheckVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		version string
+		changes bool
+	}{
+		{"1.2", false},
+		{"1.2.0", false},
+		{"== 1.2", false},
+		{"1.2.*", false},
+		{">= 1.2, != 1.2.1", false},
+		{"1.2.0.post1", true},
+		{"1.2.1", true},
+	} {
+		p := &pkg.Package{Name: "six", State: pkg.StatePresent, Version: tc.version}
+		p.PkgMgr = &pip.Manager{Sys: newRunner("Name: six\nVersion: 1.2.0\n", nil)}
+		status, err := p.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err, tc.version)
+		assert.Equal(t, tc.changes, status.HasChanges(), tc.version)
+	}
+}
+
+// MockRunner mocks out SysCaller
+type MockRunner struct {
+	mock.Mock
+}
+
+// Run mocks out Run
+func (m *MockRunner) Run(cmd string) ([]byte, error) {
+	args := m.Called(1)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// newRunner creates a new MockRunner that returns the output string and error
+func newRunner(output string, err error) *MockRunner {
+	m := &MockRunner{}
+	m.On("Run", mock.Anything).Return([]byte(output), err)
+	return m
+}
+
+// makeExitError generates a new ExitError
+func makeExitError(stderr string, exitCode uint32) error {
+	cmd := fmt.Sprintf("echo %q 1>&2; exit %d", stderr, exitCode)
+	_, err := exec.Command("/bin/bash", "-c", cmd).Output()
+	return err
+}
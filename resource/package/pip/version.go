@@ -0,0 +1,217 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pip
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource/package"
+)
+
+// versionPattern matches the versions PEP 440 allows, including the
+// alternative spellings it normalizes
+var versionPattern = regexp.MustCompile(`(?i)^v?` +
+	`(?:([0-9]+)!)?` +
+	`([0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?([0-9]+)?)?` +
+	`(?:-([0-9]+)|[-_.]?(post|rev|r)[-_.]?([0-9]+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?([0-9]+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// pre-release phases, in the order they sort in. A version without a
+// pre-release sorts after all of them, and a development release of a final
+// version before all of them.
+const (
+	phaseDevOnly = iota
+	phaseAlpha
+	phaseBeta
+	phaseCandidate
+	phaseFinal
+)
+
+// version is a version parsed by the rules of PEP 440
+type version struct {
+	epoch    int
+	release  []int
+	phase    int
+	pre      int
+	post     int // -1 without a post-release
+	dev      int // -1 without a development release
+	local    []string
+	hasLocal bool
+}
+
+// parseVersion parses a PEP 440 version, returning false if it isn't one
+func parseVersion(s string) (version, bool) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return version{}, false
+	}
+
+	v := version{phase: phaseFinal, post: -1, dev: -1}
+	v.epoch = atoi(m[1])
+	for _, part := range strings.Split(m[2], ".") {
+		v.release = append(v.release, atoi(part))
+	}
+
+	switch strings.ToLower(m[3]) {
+	case "a", "alpha":
+		v.phase = phaseAlpha
+	case "b", "beta":
+		v.phase = phaseBeta
+	case "c", "rc", "pre", "preview":
+		v.phase = phaseCandidate
+	}
+	v.pre = atoi(m[4])
+
+	switch {
+	case m[5] != "":
+		v.post = atoi(m[5])
+	case m[6] != "":
+		v.post = atoi(m[7])
+	}
+
+	if m[8] != "" {
+		v.dev = atoi(m[9])
+		if v.phase == phaseFinal && v.post < 0 {
+			v.phase = phaseDevOnly
+		}
+	}
+
+	if m[10] != "" {
+		v.hasLocal = true
+		v.local = strings.FieldsFunc(strings.ToLower(m[10]), func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+
+	return v, true
+}
+
+// isPreRelease is true for alpha, beta, candidate and development releases
+func (v version) isPreRelease() bool {
+	return v.phase != phaseFinal || v.dev >= 0
+}
+
+// compareVersions compares two versions the way pip does, returning -1 if a
+// is older than b, 1 if it is newer, and 0 if they are the same. Versions
+// that don't follow PEP 440 are compared the way dpkg and rpm compare them.
+func compareVersions(a, b pkg.PackageVersion) int {
+	va, okA := parseVersion(string(a))
+	vb, okB := parseVersion(string(b))
+	if !okA || !okB {
+		return pkg.CompareVersions(a, b)
+	}
+
+	if cmp := compareInts(va.epoch, vb.epoch); cmp != 0 {
+		return cmp
+	}
+	if cmp := compareRelease(va.release, vb.release); cmp != 0 {
+		return cmp
+	}
+	if cmp := compareInts(va.phase, vb.phase); cmp != 0 {
+		return cmp
+	}
+	if cmp := compareInts(va.pre, vb.pre); cmp != 0 {
+		return cmp
+	}
+	if cmp := compareInts(va.post, vb.post); cmp != 0 {
+		return cmp
+	}
+
+	// a development release comes before the release it leads up to
+	switch {
+	case va.dev < 0 && vb.dev >= 0:
+		return 1
+	case va.dev >= 0 && vb.dev < 0:
+		return -1
+	}
+	if cmp := compareInts(va.dev, vb.dev); cmp != 0 {
+		return cmp
+	}
+
+	return compareLocal(va, vb)
+}
+
+// compareRelease compares release segments, padding the shorter one with
+// zeros so that "1.0" and "1.0.0" are the same
+func compareRelease(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if cmp := compareInts(x, y); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareLocal compares local version labels. A version without one sorts
+// first, numeric segments sort after alphanumeric ones, and a label that
+// starts with another sorts after it.
+func compareLocal(a, b version) int {
+	switch {
+	case !a.hasLocal && !b.hasLocal:
+		return 0
+	case !a.hasLocal:
+		return -1
+	case !b.hasLocal:
+		return 1
+	}
+
+	for i := 0; i < len(a.local) && i < len(b.local); i++ {
+		x, errX := strconv.Atoi(a.local[i])
+		y, errY := strconv.Atoi(b.local[i])
+		switch {
+		case errX == nil && errY == nil:
+			if cmp := compareInts(x, y); cmp != 0 {
+				return cmp
+			}
+		case errX == nil:
+			return 1
+		case errY == nil:
+			return -1
+		default:
+			if cmp := strings.Compare(a.local[i], b.local[i]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return compareInts(len(a.local), len(b.local))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// atoi parses a run of digits, which the version pattern has already checked,
+// treating an empty string as zero
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
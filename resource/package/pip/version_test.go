@@ -0,0 +1,62 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pip
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompareVersions tests ordering versions by the rules of PEP 440
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	// each version is older than the next
+	ordered := []pkg.PackageVersion{
+		"1.0.dev1",
+		"1.0a1",
+		"1.0a2.dev1",
+		"1.0a2",
+		"1.0b1",
+		"1.0rc1",
+		"1.0",
+		"1.0+local.abc",
+		"1.0+local.1",
+		"1.0.post1.dev1",
+		"1.0.post1",
+		"1.0.1",
+		"1.10",
+		"1!0.1",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			expected := compareInts(i, j)
+			assert.Equal(t, expected, compareVersions(ordered[i], ordered[j]), "%s <=> %s", ordered[i], ordered[j])
+		}
+	}
+
+	t.Run("normalized", func(t *testing.T) {
+		assert.Equal(t, 0, compareVersions("1.0", "1.0.0"))
+		assert.Equal(t, 0, compareVersions("1.0alpha1", "1.0a1"))
+		assert.Equal(t, 0, compareVersions("1.0-1", "1.0.post1"))
+		assert.Equal(t, 0, compareVersions("v2.0RC1", "2.0rc1"))
+	})
+
+	t.Run("not pep 440", func(t *testing.T) {
+		assert.Equal(t, -1, compareVersions("1.0-foo", "1.1"))
+	})
+}
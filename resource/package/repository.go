@@ -0,0 +1,145 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+)
+
+// RepositoryFile is a file managed by a package repository resource, like a
+// list of sources or a signing key
+type RepositoryFile struct {
+	Path    string
+	Content string
+
+	// Opaque files, like keys, are not shown as a diff
+	Opaque bool
+
+	// Stale files are removed even when the repository is present, like a key
+	// left behind in another format
+	Stale bool
+}
+
+// Check adds the difference between the file on disk and the wanted content
+// to the status, or between the file and no file if absent is true. It
+// returns true if the file has to be changed.
+func (f *RepositoryFile) Check(status *resource.Status, absent bool) (bool, error) {
+	absent = absent || f.Stale
+	actual, err := ioutil.ReadFile(f.Path)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return false, errors.Wrapf(err, "cannot read %q", f.Path)
+	}
+
+	switch {
+	case absent:
+		if !exists {
+			return false, nil
+		}
+		status.AddDifference(f.Path, "<present>", "<absent>", "")
+
+	case exists && string(actual) == f.Content:
+		return false, nil
+
+	case f.Opaque:
+		original := "<absent>"
+		if exists {
+			original = "<changed>"
+		}
+		status.AddDifference(f.Path, original, "<present>", "")
+
+	default:
+		diff := resource.NewUnifiedDiff(string(actual), f.Content)
+		if !exists {
+			diff.Placeholder = "<file-missing>"
+		}
+		status.Differences[f.Path] = diff
+	}
+
+	return true, nil
+}
+
+// Write writes the file, creating its directory if needed, or removes it if
+// absent is true
+func (f *RepositoryFile) Write(absent bool) error {
+	if absent || f.Stale {
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "cannot remove %q", f.Path)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return errors.Wrapf(err, "cannot create %q", filepath.Dir(f.Path))
+	}
+	if err := ioutil.WriteFile(f.Path, []byte(f.Content), 0644); err != nil {
+		return errors.Wrapf(err, "cannot write %q", f.Path)
+	}
+	return nil
+}
+
+// IsArmored returns true if a key is ASCII armored rather than binary
+func IsArmored(key string) bool {
+	return strings.HasPrefix(strings.TrimSpace(key), "-----BEGIN PGP")
+}
+
+// ReadKeys parses the public keys in an ASCII armored or binary key
+func ReadKeys(key string) (openpgp.EntityList, error) {
+	var keys openpgp.EntityList
+	var err error
+	if IsArmored(key) {
+		keys, err = openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+	} else {
+		keys, err = openpgp.ReadKeyRing(strings.NewReader(key))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read key")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("cannot read key: no public keys found")
+	}
+	return keys, nil
+}
+
+// NormalizeFingerprint removes the spaces and "0x" prefix a key fingerprint
+// may be written with, and upper-cases it
+func NormalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.Join(strings.Fields(fingerprint), "")
+	if strings.HasPrefix(fingerprint, "0x") || strings.HasPrefix(fingerprint, "0X") {
+		fingerprint = fingerprint[2:]
+	}
+	return strings.ToUpper(fingerprint)
+}
+
+// CheckFingerprint returns an error unless the key is a single public key with
+// the given fingerprint, so that no other key is trusted along with it
+func CheckFingerprint(keys openpgp.EntityList, fingerprint string) error {
+	fingerprint = NormalizeFingerprint(fingerprint)
+	for _, key := range keys {
+		actual := fmt.Sprintf("%X", key.PrimaryKey.Fingerprint)
+		if actual != fingerprint {
+			return fmt.Errorf("key has fingerprint %s, expected %s", actual, fingerprint)
+		}
+	}
+	return nil
+}
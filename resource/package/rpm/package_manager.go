@@ -16,8 +16,10 @@ package rpm
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
 )
 
 // YumManager provides a concrete implementation of PackageManager for yum
@@ -28,25 +30,149 @@ type YumManager struct {
 
 // InstalledVersion gets the installed version of package, if available
 func (y *YumManager) InstalledVersion(p string) (pkg.PackageVersion, bool) {
-	result, err := y.Sys.Run(fmt.Sprintf("rpm -q %s", p))
+	result, err := y.Sys.Run(fmt.Sprintf("rpm -q --qf '%%{VERSION}-%%{RELEASE}\\n' %s", p))
 	exitCode, _ := pkg.GetExitCode(err)
 	if exitCode != 0 {
 		return "", false
 	}
-	return (pkg.PackageVersion)(result), true
+	// several versions of some packages (like the kernel) can be installed at
+	// once, use the newest one. rpm lists them in the order they were
+	// installed, which isn't always oldest first.
+	var newest pkg.PackageVersion
+	for _, line := range strings.Split(strings.TrimSpace(string(result)), "\n") {
+		version := pkg.PackageVersion(strings.TrimSpace(line))
+		if pkg.CompareVersions(version, newest) > 0 {
+			newest = version
+		}
+	}
+	return newest, true
+}
+
+// LatestVersion gets the newest version of a package available in the
+// configured repositories, using `repoquery` from yum-utils
+func (y *YumManager) LatestVersion(p string) (pkg.PackageVersion, error) {
+	result, err := y.Sys.Run(fmt.Sprintf("repoquery --qf '%%{VERSION}-%%{RELEASE}' %s", p))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not query available versions of %s", p)
+	}
+	var latest pkg.PackageVersion
+	for _, line := range strings.Split(strings.TrimSpace(string(result)), "\n") {
+		version := pkg.PackageVersion(strings.TrimSpace(line))
+		if version != "" && pkg.CompareVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no installable version of %s", p)
+	}
+	return latest, nil
 }
 
-// InstallPackage installs a package, returning an error if something went wrong
-func (y *YumManager) InstallPackage(pkg string) (string, error) {
-	if _, isInstalled := y.InstalledVersion(pkg); isInstalled {
-		return "already installed", nil
+// InstallPackages installs several packages with a single yum call. Packages
+// with a version older than the one installed are downgraded with a second
+// call, since `yum install` won't do that.
+func (y *YumManager) InstallPackages(specs []pkg.PackageSpec) (string, error) {
+	var install, downgrade []string
+	for _, spec := range specs {
+		if spec.Version == "" {
+			install = append(install, shellQuote(spec.Name))
+			continue
+		}
+
+		arg := shellQuote(fmt.Sprintf("%s-%s", spec.Name, spec.Version))
+		if installed, isInstalled := y.InstalledVersion(spec.Name); isInstalled && pkg.CompareVersions(installed, spec.Version) > 0 {
+			downgrade = append(downgrade, arg)
+		} else {
+			install = append(install, arg)
+		}
 	}
-	res, err := y.Sys.Run(fmt.Sprintf("yum install -y %s", pkg))
+
+	var out []string
+	for _, call := range []struct {
+		command string
+		args    []string
+	}{{"install", install}, {"downgrade", downgrade}} {
+		if len(call.args) == 0 {
+			continue
+		}
+		res, err := y.Sys.Run(fmt.Sprintf("yum %s -y %s", call.command, strings.Join(call.args, " ")))
+		out = append(out, string(res))
+		if err != nil {
+			return strings.Join(out, "\n"), err
+		}
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// RemovePackages removes several packages with a single yum call
+func (y *YumManager) RemovePackages(names []string) (string, error) {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = shellQuote(name)
+	}
+	res, err := y.Sys.Run(fmt.Sprintf("yum remove -y %s", strings.Join(quoted, " ")))
 	return string(res), err
 }
 
-// RemovePackage removes a package, returning an error if something went wrong
-func (y *YumManager) RemovePackage(pkg string) (string, error) {
-	res, err := y.Sys.Run(fmt.Sprintf("yum remove -y %s", pkg))
+// IsHeld returns true if the package is locked with the versionlock plugin
+func (y *YumManager) IsHeld(p string) (bool, error) {
+	locks, err := y.locks(p)
+	return len(locks) > 0, err
+}
+
+// HoldPackage locks a package at its installed version with the versionlock
+// plugin
+func (y *YumManager) HoldPackage(p string) (string, error) {
+	res, err := y.Sys.Run(fmt.Sprintf("yum -q versionlock add %s", p))
 	return string(res), err
 }
+
+// UnholdPackage removes every versionlock entry for a package
+func (y *YumManager) UnholdPackage(p string) (string, error) {
+	locks, err := y.locks(p)
+	if err != nil {
+		return "", err
+	}
+	if len(locks) == 0 {
+		return "package is not held", nil
+	}
+	res, err := y.Sys.Run(fmt.Sprintf("yum -q versionlock delete %s", strings.Join(locks, " ")))
+	return string(res), err
+}
+
+// locks returns the versionlock entries for a package
+func (y *YumManager) locks(p string) ([]string, error) {
+	result, err := y.Sys.Run("yum -q versionlock list")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list version locks (is yum-plugin-versionlock installed?)")
+	}
+	var locks []string
+	for _, line := range strings.Split(string(result), "\n") {
+		line = strings.TrimSpace(line)
+		if lockedName(line) == p {
+			locks = append(locks, line)
+		}
+	}
+	return locks, nil
+}
+
+// lockedName returns the name of the package in a versionlock entry, like
+// "foo" in "0:foo-1.2-3.el7.*"
+func lockedName(entry string) string {
+	if idx := strings.Index(entry, ":"); idx >= 0 {
+		entry = entry[idx+1:]
+	}
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndex(entry, "-")
+		if idx < 0 {
+			return ""
+		}
+		entry = entry[:idx]
+	}
+	return entry
+}
+
+// shellQuote quotes a value for sh
+func shellQuote(val string) string {
+	return "'" + strings.Replace(val, "'", `'\''`, -1) + "'"
+}
@@ -19,6 +19,7 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/asteris-llc/converge/resource/package"
 	"github.com/asteris-llc/converge/resource/package/rpm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,14 +31,22 @@ func TestYumInstalledVersion(t *testing.T) {
 	t.Parallel()
 
 	t.Run("when installed", func(t *testing.T) {
-		expected := "foo-0.1.2.3"
-		runner := newRunner(expected, nil)
+		expected := "0.1.2-3"
+		runner := newRunner(expected+"\n", nil)
 		y := &rpm.YumManager{Sys: runner}
 		result, found := y.InstalledVersion("foo1")
 		assert.True(t, found)
 		assert.Equal(t, expected, string(result))
 	})
 
+	t.Run("when several are installed", func(t *testing.T) {
+		runner := newRunner("3.10.0-514.el7\n3.10.0-1062.el7\n3.10.0-957.el7\n", nil)
+		y := &rpm.YumManager{Sys: runner}
+		result, found := y.InstalledVersion("kernel")
+		assert.True(t, found)
+		assert.Equal(t, "3.10.0-1062.el7", string(result))
+	})
+
 	t.Run("when not installed", func(t *testing.T) {
 		expected := ""
 		y := &rpm.YumManager{Sys: newRunner("", makeExitError("", 1))}
@@ -47,36 +56,99 @@ func TestYumInstalledVersion(t *testing.T) {
 	})
 }
 
-// TestYumInstallPackage validates that we successfully ask yum to install a
-// package
-func TestYumInstallPackage(t *testing.T) {
+// TestYumInstallPackages validates that several packages are installed with
+// a single call, and that downgrades are made separately
+func TestYumInstallPackages(t *testing.T) {
 	t.Parallel()
 
-	t.Run("when installed", func(t *testing.T) {
-		pkg := "foo1"
+	t.Run("when installing", func(t *testing.T) {
 		runner := newRunner("", nil)
 		y := &rpm.YumManager{Sys: runner}
-		_, err := y.InstallPackage(pkg)
+		_, err := y.InstallPackages([]pkg.PackageSpec{{Name: "foo"}, {Name: "bar"}})
 		assert.NoError(t, err)
 		runner.AssertNumberOfCalls(t, "Run", 1)
 	})
 
-	t.Run("when not installed", func(t *testing.T) {
-		pkg := "foo1"
-		runner := newRunner("", makeExitError("", 1))
+	t.Run("when downgrading", func(t *testing.T) {
+		runner := newRunner("2.0-1.el7\n", nil)
 		y := &rpm.YumManager{Sys: runner}
-		y.InstallPackage(pkg)
-		runner.AssertNumberOfCalls(t, "Run", 2)
+		_, err := y.InstallPackages([]pkg.PackageSpec{{Name: "foo"}, {Name: "bar", Version: "1.0-1.el7"}})
+		assert.NoError(t, err)
+		runner.AssertNumberOfCalls(t, "Run", 3)
+		runner.AssertCalled(t, "Run", "yum install -y 'foo'")
+		runner.AssertCalled(t, "Run", "yum downgrade -y 'bar-1.0-1.el7'")
 	})
 
 	t.Run("when installation error", func(t *testing.T) {
-		pkg := "foo1"
 		runner := newRunner("", makeExitError("", 1))
 		y := &rpm.YumManager{Sys: runner}
-		_, err := y.InstallPackage(pkg)
+		_, err := y.InstallPackages([]pkg.PackageSpec{{Name: "foo"}})
+		assert.Error(t, err)
+	})
+}
+
+// TestYumLatestVersion validates that the newest available version is picked
+func TestYumLatestVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when available", func(t *testing.T) {
+		y := &rpm.YumManager{Sys: newRunner("1.10-1.el7\n1.9-1.el7\n", nil)}
+		result, err := y.LatestVersion("foo")
+		assert.NoError(t, err)
+		assert.Equal(t, "1.10-1.el7", string(result))
+	})
+
+	t.Run("when not available", func(t *testing.T) {
+		y := &rpm.YumManager{Sys: newRunner("", nil)}
+		_, err := y.LatestVersion("foo")
+		assert.Error(t, err)
+	})
+}
+
+// TestYumIsHeld validates that versionlock entries are matched by name
+func TestYumIsHeld(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when held", func(t *testing.T) {
+		y := &rpm.YumManager{Sys: newRunner("0:foo-devel-1.2-3.el7.*\n0:foo-1.2-3.el7.*\n", nil)}
+		held, err := y.IsHeld("foo")
+		assert.NoError(t, err)
+		assert.True(t, held)
+	})
+
+	t.Run("when not held", func(t *testing.T) {
+		y := &rpm.YumManager{Sys: newRunner("0:foo-devel-1.2-3.el7.*\n", nil)}
+		held, err := y.IsHeld("foo")
+		assert.NoError(t, err)
+		assert.False(t, held)
+	})
+
+	t.Run("when versionlock is missing", func(t *testing.T) {
+		y := &rpm.YumManager{Sys: newRunner("", makeExitError("", 1))}
+		_, err := y.IsHeld("foo")
 		assert.Error(t, err)
+	})
+}
+
+// TestYumUnholdPackage validates that every lock for a package is removed
+func TestYumUnholdPackage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when held", func(t *testing.T) {
+		runner := newRunner("0:foo-1.2-3.el7.*\n", nil)
+		y := &rpm.YumManager{Sys: runner}
+		_, err := y.UnholdPackage("foo")
+		assert.NoError(t, err)
 		runner.AssertNumberOfCalls(t, "Run", 2)
 	})
+
+	t.Run("when not held", func(t *testing.T) {
+		runner := newRunner("", nil)
+		y := &rpm.YumManager{Sys: runner}
+		_, err := y.UnholdPackage("foo")
+		assert.NoError(t, err)
+		runner.AssertNumberOfCalls(t, "Run", 1)
+	})
 }
 
 // MockRunner mocks out SysCaller
@@ -86,7 +158,7 @@ type MockRunner struct {
 
 // Run mocks out Run
 func (m *MockRunner) Run(cmd string) ([]byte, error) {
-	args := m.Called(1)
+	args := m.Called(cmd)
 	return args.Get(0).([]byte), args.Error(1)
 }
 
@@ -111,10 +183,10 @@ func queryString(pkg string) string {
 
 // installString generates a yum install string
 func installString(pkg string) string {
-	return "yum install -y " + pkg
+	return "yum install -y '" + pkg + "'"
 }
 
 // removeString generates a yum remove string
 func removeString(pkg string) string {
-	return "yum remove -y " + pkg
+	return "yum remove -y '" + pkg + "'"
 }
@@ -15,7 +15,6 @@
 package rpm
 
 import (
-	"errors"
 	"strings"
 
 	"github.com/asteris-llc/converge/load/registry"
@@ -30,29 +29,56 @@ import (
 // both `rpm` and `yum` are installed on the system, and that the user has
 // permissions to install, remove, and query packages.
 type Preparer struct {
-	// Name of the package or package group.
-	Name string `hcl:"name" required:"true" nonempty:"true"`
+	// Name of the package or package group. Either name or packages is
+	// required.
+	Name string `hcl:"name" mutually_exclusive:"name,packages"`
+
+	// Packages to manage together. They are installed or removed with a
+	// single `yum` call, which is much faster than one resource per package.
+	// State and hold apply to all of them, and the plan shows each package
+	// that will change.
+	Packages []string `hcl:"packages" mutually_exclusive:"name,packages"`
 
 	// State of the package. Present means the package will be installed if
-	// missing; Absent means the package will be uninstalled if present.
-	State pkg.State `hcl:"state" valid_values:"present,absent"`
+	// missing; Absent means the package will be uninstalled if present; Latest
+	// means the package will be installed or upgraded to the newest available
+	// version.
+	State pkg.State `hcl:"state" valid_values:"present,absent,latest"`
+
+	// Version the package should be at. Only valid when state is present, and
+	// not with packages. Either a specific version, like "1.2.3" or
+	// "1.2.3-1.el7", or a constraint like ">= 1.2, < 2.0". A trailing "*"
+	// matches any version starting with the rest, like "1.2.*". When the
+	// installed version doesn't match a constraint, the newest available
+	// version is installed if it does. Constraints and the latest state need
+	// `repoquery` from yum-utils.
+	Version string `hcl:"version"`
+
+	// Hold the package at its installed version, so it isn't changed by other
+	// upgrades. The package is locked with the yum versionlock plugin. If hold
+	// is false, an existing lock is released. If unset, locks are left alone.
+	Hold *bool `hcl:"hold"`
 }
 
 // Prepare a new packge
 func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
-	if strings.TrimSpace(p.Name) == "" {
-		return &pkg.Package{}, errors.New("package name cannot be empty")
-	}
-
 	if p.State == "" {
 		p.State = "present"
 	}
 
-	return &pkg.Package{
-		Name:   p.Name,
-		State:  p.State,
-		PkgMgr: &YumManager{Sys: pkg.ExecCaller{}},
-	}, nil
+	task := &pkg.Package{
+		Name:     p.Name,
+		Packages: p.Packages,
+		State:    p.State,
+		Version:  strings.TrimSpace(p.Version),
+		Hold:     p.Hold,
+		PkgMgr:   &YumManager{Sys: pkg.ExecCaller{}},
+	}
+	if err := task.Validate(); err != nil {
+		return &pkg.Package{}, err
+	}
+
+	return task, nil
 }
 
 func init() {
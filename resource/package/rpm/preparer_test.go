@@ -76,4 +76,43 @@ func TestPreparerCreatesPackage(t *testing.T) {
 		assert.EqualError(t, err, "package name cannot be empty")
 	})
 
+	t.Run("when-version", func(t *testing.T) {
+		hold := true
+		p := &rpm.Preparer{Name: "test1", Version: "1.2-1.el7", Hold: &hold}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		asPkg, ok := task.(*pkg.Package)
+		require.True(t, ok)
+		assert.Equal(t, "1.2-1.el7", asPkg.Version)
+		assert.Equal(t, &hold, asPkg.Hold)
+	})
+
+	t.Run("when-version-and-latest", func(t *testing.T) {
+		p := &rpm.Preparer{Name: "test1", State: "latest", Version: "1.2-1.el7"}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, `version cannot be set when state is "latest"`)
+	})
+
+	t.Run("when-hold-and-absent", func(t *testing.T) {
+		hold := true
+		p := &rpm.Preparer{Name: "test1", State: "absent", Hold: &hold}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("when-packages", func(t *testing.T) {
+		p := &rpm.Preparer{Packages: []string{"test1", "test2"}}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		asPkg, ok := task.(*pkg.Package)
+		require.True(t, ok)
+		assert.Equal(t, []string{"test1", "test2"}, asPkg.Packages)
+	})
+
+	t.Run("when-packages-empty-name", func(t *testing.T) {
+		p := &rpm.Preparer{Packages: []string{"test1", " "}}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, "package name cannot be empty")
+	})
+
 }
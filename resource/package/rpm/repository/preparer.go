@@ -0,0 +1,168 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DefaultReposDir is where repository definitions are written
+	DefaultReposDir = "/etc/yum.repos.d"
+
+	// DefaultKeyDir is where signing keys are written
+	DefaultKeyDir = "/etc/pki/rpm-gpg"
+)
+
+// Preparer for RPM Repository
+//
+// RPM Repository manages a yum repository definition in /etc/yum.repos.d and
+// the key its packages are signed with. The repository metadata is refreshed
+// only when the definition or key change. `package.rpm` and `package`
+// resources in the same module depend on every rpm repository, so packages
+// from the repository can be installed without listing it in `depends`, and
+// they aren't installed if the repository fails.
+type Preparer struct {
+	// Name of the repository, used as its ID. The definition is written to
+	// /etc/yum.repos.d/<name>.repo.
+	Name string `hcl:"name" required:"true" nonempty:"true"`
+
+	// Description of the repository. Defaults to the name.
+	Description string `hcl:"description"`
+
+	// BaseURL of the repository. Either baseurl or mirrorlist is required
+	// unless state is absent.
+	BaseURL string `hcl:"baseurl" mutually_exclusive:"baseurl,mirrorlist"`
+
+	// Mirrorlist is the URL of a list of mirrors for the repository.
+	Mirrorlist string `hcl:"mirrorlist" mutually_exclusive:"baseurl,mirrorlist"`
+
+	// Enabled sets whether yum uses the repository.
+	Enabled *bool `hcl:"enabled"`
+
+	// GPGCheck sets whether yum checks the signatures of packages from the
+	// repository.
+	GPGCheck *bool `hcl:"gpgcheck"`
+
+	// Key the repository is signed with. It is written to
+	// /etc/pki/rpm-gpg/RPM-GPG-KEY-<name> and imported into the rpm database.
+	Key string `hcl:"key" mutually_exclusive:"key,key_url"`
+
+	// KeyURL the key the repository is signed with can be found at. Yum
+	// imports it when a package is first installed from the repository.
+	KeyURL string `hcl:"key_url" mutually_exclusive:"key,key_url"`
+
+	// Options sets other options of the repository, like "priority" or
+	// "sslverify".
+	Options map[string]string `hcl:"options"`
+
+	// State of the repository. Present means the definition and key will be
+	// written; Absent means they will be removed, and the key removed from the
+	// rpm database.
+	State State `hcl:"state" valid_values:"present,absent"`
+}
+
+// Prepare a new repository
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if strings.ContainsAny(p.Name, "/ []") {
+		return nil, fmt.Errorf("repository name %q cannot contain slashes, spaces or brackets", p.Name)
+	}
+
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
+	repo := &Repository{
+		Name:    p.Name,
+		State:   p.State,
+		Path:    filepath.Join(DefaultReposDir, p.Name+".repo"),
+		Key:     p.Key,
+		KeyPath: keyPath(DefaultKeyDir, p.Name),
+		Sys:     pkg.ExecCaller{},
+	}
+
+	if p.State == StateAbsent {
+		return repo, nil
+	}
+
+	if p.BaseURL == "" && p.Mirrorlist == "" {
+		return nil, errors.New("baseurl or mirrorlist is required")
+	}
+
+	repo.Content = p.definition()
+	return repo, nil
+}
+
+// definition renders the repository definition
+func (p *Preparer) definition() string {
+	description := p.Description
+	if description == "" {
+		description = p.Name
+	}
+
+	lines := []string{
+		"[" + p.Name + "]",
+		"name=" + description,
+	}
+	if p.BaseURL != "" {
+		lines = append(lines, "baseurl="+p.BaseURL)
+	}
+	if p.Mirrorlist != "" {
+		lines = append(lines, "mirrorlist="+p.Mirrorlist)
+	}
+	lines = append(lines,
+		"enabled="+flag(p.Enabled),
+		"gpgcheck="+flag(p.GPGCheck),
+	)
+
+	switch {
+	case p.Key != "":
+		lines = append(lines, "gpgkey=file://"+keyPath(DefaultKeyDir, p.Name))
+	case p.KeyURL != "":
+		lines = append(lines, "gpgkey="+p.KeyURL)
+	}
+
+	var keys []string
+	for key := range p.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, key+"="+p.Options[key])
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// flag renders an optional boolean, which defaults to true
+func flag(val *bool) string {
+	if val != nil && !*val {
+		return "0"
+	}
+	return "1"
+}
+
+func init() {
+	registry.Register("package.rpm.repository", (*Preparer)(nil), (*Repository)(nil))
+}
@@ -0,0 +1,102 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package/rpm/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreparerInterfaces ensures that the correct interfaces are implemented by
+// the preparer
+func TestPreparerInterfaces(t *testing.T) {
+	t.Parallel()
+	assert.Implements(t, (*resource.Resource)(nil), new(repository.Preparer))
+}
+
+// TestPreparerCreatesRepository tests that the preparer renders the repository
+// definition
+func TestPreparerCreatesRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with key", func(t *testing.T) {
+		disabled := false
+		p := &repository.Preparer{
+			Name:    "docker",
+			BaseURL: "https://download.docker.com/linux/centos/7/x86_64/stable",
+			Enabled: &disabled,
+			Key:     "key",
+			Options: map[string]string{"sslverify": "1", "priority": "10"},
+		}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		repo, ok := task.(*repository.Repository)
+		require.True(t, ok)
+		assert.Equal(t, repository.StatePresent, repo.State)
+		assert.Equal(t, "/etc/yum.repos.d/docker.repo", repo.Path)
+		assert.Equal(t, "/etc/pki/rpm-gpg/RPM-GPG-KEY-docker", repo.KeyPath)
+		assert.Equal(
+			t,
+			"[docker]\nname=docker\nbaseurl=https://download.docker.com/linux/centos/7/x86_64/stable\nenabled=0\ngpgcheck=1\ngpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-docker\npriority=10\nsslverify=1\n",
+			repo.Content,
+		)
+	})
+
+	t.Run("with key url", func(t *testing.T) {
+		p := &repository.Preparer{
+			Name:        "epel",
+			Description: "Extra Packages for Enterprise Linux",
+			Mirrorlist:  "https://mirrors.fedoraproject.org/metalink?repo=epel-7&arch=x86_64",
+			KeyURL:      "https://dl.fedoraproject.org/pub/epel/RPM-GPG-KEY-EPEL-7",
+		}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"[epel]\nname=Extra Packages for Enterprise Linux\nmirrorlist=https://mirrors.fedoraproject.org/metalink?repo=epel-7&arch=x86_64\nenabled=1\ngpgcheck=1\ngpgkey=https://dl.fedoraproject.org/pub/epel/RPM-GPG-KEY-EPEL-7\n",
+			task.(*repository.Repository).Content,
+		)
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		p := &repository.Preparer{Name: "docker", State: repository.StateAbsent}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, "", task.(*repository.Repository).Content)
+	})
+}
+
+// TestPreparerValidates tests that invalid repositories are rejected
+func TestPreparerValidates(t *testing.T) {
+	t.Parallel()
+
+	t.Run("name with bracket", func(t *testing.T) {
+		p := &repository.Preparer{Name: "a]b", BaseURL: "http://x"}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("missing baseurl", func(t *testing.T) {
+		p := &repository.Preparer{Name: "a"}
+		_, err := p.Prepare(context.Background(), fakerenderer.New())
+		assert.EqualError(t, err, "baseurl or mirrorlist is required")
+	})
+}
@@ -0,0 +1,205 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// State type for Repository
+type State string
+
+const (
+	// StatePresent indicates the repository should be configured
+	StatePresent State = "present"
+
+	// StateAbsent indicates the repository should be removed
+	StateAbsent State = "absent"
+)
+
+// Repository manages a yum repository definition and its signing key
+type Repository struct {
+	// name of the repository
+	Name string `export:"name"`
+
+	// whether the repository should be present or absent
+	State State `export:"state"`
+
+	// content of the repository definition
+	Content string `export:"content"`
+
+	// location of the repository definition
+	Path string `export:"path"`
+
+	// the signing key, if given directly
+	Key string
+
+	// location of the signing key
+	KeyPath string `export:"keypath"`
+
+	// whether the metadata was refreshed during apply
+	Updated bool `export:"updated"`
+
+	Sys pkg.SysCaller
+}
+
+// Check if the repository definition or key have to change
+func (r *Repository) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	var changed bool
+	for _, f := range r.files() {
+		fileChanged, err := f.Check(status, r.State == StateAbsent)
+		if err != nil {
+			status.RaiseLevel(resource.StatusCantChange)
+			return status, err
+		}
+		changed = changed || fileChanged
+	}
+
+	if changed && r.State != StateAbsent {
+		status.AddMessage("repository metadata will be refreshed")
+	}
+
+	if r.State == StateAbsent {
+		for _, name := range r.importedKeys(status) {
+			status.AddDifference(name, "<imported>", "<absent>", "")
+		}
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// Apply writes the repository definition and key, imports the key, and
+// refreshes the repository metadata if anything changed
+func (r *Repository) Apply(context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	// the imported keys are found from the key file, so before it is removed
+	var imported []string
+	if r.State == StateAbsent {
+		imported = r.importedKeys(status)
+	}
+
+	var changed, keyChanged bool
+	for _, f := range r.files() {
+		fileChanged, err := f.Check(status, r.State == StateAbsent)
+		if err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		if !fileChanged {
+			continue
+		}
+		if err := f.Write(r.State == StateAbsent); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		changed = true
+		keyChanged = keyChanged || f.Path == r.KeyPath
+	}
+
+	if r.State != StateAbsent && keyChanged {
+		if err := r.run(status, "imported key", fmt.Sprintf("rpm --import %s", r.KeyPath)); err != nil {
+			return status, err
+		}
+	}
+
+	if r.State != StateAbsent && changed {
+		cmd := fmt.Sprintf("yum -q makecache --disablerepo='*' --enablerepo='%s'", r.Name)
+		if err := r.run(status, "refreshed repository metadata", cmd); err != nil {
+			return status, err
+		}
+		r.Updated = true
+	}
+
+	if len(imported) > 0 {
+		if err := r.run(status, "removed imported key", "rpm -e "+strings.Join(imported, " ")); err != nil {
+			return status, err
+		}
+		for _, name := range imported {
+			status.AddDifference(name, "<imported>", "<absent>", "")
+		}
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// files returns the files making up the repository. When the repository
+// should be absent, the key it may have had is included.
+func (r *Repository) files() []*pkg.RepositoryFile {
+	files := []*pkg.RepositoryFile{{Path: r.Path, Content: r.Content}}
+	if r.Key != "" || r.State == StateAbsent {
+		files = append(files, &pkg.RepositoryFile{Path: r.KeyPath, Content: r.Key, Opaque: true})
+	}
+	return files
+}
+
+// importedKeys returns the names of the keys in the key file that are in the
+// rpm database. rpm names an imported key after its ID and creation time,
+// like "gpg-pubkey-0ebfcd88-58bd5b4e". A key file that can't be read is noted
+// in the status, since the repository can still be removed without it.
+func (r *Repository) importedKeys(status *resource.Status) []string {
+	content, err := ioutil.ReadFile(r.KeyPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		status.AddMessage(fmt.Sprintf("cannot find the imported key: %s", err))
+		return nil
+	}
+
+	keys, err := pkg.ReadKeys(string(content))
+	if err != nil {
+		status.AddMessage(fmt.Sprintf("cannot find the imported key: %s", err))
+		return nil
+	}
+
+	var names []string
+	for _, key := range keys {
+		name := fmt.Sprintf("gpg-pubkey-%08x-%08x", uint32(key.PrimaryKey.KeyId), uint32(key.PrimaryKey.CreationTime.Unix()))
+		if _, err := r.Sys.Run("rpm -q " + name); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// run runs a command, adding its output to the status
+func (r *Repository) run(status *resource.Status, message, cmd string) error {
+	out, err := r.Sys.Run(cmd)
+	status.AddMessage(message)
+	status.AddMessage(string(out))
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return errors.Wrapf(err, "%s failed", cmd)
+	}
+	return nil
+}
+
+// keyPath returns the location of the signing key for a repository
+func keyPath(dir, name string) string {
+	return filepath.Join(dir, "RPM-GPG-KEY-"+name)
+}
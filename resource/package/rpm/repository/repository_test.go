@@ -0,0 +1,205 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/package/rpm/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// armoredKey is imported by rpm as importedKey
+const armoredKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+Version: GnuPG v1
+
+mQENBFengzoBCADLJvQwXJtUfY3+vgCSj7x+X7yvgg/YT/5BfG5XhS+aR5foaTqM
+DUEQb2gDY3pcWTi1TbGYJ9bedleTyn5F0Vh92nEdIIVG/y9RrV5vRsr5zRVGUj69
+Gk2lOviZ5wWth9JI6rBy0aTtpeoDlQNofWWu4ml77LqxySu/uxZFCZXnkdqeOPw6
+NWS1+nY4p96k3xmcZY68fR1+jgnoIw+xd1B2SOZZrQEZCIx1qfcOJYUFBY0OCDIr
+n0kc3IAfT9HGHMAd0Y3t7Y2fizE3MEIb3Z0uaaQg/JOuqPgVPLgEyE/pAThCWYbF
++Hp9z2PB+DvavBRAI/3evG9cX+GJ2Px7I0K/ABEBAAG0L1Rlc3QgQXN0ZXJpcyAo
+VGVzdCBzaWduaW5nIGtleSkgPHRlc3RAYXN0ZXIuaXM+iQE4BBMBAgAiBQJXp4M6
+AhsvBgsJCAcDAgYVCAIJCgsEFgIDAQIeAQIXgAAKCRByA1H/R1zJKJxlB/9eej/S
+5Nh8NZoT6rPrfxhqoCf+53T2j+JbBnEZRoWE61dnJjMDuDw+3UK7EiObAxY+iZPi
+2K/AeHlN8cRsLdth6ohPlVlpfgSZq5TNJigzNuItoYLB0hDQjW6T30DvvJ8dgpGy
+GUVlKKKK6Tcoc9IIHuKd9G+cF5oTY91GLjRDt1tf+33Ot8Wqd9ZWcfCZuihpwa7C
+L3fui/zbU//xBzBsubv1Wa2rjRMqjM6lK3yM4l8vcxk/jW0DGuhFhuGlEMBHbEnZ
+pDSoCw4XKdy+gzQQYjJMR8niocW0xoQQ2BVSNYvSPmsXlb9grwda0BSuKx1ovykX
+bigYyuHg+BBoS3Y0uQENBFengzoBCACgr5BprdYM95RJT1PRjV5caGiLutX6nY+F
+leGMB76zDJ7UDBAhytx9+eT9qBCbtngz+LES4y78544KrrpWJmI9eEZDc+T8r8rZ
+ycZI2N7WstbcbvMLNzXqMmnOnpC3LgeQz9VMrAKufg7rdYIFWJSw7p5Q2TGSfpCE
+Wsqut0LguuOEc/GtqoPqDMej0t94a88IB9lK8lHudSsTy9jlUwNC2Aa51DuRXaCM
+jNVC9YG7v15weapa1tRm16wsBzplajHn5coFcd2fDGfxAZR5YwtQja+x8vqXMDPc
+9pYtFi0PNjHBZ8T5TV4bFqWLL9inyM2O8+ncco3ghDzJ3dsLOJ6fABEBAAGJAj4E
+GAECAAkFAlengzoCGy4BKQkQcgNR/0dcySjAXSAEGQECAAYFAlengzoACgkQH7zZ
+vhMn2Jyiggf/SZsX0YwbZvQmrpzSQ1gbs805csQfxHvJ/e1dLzdOvNaotK54DDhp
+S9nCRStwBgClMsFS2vhcPGjnJsFob3d62PghZOTabmG49/TnK5trlPYkYmAwklAy
+6sXDMWtvMAm/kDhbpLYpR/yFRHo+1OEuHkiXltEbRJFg/Drzrbg8Muf+qXdNzunh
+KN/vDfu+uOooPwTfvMH/MdwHSEuw/QLJ9is5CYCDsbK9bB2aMri4lt/791Zyaf8j
+9WASF2n0aX30/jCCzsLTUjQS2W3OTrc8jt43bdUqPl+Ce6xM5py2g0C3jk9M97bt
+FL7ucS9c7dIMYY2hsHOMunx9V6D0wJboLE29B/4mNEf6C1+gjemcpoDb3g8K4dcl
+Ttjb6PcOuqS1N2bl6NUFLY5YER1klWcVTAwbrXEfzgnadAxqjx0zwIeZwiAMoG4z
+JN7pxR/g4G0B7cTtb4hXQK/BJAJAgnL1PQ1yUDLskrE+j/f1katW8WPp/MPPVr+o
+olzg/b5Pkk3hS3ApuP0d4BHEuB/vYoKGWY66HclrAZyQJFyfFeDDu6QSYaqxznFp
+ej83qcoSoffR18y3lr53ehuZoYGAWyJSaYXTarclB4nGKZk430OwX6ldnvtmyG1a
+PBuKW5ogwQXtoeFRdX3LjG4J3Wy/VgHzfQVQHxEHaY0qFbPn542+byp46WGJ
+=6XW+
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+const importedKey = "gpg-pubkey-475cc928-57a7833a"
+
+// TestRepositoryInterface ensures that the repository implements Task
+func TestRepositoryInterface(t *testing.T) {
+	t.Parallel()
+	assert.Implements(t, (*resource.Task)(nil), new(repository.Repository))
+}
+
+// TestRepositoryPresent tests adding a repository
+func TestRepositoryPresent(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-rpm-repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, runner := newRepository(dir, repository.StatePresent)
+
+	t.Run("check when missing", func(t *testing.T) {
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Contains(t, status.Diffs(), r.Path)
+		assert.Equal(t, "<absent>", status.Diffs()[r.KeyPath].Original())
+		assert.Contains(t, status.Messages(), "repository metadata will be refreshed")
+		runner.AssertNotCalled(t, "Run", mock.Anything)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		status, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.True(t, r.Updated)
+		runner.AssertCalled(t, "Run", "rpm --import "+r.KeyPath)
+		runner.AssertCalled(t, "Run", "yum -q makecache --disablerepo='*' --enablerepo='docker'")
+
+		content, err := ioutil.ReadFile(r.Path)
+		require.NoError(t, err)
+		assert.Equal(t, r.Content, string(content))
+	})
+
+	t.Run("check when present", func(t *testing.T) {
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("changed definition", func(t *testing.T) {
+		changed, runner := newRepository(dir, repository.StatePresent)
+		changed.Content += "priority=10\n"
+
+		_, err := changed.Apply(context.Background())
+		require.NoError(t, err)
+		runner.AssertNotCalled(t, "Run", "rpm --import "+r.KeyPath)
+		runner.AssertCalled(t, "Run", "yum -q makecache --disablerepo='*' --enablerepo='docker'")
+	})
+}
+
+// TestRepositoryAbsent tests removing a repository
+func TestRepositoryAbsent(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "converge-rpm-repository")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	present, _ := newRepository(dir, repository.StatePresent)
+	_, err = present.Apply(context.Background())
+	require.NoError(t, err)
+
+	r, runner := newRepository(dir, repository.StateAbsent)
+	r.Content = ""
+	r.Key = ""
+
+	t.Run("check", func(t *testing.T) {
+		status, err := r.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "<absent>", status.Diffs()[r.KeyPath].Current())
+		assert.Equal(t, "<absent>", status.Diffs()[importedKey].Current())
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		status, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "<absent>", status.Diffs()[r.Path].Current())
+		assert.Equal(t, "<absent>", status.Diffs()[r.KeyPath].Current())
+		runner.AssertCalled(t, "Run", "rpm -q "+importedKey)
+		runner.AssertCalled(t, "Run", "rpm -e "+importedKey)
+		runner.AssertNotCalled(t, "Run", "yum -q makecache --disablerepo='*' --enablerepo='docker'")
+
+		_, err = os.Stat(r.Path)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(r.KeyPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("key not imported", func(t *testing.T) {
+		_, err := present.Apply(context.Background())
+		require.NoError(t, err)
+
+		r, runner := newRepository(dir, repository.StateAbsent)
+		runner.ExpectedCalls = nil
+		runner.On("Run", "rpm -q "+importedKey).Return([]byte{}, errors.New("exit status 1"))
+
+		_, err = r.Apply(context.Background())
+		require.NoError(t, err)
+		runner.AssertNotCalled(t, "Run", "rpm -e "+importedKey)
+	})
+}
+
+// newRepository creates a repository writing to dir
+func newRepository(dir string, state repository.State) (*repository.Repository, *MockRunner) {
+	runner := &MockRunner{}
+	runner.On("Run", mock.Anything).Return([]byte{}, nil)
+	return &repository.Repository{
+		Name:    "docker",
+		State:   state,
+		Content: "[docker]\nname=docker\nbaseurl=https://download.docker.com/linux/centos/7/x86_64/stable\n",
+		Path:    filepath.Join(dir, "yum.repos.d", "docker.repo"),
+		Key:     armoredKey,
+		KeyPath: filepath.Join(dir, "rpm-gpg", "RPM-GPG-KEY-docker"),
+		Sys:     runner,
+	}, runner
+}
+
+// MockRunner mocks out SysCaller
+type MockRunner struct {
+	mock.Mock
+}
+
+// Run mocks out Run
+func (m *MockRunner) Run(cmd string) ([]byte, error) {
+	args := m.Called(cmd)
+	return args.Get(0).([]byte), args.Error(1)
+}
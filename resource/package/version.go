@@ -0,0 +1,256 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// operators that can start a version constraint, longest first so that ">="
+// isn't read as ">"
+var operators = []string{">=", "<=", "!=", "==", "=", ">", "<"}
+
+// Constraint is a set of version requirements that all have to be met, like
+// ">= 1.2, < 2.0"
+type Constraint []Requirement
+
+// Requirement is a single comparison against a version
+type Requirement struct {
+	Operator string
+	Version  PackageVersion
+}
+
+// IsConstraint returns true if the version starts with a comparison operator
+// or lists several requirements, and false if it names a single version
+func IsConstraint(version string) bool {
+	version = strings.TrimSpace(version)
+	if strings.Contains(version, ",") {
+		return true
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(version, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConstraint parses a comma-separated list of requirements. Each
+// requirement is an operator (one of =, ==, !=, >, >=, <, <=) followed by a
+// version. A version without an operator has to match exactly.
+func ParseConstraint(constraint string) (Constraint, error) {
+	var out Constraint
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("invalid version constraint %q: empty requirement", constraint)
+		}
+
+		req := Requirement{Operator: "="}
+		for _, op := range operators {
+			if strings.HasPrefix(part, op) {
+				req.Operator = op
+				part = strings.TrimSpace(strings.TrimPrefix(part, op))
+				break
+			}
+		}
+		if req.Operator == "==" {
+			req.Operator = "="
+		}
+
+		if part == "" || strings.ContainsAny(part, " \t") {
+			return nil, fmt.Errorf("invalid version constraint %q: bad version %q", constraint, part)
+		}
+		req.Version = PackageVersion(part)
+		out = append(out, req)
+	}
+	return out, nil
+}
+
+// Match returns true if the version meets every requirement
+func (c Constraint) Match(version PackageVersion) bool {
+	return c.MatchWith(version, VersionMatches, CompareVersions)
+}
+
+// MatchWith returns true if the version meets every requirement, matching
+// versions with matches and ordering them with compare
+func (c Constraint) MatchWith(version PackageVersion, matches func(a, b PackageVersion) bool, compare func(a, b PackageVersion) int) bool {
+	for _, req := range c {
+		if !req.MatchWith(version, matches, compare) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the constraint in its canonical form
+func (c Constraint) String() string {
+	parts := make([]string, len(c))
+	for i, req := range c {
+		parts[i] = req.Operator + " " + string(req.Version)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Match returns true if the version meets the requirement
+func (r Requirement) Match(version PackageVersion) bool {
+	return r.MatchWith(version, VersionMatches, CompareVersions)
+}
+
+// MatchWith returns true if the version meets the requirement, matching
+// versions with matches and ordering them with compare
+func (r Requirement) MatchWith(version PackageVersion, matches func(a, b PackageVersion) bool, compare func(a, b PackageVersion) int) bool {
+	switch r.Operator {
+	case "=":
+		return matches(version, r.Version)
+	case "!=":
+		return !matches(version, r.Version)
+	}
+
+	cmp := compare(version, r.Version)
+	switch r.Operator {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// VersionMatches returns true if the installed version is the wanted
+// version. A wanted version without a release (like "1.2.3") matches any
+// release of it (like "1.2.3-1ubuntu1" or "1.2.3-4.el7"), and a wanted version
+// ending with "*" matches any version that starts with the rest of it. A
+// wanted version without an epoch (like "8.9p1") matches the installed
+// version whatever its epoch (like "1:8.9p1-3ubuntu0.1").
+func VersionMatches(installed, wanted PackageVersion) bool {
+	if _, rest := splitEpoch(string(wanted)); rest == string(wanted) {
+		_, rest = splitEpoch(string(installed))
+		installed = PackageVersion(rest)
+	}
+	if installed == wanted {
+		return true
+	}
+	if strings.HasSuffix(string(wanted), "*") {
+		return strings.HasPrefix(string(installed), strings.TrimSuffix(string(wanted), "*"))
+	}
+	return strings.HasPrefix(string(installed), string(wanted)+"-")
+}
+
+// CompareVersions compares two versions the way dpkg and rpm do, returning -1
+// if a is older than b, 1 if it is newer, and 0 if they are the same. Versions
+// may start with an epoch ("1:2.0"). The rest is compared in alternating runs
+// of non-digits and digits: digits numerically, and everything else
+// character by character, with letters sorting before other characters and a
+// tilde sorting before anything, even the end of the version.
+func CompareVersions(a, b PackageVersion) int {
+	epochA, restA := splitEpoch(string(a))
+	epochB, restB := splitEpoch(string(b))
+	if epochA != epochB {
+		if epochA < epochB {
+			return -1
+		}
+		return 1
+	}
+	return compareSegments(restA, restB)
+}
+
+// splitEpoch separates the epoch from a version, defaulting to zero
+func splitEpoch(version string) (int, string) {
+	idx := strings.Index(version, ":")
+	if idx < 0 {
+		return 0, version
+	}
+	epoch, err := strconv.Atoi(version[:idx])
+	if err != nil {
+		return 0, version
+	}
+	return epoch, version[idx+1:]
+}
+
+// compareSegments compares the alternating non-digit and digit runs of two
+// versions
+func compareSegments(a, b string) int {
+	for a != "" || b != "" {
+		for (a != "" && !isDigit(a[0])) || (b != "" && !isDigit(b[0])) {
+			ca, cb := charOrder(a), charOrder(b)
+			if ca != cb {
+				if ca < cb {
+					return -1
+				}
+				return 1
+			}
+			a, b = a[1:], b[1:]
+		}
+
+		var numA, numB string
+		numA, a = leadingDigits(a)
+		numB, b = leadingDigits(b)
+		if cmp := compareNumbers(numA, numB); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// charOrder returns the sort weight of the first character of a version
+// segment, or of its end if it has run out of non-digits
+func charOrder(s string) int {
+	if s == "" || isDigit(s[0]) {
+		return 0
+	}
+	c := s[0]
+	switch {
+	case c == '~':
+		return -1
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// leadingDigits splits the leading run of digits off a string
+func leadingDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareNumbers compares two runs of digits numerically, without limiting
+// their size
+func compareNumbers(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
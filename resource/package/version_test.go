@@ -0,0 +1,109 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkg_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompareVersions tests ordering of versions
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b     pkg.PackageVersion
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10", "1.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"1.2.3-1", "1.2.3-2", -1},
+		{"1.2.3-10.el7", "1.2.3-9.el7", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0a", "1.0+", -1},
+		{"1:1.0", "2.0", 1},
+		{"007", "7", 0},
+	} {
+		assert.Equal(t, tc.expected, pkg.CompareVersions(tc.a, tc.b), "%s <=> %s", tc.a, tc.b)
+		assert.Equal(t, -tc.expected, pkg.CompareVersions(tc.b, tc.a), "%s <=> %s", tc.b, tc.a)
+	}
+}
+
+// TestVersionMatches tests matching installed versions against a version
+func TestVersionMatches(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, pkg.VersionMatches("1.2.3", "1.2.3"))
+	assert.True(t, pkg.VersionMatches("1.2.3-1ubuntu1", "1.2.3"))
+	assert.True(t, pkg.VersionMatches("1.2.3-1", "1.2.*"))
+	assert.False(t, pkg.VersionMatches("1.2.30", "1.2.3"))
+	assert.False(t, pkg.VersionMatches("1.3.0", "1.2.*"))
+	assert.True(t, pkg.VersionMatches("1:8.9p1-3ubuntu0.1", "8.9p1"))
+	assert.True(t, pkg.VersionMatches("1:8.9p1-3ubuntu0.1", "1:8.9p1"))
+	assert.True(t, pkg.VersionMatches("1:8.9p1-3ubuntu0.1", "8.9*"))
+	assert.False(t, pkg.VersionMatches("1:8.9p1-3ubuntu0.1", "2:8.9p1"))
+	assert.False(t, pkg.VersionMatches("8.9p1-3ubuntu0.1", "1:8.9p1"))
+}
+
+// TestParseConstraint tests parsing version constraints
+func TestParseConstraint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("is constraint", func(t *testing.T) {
+		assert.False(t, pkg.IsConstraint("1.2.3"))
+		assert.True(t, pkg.IsConstraint(">= 1.2"))
+		assert.True(t, pkg.IsConstraint("1.2, 1.3"))
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		c, err := pkg.ParseConstraint(">=1.2, < 2.0, != 1.5, ==1.7")
+		require.NoError(t, err)
+		assert.Equal(t, ">= 1.2, < 2.0, != 1.5, = 1.7", c.String())
+	})
+
+	t.Run("empty requirement", func(t *testing.T) {
+		_, err := pkg.ParseConstraint(">= 1.2,")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		_, err := pkg.ParseConstraint(">=")
+		assert.Error(t, err)
+	})
+
+	t.Run("bad version", func(t *testing.T) {
+		_, err := pkg.ParseConstraint(">= 1.2 2.0")
+		assert.Error(t, err)
+	})
+}
+
+// TestConstraintMatch tests matching versions against constraints
+func TestConstraintMatch(t *testing.T) {
+	t.Parallel()
+
+	c, err := pkg.ParseConstraint(">= 1.2, < 2.0, != 1.5")
+	require.NoError(t, err)
+
+	assert.True(t, c.Match("1.2"))
+	assert.True(t, c.Match("1.9.9-1"))
+	assert.False(t, c.Match("1.1.9"))
+	assert.False(t, c.Match("2.0"))
+	assert.False(t, c.Match("1.5-2"))
+}
@@ -0,0 +1,105 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zypper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/pkg/errors"
+)
+
+// Manager provides a concrete implementation of PackageManager for SUSE
+// packages.
+type Manager struct {
+	Sys pkg.SysCaller
+}
+
+// InstalledVersion gets the installed version of package, if available
+func (z *Manager) InstalledVersion(p string) (pkg.PackageVersion, bool) {
+	result, err := z.Sys.Run(fmt.Sprintf("rpm -q --qf '%%{VERSION}-%%{RELEASE}\\n' %s", p))
+	if exitCode, _ := pkg.GetExitCode(err); exitCode != 0 {
+		return "", false
+	}
+	lines := strings.Split(strings.TrimSpace(string(result)), "\n")
+	return pkg.PackageVersion(strings.TrimSpace(lines[len(lines)-1])), true
+}
+
+// LatestVersion gets the version zypper would install
+func (z *Manager) LatestVersion(p string) (pkg.PackageVersion, error) {
+	result, err := z.Sys.Run(fmt.Sprintf("zypper --non-interactive --quiet info %s", p))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not query available versions of %s", p)
+	}
+	for _, line := range strings.Split(string(result), "\n") {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == "Version" {
+			if version := strings.TrimSpace(kv[1]); version != "" {
+				return pkg.PackageVersion(version), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no installable version of %s", p)
+}
+
+// InstallPackages installs several packages with a single zypper call.
+// Packages with a version are pinned to it, which may downgrade them.
+func (z *Manager) InstallPackages(specs []pkg.PackageSpec) (string, error) {
+	args := make([]string, len(specs))
+	for i, spec := range specs {
+		args[i] = spec.Name
+		if spec.Version != "" {
+			args[i] = fmt.Sprintf("'%s=%s'", spec.Name, spec.Version)
+		}
+	}
+	res, err := z.Sys.Run(fmt.Sprintf("zypper --non-interactive install --oldpackage %s", strings.Join(args, " ")))
+	return string(res), err
+}
+
+// RemovePackages removes several packages with a single zypper call
+func (z *Manager) RemovePackages(names []string) (string, error) {
+	res, err := z.Sys.Run(fmt.Sprintf("zypper --non-interactive remove %s", strings.Join(names, " ")))
+	return string(res), err
+}
+
+// IsHeld returns true if the package is locked with `zypper addlock`
+func (z *Manager) IsHeld(p string) (bool, error) {
+	result, err := z.Sys.Run("zypper --non-interactive locks")
+	if err != nil {
+		return false, errors.Wrap(err, "could not list package locks")
+	}
+
+	// locks are listed in a table like "1 | foo | package | (any)"
+	for _, line := range strings.Split(string(result), "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) > 1 && strings.TrimSpace(fields[1]) == p {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HoldPackage locks a package with `zypper addlock`
+func (z *Manager) HoldPackage(p string) (string, error) {
+	res, err := z.Sys.Run(fmt.Sprintf("zypper --non-interactive addlock %s", p))
+	return string(res), err
+}
+
+// UnholdPackage removes the lock on a package
+func (z *Manager) UnholdPackage(p string) (string, error) {
+	res, err := z.Sys.Run(fmt.Sprintf("zypper --non-interactive removelock %s", p))
+	return string(res), err
+}
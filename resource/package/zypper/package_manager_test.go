@@ -0,0 +1,123 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zypper_test
+
+import (
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/package"
+	"github.com/asteris-llc/converge/resource/package/zypper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestZypperInstalledVersion validates that the installed version is read
+// from rpm
+func TestZypperInstalledVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when installed", func(t *testing.T) {
+		z := &zypper.Manager{Sys: newRunner("1.2.3-1.1\n", nil)}
+		result, found := z.InstalledVersion("foo")
+		assert.True(t, found)
+		assert.Equal(t, "1.2.3-1.1", string(result))
+	})
+
+	t.Run("when not installed", func(t *testing.T) {
+		z := &zypper.Manager{Sys: newRunner("", makeExitError("", 1))}
+		_, found := z.InstalledVersion("foo")
+		assert.False(t, found)
+	})
+}
+
+// TestZypperLatestVersion validates that the candidate version is read from
+// zypper info
+func TestZypperLatestVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when available", func(t *testing.T) {
+		out := "Information for package foo:\n----\nRepository     : Main Repository\nName           : foo\nVersion        : 1.2.4-2.1\nArch           : x86_64\n"
+		z := &zypper.Manager{Sys: newRunner(out, nil)}
+		result, err := z.LatestVersion("foo")
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.4-2.1", string(result))
+	})
+
+	t.Run("when not available", func(t *testing.T) {
+		z := &zypper.Manager{Sys: newRunner("package 'foo' not found.\n", nil)}
+		_, err := z.LatestVersion("foo")
+		assert.Error(t, err)
+	})
+}
+
+// TestZypperInstallPackages validates that several packages are installed
+// with a single call
+func TestZypperInstallPackages(t *testing.T) {
+	t.Parallel()
+
+	runner := newRunner("", nil)
+	z := &zypper.Manager{Sys: runner}
+	_, err := z.InstallPackages([]pkg.PackageSpec{{Name: "foo"}, {Name: "bar", Version: "1.0-1.1"}})
+	assert.NoError(t, err)
+	runner.AssertNumberOfCalls(t, "Run", 1)
+}
+
+// TestZypperIsHeld validates that locks are read from zypper locks
+func TestZypperIsHeld(t *testing.T) {
+	t.Parallel()
+
+	out := "\n# | Name    | Type    | Repository\n--+---------+---------+-----------\n1 | foo     | package | (any)\n"
+
+	t.Run("when locked", func(t *testing.T) {
+		z := &zypper.Manager{Sys: newRunner(out, nil)}
+		held, err := z.IsHeld("foo")
+		assert.NoError(t, err)
+		assert.True(t, held)
+	})
+
+	t.Run("when not locked", func(t *testing.T) {
+		z := &zypper.Manager{Sys: newRunner(out, nil)}
+		held, err := z.IsHeld("bar")
+		assert.NoError(t, err)
+		assert.False(t, held)
+	})
+}
+
+// MockRunner mocks out SysCaller
+type MockRunner struct {
+	mock.Mock
+}
+
+// Run mocks out Run
+func (m *MockRunner) Run(cmd string) ([]byte, error) {
+	args := m.Called(1)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// newRunner creates a new MockRunner that returns the output string and error
+func newRunner(output string, err error) *MockRunner {
+	m := &MockRunner{}
+	m.On("Run", mock.Anything).Return([]byte(output), err)
+	return m
+}
+
+// makeExitError generates a new ExitError
+func makeExitError(stderr string, exitCode uint32) error {
+	cmd := fmt.Sprintf("echo %q 1>&2; exit %d", stderr, exitCode)
+	_, err := exec.Command("/bin/bash", "-c", cmd).Output()
+	return err
+}
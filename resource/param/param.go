@@ -17,6 +17,7 @@ package param
 import (
 	"fmt"
 
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/resource"
 	"golang.org/x/net/context"
 )
@@ -27,11 +28,21 @@ type Param struct {
 
 	// the value of the parameter
 	Val interface{} `export:"val"`
+
+	// a human-readable description of the parameter
+	Description string `export:"description"`
+
+	// whether the value of the parameter is sensitive
+	Sensitive bool `export:"sensitive"`
 }
 
 // Check just returns the current value of the parameter. It should never have to change.
 func (p *Param) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
-	p.Status = resource.Status{Output: []string{p.String()}}
+	output := p.String()
+	if p.Sensitive {
+		output = redact.Mask
+	}
+	p.Status = resource.Status{Output: []string{output}}
 
 	return p, nil
 }
@@ -41,6 +52,16 @@ func (p *Param) Apply(context.Context) (resource.TaskStatus, error) {
 	return p, nil
 }
 
+// SensitiveFields returns the exported fields to mask in output. The value of
+// a sensitive param is masked by name, since values like "true" or "80" can't
+// be masked everywhere they appear.
+func (p *Param) SensitiveFields() []string {
+	if p.Sensitive {
+		return []string{"val"}
+	}
+	return nil
+}
+
 // String is the final value of this Param
 func (p *Param) String() string {
 	return fmt.Sprintf("%v", p.Val)
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/resource/param"
 	"github.com/stretchr/testify/assert"
@@ -41,6 +42,16 @@ func TestParamCheck(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestParamCheckSensitive(t *testing.T) {
+	t.Parallel()
+
+	param := &param.Param{Val: 8080, Sensitive: true}
+
+	status, err := param.Check(context.Background(), fakerenderer.New())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{redact.Mask}, status.Messages())
+}
+
 func TestParamApply(t *testing.T) {
 	t.Parallel()
 
@@ -16,11 +16,14 @@ package param
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/load/registry"
 	"github.com/asteris-llc/converge/resource"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
@@ -34,20 +37,163 @@ type Preparer struct {
 	// provided to this parameter. If this field is not set, this param will be
 	// treated as required.
 	Default interface{} `hcl:"default"`
+
+	// Type is the type of value this param accepts. Values provided on the
+	// command line or over RPC will be coerced to this type before they are
+	// validated. If not set, values are passed through unchanged.
+	Type Type `hcl:"type" valid_values:"string,int,bool,list,map"`
+
+	// Description is a human-readable explanation of what this param is for
+	Description string `hcl:"description"`
+
+	// Regex is a regular expression that the value must match. For lists, each
+	// element must match.
+	Regex string `hcl:"regex"`
+
+	// Min is the minimum value of an int param, or the minimum length of a
+	// string, list, or map param.
+	Min *int `hcl:"min"`
+
+	// Max is the maximum value of an int param, or the maximum length of a
+	// string, list, or map param.
+	Max *int `hcl:"max"`
+
+	// AllowedValues restricts the value of this param to one of the given
+	// values.
+	AllowedValues []string `hcl:"allowed_values"`
+
+	// Sensitive marks the value of this param as sensitive, for example a
+	// password or token. Sensitive values are masked in plan and apply output,
+	// RPC responses, and logs.
+	Sensitive bool `hcl:"sensitive"`
 }
 
 // Prepare a new task
 func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
 	paramName := strings.TrimPrefix(graph.BaseID(render.GetID()), "param.")
-	if val, present := render.Value(); present {
-		return &Param{Val: val}, nil
+
+	val, present := render.Value()
+	if !present {
+		if p.Default == nil {
+			return nil, fmt.Errorf("%s param is required", paramName)
+		}
+		val = p.Default
+	}
+
+	// register sensitive values before validating so they are masked in
+	// validation errors too
+	if p.Sensitive {
+		redact.FromContext(ctx).AddValue(val)
+	}
+
+	coerced, err := p.Validate(val)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s param", paramName)
+	}
+
+	if p.Sensitive {
+		redact.FromContext(ctx).AddValue(coerced)
+	}
+
+	return &Param{
+		Val:         coerced,
+		Description: p.Description,
+		Sensitive:   p.Sensitive,
+	}, nil
+}
+
+// Validate coerces the given value to the type of this param and checks it
+// against the configured constraints. The coerced value is returned.
+func (p *Preparer) Validate(val interface{}) (interface{}, error) {
+	coerced, err := p.Type.Coerce(val)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.AllowedValues) > 0 {
+		if err := p.validateAllowed(coerced); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Regex != "" {
+		if err := p.validateRegex(coerced); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Min != nil || p.Max != nil {
+		if err := p.validateRange(coerced); err != nil {
+			return nil, err
+		}
+	}
+
+	return coerced, nil
+}
+
+func (p *Preparer) validateAllowed(val interface{}) error {
+	str := fmt.Sprintf("%v", val)
+	for _, allowed := range p.AllowedValues {
+		if str == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("value must be one of %q, was %q", p.AllowedValues, str)
+}
+
+func (p *Preparer) validateRegex(val interface{}) error {
+	re, err := regexp.Compile(p.Regex)
+	if err != nil {
+		return errors.Wrapf(err, "invalid regex %q", p.Regex)
+	}
+
+	var candidates []string
+	if list, ok := val.([]interface{}); ok {
+		for _, item := range list {
+			candidates = append(candidates, fmt.Sprintf("%v", item))
+		}
+	} else {
+		candidates = append(candidates, fmt.Sprintf("%v", val))
+	}
+
+	for _, candidate := range candidates {
+		if !re.MatchString(candidate) {
+			return fmt.Errorf("value %q does not match %q", candidate, p.Regex)
+		}
+	}
+
+	return nil
+}
+
+func (p *Preparer) validateRange(val interface{}) error {
+	var (
+		size int
+		what string
+	)
+
+	switch v := val.(type) {
+	case int:
+		size, what = v, "value"
+	case string:
+		size, what = len(v), "length"
+	case []interface{}:
+		size, what = len(v), "length"
+	case map[string]interface{}:
+		size, what = len(v), "length"
+	default:
+		return fmt.Errorf("min and max cannot be used with %T values", val)
+	}
+
+	if p.Min != nil && size < *p.Min {
+		return fmt.Errorf("%s must be at least %d, was %d", what, *p.Min, size)
 	}
 
-	if p.Default == nil {
-		return nil, fmt.Errorf("%s param is required", paramName)
+	if p.Max != nil && size > *p.Max {
+		return fmt.Errorf("%s must be at most %d, was %d", what, *p.Max, size)
 	}
 
-	return &Param{Val: p.Default}, nil
+	return nil
 }
 
 func init() {
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/resource/param"
 	"github.com/stretchr/testify/assert"
@@ -77,3 +78,170 @@ func TestPreparerRequired(t *testing.T) {
 		assert.EqualError(t, err, fmt.Sprintf("%s param is required", name))
 	}
 }
+
+func TestPreparerCoercesType(t *testing.T) {
+	t.Parallel()
+
+	prep := &param.Preparer{Type: param.TypeInt}
+
+	result, err := prep.Prepare(context.Background(), fakerenderer.NewWithValue("8080"))
+	require.NoError(t, err)
+
+	resultParam, ok := result.(*param.Param)
+	require.True(t, ok, fmt.Sprintf("expected %T, got %T", resultParam, result))
+
+	assert.Equal(t, 8080, resultParam.Val)
+}
+
+func TestPreparerCoercesDefault(t *testing.T) {
+	t.Parallel()
+
+	prep := &param.Preparer{Type: param.TypeString, Default: 1}
+
+	result, err := prep.Prepare(context.Background(), fakerenderer.New())
+	require.NoError(t, err)
+
+	resultParam, ok := result.(*param.Param)
+	require.True(t, ok, fmt.Sprintf("expected %T, got %T", resultParam, result))
+
+	assert.Equal(t, "1", resultParam.Val)
+}
+
+func TestPreparerInvalidType(t *testing.T) {
+	t.Parallel()
+
+	id := "root/param.port"
+	prep := &param.Preparer{Type: param.TypeInt}
+
+	fr := fakerenderer.NewWithValue("http")
+	fr.ID = id
+
+	_, err := prep.Prepare(context.Background(), fr)
+	assert.EqualError(t, err, `port param: cannot use "http" (string) as int`)
+}
+
+func TestPreparerDescription(t *testing.T) {
+	t.Parallel()
+
+	prep := &param.Preparer{Default: "x", Description: "a test param"}
+
+	result, err := prep.Prepare(context.Background(), fakerenderer.New())
+	require.NoError(t, err)
+
+	resultParam, ok := result.(*param.Param)
+	require.True(t, ok, fmt.Sprintf("expected %T, got %T", resultParam, result))
+
+	assert.Equal(t, "a test param", resultParam.Description)
+}
+
+func TestPreparerSensitive(t *testing.T) {
+	t.Parallel()
+
+	t.Run("string", func(t *testing.T) {
+		prep := &param.Preparer{Sensitive: true}
+
+		ctx, release := redact.Scoped(context.Background())
+		defer release()
+
+		result, err := prep.Prepare(ctx, fakerenderer.NewWithValue("param-sensitive-value"))
+		require.NoError(t, err)
+
+		resultParam, ok := result.(*param.Param)
+		require.True(t, ok, fmt.Sprintf("expected %T, got %T", resultParam, result))
+
+		assert.True(t, resultParam.Sensitive)
+		assert.Equal(t, "param-sensitive-value", resultParam.Val, "the real value should be kept")
+		assert.Equal(t, redact.Mask, redact.FromContext(ctx).String("param-sensitive-value"))
+		assert.Equal(t, []string{"val"}, resultParam.SensitiveFields())
+	})
+
+	// short scalars are masked by name only, not everywhere they appear
+	t.Run("bool", func(t *testing.T) {
+		prep := &param.Preparer{Type: param.TypeBool, Sensitive: true}
+
+		ctx, release := redact.Scoped(context.Background())
+		defer release()
+
+		result, err := prep.Prepare(ctx, fakerenderer.NewWithValue("true"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "enabled=true", redact.FromContext(ctx).String("enabled=true"))
+		assert.Equal(t, []string{"val"}, result.(*param.Param).SensitiveFields())
+	})
+}
+
+func TestPreparerValidate(t *testing.T) {
+	t.Parallel()
+
+	intPtr := func(i int) *int { return &i }
+
+	t.Run("allowed values", func(t *testing.T) {
+		prep := &param.Preparer{AllowedValues: []string{"dev", "prod"}}
+
+		t.Run("valid", func(t *testing.T) {
+			val, err := prep.Validate("dev")
+			assert.NoError(t, err)
+			assert.Equal(t, "dev", val)
+		})
+
+		t.Run("invalid", func(t *testing.T) {
+			_, err := prep.Validate("staging")
+			assert.EqualError(t, err, `value must be one of ["dev" "prod"], was "staging"`)
+		})
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		prep := &param.Preparer{Regex: "^[a-z]+$"}
+
+		t.Run("valid", func(t *testing.T) {
+			_, err := prep.Validate("abc")
+			assert.NoError(t, err)
+		})
+
+		t.Run("invalid", func(t *testing.T) {
+			_, err := prep.Validate("ABC")
+			assert.EqualError(t, err, `value "ABC" does not match "^[a-z]+$"`)
+		})
+
+		t.Run("list", func(t *testing.T) {
+			prep := &param.Preparer{Type: param.TypeList, Regex: "^[a-z]+$"}
+
+			_, err := prep.Validate([]interface{}{"abc", "1"})
+			assert.EqualError(t, err, `value "1" does not match "^[a-z]+$"`)
+		})
+
+		t.Run("bad regex", func(t *testing.T) {
+			prep := &param.Preparer{Regex: "("}
+
+			_, err := prep.Validate("abc")
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("range", func(t *testing.T) {
+		prep := &param.Preparer{Type: param.TypeInt, Min: intPtr(1), Max: intPtr(65535)}
+
+		t.Run("valid", func(t *testing.T) {
+			val, err := prep.Validate("80")
+			assert.NoError(t, err)
+			assert.Equal(t, 80, val)
+		})
+
+		t.Run("too small", func(t *testing.T) {
+			_, err := prep.Validate(0)
+			assert.EqualError(t, err, "value must be at least 1, was 0")
+		})
+
+		t.Run("too large", func(t *testing.T) {
+			_, err := prep.Validate(70000)
+			assert.EqualError(t, err, "value must be at most 65535, was 70000")
+		})
+
+		t.Run("length", func(t *testing.T) {
+			prep := &param.Preparer{Type: param.TypeString, Max: intPtr(3)}
+
+			_, err := prep.Validate("abcd")
+			assert.EqualError(t, err, "length must be at most 3, was 4")
+		})
+	})
+}
@@ -0,0 +1,188 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package param
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Type is the type of value a param accepts
+type Type string
+
+const (
+	// TypeAny accepts any value without coercion. This is the default when no
+	// type is given.
+	TypeAny Type = ""
+
+	// TypeString accepts strings. Scalar values will be formatted as strings.
+	TypeString Type = "string"
+
+	// TypeInt accepts integers, or strings that can be parsed as integers
+	TypeInt Type = "int"
+
+	// TypeBool accepts booleans, or strings that can be parsed as booleans
+	TypeBool Type = "bool"
+
+	// TypeList accepts lists, or strings containing a JSON list
+	TypeList Type = "list"
+
+	// TypeMap accepts maps, or strings containing a JSON object
+	TypeMap Type = "map"
+)
+
+// Coerce converts the given value to this type. Values that cannot be
+// converted result in an error.
+func (t Type) Coerce(val interface{}) (interface{}, error) {
+	switch t {
+	case TypeAny:
+		return val, nil
+
+	case TypeString:
+		return coerceString(val)
+
+	case TypeInt:
+		return coerceInt(val)
+
+	case TypeBool:
+		return coerceBool(val)
+
+	case TypeList:
+		return coerceList(val)
+
+	case TypeMap:
+		return coerceMap(val)
+
+	default:
+		return nil, fmt.Errorf("unknown type %q", string(t))
+	}
+}
+
+func coerceString(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+
+	case bool, int, int64, float64:
+		return fmt.Sprintf("%v", v), nil
+
+	default:
+		return nil, typeError(TypeString, val)
+	}
+}
+
+func coerceInt(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case int:
+		return v, nil
+
+	case int64:
+		return int(v), nil
+
+	case float64:
+		// JSON numbers are always floats, so we have to make sure this one is
+		// actually integral before we accept it
+		if v != math.Trunc(v) {
+			return nil, typeError(TypeInt, val)
+		}
+		return int(v), nil
+
+	case string:
+		num, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, typeError(TypeInt, val)
+		}
+		return num, nil
+
+	default:
+		return nil, typeError(TypeInt, val)
+	}
+}
+
+func coerceBool(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, typeError(TypeBool, val)
+		}
+		return parsed, nil
+
+	default:
+		return nil, typeError(TypeBool, val)
+	}
+}
+
+func coerceList(val interface{}) (interface{}, error) {
+	if str, ok := val.(string); ok {
+		var out []interface{}
+		if err := json.Unmarshal([]byte(str), &out); err != nil {
+			return nil, typeError(TypeList, val)
+		}
+		return out, nil
+	}
+
+	values := reflect.ValueOf(val)
+	if values.Kind() != reflect.Slice {
+		return nil, typeError(TypeList, val)
+	}
+
+	out := make([]interface{}, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		out[i] = values.Index(i).Interface()
+	}
+
+	return out, nil
+}
+
+func coerceMap(val interface{}) (interface{}, error) {
+	if str, ok := val.(string); ok {
+		var out map[string]interface{}
+		if err := json.Unmarshal([]byte(str), &out); err != nil {
+			return nil, typeError(TypeMap, val)
+		}
+		return out, nil
+	}
+
+	values := reflect.ValueOf(val)
+
+	// HCL decodes maps into a list containing a single map, so we unwrap that
+	// here in the same way that resource.Preparer does.
+	if values.Kind() == reflect.Slice && values.Len() == 1 {
+		values = reflect.ValueOf(values.Index(0).Interface())
+	}
+
+	if values.Kind() != reflect.Map {
+		return nil, typeError(TypeMap, val)
+	}
+
+	out := map[string]interface{}{}
+	for _, key := range values.MapKeys() {
+		out[fmt.Sprintf("%v", key.Interface())] = values.MapIndex(key).Interface()
+	}
+
+	return out, nil
+}
+
+func typeError(t Type, val interface{}) error {
+	return fmt.Errorf("cannot use %#v (%T) as %s", val, val, t)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package param_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/param"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeCoerce(t *testing.T) {
+	t.Parallel()
+
+	valid := []struct {
+		typ      param.Type
+		in       interface{}
+		expected interface{}
+	}{
+		{param.TypeAny, "x", "x"},
+		{param.TypeAny, 1, 1},
+		{param.TypeString, "x", "x"},
+		{param.TypeString, 1, "1"},
+		{param.TypeString, true, "true"},
+		{param.TypeInt, 1, 1},
+		{param.TypeInt, 1.0, 1},
+		{param.TypeInt, " 1 ", 1},
+		{param.TypeBool, true, true},
+		{param.TypeBool, "false", false},
+		{param.TypeList, []interface{}{"a"}, []interface{}{"a"}},
+		{param.TypeList, []string{"a", "b"}, []interface{}{"a", "b"}},
+		{param.TypeList, `["a", 1]`, []interface{}{"a", 1.0}},
+		{param.TypeMap, map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}},
+		{param.TypeMap, []map[string]interface{}{{"a": 1}}, map[string]interface{}{"a": 1}},
+		{param.TypeMap, `{"a": "b"}`, map[string]interface{}{"a": "b"}},
+	}
+
+	for _, test := range valid {
+		out, err := test.typ.Coerce(test.in)
+		if assert.NoError(t, err, "%s: %#v", test.typ, test.in) {
+			assert.Equal(t, test.expected, out, "%s: %#v", test.typ, test.in)
+		}
+	}
+
+	invalid := []struct {
+		typ param.Type
+		in  interface{}
+	}{
+		{param.TypeString, []interface{}{"a"}},
+		{param.TypeInt, 1.5},
+		{param.TypeInt, "x"},
+		{param.TypeBool, "yes please"},
+		{param.TypeBool, 1},
+		{param.TypeList, "a,b"},
+		{param.TypeList, 1},
+		{param.TypeMap, "a=b"},
+		{param.TypeMap, []interface{}{"a", "b"}},
+		{param.Type("float"), 1.0},
+	}
+
+	for _, test := range invalid {
+		_, err := test.typ.Coerce(test.in)
+		assert.Error(t, err, "%s: %#v", test.typ, test.in)
+	}
+}
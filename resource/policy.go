@@ -0,0 +1,236 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// MetaPolicy is the graph node metadata key for the retry and timeout policy
+// of a node, set from the "retry" and "timeout" fields that every resource
+// accepts
+const MetaPolicy = "execution-policy"
+
+// ErrTimeout is returned when a Check or Apply takes longer than the timeout
+// set in its Policy
+var ErrTimeout = errors.New("timed out")
+
+// Policy controls how many times a task's Check and Apply are attempted, and
+// how long each attempt may take
+type Policy struct {
+	// Attempts is the total number of times to try before failing. Values less
+	// than one are treated as one.
+	Attempts int
+
+	// Interval is how long to wait after the first failed attempt
+	Interval time.Duration
+
+	// Backoff multiplies the interval after each failed attempt. Values less
+	// than one are treated as one, so the interval stays the same.
+	Backoff float64
+
+	// Timeout is how long a single attempt may take. Zero means no timeout.
+	Timeout time.Duration
+}
+
+// NewPolicy creates a Policy from the raw values of the "retry" and "timeout"
+// fields. If neither is set, the returned policy is nil.
+func NewPolicy(retry, timeout interface{}) (*Policy, error) {
+	if retry == nil && timeout == nil {
+		return nil, nil
+	}
+
+	policy := &Policy{Attempts: 1, Backoff: 1}
+
+	if timeout != nil {
+		dur, err := policyDuration(timeout)
+		if err != nil {
+			return nil, errors.Wrap(err, "timeout")
+		}
+		policy.Timeout = dur
+	}
+
+	if retry == nil {
+		return policy, nil
+	}
+
+	// HCL decodes blocks into a list containing a single map
+	if list, ok := retry.([]map[string]interface{}); ok && len(list) == 1 {
+		retry = list[0]
+	}
+	values, ok := retry.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("retry must be a block, got %T", retry)
+	}
+
+	for key, val := range values {
+		var err error
+		switch key {
+		case "attempts":
+			policy.Attempts, err = policyInt(val)
+		case "interval":
+			policy.Interval, err = policyDuration(val)
+		case "backoff":
+			policy.Backoff, err = policyFloat(val)
+		default:
+			err = errors.New("unknown field, expected attempts, interval, or backoff")
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "retry.%s", key)
+		}
+	}
+
+	return policy, nil
+}
+
+// Run calls fn until it succeeds or the attempts run out, waiting between
+// attempts and timing out each attempt according to the policy. An attempt
+// fails if it returns an error or a status with an error. A nil policy calls fn
+// once, with no timeout.
+//
+// A task that times out is waited for before the next attempt starts, so a
+// task that ignores the cancellation of its context can run past its timeout.
+func (p *Policy) Run(ctx context.Context, fn func(context.Context) (TaskStatus, error)) (TaskStatus, error) {
+	if p == nil {
+		return fn(ctx)
+	}
+
+	attempts := p.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := p.Backoff
+	if backoff < 1 {
+		backoff = 1
+	}
+
+	var (
+		status   TaskStatus
+		err      error
+		interval = p.Interval
+	)
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		status, err = p.runOnce(ctx, fn)
+		if err == nil && (status == nil || status.Error() == nil) {
+			return status, nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, err
+		case <-time.After(interval):
+		}
+		interval = time.Duration(float64(interval) * backoff)
+	}
+
+	if attempts > 1 && err != nil {
+		err = errors.Wrapf(err, "failed after %d attempts", attempts)
+	}
+
+	return status, err
+}
+
+// runOnce runs a single attempt. When the timeout passes, the context of the
+// attempt is cancelled and the attempt fails once fn returns. fn is never left
+// running in the background, so a retry can't overlap it and the caller's
+// parallelism slot stays taken until it's done.
+func (p *Policy) runOnce(ctx context.Context, fn func(context.Context) (TaskStatus, error)) (TaskStatus, error) {
+	if p.Timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	status, err := fn(ctx)
+	if ctx.Err() == context.DeadlineExceeded {
+		return status, errors.Wrapf(ErrTimeout, "after %s", p.Timeout)
+	}
+	return status, err
+}
+
+// metadataLookup is anything with graph node metadata, like *node.Node
+type metadataLookup interface {
+	LookupMetadata(string) (interface{}, bool)
+}
+
+// PolicyFor gets the policy stored in the metadata of a graph node, if any
+func PolicyFor(meta metadataLookup) *Policy {
+	raw, ok := meta.LookupMetadata(MetaPolicy)
+	if !ok {
+		return nil
+	}
+
+	policy, _ := raw.(*Policy)
+	return policy
+}
+
+func policyInt(val interface{}) (int, error) {
+	switch v := val.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("expected an integer, got %v", val)
+}
+
+func policyFloat(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("expected a number, got %v", val)
+}
+
+// policyDuration converts a duration in the same way as the Preparer: integers
+// are seconds and strings are parsed with time.ParseDuration
+func policyDuration(val interface{}) (time.Duration, error) {
+	switch v := val.(type) {
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case string:
+		if strings.Contains(v, "{{") {
+			return 0, fmt.Errorf("%q cannot contain template calls", v)
+		}
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, errors.Wrapf(err, "could not convert %s to duration", v)
+		}
+		return dur, nil
+	}
+	return 0, fmt.Errorf("expected a duration, got %v", val)
+}
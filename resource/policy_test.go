@@ -0,0 +1,170 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestNewPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unset", func(t *testing.T) {
+		policy, err := resource.NewPolicy(nil, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, policy)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		policy, err := resource.NewPolicy(nil, "1m")
+		require.NoError(t, err)
+		assert.Equal(t, &resource.Policy{Attempts: 1, Backoff: 1, Timeout: time.Minute}, policy)
+
+		policy, err = resource.NewPolicy(nil, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Second, policy.Timeout)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		policy, err := resource.NewPolicy(
+			[]map[string]interface{}{{"attempts": 5, "interval": "2s", "backoff": 1.5}},
+			nil,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, &resource.Policy{Attempts: 5, Interval: 2 * time.Second, Backoff: 1.5}, policy)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			retry, timeout interface{}
+			err            string
+		}{
+			{nil, "soon", `timeout: could not convert soon to duration: time: invalid duration "soon"`},
+			{nil, "{{param `x`}}", "timeout: \"{{param `x`}}\" cannot contain template calls"},
+			{"3", nil, "retry must be a block, got string"},
+			{map[string]interface{}{"attempts": "x"}, nil, "retry.attempts: expected an integer, got x"},
+			{map[string]interface{}{"backoff": "x"}, nil, "retry.backoff: expected a number, got x"},
+			{map[string]interface{}{"tries": 1}, nil, "retry.tries: unknown field, expected attempts, interval, or backoff"},
+		} {
+			_, err := resource.NewPolicy(tc.retry, tc.timeout)
+			assert.EqualError(t, err, tc.err)
+		}
+	})
+}
+
+type countingTask struct {
+	failures int
+	calls    int
+	delay    time.Duration
+}
+
+func (c *countingTask) run(ctx context.Context) (resource.TaskStatus, error) {
+	c.calls++
+	time.Sleep(c.delay)
+	if c.calls <= c.failures {
+		return nil, errors.New("flaky")
+	}
+	return &resource.Status{}, nil
+}
+
+func TestPolicyRun(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("nil", func(t *testing.T) {
+		task := &countingTask{failures: 1}
+		_, err := (*resource.Policy)(nil).Run(ctx, task.run)
+		assert.EqualError(t, err, "flaky")
+		assert.Equal(t, 1, task.calls)
+	})
+
+	t.Run("recovers", func(t *testing.T) {
+		task := &countingTask{failures: 2}
+		policy := &resource.Policy{Attempts: 3, Interval: time.Millisecond, Backoff: 2}
+
+		status, err := policy.Run(ctx, task.run)
+		assert.NoError(t, err)
+		assert.NotNil(t, status)
+		assert.Equal(t, 3, task.calls)
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		task := &countingTask{failures: 5}
+		policy := &resource.Policy{Attempts: 2, Interval: time.Millisecond}
+
+		_, err := policy.Run(ctx, task.run)
+		assert.EqualError(t, err, "failed after 2 attempts: flaky")
+		assert.Equal(t, 2, task.calls)
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		calls := 0
+		policy := &resource.Policy{Attempts: 2}
+
+		status, err := policy.Run(ctx, func(context.Context) (resource.TaskStatus, error) {
+			calls++
+			return &resource.Status{Level: resource.StatusFatal}, nil
+		})
+		assert.NoError(t, err)
+		assert.Error(t, status.Error())
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		task := &countingTask{delay: 50 * time.Millisecond}
+		policy := &resource.Policy{Attempts: 1, Timeout: time.Millisecond}
+
+		_, err := policy.Run(ctx, task.run)
+		assert.EqualError(t, err, "after 1ms: timed out")
+	})
+
+	t.Run("timeout waits for the attempt", func(t *testing.T) {
+		var running, overlapped int32
+		policy := &resource.Policy{Attempts: 3, Timeout: time.Millisecond}
+
+		_, err := policy.Run(ctx, func(context.Context) (resource.TaskStatus, error) {
+			if atomic.AddInt32(&running, 1) > 1 {
+				atomic.StoreInt32(&overlapped, 1)
+			}
+			defer atomic.AddInt32(&running, -1)
+			time.Sleep(20 * time.Millisecond)
+			return &resource.Status{}, nil
+		})
+		assert.EqualError(t, err, "failed after 3 attempts: after 1ms: timed out")
+		assert.Equal(t, int32(0), atomic.LoadInt32(&running))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&overlapped))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		task := &countingTask{failures: 5}
+		policy := &resource.Policy{Attempts: 5, Interval: time.Hour}
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := policy.Run(cctx, task.run)
+		assert.EqualError(t, err, "flaky")
+		assert.Equal(t, 1, task.calls)
+	})
+}
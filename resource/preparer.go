@@ -23,6 +23,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/arbovm/levenshtein"
+	"github.com/asteris-llc/converge/helpers/redact"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -93,6 +94,10 @@ func (p *Preparer) Prepare(ctx context.Context, r Renderer) (Task, error) {
 			return nil, err
 		}
 
+		if field.Tag.Get("sensitive") == "true" && val.IsValid() {
+			redact.FromContext(ctx).AddValue(val.Interface())
+		}
+
 		fieldValue := value.Field(i)
 		if fieldValue.CanSet() {
 			fieldValue.Set(val)
@@ -125,6 +130,12 @@ func (p *Preparer) validateExtra(typ reflect.Type) error {
 	// add special fields
 	fieldNames["depends"] = struct{}{}
 	fieldNames["group"] = struct{}{}
+	fieldNames["retry"] = struct{}{}
+	fieldNames["timeout"] = struct{}{}
+	fieldNames["on_failure"] = struct{}{}
+	fieldNames["notifies"] = struct{}{}
+	fieldNames["subscribes"] = struct{}{}
+	fieldNames["for_each"] = struct{}{}
 
 	var err error
 	for key := range p.Source {
@@ -154,6 +165,24 @@ func (p *Preparer) validateExtra(typ reflect.Type) error {
 	return err
 }
 
+// HasField checks if the wrapped resource has a field with the given name
+func (p *Preparer) HasField(name string) bool {
+	typ := reflect.TypeOf(p.Destination)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		if p.getFieldName(typ.Field(i)) == name {
+			return true
+		}
+	}
+	return false
+}
+
 // getValueForField retrieves and converts the value for a given field
 func (p *Preparer) getValueForField(r Renderer, field reflect.StructField) (reflect.Value, error) {
 	// get the field name for use in future lookups
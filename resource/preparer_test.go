@@ -22,6 +22,7 @@ import (
 
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
 	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -411,6 +412,22 @@ func TestPreparerPrepare(t *testing.T) {
 		})
 	})
 
+	// sensitive values are registered so they can be masked in output
+	t.Run("sensitive", func(t *testing.T) {
+		prep := &resource.Preparer{
+			Source:      map[string]interface{}{"secret": "preparer-sensitive-value"},
+			Destination: new(testSensitiveTarget),
+		}
+
+		redactor := redact.New()
+		ctx := redact.WithRedactor(context.Background(), redactor)
+
+		_, err := prep.Prepare(ctx, fakerenderer.New())
+		require.NoError(t, err)
+
+		assert.Equal(t, redact.Mask, redactor.String("preparer-sensitive-value"))
+	})
+
 	// two parameters can also be mutually exclusive
 	t.Run("mutually_exclusive", func(t *testing.T) {
 		t.Run("invalid", func(t *testing.T) {
@@ -527,3 +544,16 @@ func (tpt *testMutuallyExclusiveTarget) Check(context.Context, resource.Renderer
 func (tpt *testMutuallyExclusiveTarget) Apply(context.Context) (resource.TaskStatus, error) {
 	return nil, nil
 }
+
+// testSensitiveTarget tests sensitive fields
+type testSensitiveTarget struct {
+	Secret string `hcl:"secret" sensitive:"true"`
+}
+
+func (tpt *testSensitiveTarget) Prepare(context.Context, resource.Renderer) (resource.Task, error) {
+	return tpt, nil
+}
+func (tpt *testSensitiveTarget) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	return nil, nil
+}
+func (tpt *testSensitiveTarget) Apply(context.Context) (resource.TaskStatus, error) { return nil, nil }
@@ -0,0 +1,150 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sudo
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"golang.org/x/net/context"
+)
+
+// DefaultDir is where rules are written
+const DefaultDir = "/etc/sudoers.d"
+
+// sudo ignores files in /etc/sudoers.d whose names contain a dot or end in a
+// tilde, so rule names are limited to characters it reads
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// commandEscaper escapes characters that have a special meaning in a sudoers
+// command list
+var commandEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ":", `\:`, "=", `\=`)
+
+// Preparer for Sudo Rule
+//
+// Sudo Rule writes a drop-in file to /etc/sudoers.d. The file is checked with
+// `visudo -cf` before it is moved into place, so a rule with a syntax error
+// never reaches sudo and can't lock out administrators. Once it is in place the
+// whole configuration is checked with `visudo -c`, and the previous rule is
+// restored if that fails. The file is kept at mode 0440 and owned by root.
+type Preparer struct {
+	// Name of the rule. The rule is written to /etc/sudoers.d/<name>. The name
+	// may only contain letters, digits, dashes and underscores, since sudo
+	// ignores other files in /etc/sudoers.d.
+	Name string `hcl:"name" required:"true" nonempty:"true"`
+
+	// Users the rule applies to. Groups are given with a leading %, like
+	// "%admin". Required unless state is absent.
+	Users []string `hcl:"users"`
+
+	// Hosts the rule applies to. The default value is ALL.
+	Hosts []string `hcl:"hosts"`
+
+	// RunAs is the list of users the commands may be run as. If not set, sudo
+	// only allows running commands as root.
+	RunAs []string `hcl:"run_as"`
+
+	// Commands that may be run, with their full path and optionally their
+	// arguments, like "/bin/systemctl restart nginx". Backslashes, commas, colons
+	// and equals signs in commands are escaped. The default value is ALL.
+	Commands []string `hcl:"commands"`
+
+	// NoPasswd allows running the commands without entering a password.
+	NoPasswd bool `hcl:"nopasswd"`
+
+	// State of the rule. Present means the rule will be written; Absent means
+	// it will be removed.
+	// The default value is present.
+	State State `hcl:"state" valid_values:"present,absent"`
+}
+
+// Prepare a new task
+func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if !validName.MatchString(p.Name) {
+		return nil, fmt.Errorf("sudo rule \"name\" may only contain letters, digits, dashes and underscores")
+	}
+
+	if p.State == "" {
+		p.State = StatePresent
+	}
+
+	rule := NewRule(Visudo{})
+	rule.Destination = filepath.Join(DefaultDir, p.Name)
+	rule.State = p.State
+
+	if p.State == StateAbsent {
+		return rule, nil
+	}
+
+	if len(p.Users) == 0 {
+		return nil, fmt.Errorf("sudo rule \"users\" parameter is required")
+	}
+
+	for _, list := range [][]string{p.Users, p.Hosts, p.RunAs} {
+		for _, item := range list {
+			if item == "" || strings.ContainsAny(item, ", \t\n") {
+				return nil, fmt.Errorf("sudo rule has invalid user or host %q", item)
+			}
+		}
+	}
+
+	for _, cmd := range p.Commands {
+		cmd = strings.TrimSpace(cmd)
+		if cmd != "ALL" && !strings.HasPrefix(cmd, "/") && !strings.HasPrefix(cmd, "sudoedit ") {
+			return nil, fmt.Errorf("sudo rule command %q must be ALL or start with a full path", cmd)
+		}
+		if strings.Contains(cmd, "\n") {
+			return nil, fmt.Errorf("sudo rule command %q cannot contain newlines", cmd)
+		}
+	}
+
+	rule.Content = p.render()
+	return rule, nil
+}
+
+// render renders the rule as a sudoers user specification
+func (p *Preparer) render() string {
+	hosts := p.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{"ALL"}
+	}
+
+	commands := []string{"ALL"}
+	if len(p.Commands) > 0 {
+		commands = nil
+		for _, cmd := range p.Commands {
+			commands = append(commands, commandEscaper.Replace(strings.TrimSpace(cmd)))
+		}
+	}
+
+	line := strings.Join(p.Users, ", ") + " " + strings.Join(hosts, ", ") + " ="
+	if len(p.RunAs) > 0 {
+		line += " (" + strings.Join(p.RunAs, ", ") + ")"
+	}
+	if p.NoPasswd {
+		line += " NOPASSWD:"
+	}
+	line += " " + strings.Join(commands, ", ")
+
+	return fmt.Sprintf("# %s is managed by converge\n%s\n", p.Name, line)
+}
+
+func init() {
+	registry.Register("sudo.rule", (*Preparer)(nil), (*Rule)(nil))
+}
@@ -0,0 +1,101 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sudo_test
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/sudo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+// TestPreparerInterface tests that the Preparer interface is properly
+// implemented
+func TestPreparerInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Resource)(nil), new(sudo.Preparer))
+}
+
+// TestPrepare tests the rendering and validation of rules
+func TestPrepare(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		p := sudo.Preparer{Name: "deploy", Users: []string{"deploy"}}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		rule := task.(*sudo.Rule)
+		assert.Equal(t, "/etc/sudoers.d/deploy", rule.Destination)
+		assert.Equal(t, sudo.StatePresent, rule.State)
+		assert.Equal(t, "# deploy is managed by converge\ndeploy ALL = ALL\n", rule.Content)
+	})
+
+	t.Run("all fields", func(t *testing.T) {
+		p := sudo.Preparer{
+			Name:     "web",
+			Users:    []string{"deploy", "%ops"},
+			Hosts:    []string{"web1", "web2"},
+			RunAs:    []string{"www-data"},
+			Commands: []string{"/bin/systemctl restart nginx", "/usr/bin/env FOO=a,b"},
+			NoPasswd: true,
+		}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"# web is managed by converge\ndeploy, %ops web1, web2 = (www-data) NOPASSWD: /bin/systemctl restart nginx, /usr/bin/env FOO\\=a\\,b\n",
+			task.(*sudo.Rule).Content,
+		)
+	})
+
+	t.Run("backslash", func(t *testing.T) {
+		p := sudo.Preparer{Name: "deploy", Users: []string{"deploy"}, Commands: []string{`/bin/grep a\,b`}}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		assert.Equal(t, "# deploy is managed by converge\ndeploy ALL = /bin/grep a\\\\\\,b\n", task.(*sudo.Rule).Content)
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		p := sudo.Preparer{Name: "deploy", State: sudo.StateAbsent}
+		task, err := p.Prepare(context.Background(), fakerenderer.New())
+
+		require.NoError(t, err)
+		assert.Equal(t, sudo.StateAbsent, task.(*sudo.Rule).State)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, p := range map[string]sudo.Preparer{
+			"name with dot":    {Name: "deploy.conf", Users: []string{"deploy"}},
+			"name with tilde":  {Name: "deploy~", Users: []string{"deploy"}},
+			"no users":         {Name: "deploy"},
+			"user with comma":  {Name: "deploy", Users: []string{"a,b"}},
+			"relative command": {Name: "deploy", Users: []string{"deploy"}, Commands: []string{"systemctl"}},
+		} {
+			p := p
+			t.Run(name, func(t *testing.T) {
+				_, err := p.Prepare(context.Background(), fakerenderer.New())
+				assert.Error(t, err)
+			})
+		}
+	})
+}
@@ -0,0 +1,338 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sudo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// State type for Rule
+type State string
+
+const (
+	// StatePresent indicates the rule should be present
+	StatePresent State = "present"
+
+	// StateAbsent indicates the rule should be absent
+	StateAbsent State = "absent"
+
+	// Mode is the mode sudo requires for files in /etc/sudoers.d
+	Mode os.FileMode = 0440
+)
+
+// Validator checks the syntax of sudoers files
+type Validator interface {
+	// Validate checks a single file on its own
+	Validate(path string) error
+
+	// ValidateConfig checks the whole sudoers configuration, including every
+	// drop-in file
+	ValidateConfig() error
+}
+
+// Visudo validates sudoers files with visudo
+type Visudo struct{}
+
+// Validate runs `visudo -cf` on a file
+func (Visudo) Validate(path string) error {
+	return visudo("-cf", path)
+}
+
+// ValidateConfig runs `visudo -c` on the installed configuration
+func (Visudo) ValidateConfig() error {
+	return visudo("-c")
+}
+
+func visudo(args ...string) error {
+	out, err := exec.Command("visudo", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("visudo: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Rule manages a drop-in file in /etc/sudoers.d
+type Rule struct {
+	// the location of the file
+	Destination string `export:"destination"`
+
+	// the rendered content of the file
+	Content string `export:"content"`
+
+	// whether the rule should be present or absent
+	State State `export:"state"`
+
+	validator Validator
+}
+
+// NewRule constructs and returns a new Rule
+func NewRule(validator Validator) *Rule {
+	return &Rule{
+		validator: validator,
+	}
+}
+
+// Check if the rule has to be written or removed. New content is validated
+// before it is considered for writing.
+func (r *Rule) Check(context.Context, resource.Renderer) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	actual, info, err := r.read()
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if r.State == StateAbsent {
+		if actual != nil {
+			status.AddDifference(r.Destination, "<present>", "<absent>", "")
+		}
+		status.RaiseLevelForDiffs()
+		return status, nil
+	}
+
+	if actual == nil || *actual != r.Content {
+		if err := r.validateContent(); err != nil {
+			status.RaiseLevel(resource.StatusCantChange)
+			status.AddMessage(err.Error())
+			return status, errors.Wrapf(err, "invalid sudoers rule for %s", r.Destination)
+		}
+		status.Differences[r.Destination] = r.diff(actual)
+	} else {
+		info.addDifferences(status)
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// Apply writes or removes the rule. The content is written to a temporary
+// file next to the destination, validated, and then moved into place, so an
+// invalid or partially written rule is never read by sudo. If the whole
+// configuration no longer validates with the new rule in place, the previous
+// rule is restored.
+func (r *Rule) Apply(context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	actual, info, err := r.read()
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	if r.State == StateAbsent {
+		if actual == nil {
+			return status, nil
+		}
+		if err := os.Remove(r.Destination); err != nil && !os.IsNotExist(err) {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, errors.Wrapf(err, "cannot remove %q", r.Destination)
+		}
+		status.AddDifference(r.Destination, "<present>", "<absent>", "")
+		status.AddMessage("removed " + r.Destination)
+		return status, nil
+	}
+
+	if actual != nil && *actual == r.Content {
+		if info.mode != Mode {
+			if err := os.Chmod(r.Destination, Mode); err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, errors.Wrapf(err, "cannot change mode of %q", r.Destination)
+			}
+		}
+		if info.wrongOwner() {
+			if err := os.Chown(r.Destination, 0, 0); err != nil {
+				status.RaiseLevel(resource.StatusFatal)
+				return status, errors.Wrapf(err, "cannot change owner of %q", r.Destination)
+			}
+		}
+		info.addDifferences(status)
+		return status, nil
+	}
+
+	if err := r.install(actual); err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		status.AddMessage(err.Error())
+		return status, err
+	}
+
+	status.Differences[r.Destination] = r.diff(actual)
+	status.AddMessage("wrote " + r.Destination)
+	return status, nil
+}
+
+// fileInfo is the mode and owner of an installed rule
+type fileInfo struct {
+	mode     os.FileMode
+	uid, gid uint32
+}
+
+// wrongOwner reports whether the file is not owned by root:root. The owner is
+// only enforced when running as root, since files can't be given away
+// otherwise.
+func (i fileInfo) wrongOwner() bool {
+	return os.Geteuid() == 0 && (i.uid != 0 || i.gid != 0)
+}
+
+// addDifferences adds the mode and owner differences to status
+func (i fileInfo) addDifferences(status *resource.Status) {
+	if i.mode != Mode {
+		status.AddDifference("mode", fmt.Sprintf("%04o", i.mode), fmt.Sprintf("%04o", Mode), "")
+	}
+	if i.wrongOwner() {
+		status.AddDifference("owner", fmt.Sprintf("%d:%d", i.uid, i.gid), "0:0", "")
+	}
+}
+
+// read returns the current content, mode and owner of the destination, or nil
+// if it does not exist
+func (r *Rule) read() (*string, fileInfo, error) {
+	var info fileInfo
+
+	stat, err := os.Stat(r.Destination)
+	if os.IsNotExist(err) {
+		return nil, info, nil
+	} else if err != nil {
+		return nil, info, errors.Wrapf(err, "cannot read %q", r.Destination)
+	} else if stat.IsDir() {
+		return nil, info, fmt.Errorf("cannot manage %q, it is a directory", r.Destination)
+	}
+
+	info.mode = stat.Mode().Perm()
+	if sys, ok := stat.Sys().(*syscall.Stat_t); ok {
+		info.uid, info.gid = sys.Uid, sys.Gid
+	}
+
+	data, err := ioutil.ReadFile(r.Destination)
+	if err != nil {
+		return nil, info, errors.Wrapf(err, "cannot read %q", r.Destination)
+	}
+	content := string(data)
+	return &content, info, nil
+}
+
+// diff returns the difference between the current and desired content
+func (r *Rule) diff(actual *string) resource.Diff {
+	if actual == nil {
+		diff := resource.NewUnifiedDiff("", r.Content)
+		diff.Placeholder = "<file-missing>"
+		return diff
+	}
+	return resource.NewUnifiedDiff(*actual, r.Content)
+}
+
+// validateContent validates the content in a temporary file outside of
+// /etc/sudoers.d
+func (r *Rule) validateContent() error {
+	tmp, err := writeTemp("", r.Content)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	return r.validator.Validate(tmp)
+}
+
+// install writes the content to a temporary file in the destination
+// directory, validates it, and renames it to the destination. The whole
+// configuration is then validated, and the previous content is restored if it
+// fails, since a rule can be valid on its own but conflict with other files
+// (for example by redefining an alias).
+func (r *Rule) install(previous *string) error {
+	if err := r.replace(r.Content); err != nil {
+		return err
+	}
+
+	err := r.validator.ValidateConfig()
+	if err == nil {
+		return nil
+	}
+
+	if previous == nil {
+		if rerr := os.Remove(r.Destination); rerr != nil {
+			return errors.Wrapf(rerr, "sudoers configuration is invalid (%s) and %q could not be removed", err, r.Destination)
+		}
+	} else if rerr := r.replace(*previous); rerr != nil {
+		return errors.Wrapf(rerr, "sudoers configuration is invalid (%s) and %q could not be restored", err, r.Destination)
+	}
+	return errors.Wrapf(err, "invalid sudoers configuration with rule %s", r.Destination)
+}
+
+// replace validates content in a temporary file next to the destination and
+// renames it into place
+func (r *Rule) replace(content string) error {
+	tmp, err := writeTemp(filepath.Dir(r.Destination), content)
+	if err != nil {
+		return err
+	}
+
+	if err := r.validator.Validate(tmp); err != nil {
+		os.Remove(tmp)
+		return errors.Wrapf(err, "invalid sudoers rule for %s", r.Destination)
+	}
+
+	if err := os.Rename(tmp, r.Destination); err != nil {
+		os.Remove(tmp)
+		return errors.Wrapf(err, "cannot move rule to %q", r.Destination)
+	}
+	return nil
+}
+
+// writeTemp writes content to a new file with the mode sudo requires. sudo
+// ignores files in /etc/sudoers.d whose names contain a dot, so the file is
+// never read even if it is left behind.
+func writeTemp(dir, content string) (string, error) {
+	f, err := ioutil.TempFile(dir, ".converge-sudo.")
+	if err != nil {
+		return "", errors.Wrap(err, "cannot create temporary file")
+	}
+	name := f.Name()
+
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		os.Remove(name)
+		return "", errors.Wrapf(err, "cannot write %q", name)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return "", errors.Wrapf(err, "cannot write %q", name)
+	}
+
+	if err := os.Chmod(name, Mode); err != nil {
+		os.Remove(name)
+		return "", errors.Wrapf(err, "cannot change mode of %q", name)
+	}
+
+	// sudo also requires the file to be owned by root. When not running as
+	// root, files can't be given away and sudo couldn't be configured anyway.
+	if os.Geteuid() == 0 {
+		if err := os.Chown(name, 0, 0); err != nil {
+			os.Remove(name)
+			return "", errors.Wrapf(err, "cannot change owner of %q", name)
+		}
+	}
+
+	return name, nil
+}
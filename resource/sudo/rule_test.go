@@ -0,0 +1,237 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sudo_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/sudo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+const validRule = "# deploy is managed by converge\ndeploy ALL = NOPASSWD: ALL\n"
+
+// TestRuleInterface tests that Rule is properly implemented
+func TestRuleInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Task)(nil), new(sudo.Rule))
+}
+
+// TestRulePresent tests writing a rule
+func TestRulePresent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing", func(t *testing.T) {
+		dir, rule, validator := newRule(t, validRule)
+		defer os.RemoveAll(dir)
+
+		status, err := rule.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		assert.Equal(t, "<file-missing>", status.Diffs()[rule.Destination].Original())
+		assert.Len(t, validator.validated, 1)
+
+		status, err = rule.Apply(context.Background())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+
+		content, err := ioutil.ReadFile(rule.Destination)
+		require.NoError(t, err)
+		assert.Equal(t, validRule, string(content))
+
+		stat, err := os.Stat(rule.Destination)
+		require.NoError(t, err)
+		assert.Equal(t, sudo.Mode, stat.Mode().Perm())
+
+		// the file was validated next to the destination before being moved
+		assert.Equal(t, dir, filepath.Dir(validator.validated[1]))
+		assertOnlyRule(t, dir)
+
+		status, err = rule.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("wrong mode", func(t *testing.T) {
+		dir, rule, _ := newRule(t, validRule)
+		defer os.RemoveAll(dir)
+		require.NoError(t, ioutil.WriteFile(rule.Destination, []byte(validRule), 0644))
+
+		status, err := rule.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "0644", status.Diffs()["mode"].Original())
+		assert.Equal(t, "0440", status.Diffs()["mode"].Current())
+
+		_, err = rule.Apply(context.Background())
+		require.NoError(t, err)
+
+		stat, err := os.Stat(rule.Destination)
+		require.NoError(t, err)
+		assert.Equal(t, sudo.Mode, stat.Mode().Perm())
+	})
+
+	t.Run("wrong owner", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("changing the owner requires root")
+		}
+
+		dir, rule, _ := newRule(t, validRule)
+		defer os.RemoveAll(dir)
+		require.NoError(t, ioutil.WriteFile(rule.Destination, []byte(validRule), 0440))
+		require.NoError(t, os.Chown(rule.Destination, 1, 1))
+
+		status, err := rule.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "1:1", status.Diffs()["owner"].Original())
+		assert.Equal(t, "0:0", status.Diffs()["owner"].Current())
+
+		_, err = rule.Apply(context.Background())
+		require.NoError(t, err)
+
+		status, err = rule.Check(context.Background(), nil)
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		dir, rule, validator := newRule(t, "Cmnd_Alias SHUTDOWN = /sbin/reboot\n")
+		defer os.RemoveAll(dir)
+		validator.configErr = errors.New("Duplicate Cmnd_Alias \"SHUTDOWN\"")
+		require.NoError(t, ioutil.WriteFile(rule.Destination, []byte(validRule), 0440))
+
+		status, err := rule.Apply(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusFatal, status.StatusCode())
+		assert.Equal(t, 1, validator.configChecks)
+
+		// the previous rule is restored
+		content, err := ioutil.ReadFile(rule.Destination)
+		require.NoError(t, err)
+		assert.Equal(t, validRule, string(content))
+		assertOnlyRule(t, dir)
+	})
+
+	t.Run("invalid configuration without previous rule", func(t *testing.T) {
+		dir, rule, validator := newRule(t, validRule)
+		defer os.RemoveAll(dir)
+		validator.configErr = errors.New("parse error")
+
+		_, err := rule.Apply(context.Background())
+		assert.Error(t, err)
+
+		_, err = os.Stat(rule.Destination)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		dir, rule, validator := newRule(t, "deploy ALL = NOPASSWD ALL\n")
+		defer os.RemoveAll(dir)
+		validator.err = errors.New("syntax error near line 1")
+		require.NoError(t, ioutil.WriteFile(rule.Destination, []byte(validRule), 0440))
+
+		status, err := rule.Check(context.Background(), nil)
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+
+		status, err = rule.Apply(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusFatal, status.StatusCode())
+
+		// the existing rule is left alone and the temporary file is removed
+		content, err := ioutil.ReadFile(rule.Destination)
+		require.NoError(t, err)
+		assert.Equal(t, validRule, string(content))
+		assertOnlyRule(t, dir)
+	})
+}
+
+// TestRuleAbsent tests removing a rule
+func TestRuleAbsent(t *testing.T) {
+	t.Parallel()
+
+	dir, rule, validator := newRule(t, "")
+	defer os.RemoveAll(dir)
+	rule.State = sudo.StateAbsent
+	require.NoError(t, ioutil.WriteFile(rule.Destination, []byte(validRule), 0440))
+
+	status, err := rule.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "<absent>", status.Diffs()[rule.Destination].Current())
+
+	_, err = rule.Apply(context.Background())
+	require.NoError(t, err)
+	_, err = os.Stat(rule.Destination)
+	assert.True(t, os.IsNotExist(err))
+
+	status, err = rule.Check(context.Background(), nil)
+	require.NoError(t, err)
+	assert.False(t, status.HasChanges())
+	assert.Empty(t, validator.validated)
+}
+
+// newRule creates a rule in a temporary directory
+func newRule(t *testing.T, content string) (string, *sudo.Rule, *fakeValidator) {
+	dir, err := ioutil.TempDir("", "converge-sudo")
+	require.NoError(t, err)
+
+	validator := &fakeValidator{}
+	rule := sudo.NewRule(validator)
+	rule.Destination = filepath.Join(dir, "deploy")
+	rule.Content = content
+	rule.State = sudo.StatePresent
+	return dir, rule, validator
+}
+
+// assertOnlyRule checks that no temporary files are left in dir
+func assertOnlyRule(t *testing.T, dir string) {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "deploy", files[0].Name())
+}
+
+// fakeValidator records the files it validates
+type fakeValidator struct {
+	validated []string
+	err       error
+
+	configChecks int
+	configErr    error
+}
+
+func (v *fakeValidator) Validate(path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if stat.Mode().Perm() != sudo.Mode {
+		return errors.New("bad permissions")
+	}
+	v.validated = append(v.validated, path)
+	return v.err
+}
+
+func (v *fakeValidator) ValidateConfig() error {
+	v.configChecks++
+	return v.configErr
+}
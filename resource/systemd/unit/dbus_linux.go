@@ -49,4 +49,22 @@ type SystemdConnection interface {
 
 	// KillUnit sends a unix signal to the process
 	KillUnit(name string, signal int32)
+
+	// Reload instructs systemd to reload all unit files
+	Reload() error
+
+	// ListUnitFiles that are installed, along with their enablement state
+	ListUnitFiles() ([]dbus.UnitFile, error)
+
+	// EnableUnitFiles enables unit files
+	EnableUnitFiles(files []string, runtime bool, force bool) (bool, []dbus.EnableUnitFileChange, error)
+
+	// DisableUnitFiles disables unit files
+	DisableUnitFiles(files []string, runtime bool) ([]dbus.DisableUnitFileChange, error)
+
+	// MaskUnitFiles masks unit files
+	MaskUnitFiles(files []string, runtime bool, force bool) ([]dbus.MaskUnitFileChange, error)
+
+	// UnmaskUnitFiles unmasks unit files
+	UnmaskUnitFiles(files []string, runtime bool) ([]dbus.UnmaskUnitFileChange, error)
 }
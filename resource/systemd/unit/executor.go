@@ -44,4 +44,31 @@ type SystemdExecutor interface {
 
 	// Send a unix signal to a process.
 	SendSignal(u *Unit, signal Signal)
+
+	// DaemonReload will instruct systemd to reload all unit files, as if the
+	// user had run `systemctl daemon-reload`.
+	DaemonReload() error
+
+	// UnitFileState will return the enablement state of a unit file, e.g.
+	// "enabled", "disabled", or "masked".  If systemd doesn't know of a unit file
+	// with the given name an empty string is returned.
+	UnitFileState(unitName string) (string, error)
+
+	// EnableUnitFile will enable a unit file using the [Install] section.
+	EnableUnitFile(unitName string) error
+
+	// DisableUnitFile will disable a unit file.
+	DisableUnitFile(unitName string) error
+
+	// MaskUnitFile will mask a unit file, linking it to /dev/null.
+	MaskUnitFile(unitName string) error
+
+	// UnmaskUnitFile will unmask a unit file.
+	UnmaskUnitFile(unitName string) error
+}
+
+// NewExecutor connects to systemd and returns an executor for it. It returns
+// an error on systems without systemd.
+func NewExecutor() (SystemdExecutor, error) {
+	return realExecutor()
 }
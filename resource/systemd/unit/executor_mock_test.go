@@ -73,3 +73,39 @@ func (m *ExecutorMock) SendSignal(u *Unit, signal Signal) {
 	m.Called(u, signal)
 	return
 }
+
+func (m *ExecutorMock) DaemonReload() error {
+	m.maybeSleep()
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *ExecutorMock) UnitFileState(unitName string) (string, error) {
+	m.maybeSleep()
+	args := m.Called(unitName)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ExecutorMock) EnableUnitFile(unitName string) error {
+	m.maybeSleep()
+	args := m.Called(unitName)
+	return args.Error(0)
+}
+
+func (m *ExecutorMock) DisableUnitFile(unitName string) error {
+	m.maybeSleep()
+	args := m.Called(unitName)
+	return args.Error(0)
+}
+
+func (m *ExecutorMock) MaskUnitFile(unitName string) error {
+	m.maybeSleep()
+	args := m.Called(unitName)
+	return args.Error(0)
+}
+
+func (m *ExecutorMock) UnmaskUnitFile(unitName string) error {
+	m.maybeSleep()
+	args := m.Called(unitName)
+	return args.Error(0)
+}
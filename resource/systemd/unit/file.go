@@ -0,0 +1,460 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/remove"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// FileState is the desired state of a unit file
+type FileState string
+
+const (
+	// FileStatePresent indicates the unit file should be present
+	FileStatePresent FileState = "present"
+
+	// FileStateEnabled indicates the unit file should be present and enabled
+	FileStateEnabled FileState = "enabled"
+
+	// FileStateDisabled indicates the unit file should be present and disabled
+	FileStateDisabled FileState = "disabled"
+
+	// FileStateMasked indicates the unit should be masked
+	FileStateMasked FileState = "masked"
+
+	// FileStateAbsent indicates the unit file should be absent
+	FileStateAbsent FileState = "absent"
+)
+
+// Section is a named section of a unit file, like [Unit] or [Service]
+type Section struct {
+	Name    string
+	Options map[string]string
+}
+
+// RenderSections renders sections in the unit file format. Empty sections are
+// skipped, options are sorted, and an option is repeated for every line in its
+// value.
+func RenderSections(sections []Section) string {
+	var buf bytes.Buffer
+
+	for _, section := range sections {
+		if len(section.Options) == 0 {
+			continue
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "[%s]\n", section.Name)
+
+		keys := make([]string, 0, len(section.Options))
+		for key := range section.Options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			for _, value := range strings.Split(section.Options[key], "\n") {
+				fmt.Fprintf(&buf, "%s=%s\n", key, value)
+			}
+		}
+	}
+
+	return buf.String()
+}
+
+func hasOptions(sections []Section) bool {
+	for _, section := range sections {
+		if len(section.Options) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// File manages a systemd unit file or drop-in
+type File struct {
+	// The name of the unit, including the unit type.
+	Name string `export:"name"`
+
+	// The full path to the unit file or drop-in.
+	Path string `export:"path"`
+
+	// The name of the drop-in, if one is managed instead of the unit file.
+	DropIn string `export:"dropin"`
+
+	// The content of the unit file or drop-in, rendered from its sections.
+	Content string `export:"content"`
+
+	// The desired state of the unit file.
+	State FileState `export:"state"`
+
+	// The enablement state of the unit file as reported by systemd, e.g.
+	// `enabled`, `disabled`, `static`, or `masked`. It is empty if systemd does
+	// not know of the unit file.
+	UnitFileState string `export:"unitfilestate"`
+
+	systemdExecutor SystemdExecutor
+
+	// maskDir is the directory systemd masks units in. It is
+	// DefaultUnitDirectory unless set for testing.
+	maskDir string
+}
+
+// satisfiesEnable is true if a unit file in the given state doesn't need to be
+// enabled. Static, indirect, alias and generated units have no [Install]
+// section of their own to enable, and enabled-runtime units are already
+// enabled until the next reboot.
+func satisfiesEnable(state string) bool {
+	switch state {
+	case "enabled", "enabled-runtime", "static", "indirect", "alias", "generated":
+		return true
+	}
+	return false
+}
+
+// Check if the unit file needs to be changed
+func (f *File) Check(ctx context.Context, _ resource.Renderer) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	if err := f.queryState(); err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	switch f.State {
+	case FileStateMasked:
+		if f.UnitFileState != "masked" {
+			if err := f.checkMaskConflict(ctx, status); err != nil {
+				status.RaiseLevel(resource.StatusCantChange)
+				return status, err
+			}
+			status.AddDifference("state", describeFileState(f.UnitFileState), "masked", "")
+		}
+
+	case FileStateAbsent:
+		if err := f.checkAbsent(ctx, status); err != nil {
+			return status, err
+		}
+
+	default:
+		diff, err := f.contentDiff()
+		if err != nil {
+			status.RaiseLevel(resource.StatusCantChange)
+			return status, err
+		}
+		if diff.Changes() {
+			status.Differences[f.Path] = diff
+			status.AddMessage("will reload systemd")
+		}
+		if wanted := f.wantedFileState(); wanted != "" {
+			status.AddDifference("state", describeFileState(f.UnitFileState), wanted, "")
+		}
+	}
+
+	status.RaiseLevelForDiffs()
+	return status, nil
+}
+
+// Apply changes to the unit file
+func (f *File) Apply(ctx context.Context) (resource.TaskStatus, error) {
+	status := resource.NewStatus()
+
+	if err := f.queryState(); err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return status, err
+	}
+
+	var err error
+	switch f.State {
+	case FileStateMasked:
+		err = f.applyMasked(ctx, status)
+	case FileStateAbsent:
+		err = f.applyAbsent(ctx, status)
+	default:
+		err = f.applyPresent(status)
+	}
+
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+	}
+	return status, err
+}
+
+// queryState sets the current enablement state of the unit file
+func (f *File) queryState() error {
+	state, err := f.systemdExecutor.UnitFileState(f.Name)
+	if err != nil {
+		return errors.Wrapf(err, "cannot query unit file state of %s", f.Name)
+	}
+	f.UnitFileState = state
+	return nil
+}
+
+// needsUnmask is true if the unit has to be unmasked before the unit file is
+// written or the unit is enabled or disabled. A masked unit file is a link to
+// /dev/null in place of the real unit file.
+func (f *File) needsUnmask() bool {
+	return f.UnitFileState == "masked" && (f.DropIn == "" || f.State != FileStatePresent)
+}
+
+// wantedFileState returns the enablement state the unit file will be changed
+// to, or an empty string if it won't be changed
+func (f *File) wantedFileState() string {
+	switch f.State {
+	case FileStateEnabled:
+		if !satisfiesEnable(f.UnitFileState) {
+			return "enabled"
+		}
+	case FileStateDisabled:
+		if f.UnitFileState == "enabled" || f.UnitFileState == "masked" {
+			return "disabled"
+		}
+	}
+
+	if f.needsUnmask() {
+		return "unmasked"
+	}
+	return ""
+}
+
+// contentDiff returns the difference between the file on disk and the
+// rendered content
+func (f *File) contentDiff() (*resource.UnifiedDiff, error) {
+	actual, err := f.read()
+	if os.IsNotExist(err) {
+		diff := resource.NewUnifiedDiff("", f.Content)
+		diff.Placeholder = "<file-missing>"
+		return diff, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return resource.NewUnifiedDiff(actual, f.Content), nil
+}
+
+// read the unit file. A masked unit file is treated as missing.
+func (f *File) read() (string, error) {
+	stat, err := os.Lstat(f.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if stat.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Readlink(f.Path); err == nil && target == os.DevNull {
+			return "", &os.PathError{Op: "read", Path: f.Path, Err: os.ErrNotExist}
+		}
+	} else if stat.IsDir() {
+		return "", fmt.Errorf("cannot write unit file %q, it is a directory", f.Path)
+	}
+
+	data, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot read %q", f.Path)
+	}
+	return string(data), nil
+}
+
+func (f *File) applyPresent(status *resource.Status) error {
+	diff, err := f.contentDiff()
+	if err != nil {
+		return err
+	}
+
+	reload, unmasked := false, false
+	if f.needsUnmask() {
+		if err := f.systemdExecutor.UnmaskUnitFile(f.Name); err != nil {
+			return err
+		}
+		status.AddDifference("state", "masked", "unmasked", "")
+		reload, unmasked = true, true
+	}
+
+	if diff.Changes() {
+		if err := os.MkdirAll(path.Dir(f.Path), 0755); err != nil {
+			return errors.Wrapf(err, "cannot create %q", path.Dir(f.Path))
+		}
+		if err := ioutil.WriteFile(f.Path, []byte(f.Content), 0644); err != nil {
+			return errors.Wrapf(err, "cannot write %q", f.Path)
+		}
+		status.Differences[f.Path] = diff
+		reload = true
+	}
+
+	if reload {
+		if err := f.reload(status); err != nil {
+			return err
+		}
+	}
+
+	// a unit that was masked may still be enabled underneath the mask, so its
+	// state is checked again before enabling or disabling it
+	original := f.UnitFileState
+	if unmasked {
+		if err := f.queryState(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case f.State == FileStateEnabled && !satisfiesEnable(f.UnitFileState):
+		if err := f.systemdExecutor.EnableUnitFile(f.Name); err != nil {
+			return err
+		}
+		status.AddDifference("state", describeFileState(original), "enabled", "")
+	case f.State == FileStateDisabled && f.UnitFileState == "enabled":
+		if err := f.systemdExecutor.DisableUnitFile(f.Name); err != nil {
+			return err
+		}
+		status.AddDifference("state", describeFileState(original), "disabled", "")
+	case f.State == FileStateDisabled && unmasked:
+		status.AddDifference("state", "masked", "disabled", "")
+	}
+
+	return nil
+}
+
+func (f *File) applyMasked(ctx context.Context, status *resource.Status) error {
+	if f.UnitFileState == "masked" {
+		return nil
+	}
+
+	if err := f.checkMaskConflict(ctx, status); err != nil {
+		return err
+	}
+	if f.ownsMaskPath() {
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "cannot remove %q", f.Path)
+		}
+	}
+
+	if err := f.systemdExecutor.MaskUnitFile(f.Name); err != nil {
+		return err
+	}
+	status.AddDifference("state", describeFileState(f.UnitFileState), "masked", "")
+
+	return f.reload(status)
+}
+
+// maskPath is where systemd links a masked unit to /dev/null
+func (f *File) maskPath() string {
+	dir := f.maskDir
+	if dir == "" {
+		dir = DefaultUnitDirectory
+	}
+	return path.Join(dir, f.Name)
+}
+
+// ownsMaskPath is true if the unit file this resource manages is where systemd
+// masks the unit. It is removed before masking, since systemd won't replace it.
+func (f *File) ownsMaskPath() bool {
+	return f.DropIn == "" && path.Clean(f.Path) == f.maskPath()
+}
+
+// checkMaskConflict checks for a real unit file where systemd will mask the
+// unit. systemd refuses to replace it, so it is removed first if it's the file
+// this resource manages. Any other unit file there has to be removed by hand.
+func (f *File) checkMaskConflict(ctx context.Context, status *resource.Status) error {
+	maskPath := f.maskPath()
+	stat, err := os.Lstat(maskPath)
+	if os.IsNotExist(err) || (err == nil && stat.Mode()&os.ModeSymlink != 0) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "cannot read %q", maskPath)
+	}
+
+	if !f.ownsMaskPath() || stat.IsDir() {
+		return fmt.Errorf("cannot mask %s, %q is a unit file that systemd will not replace", f.Name, maskPath)
+	}
+
+	if err := remove.Guard(ctx, maskPath); err != nil {
+		return err
+	}
+	status.AddDifference(maskPath, "<present>", "<absent>", "")
+	return nil
+}
+
+// checkAbsent checks if the unit file needs to be removed
+func (f *File) checkAbsent(ctx context.Context, status *resource.Status) error {
+	if _, err := os.Lstat(f.Path); os.IsNotExist(err) {
+		status.AddMessage(f.Path + " is already absent")
+		return nil
+	} else if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return errors.Wrapf(err, "cannot read %q", f.Path)
+	}
+
+	if err := remove.Guard(ctx, f.Path); err != nil {
+		status.RaiseLevel(resource.StatusCantChange)
+		return err
+	}
+
+	if f.DropIn == "" && f.UnitFileState == "enabled" {
+		status.AddDifference("state", "enabled", "disabled", "")
+	}
+	status.AddDifference(f.Path, "<present>", "<absent>", "")
+	status.AddMessage("will reload systemd")
+	return nil
+}
+
+func (f *File) applyAbsent(ctx context.Context, status *resource.Status) error {
+	if err := remove.Guard(ctx, f.Path); err != nil {
+		return err
+	}
+
+	if f.DropIn == "" && f.UnitFileState == "enabled" {
+		if err := f.systemdExecutor.DisableUnitFile(f.Name); err != nil {
+			return err
+		}
+		status.AddDifference("state", "enabled", "disabled", "")
+	}
+
+	if err := os.Remove(f.Path); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "cannot remove %q", f.Path)
+	}
+	status.AddDifference(f.Path, "<present>", "<absent>", "")
+
+	return f.reload(status)
+}
+
+// reload instructs systemd to reload unit files
+func (f *File) reload(status *resource.Status) error {
+	if err := f.systemdExecutor.DaemonReload(); err != nil {
+		return errors.Wrap(err, "cannot reload systemd")
+	}
+	status.AddMessage("reloaded systemd")
+	return nil
+}
+
+func describeFileState(state string) string {
+	if state == "" {
+		return "<none>"
+	}
+	return state
+}
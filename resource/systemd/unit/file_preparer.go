@@ -0,0 +1,138 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/asteris-llc/converge/load/registry"
+	"github.com/asteris-llc/converge/resource"
+	"golang.org/x/net/context"
+)
+
+// DefaultUnitDirectory is the directory unit files are written to if no other
+// directory is given
+const DefaultUnitDirectory = "/etc/systemd/system"
+
+// FilePreparer for UnitFile
+//
+// UnitFile writes systemd unit files and drop-ins from structured sections,
+// reloads systemd when they change, and enables, disables, or masks the unit.
+// Options in each section are written in sorted order. To repeat an option
+// (like `ExecStartPre` or `Environment`) put each value on its own line.
+type FilePreparer struct {
+	// The name of the unit, including the unit type, e.g. "foo.service". If the
+	// unit type is not given, "service" is used.
+	Name string `hcl:"name" required:"true" nonempty:"true"`
+
+	// The directory the unit file is written to.
+	// The default value is /etc/systemd/system.
+	Directory string `hcl:"directory"`
+
+	// The name of a drop-in. If set, the sections are written to
+	// `<directory>/<name>.d/<dropin>.conf` instead of the unit file, to extend a
+	// unit that is installed some other way.
+	DropIn string `hcl:"dropin"`
+
+	// Options for the [Unit] section
+	Unit map[string]string `hcl:"unit"`
+
+	// Options for the [Service] section
+	Service map[string]string `hcl:"service"`
+
+	// Options for the [Socket] section
+	Socket map[string]string `hcl:"socket"`
+
+	// Options for the [Timer] section
+	Timer map[string]string `hcl:"timer"`
+
+	// Options for the [Install] section. This section is needed for the unit to
+	// be enabled.
+	Install map[string]string `hcl:"install"`
+
+	// The desired state of the unit file. `present` writes the file without
+	// changing whether the unit is enabled, and `enabled` and `disabled` write
+	// the file and then enable or disable the unit. Static, indirect, alias and
+	// generated units are left as they are for `enabled`. `masked` masks the
+	// unit instead of writing a file, removing the file first if it's where
+	// systemd masks the unit, and `absent` removes the file.
+	// The default value is present.
+	State FileState `hcl:"state" valid_values:"present,enabled,disabled,masked,absent"`
+
+	executor SystemdExecutor
+}
+
+// Prepare a new task
+func (p *FilePreparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.Directory == "" {
+		p.Directory = DefaultUnitDirectory
+	}
+
+	if p.State == "" {
+		p.State = FileStatePresent
+	}
+
+	name := withDefaultSuffix(p.Name)
+
+	if path.Base(name) != name {
+		return nil, fmt.Errorf("%q is not a valid unit name", p.Name)
+	}
+
+	if p.DropIn != "" && path.Base(p.DropIn) != p.DropIn {
+		return nil, fmt.Errorf("%q is not a valid drop-in name", p.DropIn)
+	}
+
+	sections := []Section{
+		{"Unit", p.Unit},
+		{"Service", p.Service},
+		{"Socket", p.Socket},
+		{"Timer", p.Timer},
+		{"Install", p.Install},
+	}
+
+	if p.State == FileStateMasked && (p.DropIn != "" || hasOptions(sections)) {
+		return nil, errors.New("a masked unit cannot have a drop-in or options")
+	}
+
+	if p.executor == nil {
+		executor, err := realExecutor()
+		if err != nil {
+			return nil, err
+		}
+		p.executor = executor
+	}
+
+	f := &File{
+		Name:            name,
+		DropIn:          p.DropIn,
+		State:           p.State,
+		Content:         RenderSections(sections),
+		systemdExecutor: p.executor,
+	}
+
+	if p.DropIn == "" {
+		f.Path = path.Join(p.Directory, name)
+	} else {
+		f.Path = path.Join(p.Directory, name+".d", p.DropIn+".conf")
+	}
+
+	return f, nil
+}
+
+func init() {
+	registry.Register("systemd.unit.file", (*FilePreparer)(nil), (*File)(nil))
+}
@@ -0,0 +1,443 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+const sampleUnitFile = `[Unit]
+Description=test service
+
+[Service]
+ExecStart=/usr/bin/test
+ExecStartPre=/bin/true
+ExecStartPre=/bin/echo starting
+
+[Install]
+WantedBy=multi-user.target
+`
+
+func TestRenderSections(t *testing.T) {
+	t.Parallel()
+
+	actual := RenderSections([]Section{
+		{"Unit", map[string]string{"Description": "test service"}},
+		{"Service", map[string]string{
+			"ExecStartPre": "/bin/true\n/bin/echo starting",
+			"ExecStart":    "/usr/bin/test",
+		}},
+		{"Timer", nil},
+		{"Install", map[string]string{"WantedBy": "multi-user.target"}},
+	})
+	assert.Equal(t, sampleUnitFile, actual)
+}
+
+func TestFilePreparer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		task, err := (&FilePreparer{
+			Name:     "test",
+			Service:  map[string]string{"ExecStart": "/usr/bin/test"},
+			executor: &ExecutorMock{},
+		}).Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+
+		f := task.(*File)
+		assert.Equal(t, "test.service", f.Name)
+		assert.Equal(t, "/etc/systemd/system/test.service", f.Path)
+		assert.Equal(t, FileStatePresent, f.State)
+		assert.Equal(t, "[Service]\nExecStart=/usr/bin/test\n", f.Content)
+	})
+
+	t.Run("dropin", func(t *testing.T) {
+		t.Parallel()
+		task, err := (&FilePreparer{
+			Name:      "test.socket",
+			Directory: "/run/systemd/system",
+			DropIn:    "override",
+			executor:  &ExecutorMock{},
+		}).Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, "/run/systemd/system/test.socket.d/override.conf", task.(*File).Path)
+	})
+
+	t.Run("invalid-name", func(t *testing.T) {
+		t.Parallel()
+		_, err := (&FilePreparer{
+			Name:     "../test.service",
+			executor: &ExecutorMock{},
+		}).Prepare(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+
+	t.Run("masked-with-options", func(t *testing.T) {
+		t.Parallel()
+		_, err := (&FilePreparer{
+			Name:     "test.service",
+			State:    FileStateMasked,
+			Unit:     map[string]string{"Description": "test"},
+			executor: &ExecutorMock{},
+		}).Prepare(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+	})
+}
+
+func TestFileCheck(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "converge-unit-file-check")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	existing := path.Join(tmpDir, "existing.service")
+	require.NoError(t, ioutil.WriteFile(existing, []byte(sampleUnitFile), 0644))
+
+	t.Run("missing", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "missing.service").Return("", nil)
+		f := &File{
+			Name:            "missing.service",
+			Path:            path.Join(tmpDir, "missing.service"),
+			Content:         sampleUnitFile,
+			State:           FileStateEnabled,
+			systemdExecutor: e,
+		}
+
+		status, err := f.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, resource.StatusWillChange, status.StatusCode())
+		if diff, ok := status.Diffs()[f.Path]; assert.True(t, ok) {
+			assert.Equal(t, "<file-missing>", diff.Original())
+		}
+		if diff, ok := status.Diffs()["state"]; assert.True(t, ok) {
+			assert.Equal(t, "<none>", diff.Original())
+			assert.Equal(t, "enabled", diff.Current())
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "existing.service").Return("enabled", nil)
+		f := &File{
+			Name:            "existing.service",
+			Path:            existing,
+			Content:         sampleUnitFile,
+			State:           FileStateEnabled,
+			systemdExecutor: e,
+		}
+
+		status, err := f.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+		assert.Equal(t, "enabled", f.UnitFileState)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "existing.service").Return("enabled", nil)
+		f := &File{
+			Name:            "existing.service",
+			Path:            existing,
+			Content:         sampleUnitFile,
+			State:           FileStateDisabled,
+			systemdExecutor: e,
+		}
+
+		status, err := f.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		_, ok := status.Diffs()[existing]
+		assert.False(t, ok)
+	})
+
+	t.Run("masked", func(t *testing.T) {
+		masked := path.Join(tmpDir, "masked.service")
+		require.NoError(t, os.Symlink(os.DevNull, masked))
+
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "masked.service").Return("masked", nil)
+		f := &File{
+			Name:            "masked.service",
+			Path:            masked,
+			Content:         sampleUnitFile,
+			State:           FileStatePresent,
+			systemdExecutor: e,
+		}
+
+		status, err := f.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		if diff, ok := status.Diffs()[masked]; assert.True(t, ok) {
+			assert.Equal(t, "<file-missing>", diff.Original())
+		}
+		if diff, ok := status.Diffs()["state"]; assert.True(t, ok) {
+			assert.Equal(t, "unmasked", diff.Current())
+		}
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "existing.service").Return("disabled", nil)
+		f := &File{
+			Name:            "existing.service",
+			Path:            existing,
+			State:           FileStateMasked,
+			systemdExecutor: e,
+		}
+
+		status, err := f.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		if diff, ok := status.Diffs()["state"]; assert.True(t, ok) {
+			assert.Equal(t, "disabled", diff.Original())
+			assert.Equal(t, "masked", diff.Current())
+		}
+	})
+	t.Run("mask-conflict", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "existing.service").Return("disabled", nil)
+		f := &File{
+			Name:            "existing.service",
+			Path:            path.Join(tmpDir, "vendor", "existing.service"),
+			State:           FileStateMasked,
+			systemdExecutor: e,
+			maskDir:         tmpDir,
+		}
+
+		status, err := f.Check(context.Background(), fakerenderer.New())
+		assert.Error(t, err)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+	})
+	t.Run("static", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "existing.service").Return("static", nil)
+		f := &File{
+			Name:            "existing.service",
+			Path:            existing,
+			Content:         sampleUnitFile,
+			State:           FileStateEnabled,
+			systemdExecutor: e,
+		}
+
+		status, err := f.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+}
+
+func TestFileApply(t *testing.T) {
+	t.Parallel()
+
+	tmpDir, err := ioutil.TempDir("", "converge-unit-file-apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	t.Run("write-and-enable", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "new.service").Return("", nil)
+		e.On("DaemonReload").Return(nil)
+		e.On("EnableUnitFile", "new.service").Return(nil)
+		f := &File{
+			Name:            "new.service",
+			Path:            path.Join(tmpDir, "new.service"),
+			Content:         sampleUnitFile,
+			State:           FileStateEnabled,
+			systemdExecutor: e,
+		}
+
+		_, err := f.Apply(context.Background())
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadFile(f.Path)
+		require.NoError(t, err)
+		assert.Equal(t, sampleUnitFile, string(content))
+		e.AssertCalled(t, "DaemonReload")
+		e.AssertCalled(t, "EnableUnitFile", "new.service")
+	})
+
+	t.Run("dropin", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "vendor.service").Return("masked", nil)
+		e.On("DaemonReload").Return(nil)
+		f := &File{
+			Name:            "vendor.service",
+			Path:            path.Join(tmpDir, "vendor.service.d", "override.conf"),
+			DropIn:          "override",
+			Content:         "[Service]\nNice=10\n",
+			State:           FileStatePresent,
+			systemdExecutor: e,
+		}
+
+		_, err := f.Apply(context.Background())
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadFile(f.Path)
+		require.NoError(t, err)
+		assert.Equal(t, f.Content, string(content))
+		e.AssertNotCalled(t, "UnmaskUnitFile", "vendor.service")
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		dest := path.Join(tmpDir, "unchanged.service")
+		require.NoError(t, ioutil.WriteFile(dest, []byte(sampleUnitFile), 0644))
+
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "unchanged.service").Return("disabled", nil)
+		f := &File{
+			Name:            "unchanged.service",
+			Path:            dest,
+			Content:         sampleUnitFile,
+			State:           FileStatePresent,
+			systemdExecutor: e,
+		}
+
+		_, err := f.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertNotCalled(t, "DaemonReload")
+	})
+
+	t.Run("unmask", func(t *testing.T) {
+		dest := path.Join(tmpDir, "masked.service")
+		require.NoError(t, os.Symlink(os.DevNull, dest))
+
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "masked.service").Return("masked", nil)
+		e.On("UnmaskUnitFile", "masked.service").Return(nil).Run(func(mock.Arguments) {
+			os.Remove(dest)
+		})
+		e.On("DaemonReload").Return(nil)
+		f := &File{
+			Name:            "masked.service",
+			Path:            dest,
+			Content:         sampleUnitFile,
+			State:           FileStatePresent,
+			systemdExecutor: e,
+		}
+
+		_, err := f.Apply(context.Background())
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, sampleUnitFile, string(content))
+		e.AssertCalled(t, "UnmaskUnitFile", "masked.service")
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		dest := path.Join(tmpDir, "absent.service")
+		require.NoError(t, ioutil.WriteFile(dest, []byte(sampleUnitFile), 0644))
+
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "absent.service").Return("enabled", nil)
+		e.On("DisableUnitFile", "absent.service").Return(nil)
+		e.On("DaemonReload").Return(nil)
+		f := &File{
+			Name:            "absent.service",
+			Path:            dest,
+			State:           FileStateAbsent,
+			systemdExecutor: e,
+		}
+
+		_, err := f.Apply(context.Background())
+		require.NoError(t, err)
+
+		_, err = os.Stat(dest)
+		assert.True(t, os.IsNotExist(err))
+		e.AssertCalled(t, "DisableUnitFile", "absent.service")
+		e.AssertCalled(t, "DaemonReload")
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "vendor.service").Return("enabled", nil)
+		e.On("MaskUnitFile", "vendor.service").Return(nil)
+		e.On("DaemonReload").Return(nil)
+		f := &File{
+			Name:            "vendor.service",
+			Path:            path.Join(tmpDir, "vendor.service"),
+			State:           FileStateMasked,
+			systemdExecutor: e,
+		}
+
+		_, err := f.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertCalled(t, "MaskUnitFile", "vendor.service")
+	})
+	t.Run("mask-own-file", func(t *testing.T) {
+		dest := path.Join(tmpDir, "own.service")
+		require.NoError(t, ioutil.WriteFile(dest, []byte(sampleUnitFile), 0644))
+
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "own.service").Return("disabled", nil)
+		e.On("MaskUnitFile", "own.service").Return(nil)
+		e.On("DaemonReload").Return(nil)
+		f := &File{
+			Name:            "own.service",
+			Path:            dest,
+			State:           FileStateMasked,
+			systemdExecutor: e,
+			maskDir:         tmpDir,
+		}
+
+		status, err := f.Apply(context.Background())
+		require.NoError(t, err)
+		assert.Contains(t, status.Diffs(), dest)
+
+		_, err = os.Lstat(dest)
+		assert.True(t, os.IsNotExist(err))
+		e.AssertCalled(t, "MaskUnitFile", "own.service")
+	})
+	t.Run("unmask-and-disable", func(t *testing.T) {
+		dest := path.Join(tmpDir, "masked-enabled.service")
+		require.NoError(t, os.Symlink(os.DevNull, dest))
+
+		e := &ExecutorMock{}
+		e.On("UnitFileState", "masked-enabled.service").Return("masked", nil).Once()
+		e.On("UnitFileState", "masked-enabled.service").Return("enabled", nil)
+		e.On("UnmaskUnitFile", "masked-enabled.service").Return(nil).Run(func(mock.Arguments) {
+			os.Remove(dest)
+		})
+		e.On("DisableUnitFile", "masked-enabled.service").Return(nil)
+		e.On("DaemonReload").Return(nil)
+		f := &File{
+			Name:            "masked-enabled.service",
+			Path:            dest,
+			Content:         sampleUnitFile,
+			State:           FileStateDisabled,
+			systemdExecutor: e,
+		}
+
+		status, err := f.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertCalled(t, "UnmaskUnitFile", "masked-enabled.service")
+		e.AssertCalled(t, "DisableUnitFile", "masked-enabled.service")
+		if diff, ok := status.Diffs()["state"]; assert.True(t, ok) {
+			assert.Equal(t, "masked", diff.Original())
+			assert.Equal(t, "disabled", diff.Current())
+		}
+	})
+}
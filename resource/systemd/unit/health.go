@@ -0,0 +1,160 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asteris-llc/converge/resource"
+)
+
+// unitHealth is the status of a unit checked in healthcheck mode. During
+// `converge healthcheck` it reports the health of the unit instead of the
+// changes needed.
+type unitHealth struct {
+	*resource.Status
+	level resource.HealthStatusCode
+}
+
+// HealthCheck reports the health of the unit
+func (h *unitHealth) HealthCheck() (*resource.HealthStatus, error) {
+	status, err := h.Status.HealthCheck()
+	if err != nil {
+		return nil, err
+	}
+	status.TaskStatus = h
+	status.UpgradeWarning(h.level)
+	if status.WarningLevel != resource.StatusHealthy {
+		status.Details = h.Messages()
+	}
+	return status, nil
+}
+
+// degrade adds a reason for the unit to be unhealthy
+func (h *unitHealth) degrade(level resource.HealthStatusCode, reason string) {
+	if level > h.level {
+		h.level = level
+	}
+	h.AddMessage(reason)
+}
+
+// checkHealth inspects the unit without changing it
+func (r *Resource) checkHealth(u *Unit) *unitHealth {
+	health := &unitHealth{Status: resource.NewStatus()}
+
+	switch u.LoadState {
+	case "not-found", "error", "masked":
+		health.degrade(resource.StatusError, fmt.Sprintf("unit is not loaded (%s)", u.LoadState))
+	}
+
+	subState := u.Properties.SubState
+	switch u.ActiveState {
+	case "active":
+		health.AddMessage(fmt.Sprintf("unit is active (%s)", subState))
+	case "failed":
+		health.degrade(resource.StatusError, "unit has failed")
+		r.addFailureReasons(u, health)
+	case "unknown":
+		health.degrade(resource.StatusError, "unit was in an unknown state")
+	default:
+		health.degrade(resource.StatusWarning, fmt.Sprintf("unit is %s (%s)", u.ActiveState, subState))
+	}
+
+	if u.TimerProperties != nil {
+		health.AddMessage(timerMessages(u.TimerProperties)...)
+		r.checkTriggeredUnit(u.TimerProperties.Unit, health)
+	}
+
+	switch health.level {
+	case resource.StatusError:
+		health.SetWarning("unit is unhealthy")
+	case resource.StatusWarning:
+		health.SetWarning("unit is degraded")
+	}
+
+	return health
+}
+
+// checkTriggeredUnit degrades the health of a timer if the unit it triggers
+// has failed
+func (r *Resource) checkTriggeredUnit(name string, health *unitHealth) {
+	if name == "" {
+		return
+	}
+
+	triggered, err := r.systemdExecutor.QueryUnit(name, false)
+	if err != nil {
+		health.degrade(resource.StatusWarning, fmt.Sprintf("cannot query triggered unit %s: %v", name, err))
+		return
+	}
+
+	if triggered.ActiveState == "failed" {
+		health.degrade(resource.StatusError, fmt.Sprintf("triggered unit %s has failed", name))
+		r.addFailureReasons(triggered, health)
+	}
+}
+
+// addFailureReasons adds the recorded cause of a failed unit to the health
+// status
+func (r *Resource) addFailureReasons(u *Unit, health *unitHealth) {
+	if reason, err := getFailedReason(u); err != nil {
+		health.AddMessage(fmt.Sprintf("cannot determine root cause of failure: %v", err))
+	} else {
+		health.AddMessage(fmt.Sprintf("the failure reason was: %s", reason))
+	}
+
+	if props := u.ServiceProperties; props != nil {
+		if props.Result == "exit-code" {
+			health.AddMessage(fmt.Sprintf("main process exited with status %d", props.ExecMainStatus))
+		}
+		if props.StatusText != "" {
+			health.AddMessage(fmt.Sprintf("last status: %s", props.StatusText))
+		}
+	}
+
+	if ts := formatUSec(u.Properties.InactiveEnterTimestamp); ts != "" {
+		health.AddMessage(fmt.Sprintf("failed at %s", ts))
+	}
+}
+
+// timerMessages describes when a timer last triggered and will next elapse
+func timerMessages(props *TimerTypeProperties) []string {
+	next := "not scheduled"
+	if ts := formatUSec(props.NextElapseUSecRealtime); ts != "" {
+		next = ts
+	} else if props.NextElapseUSecMonotonic != 0 {
+		next = fmt.Sprintf("%s after boot", time.Duration(props.NextElapseUSecMonotonic)*time.Microsecond)
+	}
+
+	last := "never"
+	if ts := formatUSec(props.LastTriggerUSec); ts != "" {
+		last = ts
+	}
+
+	return []string{
+		fmt.Sprintf("next elapse: %s", next),
+		fmt.Sprintf("last trigger: %s", last),
+	}
+}
+
+// formatUSec formats a systemd realtime timestamp in microseconds. Unset
+// timestamps are formatted as an empty string.
+func formatUSec(usec uint64) string {
+	if usec == 0 {
+		return ""
+	}
+	return time.Unix(0, int64(usec)*int64(time.Microsecond)).UTC().Format(time.RFC3339)
+}
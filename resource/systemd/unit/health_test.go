@@ -0,0 +1,179 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unit
+
+import (
+	"testing"
+
+	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func healthOf(t *testing.T, r *Resource) *resource.HealthStatus {
+	status, err := r.Check(context.Background(), fakerenderer.New())
+	require.NoError(t, err)
+	checker, ok := status.(interface {
+		HealthCheck() (*resource.HealthStatus, error)
+	})
+	require.True(t, ok)
+	health, err := checker.HealthCheck()
+	require.NoError(t, err)
+	assert.False(t, status.HasChanges())
+	return health
+}
+
+func TestHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("active", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Healthcheck: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		u := &Unit{ActiveState: "active", LoadState: "loaded", Type: UnitTypeService}
+		u.Properties.SubState = "running"
+		e.On("QueryUnit", "foo.service", false).Return(u, nil)
+
+		health := healthOf(t, r)
+		assert.Equal(t, resource.StatusHealthy, health.WarningLevel)
+		assert.False(t, health.ShouldDisplay())
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Healthcheck: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		u := &Unit{
+			ActiveState: "failed",
+			LoadState:   "loaded",
+			Type:        UnitTypeService,
+			ServiceProperties: &ServiceTypeProperties{
+				Result:         "exit-code",
+				ExecMainStatus: 3,
+				StatusText:     "cannot bind to port",
+			},
+		}
+		e.On("QueryUnit", "foo.service", false).Return(u, nil)
+
+		health := healthOf(t, r)
+		assert.True(t, health.IsError())
+		assert.Contains(t, health.Messages(), "unit has failed")
+		assert.Contains(t, health.Messages(), "the failure reason was: unit exited with a non-zero exit code")
+		assert.Contains(t, health.Messages(), "main process exited with status 3")
+		assert.Contains(t, health.Messages(), "last status: cannot bind to port")
+	})
+
+	t.Run("activating", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Healthcheck: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		u := &Unit{ActiveState: "activating", LoadState: "loaded", Type: UnitTypeService}
+		u.Properties.SubState = "start-pre"
+		e.On("QueryUnit", "foo.service", false).Return(u, nil)
+
+		health := healthOf(t, r)
+		assert.True(t, health.IsWarning())
+		assert.Contains(t, health.Messages(), "unit is activating (start-pre)")
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Healthcheck: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", "foo.service", false).Return(&Unit{ActiveState: "inactive", LoadState: "not-found"}, nil)
+
+		health := healthOf(t, r)
+		assert.True(t, health.IsError())
+	})
+
+	t.Run("timer-with-failed-unit", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.timer", Healthcheck: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		u := &Unit{
+			ActiveState: "active",
+			LoadState:   "loaded",
+			Type:        UnitTypeTimer,
+			TimerProperties: &TimerTypeProperties{
+				Unit:                   "foo.service",
+				NextElapseUSecRealtime: 1500000000000000,
+			},
+		}
+		u.Properties.SubState = "waiting"
+		e.On("QueryUnit", "foo.timer", false).Return(u, nil)
+		e.On("QueryUnit", "foo.service", false).Return(&Unit{
+			ActiveState:       "failed",
+			Type:              UnitTypeService,
+			ServiceProperties: &ServiceTypeProperties{Result: "timeout"},
+		}, nil)
+
+		health := healthOf(t, r)
+		assert.True(t, health.IsError())
+		assert.Contains(t, health.Messages(), "next elapse: 2017-07-14T02:40:00Z")
+		assert.Contains(t, health.Messages(), "triggered unit foo.service has failed")
+		assert.Equal(t, "2017-07-14T02:40:00Z", r.NextElapse)
+	})
+
+	t.Run("apply-does-nothing", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Healthcheck: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		_, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertNotCalled(t, "QueryUnit", any, any)
+	})
+}
+
+func TestTimerMessages(t *testing.T) {
+	t.Parallel()
+
+	t.Run("calendar", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(
+			t,
+			[]string{"next elapse: 2017-07-14T02:40:00Z", "last trigger: never"},
+			timerMessages(&TimerTypeProperties{NextElapseUSecRealtime: 1500000000000000}),
+		)
+	})
+
+	t.Run("monotonic", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(
+			t,
+			[]string{"next elapse: 15m0s after boot", "last trigger: 2017-07-14T02:40:00Z"},
+			timerMessages(&TimerTypeProperties{
+				NextElapseUSecMonotonic: 900000000,
+				LastTriggerUSec:         1500000000000000,
+			}),
+		)
+	})
+
+	t.Run("not-scheduled", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(
+			t,
+			[]string{"next elapse: not scheduled", "last trigger: never"},
+			timerMessages(&TimerTypeProperties{}),
+		)
+	})
+}
@@ -108,6 +108,42 @@ func (m *DbusMock) KillUnit(name string, signal int32) {
 	return
 }
 
+// Reload mocks Reload
+func (m *DbusMock) Reload() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// ListUnitFiles mocks ListUnitFiles
+func (m *DbusMock) ListUnitFiles() ([]dbus.UnitFile, error) {
+	args := m.Called()
+	return args.Get(0).([]dbus.UnitFile), args.Error(1)
+}
+
+// EnableUnitFiles mocks EnableUnitFiles
+func (m *DbusMock) EnableUnitFiles(files []string, runtime bool, force bool) (bool, []dbus.EnableUnitFileChange, error) {
+	args := m.Called(files, runtime, force)
+	return args.Bool(0), nil, args.Error(1)
+}
+
+// DisableUnitFiles mocks DisableUnitFiles
+func (m *DbusMock) DisableUnitFiles(files []string, runtime bool) ([]dbus.DisableUnitFileChange, error) {
+	args := m.Called(files, runtime)
+	return nil, args.Error(0)
+}
+
+// MaskUnitFiles mocks MaskUnitFiles
+func (m *DbusMock) MaskUnitFiles(files []string, runtime bool, force bool) ([]dbus.MaskUnitFileChange, error) {
+	args := m.Called(files, runtime, force)
+	return nil, args.Error(0)
+}
+
+// UnmaskUnitFiles mocks UnmaskUnitFiles
+func (m *DbusMock) UnmaskUnitFiles(files []string, runtime bool) ([]dbus.UnmaskUnitFileChange, error) {
+	args := m.Called(files, runtime)
+	return nil, args.Error(0)
+}
+
 type rets struct {
 	Val interface{}
 	Err error
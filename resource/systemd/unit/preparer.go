@@ -15,6 +15,9 @@
 package unit
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/asteris-llc/converge/load/registry"
 	"github.com/asteris-llc/converge/resource"
 	"golang.org/x/net/context"
@@ -30,10 +33,23 @@ type Preparer struct {
 	Name string `hcl:"unit" required:"true"`
 
 	// The desired state of the unit.  This will affect the current unit job.  Use
-	// `systemd.unit.file` to enable and disable jobs, or `systemd.unit.config` to
-	// set options.
+	// `enable` to control whether the unit is started at boot, or
+	// `systemd.unit.file` to write the unit file.
 	State string `hcl:"state" valid_values:"running,stopped,restarted"`
 
+	// If set, whether the unit should be enabled, so that it is started at boot,
+	// as if the user had run `systemctl enable` or `systemctl disable`.  Enabling
+	// a unit requires an [Install] section in its unit file, so static,
+	// indirect, alias and generated units are left as they are.  If not set,
+	// the unit is left as it is.
+	Enable *bool `hcl:"enable"`
+
+	// If set, whether the unit should be masked, so that it can't be started at
+	// all, as if the user had run `systemctl mask` or `systemctl unmask`.  A
+	// masked unit can't be enabled or started.  If not set, the unit is left as
+	// it is.
+	Mask *bool `hcl:"mask"`
+
 	// If reload is true then the service will be instructed to reload it's
 	// configuration as if the user had run `systemctl reload`.  This will reload
 	// the actual confguration file for the service, not the systemd unit file
@@ -53,11 +69,32 @@ type Preparer struct {
 	// an unsigned integer value between 1 and 31 inclusive.
 	SignalNumber uint `hcl:"signal_number" mutually_exclusive:"signal_name,signal_num"`
 
+	// If healthcheck is true the unit is only checked, and never changed.  During
+	// `converge healthcheck` a unit that has failed, or is not loaded, is
+	// reported as an error along with the reason it failed, and a unit that is
+	// not active is reported as a warning.  For timers, a failure of the unit the
+	// timer triggers is reported as well.  This can't be combined with the other
+	// options.
+	Healthcheck bool `hcl:"healthcheck"`
+
 	executor SystemdExecutor
 }
 
 // Prepare a new task
 func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resource.Task, error) {
+	if p.Healthcheck && (p.State != "" || p.Reload || p.Enable != nil || p.Mask != nil || p.SignalName != "" || p.SignalNumber != 0) {
+		return nil, errors.New("healthcheck cannot be combined with other options")
+	}
+
+	if p.Mask != nil && *p.Mask {
+		if p.Enable != nil && *p.Enable {
+			return nil, errors.New("a masked unit cannot be enabled")
+		}
+		if p.State == "running" || p.State == "restarted" {
+			return nil, fmt.Errorf("a masked unit cannot be %s", p.State)
+		}
+	}
+
 	var signal *Signal
 	if p.SignalName != "" {
 		num, err := ParseSignalByName(p.SignalName)
@@ -83,11 +120,22 @@ func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resou
 
 	r := &Resource{
 		Reload:          p.Reload,
+		Healthcheck:     p.Healthcheck,
 		Name:            p.Name,
 		State:           p.State,
 		systemdExecutor: p.executor,
 	}
 
+	if p.Enable != nil {
+		r.Enable = *p.Enable
+		r.manageEnable = true
+	}
+
+	if p.Mask != nil {
+		r.Mask = *p.Mask
+		r.manageMask = true
+	}
+
 	if signal != nil {
 		r.SignalName = signal.String()
 		r.SignalNumber = uint(*signal)
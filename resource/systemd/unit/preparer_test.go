@@ -133,5 +133,86 @@ func TestPreparer(t *testing.T) {
 		assert.Equal(t, "", res.(*Resource).SignalName)
 		assert.Equal(t, uint(0), res.(*Resource).SignalNumber)
 	})
-
+	t.Run("sets-enable-and-mask", func(t *testing.T) {
+		t.Parallel()
+		enable, mask := true, false
+		res, err := (&Preparer{
+			Name:     "test1",
+			Enable:   &enable,
+			Mask:     &mask,
+			executor: &ExecutorMock{},
+		}).Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, res.(*Resource).Enable)
+		assert.True(t, res.(*Resource).manageEnable)
+		assert.False(t, res.(*Resource).Mask)
+		assert.True(t, res.(*Resource).manageMask)
+	})
+	t.Run("leaves-enable-and-mask-unset", func(t *testing.T) {
+		t.Parallel()
+		res, err := (&Preparer{
+			Name:     "test1",
+			executor: &ExecutorMock{},
+		}).Prepare(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, res.(*Resource).manageEnable)
+		assert.False(t, res.(*Resource).manageMask)
+	})
+	t.Run("when-masked", func(t *testing.T) {
+		t.Parallel()
+		yes := true
+		t.Run("and-enabled", func(t *testing.T) {
+			t.Parallel()
+			_, err := (&Preparer{
+				Name:     "test1",
+				Enable:   &yes,
+				Mask:     &yes,
+				executor: &ExecutorMock{},
+			}).Prepare(context.Background(), fakerenderer.New())
+			assert.Error(t, err)
+		})
+		t.Run("and-running", func(t *testing.T) {
+			t.Parallel()
+			_, err := (&Preparer{
+				Name:     "test1",
+				State:    "running",
+				Mask:     &yes,
+				executor: &ExecutorMock{},
+			}).Prepare(context.Background(), fakerenderer.New())
+			assert.Error(t, err)
+		})
+		t.Run("and-stopped", func(t *testing.T) {
+			t.Parallel()
+			_, err := (&Preparer{
+				Name:     "test1",
+				State:    "stopped",
+				Mask:     &yes,
+				executor: &ExecutorMock{},
+			}).Prepare(context.Background(), fakerenderer.New())
+			assert.NoError(t, err)
+		})
+	})
+	t.Run("when-healthcheck", func(t *testing.T) {
+		t.Parallel()
+		t.Run("alone", func(t *testing.T) {
+			t.Parallel()
+			res, err := (&Preparer{
+				Name:        "test1",
+				Healthcheck: true,
+				executor:    &ExecutorMock{},
+			}).Prepare(context.Background(), fakerenderer.New())
+			require.NoError(t, err)
+			assert.True(t, res.(*Resource).Healthcheck)
+		})
+		t.Run("with-state", func(t *testing.T) {
+			t.Parallel()
+			_, err := (&Preparer{
+				Name:        "test1",
+				State:       "running",
+				Healthcheck: true,
+				executor:    &ExecutorMock{},
+			}).Prepare(context.Background(), fakerenderer.New())
+			assert.Error(t, err)
+		})
+	})
 }
@@ -41,6 +41,30 @@ type Resource struct {
 	// This field is set to true if the reload flag was configured by the user.
 	Reload bool `export:"reload"`
 
+	// Whether the unit should be enabled.  This field is only used if the
+	// enable flag was configured by the user.
+	Enable bool `export:"enable"`
+
+	// Whether the unit should be masked.  This field is only used if the mask
+	// flag was configured by the user.
+	Mask bool `export:"mask"`
+
+	// The enablement state of the unit file, e.g. `enabled`, `disabled`,
+	// `static`, or `masked`.  This field will be empty unless the enable or mask
+	// flags were configured by the user.
+	UnitFileState string `export:"unitfilestate"`
+
+	// This field is set to true if the unit is only checked for health.
+	Healthcheck bool `export:"healthcheck"`
+
+	// The time a timer unit will next elapse, in RFC3339 format.  This field
+	// will be empty unless the unit is a timer with a calendar trigger.
+	NextElapse string `export:"next_elapse"`
+
+	// The time a timer unit last triggered, in RFC3339 format.  This field will
+	// be empty unless the unit is a timer that has triggered.
+	LastTrigger string `export:"last_trigger"`
+
 	// The human-readable name of a unix signal that will be sent to the process.
 	// If this is set the name will match the field set in SignalNumber.  See the
 	// man pages for `signal(3)` on BSD/Darwin or `signal(7)` on GNU Linux for a
@@ -146,8 +170,11 @@ type Resource struct {
 	ScopeProperties *ScopeTypeProperties `re-export-as:"scope_properties"`
 
 	sendSignal      bool
+	manageEnable    bool
+	manageMask      bool
 	systemdExecutor SystemdExecutor
 	hasRun          bool
+	restarted       bool
 }
 
 type response struct {
@@ -194,6 +221,21 @@ func (r *Resource) runCheck() (resource.TaskStatus, error) {
 		return nil, err
 	}
 	r.populateFromUnit(u)
+	if r.Healthcheck {
+		r.hasRun = true
+		return r.checkHealth(u), nil
+	}
+	if u.TimerProperties != nil {
+		status.AddMessage(timerMessages(u.TimerProperties)...)
+	}
+	if r.manageEnable || r.manageMask {
+		state, err := r.systemdExecutor.UnitFileState(withDefaultSuffix(r.Name))
+		if err != nil {
+			return nil, err
+		}
+		r.UnitFileState = state
+		r.checkUnitFile(status)
+	}
 	if r.sendSignal && !r.hasRun {
 		status.RaiseLevel(resource.StatusWillChange)
 		status.AddMessage(fmt.Sprintf("Sending signal `%s` to unit", r.SignalName))
@@ -205,9 +247,13 @@ func (r *Resource) runCheck() (resource.TaskStatus, error) {
 	}
 	switch r.State {
 	case "restarted":
-		status.RaiseLevel(resource.StatusWillChange)
-		status.AddMessage("Restarting unit")
-		status.AddDifference("state", u.ActiveState, "restarted", "")
+		// once the unit has been restarted the check after apply shouldn't ask
+		// for another restart
+		if !r.restarted {
+			status.RaiseLevel(resource.StatusWillChange)
+			status.AddMessage("Restarting unit")
+			status.AddDifference("state", u.ActiveState, "restarted", "")
+		}
 	case "running":
 		r.shouldStart(u, status)
 	case "stopped":
@@ -220,6 +266,9 @@ func (r *Resource) runCheck() (resource.TaskStatus, error) {
 func (r *Resource) runApply() (resource.TaskStatus, error) {
 	log.WithField("Unit Name: ", r.Name).Infof("calling runApply()....")
 	status := resource.NewStatus()
+	if r.Healthcheck {
+		return status, nil
+	}
 	tempStatus := resource.NewStatus()
 	u, err := r.systemdExecutor.QueryUnit(r.Name, false)
 	if err != nil {
@@ -232,6 +281,12 @@ func (r *Resource) runApply() (resource.TaskStatus, error) {
 		u.Name = r.Name
 	}
 
+	if r.manageEnable || r.manageMask {
+		if err := r.applyUnitFile(status); err != nil {
+			return nil, err
+		}
+	}
+
 	if r.sendSignal {
 		status.AddMessage(fmt.Sprintf("Sending signal `%s` to unit", r.SignalName))
 		r.systemdExecutor.SendSignal(u, Signal(r.SignalNumber))
@@ -256,6 +311,7 @@ func (r *Resource) runApply() (resource.TaskStatus, error) {
 		}
 	case "restarted":
 		runstateErr = r.systemdExecutor.RestartUnit(u)
+		r.restarted = runstateErr == nil
 	}
 	return status, runstateErr
 }
@@ -279,6 +335,111 @@ func (r *Resource) populateFromUnit(u *Unit) {
 	r.TimerProperties = u.TimerProperties
 	r.SliceProperties = u.SliceProperties
 	r.ScopeProperties = u.ScopeProperties
+	if u.TimerProperties != nil {
+		r.NextElapse = formatUSec(u.TimerProperties.NextElapseUSecRealtime)
+		r.LastTrigger = formatUSec(u.TimerProperties.LastTriggerUSec)
+	}
+}
+
+// unitFileChanges are the changes to the unit file needed to reach the
+// configured enable and mask flags, in the order they are applied
+type unitFileChanges struct {
+	blocked bool
+	unmask  bool
+	disable bool
+	enable  bool
+	mask    bool
+}
+
+func (c unitFileChanges) any() bool {
+	return c.unmask || c.disable || c.enable || c.mask
+}
+
+func (r *Resource) checkUnitFile(st *resource.Status) unitFileChanges {
+	var changes unitFileChanges
+	masked := r.UnitFileState == "masked"
+
+	if r.manageMask {
+		if r.Mask && !masked {
+			changes.mask = true
+			st.AddDifference("mask", describeFileState(r.UnitFileState), "masked", "")
+		} else if !r.Mask && masked {
+			changes.unmask = true
+			masked = false
+			st.AddDifference("mask", "masked", "unmasked", "")
+		}
+	}
+
+	if r.manageEnable {
+		if r.Enable && masked {
+			st.AddMessage("unit is masked and will not be enabled (set mask to false to do this)")
+			st.RaiseLevel(resource.StatusCantChange)
+			return unitFileChanges{blocked: true}
+		}
+		if r.Enable && !satisfiesEnable(r.UnitFileState) {
+			changes.enable = true
+			st.AddDifference("enable", describeFileState(r.UnitFileState), "enabled", "")
+		} else if !r.Enable && r.UnitFileState == "enabled" {
+			changes.disable = true
+			st.AddDifference("enable", "enabled", "disabled", "")
+		}
+	}
+
+	if changes.any() {
+		st.RaiseLevel(resource.StatusWillChange)
+	}
+	return changes
+}
+
+func (r *Resource) applyUnitFile(st *resource.Status) error {
+	name := withDefaultSuffix(r.Name)
+	state, err := r.systemdExecutor.UnitFileState(name)
+	if err != nil {
+		return err
+	}
+	r.UnitFileState = state
+
+	changes := r.checkUnitFile(st)
+	if changes.blocked {
+		return fmt.Errorf("%s: cannot enable a masked unit", name)
+	}
+
+	if changes.unmask {
+		if err := r.systemdExecutor.UnmaskUnitFile(name); err != nil {
+			return err
+		}
+
+		// the unit's state underneath the mask decides whether it still needs
+		// to be enabled or disabled
+		if r.manageEnable {
+			state, err := r.systemdExecutor.UnitFileState(name)
+			if err != nil {
+				return err
+			}
+			changes.enable = r.Enable && !satisfiesEnable(state)
+			changes.disable = !r.Enable && state == "enabled"
+		}
+	}
+	if changes.disable {
+		if err := r.systemdExecutor.DisableUnitFile(name); err != nil {
+			return err
+		}
+	}
+	if changes.enable {
+		if err := r.systemdExecutor.EnableUnitFile(name); err != nil {
+			return err
+		}
+	}
+	if changes.mask {
+		if err := r.systemdExecutor.MaskUnitFile(name); err != nil {
+			return err
+		}
+	}
+
+	if changes.any() {
+		return r.systemdExecutor.DaemonReload()
+	}
+	return nil
 }
 
 func (r *Resource) shouldStart(u *Unit, st *resource.Status) bool {
@@ -21,6 +21,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/resource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
@@ -661,6 +662,21 @@ func TestCheckAfterApply(t *testing.T) {
 		assert.NoError(t, err)
 		assert.False(t, status.HasChanges())
 	})
+
+	t.Run("when-restarted", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{State: "restarted"}
+		u := &Unit{ActiveState: "active"}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(u, nil)
+		e.On("RestartUnit", any).Return(nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		status, err = r.Apply(context.Background())
+		status, err = r.Check(context.Background(), fakerenderer.New())
+		assert.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
 }
 
 // TestHandlesContext runs a test
@@ -723,3 +739,194 @@ func TestHandlesContext(t *testing.T) {
 		})
 	})
 }
+
+func TestCheckUnitFile(t *testing.T) {
+	t.Parallel()
+	t.Run("enable", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo", Enable: true, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "active"}, nil)
+		e.On("UnitFileState", "foo.service").Return("disabled", nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.True(t, status.HasChanges())
+		if diff, ok := status.Diffs()["enable"]; assert.True(t, ok) {
+			assert.Equal(t, "disabled", diff.Original())
+			assert.Equal(t, "enabled", diff.Current())
+		}
+		assert.Equal(t, "disabled", r.UnitFileState)
+	})
+	t.Run("already-enabled", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: true, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "active"}, nil)
+		e.On("UnitFileState", "foo.service").Return("enabled", nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+	t.Run("static", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: true, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "active"}, nil)
+		e.On("UnitFileState", "foo.service").Return("static", nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.False(t, status.HasChanges())
+	})
+	t.Run("disable", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: false, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "active"}, nil)
+		e.On("UnitFileState", "foo.service").Return("enabled", nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		if diff, ok := status.Diffs()["enable"]; assert.True(t, ok) {
+			assert.Equal(t, "disabled", diff.Current())
+		}
+	})
+	t.Run("enable-when-masked", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: true, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("masked", nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+	})
+	t.Run("unmask-and-enable", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: true, manageEnable: true, manageMask: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("masked", nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		assert.Equal(t, resource.StatusWillChange, status.StatusCode())
+		assert.Contains(t, status.Diffs(), "mask")
+		assert.Contains(t, status.Diffs(), "enable")
+	})
+	t.Run("mask", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Mask: true, manageMask: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("static", nil)
+		status, err := r.Check(context.Background(), fakerenderer.New())
+		require.NoError(t, err)
+		if diff, ok := status.Diffs()["mask"]; assert.True(t, ok) {
+			assert.Equal(t, "static", diff.Original())
+			assert.Equal(t, "masked", diff.Current())
+		}
+	})
+	t.Run("unit-file-state-returns-error", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Mask: true, manageMask: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		expected := errors.New("error1")
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("", expected)
+		_, err := r.Check(context.Background(), fakerenderer.New())
+		assert.Equal(t, expected, err)
+	})
+}
+
+func TestApplyUnitFile(t *testing.T) {
+	t.Parallel()
+	t.Run("unmask-and-enable", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", State: "running", Enable: true, manageEnable: true, manageMask: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("masked", nil)
+		e.On("UnmaskUnitFile", "foo.service").Return(nil)
+		e.On("EnableUnitFile", "foo.service").Return(nil)
+		e.On("DaemonReload").Return(nil)
+		e.On("StartUnit", any).Return(nil)
+		_, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertCalled(t, "UnmaskUnitFile", "foo.service")
+		e.AssertCalled(t, "EnableUnitFile", "foo.service")
+		e.AssertCalled(t, "DaemonReload")
+		e.AssertCalled(t, "StartUnit", any)
+	})
+	t.Run("unmask-and-disable", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", manageEnable: true, manageMask: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("masked", nil).Once()
+		e.On("UnitFileState", "foo.service").Return("enabled", nil)
+		e.On("UnmaskUnitFile", "foo.service").Return(nil)
+		e.On("DisableUnitFile", "foo.service").Return(nil)
+		e.On("DaemonReload").Return(nil)
+		_, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertCalled(t, "UnmaskUnitFile", "foo.service")
+		e.AssertCalled(t, "DisableUnitFile", "foo.service")
+	})
+	t.Run("disable-and-mask", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Mask: true, manageEnable: true, manageMask: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("enabled", nil)
+		e.On("DisableUnitFile", "foo.service").Return(nil)
+		e.On("MaskUnitFile", "foo.service").Return(nil)
+		e.On("DaemonReload").Return(nil)
+		_, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertCalled(t, "DisableUnitFile", "foo.service")
+		e.AssertCalled(t, "MaskUnitFile", "foo.service")
+	})
+	t.Run("no-changes", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: true, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "active"}, nil)
+		e.On("UnitFileState", "foo.service").Return("enabled", nil)
+		_, err := r.Apply(context.Background())
+		require.NoError(t, err)
+		e.AssertNotCalled(t, "DaemonReload")
+	})
+	t.Run("enable-when-masked", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: true, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("masked", nil)
+		_, err := r.Apply(context.Background())
+		assert.Error(t, err)
+		e.AssertNotCalled(t, "EnableUnitFile", any)
+	})
+	t.Run("enable-returns-error", func(t *testing.T) {
+		t.Parallel()
+		r := &Resource{Name: "foo.service", Enable: true, manageEnable: true}
+		e := &ExecutorMock{}
+		r.systemdExecutor = e
+		expected := errors.New("error1")
+		e.On("QueryUnit", any, any).Return(&Unit{ActiveState: "inactive"}, nil)
+		e.On("UnitFileState", "foo.service").Return("disabled", nil)
+		e.On("EnableUnitFile", "foo.service").Return(expected)
+		_, err := r.Apply(context.Background())
+		assert.Equal(t, expected, err)
+	})
+}
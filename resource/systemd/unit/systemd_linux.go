@@ -18,6 +18,7 @@ package unit
 
 import (
 	"fmt"
+	"path"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/coreos/go-systemd/dbus"
@@ -97,6 +98,56 @@ func (l LinuxExecutor) SendSignal(u *Unit, signal Signal) {
 	l.dbusConn.KillUnit(u.Name, int32(signal))
 }
 
+// DaemonReload will use dbus to reload all unit files
+func (l LinuxExecutor) DaemonReload() error {
+	return l.dbusConn.Reload()
+}
+
+// UnitFileState will use dbus to get the enablement state of a unit file
+func (l LinuxExecutor) UnitFileState(unitName string) (string, error) {
+	files, err := l.dbusConn.ListUnitFiles()
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot list unit files")
+	}
+
+	for _, f := range files {
+		if path.Base(f.Path) == unitName {
+			return f.Type, nil
+		}
+	}
+	return "", nil
+}
+
+// EnableUnitFile will use dbus to enable a unit file
+func (l LinuxExecutor) EnableUnitFile(unitName string) error {
+	hasInstall, _, err := l.dbusConn.EnableUnitFiles([]string{unitName}, false, false)
+	if err != nil {
+		return errors.Wrapf(err, "enabling %s", unitName)
+	}
+	if !hasInstall {
+		return fmt.Errorf("%s: cannot enable a unit without an [Install] section", unitName)
+	}
+	return nil
+}
+
+// DisableUnitFile will use dbus to disable a unit file
+func (l LinuxExecutor) DisableUnitFile(unitName string) error {
+	_, err := l.dbusConn.DisableUnitFiles([]string{unitName}, false)
+	return errors.Wrapf(err, "disabling %s", unitName)
+}
+
+// MaskUnitFile will use dbus to mask a unit file
+func (l LinuxExecutor) MaskUnitFile(unitName string) error {
+	_, err := l.dbusConn.MaskUnitFiles([]string{unitName}, false, false)
+	return errors.Wrapf(err, "masking %s", unitName)
+}
+
+// UnmaskUnitFile will use dbus to unmask a unit file
+func (l LinuxExecutor) UnmaskUnitFile(unitName string) error {
+	_, err := l.dbusConn.UnmaskUnitFiles([]string{unitName}, false)
+	return errors.Wrapf(err, "unmasking %s", unitName)
+}
+
 func runDbusCommand(f func(string, string, chan<- string) (int, error), name, mode, operation string) error {
 	ch := make(chan string)
 	defer close(ch)
@@ -878,3 +878,89 @@ func TestSendSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitFileState(t *testing.T) {
+	t.Parallel()
+	t.Run("when-unit-file-exists", func(t *testing.T) {
+		t.Parallel()
+		m := &DbusMock{}
+		m.On("ListUnitFiles").Return([]dbus.UnitFile{
+			{Path: "/lib/systemd/system/other.service", Type: "static"},
+			{Path: "/etc/systemd/system/foo.service", Type: "masked"},
+		}, nil)
+		l := LinuxExecutor{m}
+		actual, err := l.UnitFileState("foo.service")
+		require.NoError(t, err)
+		assert.Equal(t, "masked", actual)
+	})
+	t.Run("when-unit-file-not-exists", func(t *testing.T) {
+		t.Parallel()
+		m := &DbusMock{}
+		m.On("ListUnitFiles").Return([]dbus.UnitFile{}, nil)
+		l := LinuxExecutor{m}
+		actual, err := l.UnitFileState("foo.service")
+		require.NoError(t, err)
+		assert.Equal(t, "", actual)
+	})
+	t.Run("when-list-unit-files-error", func(t *testing.T) {
+		t.Parallel()
+		expected := errors.New("err1")
+		m := &DbusMock{}
+		m.On("ListUnitFiles").Return([]dbus.UnitFile{}, expected)
+		l := LinuxExecutor{m}
+		_, err := l.UnitFileState("foo.service")
+		assert.Equal(t, expected, errors.Cause(err))
+	})
+}
+
+func TestEnableUnitFile(t *testing.T) {
+	t.Parallel()
+	t.Run("when-has-install", func(t *testing.T) {
+		t.Parallel()
+		m := &DbusMock{}
+		m.On("EnableUnitFiles", []string{"foo.service"}, false, false).Return(true, nil)
+		l := LinuxExecutor{m}
+		assert.NoError(t, l.EnableUnitFile("foo.service"))
+	})
+	t.Run("when-no-install", func(t *testing.T) {
+		t.Parallel()
+		m := &DbusMock{}
+		m.On("EnableUnitFiles", any, any, any).Return(false, nil)
+		l := LinuxExecutor{m}
+		assert.Error(t, l.EnableUnitFile("foo.service"))
+	})
+	t.Run("when-error", func(t *testing.T) {
+		t.Parallel()
+		expected := errors.New("err1")
+		m := &DbusMock{}
+		m.On("EnableUnitFiles", any, any, any).Return(false, expected)
+		l := LinuxExecutor{m}
+		assert.Equal(t, expected, errors.Cause(l.EnableUnitFile("foo.service")))
+	})
+}
+
+func TestMaskUnitFile(t *testing.T) {
+	t.Parallel()
+	t.Run("mask", func(t *testing.T) {
+		t.Parallel()
+		m := &DbusMock{}
+		m.On("MaskUnitFiles", []string{"foo.service"}, false, false).Return(nil)
+		l := LinuxExecutor{m}
+		assert.NoError(t, l.MaskUnitFile("foo.service"))
+	})
+	t.Run("unmask", func(t *testing.T) {
+		t.Parallel()
+		m := &DbusMock{}
+		m.On("UnmaskUnitFiles", []string{"foo.service"}, false).Return(nil)
+		l := LinuxExecutor{m}
+		assert.NoError(t, l.UnmaskUnitFile("foo.service"))
+	})
+	t.Run("mask-returns-error", func(t *testing.T) {
+		t.Parallel()
+		expected := errors.New("err1")
+		m := &DbusMock{}
+		m.On("MaskUnitFiles", any, any, any).Return(expected)
+		l := LinuxExecutor{m}
+		assert.Equal(t, expected, errors.Cause(l.MaskUnitFile("foo.service")))
+	})
+}
@@ -64,3 +64,33 @@ func (s StubExecutor) SendSignal(*Unit, Signal) {
 func realExecutor() (SystemdExecutor, error) {
 	return StubExecutor{}, ErrUnsupportedOS
 }
+
+// DaemonReload is a stub
+func (s StubExecutor) DaemonReload() error {
+	return ErrUnsupportedOS
+}
+
+// UnitFileState is a stub
+func (s StubExecutor) UnitFileState(string) (string, error) {
+	return "", ErrUnsupportedOS
+}
+
+// EnableUnitFile is a stub
+func (s StubExecutor) EnableUnitFile(string) error {
+	return ErrUnsupportedOS
+}
+
+// DisableUnitFile is a stub
+func (s StubExecutor) DisableUnitFile(string) error {
+	return ErrUnsupportedOS
+}
+
+// MaskUnitFile is a stub
+func (s StubExecutor) MaskUnitFile(string) error {
+	return ErrUnsupportedOS
+}
+
+// UnmaskUnitFile is a stub
+func (s StubExecutor) UnmaskUnitFile(string) error {
+	return ErrUnsupportedOS
+}
@@ -103,6 +103,15 @@ func UnitTypeFromName(s string) UnitType {
 	return UnitTypeUnknown
 }
 
+// withDefaultSuffix adds the service suffix to a unit name without a
+// recognized unit type, the same way systemctl does
+func withDefaultSuffix(name string) string {
+	if UnitTypeFromName(name) == UnitTypeUnknown {
+		return name + "." + UnitTypeService.Suffix()
+	}
+	return name
+}
+
 // Suffix is the dual of UnitTypeFromName and generates the correct unit file
 // suffix based on the type
 func (u UnitType) Suffix() string {
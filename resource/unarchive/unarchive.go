@@ -30,6 +30,7 @@ import (
 	"syscall"
 
 	"github.com/asteris-llc/converge/resource"
+	"github.com/asteris-llc/converge/resource/file/backup"
 	"github.com/asteris-llc/converge/resource/file/fetch"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
@@ -73,6 +74,18 @@ type Unarchive struct {
 	// destination if it already exists
 	Force bool `export:"force"`
 
+	// whether replaced files will be backed up
+	Backup bool `export:"backup"`
+
+	// the directory backups are made in, if not next to the destination
+	BackupDir string `export:"backupdir"`
+
+	// the number of backups that are kept, if limited
+	BackupKeep int `export:"backupkeep"`
+
+	// the location of the backup directory made during apply, if any
+	BackupPath string `export:"backuppath"`
+
 	// fetch is used to fetch the file to be unarchived
 	fetch fetch.Fetch
 
@@ -207,6 +220,16 @@ func (u *Unarchive) applyWithContext(ctx context.Context) (resource.TaskStatus,
 		}
 	}
 
+	if u.Backup {
+		if err := u.backup(); err != nil {
+			status.RaiseLevel(resource.StatusFatal)
+			return status, err
+		}
+		if u.BackupPath != "" {
+			status.AddMessage("backed up to " + u.BackupPath)
+		}
+	}
+
 	err = u.copyToFinalDest()
 	if err != nil {
 		status.RaiseLevel(resource.StatusFatal)
@@ -390,6 +413,26 @@ func (u *Unarchive) evaluateDuplicates() error {
 	return nil
 }
 
+// backup copies the files in the destination that will be replaced by the
+// unarchived files to a backup directory
+func (u *Unarchive) backup() error {
+	var files []string
+	for _, file := range u.fetchContents {
+		if name := strings.TrimPrefix(file, u.fetchDir.Name()); name != "" {
+			files = append(files, name)
+		}
+	}
+
+	opts := backup.Options{Dir: u.BackupDir, Keep: u.BackupKeep}
+	path, err := opts.CreateTree(u.Destination, files)
+	if err != nil {
+		return errors.Wrapf(err, "error backing up files in %q", u.Destination)
+	}
+
+	u.BackupPath = path
+	return nil
+}
+
 // copyToFinalDest copies the fetched and unarchived files from their temporary
 // directory to the final destination
 func (u *Unarchive) copyToFinalDest() error {
@@ -0,0 +1,174 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// DefaultDiffContext is the number of unchanged lines shown around each
+	// change in a unified diff
+	DefaultDiffContext = 3
+
+	// DefaultDiffMaxLines is the maximum number of lines in a unified diff
+	// before it is truncated
+	DefaultDiffMaxLines = 200
+
+	noNewline = `\ No newline at end of file`
+)
+
+// UnifiedDiffer is implemented by diffs that can be shown as a line-based
+// unified diff
+type UnifiedDiffer interface {
+	Diff
+
+	// Unified returns the lines of the unified diff, including hunk headers
+	Unified() []string
+}
+
+// UnifiedDiff is a Diff of multi-line text that is shown as a line-based
+// unified diff
+type UnifiedDiff struct {
+	// Values are the original and current text
+	Values [2]string
+
+	// Placeholder is shown as the original value when it is empty, for
+	// example when a file does not exist yet
+	Placeholder string
+
+	// Context is the number of unchanged lines shown around each change
+	Context int
+
+	// MaxLines is the maximum number of lines in the diff. Diffs longer than
+	// this are truncated. Zero means no limit.
+	MaxLines int
+}
+
+// NewUnifiedDiff returns a UnifiedDiff with the default context and
+// truncation settings
+func NewUnifiedDiff(original, current string) *UnifiedDiff {
+	return &UnifiedDiff{
+		Values:   [2]string{original, current},
+		Context:  DefaultDiffContext,
+		MaxLines: DefaultDiffMaxLines,
+	}
+}
+
+// Original returns the original text
+func (d *UnifiedDiff) Original() string {
+	if d.Values[0] == "" {
+		return d.Placeholder
+	}
+	return d.Values[0]
+}
+
+// Current returns the current text
+func (d *UnifiedDiff) Current() string {
+	return d.Values[1]
+}
+
+// Changes is true if the original and current text differ
+func (d *UnifiedDiff) Changes() bool {
+	return d.Values[0] != d.Values[1]
+}
+
+// Unified returns the lines of the unified diff between the original and
+// current text. Lines are prefixed with " ", "-", or "+", and each group of
+// changes starts with a "@@" hunk header.
+func (d *UnifiedDiff) Unified() []string {
+	if !d.Changes() {
+		return nil
+	}
+
+	// lines keep their newlines so that a change to only the trailing newline
+	// still shows up in the diff
+	a := strings.SplitAfter(d.Values[0], "\n")
+	b := strings.SplitAfter(d.Values[1], "\n")
+	if a[len(a)-1] == "" {
+		a = a[:len(a)-1]
+	}
+	if b[len(b)-1] == "" {
+		b = b[:len(b)-1]
+	}
+
+	var out []string
+	matcher := difflib.NewMatcher(a, b)
+	for _, group := range matcher.GetGroupedOpCodes(d.Context) {
+		first, last := group[0], group[len(group)-1]
+		out = append(out, fmt.Sprintf(
+			"@@ -%s +%s @@",
+			hunkRange(first.I1, last.I2),
+			hunkRange(first.J1, last.J2),
+		))
+
+		for _, op := range group {
+			if op.Tag == 'e' {
+				out = appendDiffLines(out, " ", a[op.I1:op.I2])
+				continue
+			}
+			if op.Tag == 'r' || op.Tag == 'd' {
+				out = appendDiffLines(out, "-", a[op.I1:op.I2])
+			}
+			if op.Tag == 'r' || op.Tag == 'i' {
+				out = appendDiffLines(out, "+", b[op.J1:op.J2])
+			}
+		}
+	}
+
+	if d.MaxLines > 0 && len(out) > d.MaxLines {
+		omitted := len(out) - d.MaxLines
+		out = append(out[:d.MaxLines], fmt.Sprintf("... (%d more lines)", omitted))
+	}
+
+	return out
+}
+
+// MarshalJSON includes the unified diff in the JSON representation
+func (d *UnifiedDiff) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Original string   `json:"original"`
+		Current  string   `json:"current"`
+		Changes  bool     `json:"changes"`
+		Unified  []string `json:"unified"`
+	}{d.Original(), d.Current(), d.Changes(), d.Unified()})
+}
+
+func appendDiffLines(out []string, prefix string, lines []string) []string {
+	for _, line := range lines {
+		out = append(out, prefix+strings.TrimSuffix(line, "\n"))
+		if !strings.HasSuffix(line, "\n") {
+			out = append(out, noNewline)
+		}
+	}
+	return out
+}
+
+// hunkRange formats a range of lines for a hunk header
+func hunkRange(start, stop int) string {
+	length := stop - start
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	if length == 0 {
+		// empty ranges begin at the line before the range
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,124 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnifiedDiffInterface tests that UnifiedDiff implements the right
+// interfaces
+func TestUnifiedDiffInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.Diff)(nil), new(resource.UnifiedDiff))
+	assert.Implements(t, (*resource.UnifiedDiffer)(nil), new(resource.UnifiedDiff))
+}
+
+// TestUnifiedDiff tests generating unified diffs
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no changes", func(t *testing.T) {
+		diff := resource.NewUnifiedDiff("a\nb\n", "a\nb\n")
+
+		assert.False(t, diff.Changes())
+		assert.Empty(t, diff.Unified())
+	})
+
+	t.Run("changed line", func(t *testing.T) {
+		diff := resource.NewUnifiedDiff("a\nb\nc\n", "a\nx\nc\n")
+
+		assert.True(t, diff.Changes())
+		assert.Equal(
+			t,
+			[]string{"@@ -1,3 +1,3 @@", " a", "-b", "+x", " c"},
+			diff.Unified(),
+		)
+	})
+
+	t.Run("new file", func(t *testing.T) {
+		diff := resource.NewUnifiedDiff("", "a\nb\n")
+		diff.Placeholder = "<file-missing>"
+
+		assert.Equal(t, "<file-missing>", diff.Original())
+		assert.Equal(t, []string{"@@ -0,0 +1,2 @@", "+a", "+b"}, diff.Unified())
+	})
+
+	t.Run("trailing newline", func(t *testing.T) {
+		diff := resource.NewUnifiedDiff("a\n", "a")
+
+		assert.Equal(
+			t,
+			[]string{"@@ -1 +1 @@", "-a", "+a", `\ No newline at end of file`},
+			diff.Unified(),
+		)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		var lines []string
+		for i := 0; i < 20; i++ {
+			lines = append(lines, fmt.Sprint(i))
+		}
+		original := strings.Join(lines, "\n") + "\n"
+		lines[10] = "ten"
+		current := strings.Join(lines, "\n") + "\n"
+
+		diff := resource.NewUnifiedDiff(original, current)
+		diff.Context = 1
+
+		assert.Equal(
+			t,
+			[]string{"@@ -10,3 +10,3 @@", " 9", "-10", "+ten", " 11"},
+			diff.Unified(),
+		)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		diff := resource.NewUnifiedDiff("a\nb\nc\n", "x\ny\nz\n")
+		diff.MaxLines = 3
+
+		assert.Equal(
+			t,
+			[]string{"@@ -1,3 +1,3 @@", "-a", "-b", "... (4 more lines)"},
+			diff.Unified(),
+		)
+	})
+}
+
+// TestUnifiedDiffJSON tests that the JSON representation of a UnifiedDiff
+// includes the unified diff
+func TestUnifiedDiffJSON(t *testing.T) {
+	t.Parallel()
+
+	out, err := json.Marshal(resource.NewUnifiedDiff("a\n", "b\n"))
+	require.NoError(t, err)
+
+	var decoded struct {
+		Changes bool
+		Unified []string
+	}
+	require.NoError(t, json.Unmarshal(out, &decoded))
+
+	assert.True(t, decoded.Changes)
+	assert.Equal(t, []string{"@@ -1 +1 @@", "-a", "+b"}, decoded.Unified)
+}
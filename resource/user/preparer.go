@@ -17,6 +17,8 @@ package user
 import (
 	"fmt"
 	"math"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/asteris-llc/converge/load/registry"
@@ -79,6 +81,44 @@ type Preparer struct {
 	// string (no expiry) will be used by default.
 	Expiry time.Time `hcl:"expiry"`
 
+	// Shell is the path to the user's login shell.
+	// This field can be indicated when adding or modifying a user.
+	Shell string `hcl:"shell" nonempty:"true"`
+
+	// Groups are the supplementary groups the user belongs to. The groups must
+	// already exist.
+	// This field can be indicated when adding or modifying a user.
+	Groups []string `hcl:"groups"`
+
+	// GroupsMode is how Groups are applied when modifying a user. In append
+	// mode, the user is added to the groups and keeps any others it belongs to.
+	// In exact mode, the user is removed from any groups that are not listed.
+	// The default value is append.
+	GroupsMode GroupsMode `hcl:"groups_mode" valid_values:"append,exact"`
+
+	// PasswordHash is the encrypted password of the user, as it appears in
+	// /etc/shadow. It can be generated with `mkpasswd -m sha-512`. The hash is
+	// sensitive, so it is masked wherever it would appear in the output or
+	// logs, and it is set with `chpasswd -e` so that it isn't on a command line.
+	// This field can be indicated when adding or modifying a user.
+	PasswordHash string `hcl:"password_hash" nonempty:"true" sensitive:"true"`
+
+	// System when set to true will create a system account. The uid is chosen
+	// from the range of system uids, and no aging information is set.
+	// This field is only used when adding a user.
+	System bool `hcl:"system"`
+
+	// AuthorizedKeys are SSH public keys written to the user's
+	// ~/.ssh/authorized_keys. The file and directory are owned by the user and
+	// only accessible by the user. Neither may be a symbolic link.
+	// This field can be indicated when adding or modifying a user.
+	AuthorizedKeys []string `hcl:"authorized_keys"`
+
+	// ExclusiveKeys when set to true will remove any authorized keys that are
+	// not listed in AuthorizedKeys. Otherwise the listed keys are added to the
+	// existing ones.
+	ExclusiveKeys bool `hcl:"authorized_keys_exclusive"`
+
 	// State is whether the user should be present.
 	// The default value is present.
 	State State `hcl:"state" valid_values:"present,absent"`
@@ -104,10 +144,34 @@ func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resou
 		return nil, fmt.Errorf("user \"home_dir\" parameter required with \"move_dir\" parameter")
 	}
 
+	if p.Shell != "" && !filepath.IsAbs(p.Shell) {
+		return nil, fmt.Errorf("user \"shell\" parameter must be an absolute path")
+	}
+
+	for _, grp := range p.Groups {
+		if grp == "" || strings.ContainsAny(grp, ", ") {
+			return nil, fmt.Errorf("user \"groups\" parameter contains invalid group name %q", grp)
+		}
+	}
+
+	if strings.ContainsAny(p.PasswordHash, ":\n") {
+		return nil, fmt.Errorf("user \"password_hash\" parameter cannot contain colons or newlines")
+	}
+
+	for _, key := range p.AuthorizedKeys {
+		if strings.Contains(strings.TrimSpace(key), "\n") {
+			return nil, fmt.Errorf("user \"authorized_keys\" parameter must have one key per item")
+		}
+	}
+
 	if p.State == "" {
 		p.State = StatePresent
 	}
 
+	if p.GroupsMode == "" {
+		p.GroupsMode = GroupsAppend
+	}
+
 	usr := NewUser(new(System))
 	usr.Username = p.Username
 	usr.NewUsername = p.NewUsername
@@ -119,6 +183,13 @@ func (p *Preparer) Prepare(ctx context.Context, render resource.Renderer) (resou
 	usr.MoveDir = p.MoveDir
 	usr.State = p.State
 	usr.Expiry = p.Expiry
+	usr.Shell = p.Shell
+	usr.Groups = p.Groups
+	usr.GroupsMode = p.GroupsMode
+	usr.PasswordHash = p.PasswordHash
+	usr.System = p.System
+	usr.AuthorizedKeys = p.AuthorizedKeys
+	usr.ExclusiveKeys = p.ExclusiveKeys
 
 	if p.UID != nil {
 		usr.UID = fmt.Sprintf("%v", *p.UID)
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/asteris-llc/converge/helpers/fakerenderer"
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/resource/user"
 	"github.com/stretchr/testify/assert"
@@ -154,6 +155,46 @@ func TestPrepare(t *testing.T) {
 
 			assert.NoError(t, err)
 		})
+
+		t.Run("shell, groups, password_hash and authorized_keys", func(t *testing.T) {
+			p := user.Preparer{
+				Username:       "test",
+				Shell:          "/bin/bash",
+				Groups:         []string{"wheel", "docker"},
+				PasswordHash:   "$6$salt$hash",
+				System:         true,
+				AuthorizedKeys: []string{"ssh-ed25519 AAAA test@example.com"},
+				ExclusiveKeys:  true,
+			}
+			task, err := p.Prepare(context.Background(), &fr)
+
+			require.NoError(t, err)
+			usr := task.(*user.User)
+			assert.Equal(t, "/bin/bash", usr.Shell)
+			assert.Equal(t, []string{"wheel", "docker"}, usr.Groups)
+			assert.Equal(t, user.GroupsAppend, usr.GroupsMode)
+			assert.Equal(t, "$6$salt$hash", usr.PasswordHash)
+			assert.True(t, usr.System)
+			assert.Equal(t, []string{"ssh-ed25519 AAAA test@example.com"}, usr.AuthorizedKeys)
+			assert.True(t, usr.ExclusiveKeys)
+		})
+	})
+
+	// a literal hash is registered as sensitive, not only one interpolated
+	// from a sensitive param
+	t.Run("password_hash is sensitive", func(t *testing.T) {
+		prep := &resource.Preparer{
+			Source:      map[string]interface{}{"username": "test", "password_hash": "$6$salt$literal-hash"},
+			Destination: new(user.Preparer),
+		}
+
+		redactor := redact.New()
+		ctx := redact.WithRedactor(context.Background(), redactor)
+
+		_, err := prep.Prepare(ctx, &fr)
+		require.NoError(t, err)
+
+		assert.Equal(t, "error: "+redact.Mask, redactor.String("error: $6$salt$literal-hash"))
 	})
 
 	t.Run("invalid", func(t *testing.T) {
@@ -184,5 +225,33 @@ func TestPrepare(t *testing.T) {
 
 			assert.EqualError(t, err, fmt.Sprintf("user \"home_dir\" parameter required with \"move_dir\" parameter"))
 		})
+
+		t.Run("relative shell", func(t *testing.T) {
+			p := user.Preparer{Username: "test", Shell: "bash"}
+			_, err := p.Prepare(context.Background(), &fr)
+
+			assert.EqualError(t, err, "user \"shell\" parameter must be an absolute path")
+		})
+
+		t.Run("invalid group name", func(t *testing.T) {
+			p := user.Preparer{Username: "test", Groups: []string{"wheel,docker"}}
+			_, err := p.Prepare(context.Background(), &fr)
+
+			assert.EqualError(t, err, "user \"groups\" parameter contains invalid group name \"wheel,docker\"")
+		})
+
+		t.Run("invalid password_hash", func(t *testing.T) {
+			p := user.Preparer{Username: "test", PasswordHash: "a:b"}
+			_, err := p.Prepare(context.Background(), &fr)
+
+			assert.EqualError(t, err, "user \"password_hash\" parameter cannot contain colons or newlines")
+		})
+
+		t.Run("several keys in one item", func(t *testing.T) {
+			p := user.Preparer{Username: "test", AuthorizedKeys: []string{"ssh-rsa AAAA a\nssh-rsa BBBB b"}}
+			_, err := p.Prepare(context.Background(), &fr)
+
+			assert.EqualError(t, err, "user \"authorized_keys\" parameter must have one key per item")
+		})
 	})
 }
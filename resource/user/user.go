@@ -17,6 +17,8 @@ package user
 import (
 	"fmt"
 	"os/user"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/asteris-llc/converge/resource"
@@ -41,6 +43,19 @@ const (
 	MaxTime = "2038-01-19"
 )
 
+// GroupsMode type for User
+type GroupsMode string
+
+const (
+	// GroupsAppend indicates the user should be added to the supplementary
+	// groups, keeping any others it belongs to
+	GroupsAppend GroupsMode = "append"
+
+	// GroupsExact indicates the user should belong to exactly the supplementary
+	// groups
+	GroupsExact GroupsMode = "exact"
+)
+
 // User manages user users
 type User struct {
 
@@ -77,6 +92,28 @@ type User struct {
 	// the date the user account will be disabled
 	Expiry time.Time `export:"expiry"`
 
+	// the login shell
+	Shell string `export:"shell"`
+
+	// the supplementary groups
+	Groups []string `export:"groups"`
+
+	// whether the supplementary groups are appended to or replace the current
+	// ones
+	GroupsMode GroupsMode `export:"groupsmode"`
+
+	// the encrypted password
+	PasswordHash string
+
+	// if the user should be created as a system account
+	System bool `export:"system"`
+
+	// the authorized SSH keys
+	AuthorizedKeys []string `export:"authorizedkeys"`
+
+	// if keys that are not listed should be removed
+	ExclusiveKeys bool `export:"exclusivekeys"`
+
 	// configured the user state
 	State State `export:"state"`
 
@@ -93,18 +130,40 @@ type AddUserOptions struct {
 	SkelDir    string
 	Directory  string
 	Expiry     string
+	Shell      string
+	Groups     []string
+	Password   string
+	System     bool
+
+	// AuthorizedKeys are written after the user is added
+	AuthorizedKeys []string
 }
 
 // ModUserOptions are the options specified in the configuration to be used
 // when modifying a user
 type ModUserOptions struct {
-	Username  string
-	UID       string
-	Group     string
-	Comment   string
-	Directory string
-	MoveDir   bool
-	Expiry    string
+	Username     string
+	UID          string
+	Group        string
+	Comment      string
+	Directory    string
+	MoveDir      bool
+	Expiry       string
+	Shell        string
+	Groups       []string
+	AppendGroups bool
+	Password     string
+
+	// AuthorizedKeys are written after the user is modified
+	AuthorizedKeys []string
+}
+
+// hasUserChanges returns true if the user account itself has to be modified,
+// as opposed to only its authorized keys
+func (o *ModUserOptions) hasUserChanges() bool {
+	return o.Username != "" || o.UID != "" || o.Group != "" || o.Comment != "" ||
+		o.Directory != "" || o.Expiry != "" || o.Shell != "" || o.Groups != nil ||
+		o.Password != ""
 }
 
 // SystemUtils provides system utilities for user
@@ -117,6 +176,11 @@ type SystemUtils interface {
 	LookupID(userID string) (*user.User, error)
 	LookupGroup(groupName string) (*user.Group, error)
 	LookupGroupID(groupID string) (*user.Group, error)
+	LookupUserShell(userName string) (string, error)
+	LookupUserGroups(userName string) ([]string, error)
+	LookupUserPassword(userName string) (string, error)
+	LookupAuthorizedKeys(usr *user.User) ([]string, error)
+	SetAuthorizedKeys(usr *user.User, keys []string) error
 }
 
 // ErrUnsupported is used when a system is not supported
@@ -216,13 +280,18 @@ func (u *User) Apply(context.Context) (resource.TaskStatus, error) {
 				if u.CreateHome {
 					u.createHomeDiffs(status)
 				}
+				if options.AuthorizedKeys != nil {
+					if err := u.setAuthorizedKeys(status, u.Username, options.AuthorizedKeys); err != nil {
+						return status, err
+					}
+				}
 			}
 		case userByName != nil:
 			options, err := u.DiffMod(status, userByName)
 			if err != nil {
 				return status, errors.Wrapf(err, "will not attempt to modify user %s", u.Username)
 			}
-			if resource.AnyChanges(status.Differences) {
+			if resource.AnyChanges(status.Differences) && options.hasUserChanges() {
 				err = u.system.ModUser(u.Username, options)
 				if err != nil {
 					status.RaiseLevel(resource.StatusFatal)
@@ -231,6 +300,15 @@ func (u *User) Apply(context.Context) (resource.TaskStatus, error) {
 				}
 				status.AddMessage(fmt.Sprintf("modified user %s", u.Username))
 			}
+			if options.AuthorizedKeys != nil {
+				userName := u.Username
+				if u.NewUsername != "" {
+					userName = u.NewUsername
+				}
+				if err := u.setAuthorizedKeys(status, userName, options.AuthorizedKeys); err != nil {
+					return status, err
+				}
+			}
 		}
 	case StateAbsent:
 		err := u.DiffDel(status, userByName, nameNotFound)
@@ -332,6 +410,34 @@ func (u *User) DiffAdd(status *resource.Status) (*AddUserOptions, error) {
 		status.AddDifference("expiry", "<default expiry>", options.Expiry, "")
 	}
 
+	if u.Shell != "" {
+		options.Shell = u.Shell
+		status.AddDifference("shell", "<default shell>", u.Shell, "")
+	}
+
+	if len(u.Groups) > 0 {
+		if err := u.checkGroups(status); err != nil {
+			return nil, err
+		}
+		options.Groups = u.Groups
+		status.AddDifference("groups", fmt.Sprintf("<%s>", string(StateAbsent)), strings.Join(u.Groups, ","), "")
+	}
+
+	if u.PasswordHash != "" {
+		options.Password = u.PasswordHash
+		status.AddDifference("password_hash", fmt.Sprintf("<%s>", string(StateAbsent)), "<hidden>", "")
+	}
+
+	if u.System {
+		options.System = true
+		status.AddDifference("system", fmt.Sprintf("<%s>", string(StateAbsent)), "true", "")
+	}
+
+	if len(u.AuthorizedKeys) > 0 {
+		options.AuthorizedKeys = u.AuthorizedKeys
+		status.AddDifference("authorized_keys", fmt.Sprintf("<%s>", string(StateAbsent)), strings.Join(u.AuthorizedKeys, "\n"), "")
+	}
+
 	status.RaiseLevelForDiffs()
 
 	return options, nil
@@ -458,11 +564,171 @@ func (u *User) DiffMod(status *resource.Status, currUser *user.User) (*ModUserOp
 		}
 	}
 
+	if u.Shell != "" {
+		shell, err := u.system.LookupUserShell(u.Username)
+		if err != nil {
+			return nil, fmt.Errorf("could not acquire current shell for %s: %s", u.Username, err)
+		}
+		if shell != u.Shell {
+			options.Shell = u.Shell
+			status.AddDifference("shell", shell, u.Shell, "")
+		}
+	}
+
+	if u.Groups != nil || u.GroupsMode == GroupsExact {
+		if err := u.diffGroups(status, options); err != nil {
+			return nil, err
+		}
+	}
+
+	if u.PasswordHash != "" {
+		password, err := u.system.LookupUserPassword(u.Username)
+		if err != nil {
+			return nil, fmt.Errorf("could not acquire current password hash for %s: %s", u.Username, err)
+		}
+		if password != u.PasswordHash {
+			options.Password = u.PasswordHash
+			status.AddDifference("password_hash", "<hidden>", "<hidden>", "")
+		}
+	}
+
+	if u.AuthorizedKeys != nil || u.ExclusiveKeys {
+		keys, err := u.system.LookupAuthorizedKeys(currUser)
+		if err != nil {
+			return nil, fmt.Errorf("could not acquire current authorized keys for %s: %s", u.Username, err)
+		}
+		wanted := u.wantedKeys(keys)
+		if !equalStrings(keys, wanted) {
+			options.AuthorizedKeys = wanted
+			status.AddDifference("authorized_keys", strings.Join(keys, "\n"), strings.Join(wanted, "\n"), "")
+		}
+	}
+
 	status.RaiseLevelForDiffs()
 
 	return options, nil
 }
 
+// checkGroups checks that the supplementary groups exist
+func (u *User) checkGroups(status *resource.Status) error {
+	for _, name := range u.Groups {
+		if _, err := u.system.LookupGroup(name); err != nil {
+			status.RaiseLevel(resource.StatusCantChange)
+			return fmt.Errorf("group %s does not exist", name)
+		}
+	}
+	return nil
+}
+
+// diffGroups checks for differences between the current and desired
+// supplementary groups of the user. In append mode, only groups the user does
+// not belong to yet are added.
+func (u *User) diffGroups(status *resource.Status, options *ModUserOptions) error {
+	if err := u.checkGroups(status); err != nil {
+		return err
+	}
+
+	current, err := u.system.LookupUserGroups(u.Username)
+	if err != nil {
+		return fmt.Errorf("could not acquire current groups for %s: %s", u.Username, err)
+	}
+	sort.Strings(current)
+
+	var wanted []string
+	if u.GroupsMode == GroupsExact {
+		wanted = append([]string{}, u.Groups...)
+		sort.Strings(wanted)
+		wanted = uniqStrings(wanted)
+		if equalStrings(current, wanted) {
+			return nil
+		}
+		options.Groups = wanted
+	} else {
+		var missing []string
+		for _, name := range u.Groups {
+			if !containsString(current, name) && !containsString(missing, name) {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		options.Groups = missing
+		options.AppendGroups = true
+		wanted = append(append([]string{}, current...), missing...)
+		sort.Strings(wanted)
+	}
+
+	status.AddDifference("groups", strings.Join(current, ","), strings.Join(wanted, ","), "")
+	return nil
+}
+
+// wantedKeys returns the authorized keys the user should have, given the keys
+// it currently has. Unless the keys are exclusive, current keys are kept.
+func (u *User) wantedKeys(current []string) []string {
+	wanted := []string{}
+	if !u.ExclusiveKeys {
+		wanted = append(wanted, current...)
+	}
+	for _, key := range u.AuthorizedKeys {
+		key = strings.TrimSpace(key)
+		if key != "" && !containsString(wanted, key) {
+			wanted = append(wanted, key)
+		}
+	}
+	return wanted
+}
+
+// setAuthorizedKeys writes the authorized keys of a user after it has been
+// added or modified
+func (u *User) setAuthorizedKeys(status *resource.Status, userName string, keys []string) error {
+	usr, err := u.system.Lookup(userName)
+	if err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		return errors.Wrapf(err, "cannot find user %s", userName)
+	}
+
+	if err := u.system.SetAuthorizedKeys(usr, keys); err != nil {
+		status.RaiseLevel(resource.StatusFatal)
+		status.AddMessage(fmt.Sprintf("error writing authorized keys for user %s", userName))
+		return errors.Wrap(err, "authorized keys")
+	}
+	status.AddMessage(fmt.Sprintf("wrote authorized keys for user %s", userName))
+	return nil
+}
+
+func containsString(list []string, val string) bool {
+	for _, item := range list {
+		if item == val {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqStrings removes adjacent duplicates from a sorted list
+func uniqStrings(list []string) []string {
+	out := []string{}
+	for i, item := range list {
+		if i == 0 || item != list[i-1] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 // createHomeDiffs calls AddDifference for create_home and skel_dir after
 // adding a user. The actual value of home_dir is accessed so the differences
 // can be updated to no longer show <default home>.
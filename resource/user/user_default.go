@@ -44,6 +44,31 @@ func (s *System) LookupUserExpiry(userName string) (time.Time, error) {
 	return time.Time{}, ErrUnsupported
 }
 
+// LookupUserShell implementation for systems which are not supported
+func (s *System) LookupUserShell(userName string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// LookupUserGroups implementation for systems which are not supported
+func (s *System) LookupUserGroups(userName string) ([]string, error) {
+	return nil, ErrUnsupported
+}
+
+// LookupUserPassword implementation for systems which are not supported
+func (s *System) LookupUserPassword(userName string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// LookupAuthorizedKeys implementation for systems which are not supported
+func (s *System) LookupAuthorizedKeys(usr *user.User) ([]string, error) {
+	return nil, ErrUnsupported
+}
+
+// SetAuthorizedKeys implementation for systems which are not supported
+func (s *System) SetAuthorizedKeys(usr *user.User, keys []string) error {
+	return ErrUnsupported
+}
+
 // Lookup implementation for systems which are not supported
 func (s *System) Lookup(userName string) (*user.User, error) {
 	return nil, ErrUnsupported
@@ -18,10 +18,16 @@ package user
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
 	"os/exec"
 	"os/user"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -52,12 +58,25 @@ func (s *System) AddUser(userName string, options *AddUserOptions) error {
 	if options.Expiry != "" {
 		args = append(args, "-e", options.Expiry)
 	}
+	if options.Shell != "" {
+		args = append(args, "-s", options.Shell)
+	}
+	if len(options.Groups) > 0 {
+		args = append(args, "-G", strings.Join(options.Groups, ","))
+	}
+	if options.System {
+		args = append(args, "-r")
+	}
 
 	cmd := exec.Command("useradd", args...)
 	err := cmd.Run()
 	if err != nil {
 		return errors.Wrap(err, "useradd")
 	}
+
+	if options.Password != "" {
+		return setPassword(userName, options.Password)
+	}
 	return nil
 }
 
@@ -95,11 +114,45 @@ func (s *System) ModUser(userName string, options *ModUserOptions) error {
 	if options.Expiry != "" {
 		args = append(args, "-e", options.Expiry)
 	}
+	if options.Shell != "" {
+		args = append(args, "-s", options.Shell)
+	}
+	if options.Groups != nil {
+		args = append(args, "-G", strings.Join(options.Groups, ","))
+		if options.AppendGroups {
+			args = append(args, "-a")
+		}
+	}
+
+	if len(args) > 1 {
+		cmd := exec.Command("usermod", args...)
+		err := cmd.Run()
+		if err != nil {
+			return errors.Wrap(err, "usermod")
+		}
+	}
 
-	cmd := exec.Command("usermod", args...)
+	if options.Password != "" {
+		if options.Username != "" {
+			userName = options.Username
+		}
+		return setPassword(userName, options.Password)
+	}
+	return nil
+}
+
+// setPassword sets a user's encrypted password with chpasswd, which reads it
+// from stdin so that it isn't visible in the process list
+func setPassword(userName, hash string) error {
+	if strings.ContainsAny(hash, ":\n") {
+		return errors.New("chpasswd: password hash cannot contain colons or newlines")
+	}
+
+	cmd := exec.Command("chpasswd", "-e")
+	cmd.Stdin = strings.NewReader(userName + ":" + hash + "\n")
 	err := cmd.Run()
 	if err != nil {
-		return errors.Wrap(err, "usermod")
+		return errors.Wrap(err, "chpasswd")
 	}
 	return nil
 }
@@ -124,6 +177,200 @@ func (s *System) LookupUserExpiry(userName string) (time.Time, error) {
 	return expiry, nil
 }
 
+// LookupUserShell looks up a user's login shell
+func (s *System) LookupUserShell(userName string) (string, error) {
+	fields, err := getent("passwd", userName)
+	if err != nil {
+		return "", err
+	}
+	if len(fields) < 7 {
+		return "", errors.New("could not parse passwd entry for user")
+	}
+	return fields[6], nil
+}
+
+// LookupUserGroups looks up the names of a user's supplementary groups
+func (s *System) LookupUserGroups(userName string) ([]string, error) {
+	usr, err := user.Lookup(userName)
+	if err != nil {
+		return nil, err
+	}
+
+	gids, err := usr.GroupIds()
+	if err != nil {
+		return nil, errors.Wrap(err, "group ids")
+	}
+
+	var names []string
+	for _, gid := range gids {
+		if gid == usr.Gid {
+			continue
+		}
+		grp, err := user.LookupGroupId(gid)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, grp.Name)
+	}
+	return names, nil
+}
+
+// LookupUserPassword looks up a user's encrypted password
+func (s *System) LookupUserPassword(userName string) (string, error) {
+	fields, err := getent("shadow", userName)
+	if err != nil {
+		return "", err
+	}
+	if len(fields) < 2 {
+		return "", errors.New("could not parse shadow entry for user")
+	}
+	return fields[1], nil
+}
+
+// LookupAuthorizedKeys reads the keys in a user's authorized_keys file. No
+// keys are returned if the file does not exist. Symbolic links are not
+// followed, so a user can't have another user's keys read in place of theirs.
+func (s *System) LookupAuthorizedKeys(usr *user.User) ([]string, error) {
+	path := authorizedKeysPath(usr)
+	dir, err := openSSHDir(filepath.Dir(path))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	fd, err := syscall.Openat(int(dir.Fd()), filepath.Base(path), syscall.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_CLOEXEC, 0)
+	switch err {
+	case nil:
+	case syscall.ENOENT:
+		return []string{}, nil
+	case syscall.ELOOP:
+		return nil, fmt.Errorf("refusing to read %q, it is a symbolic link", path)
+	default:
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	file := os.NewFile(uintptr(fd), path)
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, nil
+}
+
+// SetAuthorizedKeys writes a user's authorized_keys file. The file and the
+// .ssh directory are only accessible by the user. The file is written next to
+// the old one and renamed over it, and neither it nor the .ssh directory may
+// be a symbolic link, so a user can't have converge write to another file.
+func (s *System) SetAuthorizedKeys(usr *user.User, keys []string) error {
+	uid, err := strconv.Atoi(usr.Uid)
+	if err != nil {
+		return errors.Wrap(err, "uid")
+	}
+	gid, err := strconv.Atoi(usr.Gid)
+	if err != nil {
+		return errors.Wrap(err, "gid")
+	}
+
+	path := authorizedKeysPath(usr)
+	created := false
+	for _, dir := range []string{usr.HomeDir, filepath.Dir(path)} {
+		if _, err := os.Lstat(dir); err == nil {
+			continue
+		}
+		if err := os.Mkdir(dir, 0700); err != nil {
+			return err
+		}
+		if err := os.Lchown(dir, uid, gid); err != nil {
+			return err
+		}
+		created = true
+	}
+
+	dir, err := openSSHDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	if created {
+		// the directory may have been replaced before it was opened
+		if err := dir.Chown(uid, gid); err != nil {
+			return err
+		}
+	}
+
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("refusing to replace %q, it is a symbolic link", path)
+	}
+
+	var content string
+	for _, key := range keys {
+		content += key + "\n"
+	}
+
+	dirfd := int(dir.Fd())
+	tmpName := fmt.Sprintf(".%s.%d", filepath.Base(path), os.Getpid())
+	syscall.Unlinkat(dirfd, tmpName)
+	fd, err := syscall.Openat(dirfd, tmpName, syscall.O_WRONLY|syscall.O_CREAT|syscall.O_EXCL|syscall.O_NOFOLLOW|syscall.O_CLOEXEC, 0600)
+	if err != nil {
+		return &os.PathError{Op: "open", Path: filepath.Join(filepath.Dir(path), tmpName), Err: err}
+	}
+	tmp := os.NewFile(uintptr(fd), filepath.Join(filepath.Dir(path), tmpName))
+
+	err = writeKeys(tmp, content, uid, gid)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = syscall.Renameat(dirfd, tmpName, dirfd, filepath.Base(path))
+	}
+	if err != nil {
+		syscall.Unlinkat(dirfd, tmpName)
+		return errors.Wrapf(err, "cannot write %q", path)
+	}
+	return nil
+}
+
+// writeKeys writes the content of an authorized_keys file to an open file,
+// and gives it to the user
+func writeKeys(file *os.File, content string, uid, gid int) error {
+	if err := file.Chmod(0600); err != nil {
+		return err
+	}
+	if err := file.Chown(uid, gid); err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// openSSHDir opens a .ssh directory, refusing to follow a symbolic link
+func openSSHDir(path string) (*os.File, error) {
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_NOFOLLOW|syscall.O_CLOEXEC, 0)
+	switch err {
+	case nil:
+		return os.NewFile(uintptr(fd), path), nil
+	case syscall.ELOOP:
+		return nil, fmt.Errorf("refusing to use %q, it is a symbolic link", path)
+	case syscall.ENOTDIR:
+		if info, lstatErr := os.Lstat(path); lstatErr == nil && info.Mode()&os.ModeSymlink != 0 {
+			return nil, fmt.Errorf("refusing to use %q, it is a symbolic link", path)
+		}
+	}
+	return nil, &os.PathError{Op: "open", Path: path, Err: err}
+}
+
 // Lookup looks up a user by name
 // If the user cannot be found an error is returned
 func (s *System) Lookup(userName string) (*user.User, error) {
@@ -170,3 +417,23 @@ func parseForExpiry(data string) (time.Time, error) {
 
 	return time.Time{}, errors.New("could not parse expiry data for current user")
 }
+
+// getent returns the fields of a user's entry in a database like passwd or
+// shadow
+func getent(database, userName string) ([]string, error) {
+	var out bytes.Buffer
+
+	cmd := exec.Command("getent", database, userName)
+	cmd.Stdout = &out
+	err := cmd.Run()
+	if err != nil {
+		return nil, errors.Wrap(err, "getent")
+	}
+
+	return strings.Split(strings.TrimSpace(out.String()), ":"), nil
+}
+
+// authorizedKeysPath returns the location of a user's authorized_keys file
+func authorizedKeysPath(usr *user.User) string {
+	return filepath.Join(usr.HomeDir, ".ssh", "authorized_keys")
+}
@@ -0,0 +1,106 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build linux
+
+package user_test
+
+import (
+	"io/ioutil"
+	"os"
+	osuser "os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/asteris-llc/converge/resource/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuthorizedKeys tests reading and writing authorized_keys files
+func TestAuthorizedKeys(t *testing.T) {
+	t.Parallel()
+
+	current, err := osuser.Current()
+	require.NoError(t, err)
+
+	newUser := func(t *testing.T) (*osuser.User, func()) {
+		home, err := ioutil.TempDir("", "converge-authorized-keys")
+		require.NoError(t, err)
+		usr := *current
+		usr.HomeDir = home
+		return &usr, func() { os.RemoveAll(home) }
+	}
+
+	system := &user.System{}
+	keys := []string{"ssh-ed25519 AAAA one", "ssh-rsa AAAA two"}
+
+	t.Run("write and read", func(t *testing.T) {
+		usr, cleanup := newUser(t)
+		defer cleanup()
+
+		found, err := system.LookupAuthorizedKeys(usr)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+
+		require.NoError(t, system.SetAuthorizedKeys(usr, keys))
+		require.NoError(t, system.SetAuthorizedKeys(usr, keys[:1]))
+
+		found, err = system.LookupAuthorizedKeys(usr)
+		require.NoError(t, err)
+		assert.Equal(t, keys[:1], found)
+
+		info, err := os.Stat(filepath.Join(usr.HomeDir, ".ssh", "authorized_keys"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode())
+
+		entries, err := ioutil.ReadDir(filepath.Join(usr.HomeDir, ".ssh"))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary files should be removed")
+	})
+
+	t.Run("symlinked ssh directory", func(t *testing.T) {
+		usr, cleanup := newUser(t)
+		defer cleanup()
+
+		target := filepath.Join(usr.HomeDir, "elsewhere")
+		require.NoError(t, os.Mkdir(target, 0700))
+		require.NoError(t, os.Symlink(target, filepath.Join(usr.HomeDir, ".ssh")))
+
+		_, err := system.LookupAuthorizedKeys(usr)
+		assert.Error(t, err)
+		assert.Error(t, system.SetAuthorizedKeys(usr, keys))
+
+		_, err = os.Stat(filepath.Join(target, "authorized_keys"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("symlinked authorized_keys", func(t *testing.T) {
+		usr, cleanup := newUser(t)
+		defer cleanup()
+
+		target := filepath.Join(usr.HomeDir, "other_keys")
+		require.NoError(t, ioutil.WriteFile(target, []byte("ssh-rsa AAAA other\n"), 0600))
+		require.NoError(t, os.Mkdir(filepath.Join(usr.HomeDir, ".ssh"), 0700))
+		require.NoError(t, os.Symlink(target, filepath.Join(usr.HomeDir, ".ssh", "authorized_keys")))
+
+		_, err := system.LookupAuthorizedKeys(usr)
+		assert.Error(t, err)
+		assert.Error(t, system.SetAuthorizedKeys(usr, keys))
+
+		content, err := ioutil.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "ssh-rsa AAAA other\n", string(content))
+	})
+}
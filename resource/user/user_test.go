@@ -473,6 +473,50 @@ func TestApply(t *testing.T) {
 				assert.Equal(t, resource.StatusFatal, status.StatusCode())
 				assert.Equal(t, fmt.Sprintf("error modifying user %s", u.Username), status.Messages()[0])
 			})
+
+			t.Run("authorized keys only", func(t *testing.T) {
+				usr := &os.User{
+					Username: currUsername,
+					HomeDir:  "/tmp/test",
+				}
+				m := &MockSystem{}
+				u := user.NewUser(m)
+				u.Username = usr.Username
+				u.AuthorizedKeys = []string{"ssh-ed25519 AAAA a"}
+				u.State = user.StatePresent
+				keys := []string{"ssh-ed25519 AAAA a"}
+
+				m.On("Lookup", u.Username).Return(usr, nil)
+				m.On("LookupAuthorizedKeys", usr).Return([]string{}, nil)
+				m.On("SetAuthorizedKeys", usr, keys).Return(nil)
+				status, err := u.Apply(context.Background())
+
+				assert.NoError(t, err)
+				m.AssertNotCalled(t, "ModUser", mock.Anything, mock.Anything)
+				m.AssertCalled(t, "SetAuthorizedKeys", usr, keys)
+				assert.Equal(t, fmt.Sprintf("wrote authorized keys for user %s", u.Username), status.Messages()[0])
+			})
+
+			t.Run("error writing authorized keys", func(t *testing.T) {
+				usr := &os.User{
+					Username: currUsername,
+					HomeDir:  "/tmp/test",
+				}
+				m := &MockSystem{}
+				u := user.NewUser(m)
+				u.Username = usr.Username
+				u.AuthorizedKeys = []string{"ssh-ed25519 AAAA a"}
+				u.State = user.StatePresent
+
+				m.On("Lookup", u.Username).Return(usr, nil)
+				m.On("LookupAuthorizedKeys", usr).Return([]string{}, nil)
+				m.On("SetAuthorizedKeys", usr, mock.Anything).Return(fmt.Errorf("permission denied"))
+				status, err := u.Apply(context.Background())
+
+				assert.EqualError(t, err, "authorized keys: permission denied")
+				assert.Equal(t, resource.StatusFatal, status.StatusCode())
+				assert.Equal(t, fmt.Sprintf("error writing authorized keys for user %s", u.Username), status.Messages()[0])
+			})
 		})
 	})
 
@@ -910,6 +954,55 @@ func TestDiffAdd(t *testing.T) {
 		})
 	})
 
+	t.Run("shell, groups, password_hash, system and authorized_keys", func(t *testing.T) {
+		m := &MockSystem{}
+		u := user.NewUser(m)
+		u.Username = fakeUsername
+		u.Shell = "/bin/zsh"
+		u.Groups = []string{existingGroupName}
+		u.PasswordHash = "$6$salt$hash"
+		u.System = true
+		u.AuthorizedKeys = []string{"ssh-ed25519 AAAA a@example.com", "ssh-ed25519 BBBB b@example.com"}
+		status := resource.NewStatus()
+
+		expected := &user.AddUserOptions{
+			Shell:          u.Shell,
+			Groups:         u.Groups,
+			Password:       u.PasswordHash,
+			System:         true,
+			AuthorizedKeys: u.AuthorizedKeys,
+		}
+
+		m.On("LookupGroup", existingGroupName).Return(existingGroup, nil)
+		options, err := u.DiffAdd(status)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, options)
+		assert.Equal(t, resource.StatusWillChange, status.StatusCode())
+		assert.Equal(t, "<default shell>", status.Diffs()["shell"].Original())
+		assert.Equal(t, u.Shell, status.Diffs()["shell"].Current())
+		assert.Equal(t, fmt.Sprintf("<%s>", string(user.StateAbsent)), status.Diffs()["groups"].Original())
+		assert.Equal(t, existingGroupName, status.Diffs()["groups"].Current())
+		assert.Equal(t, "<hidden>", status.Diffs()["password_hash"].Current())
+		assert.Equal(t, "true", status.Diffs()["system"].Current())
+		assert.Equal(t, "ssh-ed25519 AAAA a@example.com\nssh-ed25519 BBBB b@example.com", status.Diffs()["authorized_keys"].Current())
+	})
+
+	t.Run("error-supplementary group not found", func(t *testing.T) {
+		m := &MockSystem{}
+		u := user.NewUser(m)
+		u.Username = fakeUsername
+		u.Groups = []string{fakeGroupName}
+		status := resource.NewStatus()
+
+		m.On("LookupGroup", fakeGroupName).Return((*os.Group)(nil), os.UnknownGroupError(fakeGroupName))
+		options, err := u.DiffAdd(status)
+
+		assert.EqualError(t, err, fmt.Sprintf("group %s does not exist", fakeGroupName))
+		assert.Nil(t, options)
+		assert.Equal(t, resource.StatusCantChange, status.StatusCode())
+	})
+
 	t.Run("no options", func(t *testing.T) {
 		u := user.NewUser(new(user.System))
 		u.Username = fakeUsername
@@ -1377,6 +1470,175 @@ func TestDiffMod(t *testing.T) {
 		})
 	})
 
+	t.Run("shell", func(t *testing.T) {
+		t.Run("changed", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.Shell = "/bin/zsh"
+			status := resource.NewStatus()
+
+			m.On("LookupUserShell", currUsername).Return("/bin/sh", nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{Shell: u.Shell}, options)
+			assert.Equal(t, "/bin/sh", status.Diffs()["shell"].Original())
+			assert.Equal(t, u.Shell, status.Diffs()["shell"].Current())
+		})
+
+		t.Run("unchanged", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.Shell = "/bin/zsh"
+			status := resource.NewStatus()
+
+			m.On("LookupUserShell", currUsername).Return("/bin/zsh", nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{}, options)
+			assert.False(t, status.HasChanges())
+		})
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		t.Run("append", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.Groups = []string{"docker", "adm"}
+			u.GroupsMode = user.GroupsAppend
+			status := resource.NewStatus()
+
+			m.On("LookupGroup", mock.Anything).Return(existingGroup, nil)
+			m.On("LookupUserGroups", currUsername).Return([]string{"video", "adm"}, nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{Groups: []string{"docker"}, AppendGroups: true}, options)
+			assert.Equal(t, "adm,video", status.Diffs()["groups"].Original())
+			assert.Equal(t, "adm,docker,video", status.Diffs()["groups"].Current())
+		})
+
+		t.Run("exact", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.Groups = []string{"docker", "adm"}
+			u.GroupsMode = user.GroupsExact
+			status := resource.NewStatus()
+
+			m.On("LookupGroup", mock.Anything).Return(existingGroup, nil)
+			m.On("LookupUserGroups", currUsername).Return([]string{"video", "adm"}, nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{Groups: []string{"adm", "docker"}}, options)
+			assert.Equal(t, "adm,video", status.Diffs()["groups"].Original())
+			assert.Equal(t, "adm,docker", status.Diffs()["groups"].Current())
+		})
+
+		t.Run("exact without groups", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.GroupsMode = user.GroupsExact
+			status := resource.NewStatus()
+
+			m.On("LookupUserGroups", currUsername).Return([]string{"adm"}, nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{Groups: []string{}}, options)
+			assert.Equal(t, "adm", status.Diffs()["groups"].Original())
+			assert.Equal(t, "", status.Diffs()["groups"].Current())
+		})
+
+		t.Run("already member", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.Groups = []string{"adm"}
+			u.GroupsMode = user.GroupsAppend
+			status := resource.NewStatus()
+
+			m.On("LookupGroup", mock.Anything).Return(existingGroup, nil)
+			m.On("LookupUserGroups", currUsername).Return([]string{"video", "adm"}, nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{}, options)
+			assert.False(t, status.HasChanges())
+		})
+	})
+
+	t.Run("password_hash", func(t *testing.T) {
+		m := &MockSystem{}
+		u := user.NewUser(m)
+		u.Username = currUsername
+		u.PasswordHash = "$6$salt$new"
+		status := resource.NewStatus()
+
+		m.On("LookupUserPassword", currUsername).Return("$6$salt$old", nil)
+		options, err := u.DiffMod(status, currUser)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &user.ModUserOptions{Password: u.PasswordHash}, options)
+		assert.Equal(t, "<hidden>", status.Diffs()["password_hash"].Original())
+		assert.Equal(t, "<hidden>", status.Diffs()["password_hash"].Current())
+	})
+
+	t.Run("authorized_keys", func(t *testing.T) {
+		t.Run("additive", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.AuthorizedKeys = []string{"ssh-ed25519 BBBB b"}
+			status := resource.NewStatus()
+
+			m.On("LookupAuthorizedKeys", currUser).Return([]string{"ssh-ed25519 AAAA a"}, nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{AuthorizedKeys: []string{"ssh-ed25519 AAAA a", "ssh-ed25519 BBBB b"}}, options)
+			assert.Equal(t, "ssh-ed25519 AAAA a", status.Diffs()["authorized_keys"].Original())
+			assert.Equal(t, "ssh-ed25519 AAAA a\nssh-ed25519 BBBB b", status.Diffs()["authorized_keys"].Current())
+		})
+
+		t.Run("exclusive", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.AuthorizedKeys = []string{"ssh-ed25519 BBBB b"}
+			u.ExclusiveKeys = true
+			status := resource.NewStatus()
+
+			m.On("LookupAuthorizedKeys", currUser).Return([]string{"ssh-ed25519 AAAA a", "ssh-ed25519 BBBB b"}, nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{AuthorizedKeys: []string{"ssh-ed25519 BBBB b"}}, options)
+			assert.Equal(t, "ssh-ed25519 BBBB b", status.Diffs()["authorized_keys"].Current())
+		})
+
+		t.Run("unchanged", func(t *testing.T) {
+			m := &MockSystem{}
+			u := user.NewUser(m)
+			u.Username = currUsername
+			u.AuthorizedKeys = []string{"ssh-ed25519 AAAA a"}
+			status := resource.NewStatus()
+
+			m.On("LookupAuthorizedKeys", currUser).Return([]string{"ssh-ed25519 AAAA a"}, nil)
+			options, err := u.DiffMod(status, currUser)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &user.ModUserOptions{}, options)
+			assert.False(t, status.HasChanges())
+		})
+	})
+
 	t.Run("no options", func(t *testing.T) {
 		u := user.NewUser(new(user.System))
 		u.Username = currUsername
@@ -1496,6 +1758,36 @@ func (m *MockSystem) LookupGroupID(gid string) (*os.Group, error) {
 	return args.Get(0).(*os.Group), args.Error(1)
 }
 
+// LookupUserShell looks up a user's login shell
+func (m *MockSystem) LookupUserShell(name string) (string, error) {
+	args := m.Called(name)
+	return args.String(0), args.Error(1)
+}
+
+// LookupUserGroups looks up a user's supplementary groups
+func (m *MockSystem) LookupUserGroups(name string) ([]string, error) {
+	args := m.Called(name)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// LookupUserPassword looks up a user's encrypted password
+func (m *MockSystem) LookupUserPassword(name string) (string, error) {
+	args := m.Called(name)
+	return args.String(0), args.Error(1)
+}
+
+// LookupAuthorizedKeys looks up a user's authorized keys
+func (m *MockSystem) LookupAuthorizedKeys(usr *os.User) ([]string, error) {
+	args := m.Called(usr)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// SetAuthorizedKeys writes a user's authorized keys
+func (m *MockSystem) SetAuthorizedKeys(usr *os.User, keys []string) error {
+	args := m.Called(usr, keys)
+	return args.Error(0)
+}
+
 // MockSystem2 is a mock implementation of user.System, with a special
 // implementation of Lookup
 type MockSystem2 struct {
@@ -15,7 +15,11 @@
 package rpc
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
 
 	"google.golang.org/grpc/metadata"
 
@@ -23,21 +27,35 @@ import (
 	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/graph/node"
 	"github.com/asteris-llc/converge/healthcheck"
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/plan"
+	"github.com/asteris-llc/converge/plan/planfile"
 	"github.com/asteris-llc/converge/prettyprinters/human"
+	"github.com/asteris-llc/converge/resource/file/remove"
 	"github.com/asteris-llc/converge/rpc/pb"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
-type executor struct{}
+type executor struct {
+	limits        graph.Limits
+	removeAllowed []string
+}
+
+// withLimits sets the parallelism limits for a request. The stricter of the
+// server's limits and the requested limits is used. The paths resources may
+// remove are set by the server alone.
+func (e *executor) withLimits(ctx context.Context, in *pb.LoadRequest) context.Context {
+	ctx = remove.WithAllowed(ctx, e.removeAllowed)
+	return graph.WithLimits(ctx, e.limits.Merge(in.Limits()))
+}
 
 type statusResponseStream interface {
 	Send(*pb.StatusResponse) error
 	SendHeader(metadata.MD) error
 }
 
-func (e *executor) edgeMeta(ctx context.Context, g *graph.Graph) (metadata.MD, error) {
+func (e *executor) edgeMeta(ctx context.Context, g *graph.Graph, salt []byte) (metadata.MD, error) {
 	logger := getLogger(ctx).WithField("function", "executor.edgeMeta")
 
 	edges, err := json.Marshal(g.Edges())
@@ -46,14 +64,26 @@ func (e *executor) edgeMeta(ctx context.Context, g *graph.Graph) (metadata.MD, e
 		return nil, errors.Wrapf(err, "serializing edges")
 	}
 
-	return metadata.New(map[string]string{"edges": string(edges)}), nil
+	modules, err := json.Marshal(planfile.ModulesFromGraph(g))
+	if err != nil {
+		logger.WithError(err).Error("could not serialize modules")
+		return nil, errors.Wrapf(err, "serializing modules")
+	}
+
+	return metadata.New(map[string]string{
+		"edges":   string(edges),
+		"modules": string(modules),
+		"salt":    hex.EncodeToString(salt),
+	}), nil
 }
 
-func (e *executor) sendMeta(ctx context.Context, g *graph.Graph, stream statusResponseStream) error {
+// sendMeta sends the graph edges, the loaded modules, and the salt of the
+// fingerprints in the responses as header metadata
+func (e *executor) sendMeta(ctx context.Context, g *graph.Graph, stream statusResponseStream, salt []byte) error {
 	logger := getLogger(ctx).WithField("function", "executor.sendMeta")
 
 	// dehydrate graph edges and send them in the header metadata
-	meta, err := e.edgeMeta(ctx, g)
+	meta, err := e.edgeMeta(ctx, g, salt)
 	if err != nil {
 		// already logged, don't log here
 		return errors.Wrap(err, "preparing metadata")
@@ -67,7 +97,9 @@ func (e *executor) sendMeta(ctx context.Context, g *graph.Graph, stream statusRe
 	return nil
 }
 
-func (e *executor) stageNotifier(stage pb.StatusResponse_Stage, stream statusResponseStream) *graph.Notifier {
+func (e *executor) stageNotifier(ctx context.Context, stage pb.StatusResponse_Stage, stream statusResponseStream, salt []byte) *graph.Notifier {
+	redactor := redact.FromContext(ctx)
+
 	return &graph.Notifier{
 		Pre: func(meta *node.Node) error {
 			return stream.Send(&pb.StatusResponse{
@@ -79,6 +111,8 @@ func (e *executor) stageNotifier(stage pb.StatusResponse_Stage, stream statusRes
 		},
 		Post: func(meta *node.Node) error {
 			response := statusResponseFromPrintable(
+				redactor,
+				salt,
 				meta,
 				meta.Value().(human.Printable),
 				stage,
@@ -90,8 +124,8 @@ func (e *executor) stageNotifier(stage pb.StatusResponse_Stage, stream statusRes
 	}
 }
 
-func (e *executor) sendPlan(ctx context.Context, stream statusResponseStream, in *graph.Graph) (*graph.Graph, error) {
-	out, err := plan.WithNotify(ctx, in, e.stageNotifier(pb.StatusResponse_PLAN, stream))
+func (e *executor) sendPlan(ctx context.Context, stream statusResponseStream, in *graph.Graph, salt []byte) (*graph.Graph, error) {
+	out, err := plan.WithNotify(ctx, in, e.stageNotifier(ctx, pb.StatusResponse_PLAN, stream, salt))
 	if err != nil && err != plan.ErrTreeContainsErrors {
 		return nil, err
 	}
@@ -101,18 +135,27 @@ func (e *executor) sendPlan(ctx context.Context, stream statusResponseStream, in
 func (e *executor) Plan(in *pb.LoadRequest, stream pb.Executor_PlanServer) error {
 	logger, ctx := setIDLogger(stream.Context())
 	logger = logger.WithField("function", "executor.Plan")
+	ctx = e.withLimits(ctx, in)
+
+	ctx, release := redact.Scoped(ctx)
+	defer release()
 
 	loaded, err := in.Load(ctx)
 	if err != nil {
 		return err
 	}
 
-	if err = e.sendMeta(ctx, loaded, stream); err != nil {
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+
+	if err = e.sendMeta(ctx, loaded, stream, salt); err != nil {
 		return err
 	}
 
 	// send the plan
-	_, err = e.sendPlan(ctx, stream, loaded)
+	_, err = e.sendPlan(ctx, stream, loaded, salt)
 	if err != nil {
 		logger.WithError(err).WithField("location", in.Location).Error("planning failed")
 		return errors.Wrapf(err, "planning %s", in.Location)
@@ -121,8 +164,8 @@ func (e *executor) Plan(in *pb.LoadRequest, stream pb.Executor_PlanServer) error
 	return nil
 }
 
-func (e *executor) sendHealthCheck(ctx context.Context, stream statusResponseStream, in *graph.Graph) (*graph.Graph, error) {
-	out, err := healthcheck.WithNotify(ctx, in, e.stageNotifier(pb.StatusResponse_PLAN, stream))
+func (e *executor) sendHealthCheck(ctx context.Context, stream statusResponseStream, in *graph.Graph, salt []byte) (*graph.Graph, error) {
+	out, err := healthcheck.WithNotify(ctx, in, e.stageNotifier(ctx, pb.StatusResponse_PLAN, stream, salt))
 	if err != nil && err != plan.ErrTreeContainsErrors {
 		return nil, err
 	}
@@ -132,24 +175,33 @@ func (e *executor) sendHealthCheck(ctx context.Context, stream statusResponseStr
 func (e *executor) HealthCheck(in *pb.LoadRequest, stream pb.Executor_HealthCheckServer) error {
 	logger, ctx := setIDLogger(stream.Context())
 	logger = logger.WithField("function", "executor.Plan")
+	ctx = e.withLimits(ctx, in)
+
+	ctx, release := redact.Scoped(ctx)
+	defer release()
 
 	loaded, err := in.Load(ctx)
 	if err != nil {
 		return err
 	}
 
-	if err = e.sendMeta(ctx, loaded, stream); err != nil {
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+
+	if err = e.sendMeta(ctx, loaded, stream, salt); err != nil {
 		return err
 	}
 
 	// send the plan
-	planned, err := e.sendPlan(ctx, stream, loaded)
+	planned, err := e.sendPlan(ctx, stream, loaded, salt)
 	if err != nil {
 		logger.WithError(err).WithField("location", in.Location).Error("planning failed")
 		return errors.Wrapf(err, "planning %s", in.Location)
 	}
 
-	_, err = e.sendHealthCheck(ctx, stream, planned)
+	_, err = e.sendHealthCheck(ctx, stream, planned, salt)
 	if err != nil {
 		logger.WithError(err).WithField("location", in.Location).Error("health check failed")
 		return errors.Wrapf(err, "health check %s", in.Location)
@@ -158,28 +210,98 @@ func (e *executor) HealthCheck(in *pb.LoadRequest, stream pb.Executor_HealthChec
 	return nil
 }
 
-func (e *executor) sendApply(ctx context.Context, stream statusResponseStream, in *graph.Graph) (*graph.Graph, error) {
-	out, err := apply.WithNotify(ctx, in, e.stageNotifier(pb.StatusResponse_APPLY, stream))
+func (e *executor) sendApply(ctx context.Context, stream statusResponseStream, in *graph.Graph, salt []byte) (*graph.Graph, error) {
+	out, err := apply.WithNotify(ctx, in, e.stageNotifier(ctx, pb.StatusResponse_APPLY, stream, salt))
 	if err != nil && err != apply.ErrTreeContainsErrors {
 		return nil, err
 	}
 	return out, nil
 }
 
+// checkPlan plans the loaded graph again and compares the result to the saved
+// plan in the request, returning an error describing any drift
+func (e *executor) checkPlan(ctx context.Context, in *pb.LoadRequest, loaded *graph.Graph) error {
+	saved, err := planfile.Read(strings.NewReader(in.Plan))
+	if err != nil {
+		return err
+	}
+
+	// sensitive values are fingerprinted with the salt of the saved plan, so
+	// unchanged values have the same fingerprint
+	salt, err := hex.DecodeString(saved.Salt)
+	if err != nil || len(salt) == 0 {
+		return errors.New("saved plan has no valid salt")
+	}
+
+	var lock sync.Mutex
+	redactor := redact.FromContext(ctx)
+	current := planfile.New(in.Location, planfile.ModulesFromGraph(loaded))
+
+	notifier := &graph.Notifier{
+		Post: func(meta *node.Node) error {
+			response := statusResponseFromPrintable(
+				redactor,
+				salt,
+				meta,
+				meta.Value().(human.Printable),
+				pb.StatusResponse_PLAN,
+				pb.StatusResponse_FINISHED,
+			)
+
+			lock.Lock()
+			defer lock.Unlock()
+			current.Add(response)
+
+			return nil
+		},
+	}
+
+	_, err = plan.WithNotify(ctx, loaded, notifier)
+	if err != nil && err != plan.ErrTreeContainsErrors {
+		return errors.Wrapf(err, "planning %s", in.Location)
+	}
+
+	if drift := saved.Drift(current); len(drift) > 0 {
+		return fmt.Errorf(
+			"%s has drifted since it was planned, plan again before applying:\n  %s",
+			in.Location,
+			strings.Join(drift, "\n  "),
+		)
+	}
+
+	return nil
+}
+
 func (e *executor) Apply(in *pb.LoadRequest, stream pb.Executor_ApplyServer) error {
 	logger, ctx := setIDLogger(stream.Context())
 	logger = logger.WithField("function", "executor.Apply")
+	ctx = e.withLimits(ctx, in)
+
+	ctx, release := redact.Scoped(ctx)
+	defer release()
 
 	loaded, err := in.Load(ctx)
 	if err != nil {
 		return err
 	}
 
-	if err = e.sendMeta(ctx, loaded, stream); err != nil {
+	if in.Plan != "" {
+		if err = e.checkPlan(ctx, in, loaded); err != nil {
+			logger.WithError(err).WithField("location", in.Location).Error("plan check failed")
+			return err
+		}
+	}
+
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+
+	if err = e.sendMeta(ctx, loaded, stream, salt); err != nil {
 		return err
 	}
 
-	_, err = e.sendApply(ctx, stream, loaded)
+	_, err = e.sendApply(ctx, stream, loaded, salt)
 	if err != nil {
 		return errors.Wrapf(err, "applying %s", in.Location)
 	}
@@ -0,0 +1,255 @@
+// Copyright © 2016 Asteris, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/asteris-llc/converge/graph"
+	"github.com/asteris-llc/converge/graph/node"
+	"github.com/asteris-llc/converge/helpers/logging"
+	"github.com/asteris-llc/converge/helpers/redact"
+	"github.com/asteris-llc/converge/plan/planfile"
+	"github.com/asteris-llc/converge/prettyprinters"
+	"github.com/asteris-llc/converge/prettyprinters/human"
+	"github.com/asteris-llc/converge/prettyprinters/jsonl"
+	"github.com/asteris-llc/converge/rpc/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeStatusStream records the responses sent by the executor
+type fakeStatusStream struct {
+	grpc.ServerStream
+
+	ctx       context.Context
+	header    metadata.MD
+	responses []*pb.StatusResponse
+}
+
+func (f *fakeStatusStream) Context() context.Context { return f.ctx }
+
+func (f *fakeStatusStream) SendHeader(md metadata.MD) error {
+	f.header = md
+	return nil
+}
+
+func (f *fakeStatusStream) Send(resp *pb.StatusResponse) error {
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+func TestExecutorApplyPlan(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	ctx := logging.WithLogger(context.Background(), logrus.WithField("testing", true))
+	e := executor{}
+
+	dir, err := ioutil.TempDir("", "converge-executor")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	module := filepath.Join(dir, "module.hcl")
+	target := filepath.Join(dir, "target.txt")
+
+	writeModule := func(content string) {
+		src := fmt.Sprintf("file.content \"target\" {\n  destination = %q\n  content = %q\n}\n", target, content)
+		require.NoError(t, ioutil.WriteFile(module, []byte(src), 0600))
+	}
+
+	// savePlan plans the module the same way the client does for plan --out
+	savePlan := func() string {
+		stream := &fakeStatusStream{ctx: ctx}
+		require.NoError(t, e.Plan(&pb.LoadRequest{Location: module}, stream))
+
+		modules, err := getTestModules(stream.header)
+		require.NoError(t, err)
+
+		saved := planfile.New(module, modules)
+		saved.Salt = stream.header["salt"][0]
+		for _, resp := range stream.responses {
+			saved.Add(resp)
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, saved.Write(&buf))
+		return buf.String()
+	}
+
+	apply := func(saved string) error {
+		return e.Apply(&pb.LoadRequest{Location: module, Plan: saved}, &fakeStatusStream{ctx: ctx})
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		writeModule("a")
+		require.NoError(t, ioutil.WriteFile(target, []byte("old"), 0600))
+
+		require.NoError(t, apply(savePlan()))
+
+		content, err := ioutil.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(content))
+	})
+
+	t.Run("state drifted", func(t *testing.T) {
+		writeModule("b")
+		require.NoError(t, ioutil.WriteFile(target, []byte("old"), 0600))
+		saved := savePlan()
+
+		require.NoError(t, ioutil.WriteFile(target, []byte("changed underneath"), 0600))
+
+		err := apply(saved)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has drifted since it was planned")
+		assert.Contains(t, err.Error(), "root/file.content.target: "+target+" is different than planned")
+
+		content, err := ioutil.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "changed underneath", string(content), "apply should not have run")
+	})
+
+	t.Run("module drifted", func(t *testing.T) {
+		writeModule("c")
+		saved := savePlan()
+
+		writeModule("d")
+
+		err := apply(saved)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "root: module file://"+module+" has changed")
+	})
+
+	// a changed secret is masked the same way in both plans, so it's only
+	// detected through the fingerprint of the real values
+	t.Run("sensitive drifted", func(t *testing.T) {
+		src := fmt.Sprintf(
+			"param \"secret\" {\n  sensitive = true\n}\n\nfile.content \"target\" {\n  destination = %q\n  content = \"{{param `secret`}}\"\n}\n",
+			target,
+		)
+		require.NoError(t, ioutil.WriteFile(module, []byte(src), 0600))
+		require.NoError(t, os.RemoveAll(target))
+
+		stream := &fakeStatusStream{ctx: ctx}
+		require.NoError(t, e.Plan(&pb.LoadRequest{Location: module, Parameters: map[string]string{"secret": "first-secret"}}, stream))
+
+		modules, err := getTestModules(stream.header)
+		require.NoError(t, err)
+		saved := planfile.New(module, modules)
+		saved.Salt = stream.header["salt"][0]
+		for _, resp := range stream.responses {
+			saved.Add(resp)
+			assert.NotContains(t, resp.String(), "first-secret")
+		}
+
+		// the same secret is fingerprinted differently in every plan
+		again := &fakeStatusStream{ctx: ctx}
+		require.NoError(t, e.Plan(&pb.LoadRequest{Location: module, Parameters: map[string]string{"secret": "first-secret"}}, again))
+		assert.NotEqual(t, stream.header["salt"], again.header["salt"])
+		fingerprints := map[string]string{}
+		for _, resp := range again.responses {
+			fingerprints[resp.Id] += resp.GetDetails().GetFingerprint()
+		}
+		planned, _ := saved.Get("root/file.content.target")
+		require.NotNil(t, planned)
+		require.NotEmpty(t, planned.Fingerprint)
+		assert.NotEqual(t, planned.Fingerprint, fingerprints["root/file.content.target"])
+
+		var buf bytes.Buffer
+		require.NoError(t, saved.Write(&buf))
+
+		err = e.Apply(
+			&pb.LoadRequest{Location: module, Plan: buf.String(), Parameters: map[string]string{"secret": "second-secret"}},
+			&fakeStatusStream{ctx: ctx},
+		)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sensitive values are different than planned")
+
+		_, err = os.Stat(target)
+		assert.True(t, os.IsNotExist(err), "apply should not have run")
+	})
+
+	t.Run("bad plan", func(t *testing.T) {
+		writeModule("e")
+		assert.Error(t, apply("not a plan"))
+	})
+}
+
+// TestExecutorPlanSensitiveField tests that a value from a field tagged as
+// sensitive is masked everywhere it shows up in a plan, including in the
+// output of other resources
+func TestExecutorPlanSensitiveField(t *testing.T) {
+	defer logging.HideLogs(t)()
+
+	ctx := logging.WithLogger(context.Background(), logrus.WithField("testing", true))
+	e := executor{}
+
+	dir, err := ioutil.TempDir("", "converge-executor-sensitive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	const hash = "$6$converge$sensitive-field-hash"
+	module := filepath.Join(dir, "module.hcl")
+	src := fmt.Sprintf(
+		"user.user \"app\" {\n  username = \"converge-sensitive-test\"\n  password_hash = %q\n}\n\n"+
+			"file.content \"shadow\" {\n  destination = %q\n  content = %q\n}\n",
+		hash, filepath.Join(dir, "shadow"), "converge-sensitive-test:"+hash+"\n",
+	)
+	require.NoError(t, ioutil.WriteFile(module, []byte(src), 0600))
+
+	stream := &fakeStatusStream{ctx: ctx}
+	require.NoError(t, e.Plan(&pb.LoadRequest{Location: module}, stream))
+
+	// build the graph the way the plan command does
+	g := graph.New()
+	for _, resp := range stream.responses {
+		assert.NotContains(t, resp.String(), hash)
+
+		if details := resp.GetDetails(); details != nil && resp.Run == pb.StatusResponse_FINISHED {
+			g.Add(node.New(resp.Id, details.ToPrintable()))
+		}
+	}
+
+	printer := human.New()
+	printer.InitColors()
+	humanOut, err := prettyprinters.New(printer).Show(ctx, g)
+	require.NoError(t, err)
+	assert.NotContains(t, humanOut, hash)
+	assert.Contains(t, humanOut, redact.Mask)
+
+	jsonlOut, err := prettyprinters.New(&jsonl.Printer{}).Show(ctx, g)
+	require.NoError(t, err)
+	assert.NotContains(t, jsonlOut, hash)
+	assert.Contains(t, jsonlOut, redact.Mask)
+}
+
+func getTestModules(md metadata.MD) (map[string]*planfile.Module, error) {
+	modules := map[string]*planfile.Module{}
+	for _, blob := range md["modules"] {
+		if err := json.Unmarshal([]byte(blob), &modules); err != nil {
+			return nil, err
+		}
+	}
+	return modules, nil
+}
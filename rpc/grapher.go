@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/asteris-llc/converge/helpers/redact"
 	"github.com/asteris-llc/converge/load/registry"
 	"github.com/asteris-llc/converge/render"
 	"github.com/asteris-llc/converge/resource"
@@ -32,6 +33,10 @@ func (g *grapher) Graph(in *pb.LoadRequest, stream pb.Grapher_GraphServer) error
 	logger, ctx := setIDLogger(stream.Context())
 	logger = logger.WithField("function", "grapher.Graph")
 
+	ctx, release := redact.Scoped(ctx)
+	defer release()
+	redactor := redact.FromContext(ctx)
+
 	loaded, err := in.Load(ctx)
 	if err != nil {
 		logger.WithError(err).Error("loading failed")
@@ -63,7 +68,7 @@ func (g *grapher) Graph(in *pb.LoadRequest, stream pb.Grapher_GraphServer) error
 			pb.NewGraphComponent(&pb.GraphComponent_Vertex{
 				Id:      vertex,
 				Kind:    kind,
-				Details: vbytes,
+				Details: []byte(redactor.String(string(vbytes))),
 			}),
 		)
 		if err != nil {
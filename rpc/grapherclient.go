@@ -70,15 +70,20 @@ func (gc *GrapherClient) Graph(ctx context.Context, loc *pb.LoadRequest, opts ..
 		if vertex := container.GetVertex(); vertex != nil {
 			g.Add(node.New(vertex.Id, vertex))
 		} else if edge := container.GetEdge(); edge != nil {
-			var parent bool
+			var parent, order bool
 			for _, attr := range edge.Attributes {
-				if attr == "parent" {
+				switch attr {
+				case "parent":
 					parent = true
+				case "order":
+					order = true
 				}
 			}
 
 			if parent {
 				g.ConnectParent(edge.Source, edge.Dest)
+			} else if order {
+				g.ConnectOrder(edge.Source, edge.Dest)
 			} else {
 				g.Connect(edge.Source, edge.Dest)
 			}
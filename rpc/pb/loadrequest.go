@@ -23,20 +23,34 @@ import (
 	"golang.org/x/net/context"
 )
 
+// Limits gets the parallelism limits requested by the client
+func (lr *LoadRequest) Limits() graph.Limits {
+	limits := graph.Limits{
+		Total: int(lr.Parallelism),
+		Kinds: map[string]int{},
+	}
+
+	for kind, limit := range lr.KindParallelism {
+		limits.Kinds[kind] = int(limit)
+	}
+
+	return limits
+}
+
 // Load gets a graph from a LocationRequest
 func (lr *LoadRequest) Load(ctx context.Context) (*graph.Graph, error) {
 	logger := logging.GetLogger(ctx).WithField("location", lr.Location)
 
-	loaded, err := load.Load(ctx, lr.Location, lr.Verify)
-	if err != nil {
-		logger.WithError(err).Error("could not load")
-		return nil, errors.Wrapf(err, "loading %s", lr.Location)
-	}
-
 	values := render.Values{}
 	for k, v := range lr.Parameters {
 		values[k] = v
 	}
+
+	loaded, err := load.Load(ctx, lr.Location, lr.Verify, values)
+	if err != nil {
+		logger.WithError(err).Error("could not load")
+		return nil, errors.Wrapf(err, "loading %s", lr.Location)
+	}
 	rendered, err := render.Render(ctx, loaded, values)
 	if err != nil {
 		logger.WithError(err).Error("could not render")
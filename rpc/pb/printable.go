@@ -48,6 +48,11 @@ func (sr *StatusResponse_Details) ToPrintable() human.Printable {
 		psr.warning = sr.Warning
 	}
 
+	// set up allowed failure
+	if sr.AllowedFailure != "" {
+		psr.allowedFailure = errors.New(sr.AllowedFailure)
+	}
+
 	return psr
 }
 
@@ -57,6 +62,8 @@ type printableStatusResponse struct {
 	hasChanges bool
 	error      error
 	warning    string
+
+	allowedFailure error
 }
 
 func (psr *printableStatusResponse) Changes() map[string]resource.Diff { return psr.changes }
@@ -64,14 +71,22 @@ func (psr *printableStatusResponse) Messages() []string                { return
 func (psr *printableStatusResponse) HasChanges() bool                  { return psr.hasChanges }
 func (psr *printableStatusResponse) Error() error                      { return psr.error }
 func (psr *printableStatusResponse) Warning() string                   { return psr.warning }
+func (psr *printableStatusResponse) AllowedFailure() error             { return psr.allowedFailure }
 
-// ToPrintable returns a view that can be used in a human printer
+// ToPrintable returns a view that can be used in a human printer. Diffs that
+// carry a unified diff are returned as a resource.UnifiedDiffer.
 func (d *DiffResponse) ToPrintable() resource.Diff {
-	return &printableDiff{
+	diff := &printableDiff{
 		original: d.Original,
 		current:  d.Current,
 		changes:  d.Changes,
 	}
+
+	if len(d.Unified) > 0 {
+		return &printableUnifiedDiff{printableDiff: diff, unified: d.Unified}
+	}
+
+	return diff
 }
 
 type printableDiff struct {
@@ -83,3 +98,10 @@ type printableDiff struct {
 func (pd *printableDiff) Original() string { return pd.original }
 func (pd *printableDiff) Current() string  { return pd.current }
 func (pd *printableDiff) Changes() bool    { return pd.changes }
+
+type printableUnifiedDiff struct {
+	*printableDiff
+	unified []string
+}
+
+func (pd *printableUnifiedDiff) Unified() []string { return pd.unified }
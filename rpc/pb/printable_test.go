@@ -33,3 +33,32 @@ func TestPrintableDiffSatisfiesInterface(t *testing.T) {
 
 	assert.Implements(t, (*resource.Diff)(nil), new(printableDiff))
 }
+
+func TestPrintableUnifiedDiffSatisfiesInterface(t *testing.T) {
+	t.Parallel()
+
+	assert.Implements(t, (*resource.UnifiedDiffer)(nil), new(printableUnifiedDiff))
+}
+
+func TestDiffResponseToPrintable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text", func(t *testing.T) {
+		diff := (&DiffResponse{Original: "a", Current: "b", Changes: true}).ToPrintable()
+
+		_, ok := diff.(resource.UnifiedDiffer)
+		assert.False(t, ok)
+		assert.Equal(t, "a", diff.Original())
+	})
+
+	t.Run("unified", func(t *testing.T) {
+		unified := []string{"@@ -1 +1 @@", "-a", "+b"}
+		diff := (&DiffResponse{Original: "a\n", Current: "b\n", Changes: true, Unified: unified}).ToPrintable()
+
+		unifiedDiff, ok := diff.(resource.UnifiedDiffer)
+		if assert.True(t, ok) {
+			assert.Equal(t, unified, unifiedDiff.Unified())
+			assert.Equal(t, "b\n", unifiedDiff.Current())
+		}
+	})
+}
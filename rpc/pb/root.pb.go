@@ -90,9 +90,12 @@ func (x StatusResponse_Run) String() string {
 func (StatusResponse_Run) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2, 1} }
 
 type LoadRequest struct {
-	Location   string            `protobuf:"bytes,1,opt,name=location" json:"location,omitempty"`
-	Parameters map[string]string `protobuf:"bytes,2,rep,name=parameters" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Verify     bool              `protobuf:"varint,3,opt,name=verify" json:"verify,omitempty"`
+	Location        string            `protobuf:"bytes,1,opt,name=location" json:"location,omitempty"`
+	Parameters      map[string]string `protobuf:"bytes,2,rep,name=parameters" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Verify          bool              `protobuf:"varint,3,opt,name=verify" json:"verify,omitempty"`
+	Plan            string            `protobuf:"bytes,4,opt,name=plan" json:"plan,omitempty"`
+	Parallelism     int32             `protobuf:"varint,5,opt,name=parallelism" json:"parallelism,omitempty"`
+	KindParallelism map[string]int32  `protobuf:"bytes,6,rep,name=kindParallelism" json:"kindParallelism,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 }
 
 func (m *LoadRequest) Reset()                    { *m = LoadRequest{} }
@@ -121,6 +124,27 @@ func (m *LoadRequest) GetVerify() bool {
 	return false
 }
 
+func (m *LoadRequest) GetPlan() string {
+	if m != nil {
+		return m.Plan
+	}
+	return ""
+}
+
+func (m *LoadRequest) GetParallelism() int32 {
+	if m != nil {
+		return m.Parallelism
+	}
+	return 0
+}
+
+func (m *LoadRequest) GetKindParallelism() map[string]int32 {
+	if m != nil {
+		return m.KindParallelism
+	}
+	return nil
+}
+
 type ContentResponse struct {
 	Content string `protobuf:"bytes,1,opt,name=content" json:"content,omitempty"`
 }
@@ -194,6 +218,13 @@ type StatusResponse_Details struct {
 	HasChanges bool                     `protobuf:"varint,3,opt,name=hasChanges" json:"hasChanges,omitempty"`
 	Error      string                   `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
 	Warning    string                   `protobuf:"bytes,5,opt,name=warning" json:"warning,omitempty"`
+	Level      string                   `protobuf:"bytes,6,opt,name=level" json:"level,omitempty"`
+	Fields     string                   `protobuf:"bytes,7,opt,name=fields" json:"fields,omitempty"`
+	// the error of a node that was allowed to fail by its on_failure policy
+	AllowedFailure string `protobuf:"bytes,8,opt,name=allowedFailure" json:"allowedFailure,omitempty"`
+	// a hash of the real values behind any masked sensitive values, so saved
+	// plans can detect when they change
+	Fingerprint string `protobuf:"bytes,9,opt,name=fingerprint" json:"fingerprint,omitempty"`
 }
 
 func (m *StatusResponse_Details) Reset()                    { *m = StatusResponse_Details{} }
@@ -229,8 +260,37 @@ func (m *StatusResponse_Details) GetError() string {
 	return ""
 }
 
+func (m *StatusResponse_Details) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+func (m *StatusResponse_Details) GetFields() string {
+	if m != nil {
+		return m.Fields
+	}
+	return ""
+}
+
+func (m *StatusResponse_Details) GetAllowedFailure() string {
+	if m != nil {
+		return m.AllowedFailure
+	}
+	return ""
+}
+
+func (m *StatusResponse_Details) GetFingerprint() string {
+	if m != nil {
+		return m.Fingerprint
+	}
+	return ""
+}
+
 type StatusResponse_Meta struct {
-	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Id   string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Kind string `protobuf:"bytes,2,opt,name=kind" json:"kind,omitempty"`
 }
 
 func (m *StatusResponse_Meta) Reset()                    { *m = StatusResponse_Meta{} }
@@ -245,10 +305,18 @@ func (m *StatusResponse_Meta) GetId() string {
 	return ""
 }
 
+func (m *StatusResponse_Meta) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
 type DiffResponse struct {
-	Original string `protobuf:"bytes,1,opt,name=original" json:"original,omitempty"`
-	Current  string `protobuf:"bytes,2,opt,name=current" json:"current,omitempty"`
-	Changes  bool   `protobuf:"varint,3,opt,name=changes" json:"changes,omitempty"`
+	Original string   `protobuf:"bytes,1,opt,name=original" json:"original,omitempty"`
+	Current  string   `protobuf:"bytes,2,opt,name=current" json:"current,omitempty"`
+	Changes  bool     `protobuf:"varint,3,opt,name=changes" json:"changes,omitempty"`
+	Unified  []string `protobuf:"bytes,4,rep,name=unified" json:"unified,omitempty"`
 }
 
 func (m *DiffResponse) Reset()                    { *m = DiffResponse{} }
@@ -277,6 +345,13 @@ func (m *DiffResponse) GetChanges() bool {
 	return false
 }
 
+func (m *DiffResponse) GetUnified() []string {
+	if m != nil {
+		return m.Unified
+	}
+	return nil
+}
+
 type GraphComponent struct {
 	// Types that are valid to be assigned to Component:
 	//	*GraphComponent_Vertex_
@@ -963,65 +1038,72 @@ var _Info_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("root.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 957 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x55, 0x4f, 0x6f, 0xe3, 0x44,
-	0x14, 0xaf, 0x1d, 0xa7, 0x69, 0x5e, 0xa2, 0x34, 0xcc, 0x76, 0xbb, 0x5e, 0x2f, 0x62, 0x23, 0x1f,
-	0x76, 0x4b, 0x57, 0x38, 0x90, 0x82, 0x84, 0x56, 0x5a, 0xa1, 0xb4, 0x4d, 0x9b, 0x48, 0xd9, 0x28,
-	0x9a, 0x74, 0x91, 0xf8, 0x23, 0xd0, 0x24, 0x99, 0x38, 0x56, 0x9d, 0x19, 0x33, 0x1e, 0x97, 0x8d,
-	0x10, 0x17, 0x0e, 0x1c, 0xb8, 0x72, 0xe6, 0xc3, 0xf0, 0x05, 0xb8, 0xf0, 0x15, 0x38, 0xf3, 0x19,
-	0xd0, 0x8c, 0xed, 0x92, 0xa6, 0x89, 0xb4, 0x37, 0xff, 0x66, 0x7e, 0xef, 0xf7, 0xde, 0xfc, 0xde,
-	0xf3, 0x0c, 0x80, 0xe0, 0x5c, 0x7a, 0x91, 0xe0, 0x92, 0x23, 0x33, 0x1a, 0x3b, 0xef, 0xfb, 0x9c,
-	0xfb, 0x21, 0x6d, 0x92, 0x28, 0x68, 0x12, 0xc6, 0xb8, 0x24, 0x32, 0xe0, 0x2c, 0x4e, 0x19, 0xce,
-	0x93, 0x6c, 0x57, 0xa3, 0x71, 0x32, 0x6b, 0xd2, 0x45, 0x24, 0x97, 0xe9, 0xa6, 0xfb, 0xa7, 0x01,
-	0x95, 0x3e, 0x27, 0x53, 0x4c, 0x7f, 0x48, 0x68, 0x2c, 0x91, 0x03, 0x7b, 0x21, 0x9f, 0xe8, 0x78,
-	0xdb, 0x68, 0x18, 0x47, 0x65, 0x7c, 0x8b, 0xd1, 0x17, 0x00, 0x11, 0x11, 0x64, 0x41, 0x25, 0x15,
-	0xb1, 0x6d, 0x36, 0x0a, 0x47, 0x95, 0xd6, 0x53, 0x2f, 0x1a, 0x7b, 0x2b, 0x02, 0xde, 0xf0, 0x96,
-	0xd1, 0x61, 0x52, 0x2c, 0xf1, 0x4a, 0x08, 0x3a, 0x84, 0xdd, 0x1b, 0x2a, 0x82, 0xd9, 0xd2, 0x2e,
-	0x34, 0x8c, 0xa3, 0x3d, 0x9c, 0x21, 0xe7, 0x15, 0xec, 0xaf, 0x85, 0xa1, 0x3a, 0x14, 0xae, 0xe9,
-	0x32, 0x2b, 0x41, 0x7d, 0xa2, 0x03, 0x28, 0xde, 0x90, 0x30, 0xa1, 0xb6, 0xa9, 0xd7, 0x52, 0xf0,
-	0xd2, 0xfc, 0xdc, 0x70, 0x5f, 0xc0, 0xfe, 0x19, 0x67, 0x92, 0x32, 0x89, 0x69, 0x1c, 0x71, 0x16,
-	0x53, 0x64, 0x43, 0x69, 0x92, 0x2e, 0x65, 0x12, 0x39, 0x74, 0xff, 0xb5, 0xa0, 0x36, 0x92, 0x44,
-	0x26, 0xf1, 0x2d, 0x19, 0x81, 0x19, 0x4c, 0x53, 0xde, 0xa9, 0x69, 0x1b, 0xd8, 0x0c, 0xa6, 0xc8,
-	0x83, 0x62, 0x2c, 0x89, 0x9f, 0x66, 0xab, 0xb5, 0x6c, 0x75, 0xcc, 0xbb, 0x61, 0x0a, 0xfa, 0x14,
-	0xa7, 0x34, 0x74, 0x04, 0x05, 0x91, 0x30, 0x7d, 0xae, 0x5a, 0xeb, 0x70, 0x03, 0x1b, 0x27, 0x0c,
-	0x2b, 0x0a, 0xfa, 0x14, 0x4a, 0x53, 0x2a, 0x49, 0x10, 0xc6, 0xb6, 0xd5, 0x30, 0x8e, 0x2a, 0x2d,
-	0x67, 0x03, 0xfb, 0x3c, 0x65, 0xe0, 0x9c, 0x8a, 0x5e, 0x80, 0xb5, 0xa0, 0x92, 0xd8, 0x45, 0x1d,
-	0xf2, 0x68, 0x43, 0xc8, 0x6b, 0x2a, 0x09, 0xd6, 0x24, 0xe7, 0x57, 0x13, 0x4a, 0x99, 0x82, 0x6a,
-	0xe8, 0x82, 0xc6, 0x31, 0xf1, 0x69, 0x6c, 0x1b, 0x8d, 0x82, 0x6a, 0x68, 0x8e, 0x51, 0x1b, 0x4a,
-	0x93, 0x39, 0x61, 0x6a, 0x2b, 0xed, 0xe6, 0xf3, 0xed, 0xa5, 0x78, 0x67, 0x29, 0x33, 0xed, 0x6a,
-	0x1e, 0x87, 0x3e, 0x00, 0x98, 0x93, 0x38, 0xdb, 0xcb, 0xda, 0xba, 0xb2, 0xa2, 0xba, 0x46, 0x85,
-	0xe0, 0x42, 0x9f, 0xb5, 0x8c, 0x53, 0xa0, 0xda, 0xf3, 0x23, 0x11, 0x2c, 0x60, 0xbe, 0x3e, 0x50,
-	0x19, 0xe7, 0xd0, 0xe9, 0x43, 0x75, 0x35, 0xd1, 0x86, 0x39, 0x78, 0xb6, 0x3a, 0x07, 0x95, 0x56,
-	0x5d, 0x95, 0x7c, 0x1e, 0xcc, 0x66, 0x79, 0xc1, 0x2b, 0x93, 0xe1, 0x1c, 0x82, 0xa5, 0x6c, 0x41,
-	0xb5, 0xff, 0x3b, 0xac, 0xba, 0xeb, 0x9e, 0x40, 0x51, 0x77, 0x0f, 0x3d, 0x84, 0xf7, 0xde, 0x0c,
-	0x46, 0xc3, 0xce, 0x59, 0xef, 0xa2, 0xd7, 0x39, 0xff, 0x7e, 0x74, 0xd5, 0xbe, 0xec, 0xd4, 0x77,
-	0xd0, 0x1e, 0x58, 0xc3, 0x7e, 0x7b, 0x50, 0x37, 0x50, 0x19, 0x8a, 0xed, 0xe1, 0xb0, 0xff, 0x55,
-	0xdd, 0x74, 0x3f, 0x83, 0x02, 0x4e, 0x18, 0x7a, 0x00, 0xfb, 0xab, 0x21, 0xf8, 0xcd, 0xa0, 0xbe,
-	0x83, 0x2a, 0x50, 0x1a, 0x5d, 0xb5, 0xf1, 0x55, 0xe7, 0xbc, 0x6e, 0xa0, 0x2a, 0xec, 0x5d, 0xf4,
-	0x06, 0xbd, 0x51, 0xb7, 0x73, 0x5e, 0x37, 0xdd, 0xef, 0xa0, 0xba, 0x5a, 0x9e, 0x6a, 0x08, 0x17,
-	0x81, 0x1f, 0x30, 0x12, 0xe6, 0x7f, 0x58, 0x8e, 0xf5, 0xd8, 0x26, 0x42, 0xa8, 0xb1, 0x35, 0xb3,
-	0xb1, 0x4d, 0xa1, 0xde, 0xb9, 0x63, 0x72, 0x0e, 0xdd, 0x3f, 0x4c, 0xa8, 0x5d, 0x0a, 0x12, 0xcd,
-	0xcf, 0xf8, 0x22, 0xe2, 0x4c, 0x91, 0x4f, 0xf4, 0x7f, 0x26, 0xe9, 0x5b, 0x9d, 0xa0, 0xd2, 0x7a,
-	0xac, 0x3c, 0xba, 0xcb, 0xf1, 0xbe, 0xd4, 0x84, 0xee, 0x0e, 0xce, 0xa8, 0xe8, 0x23, 0xb0, 0xe8,
-	0xd4, 0xcf, 0x6d, 0x7d, 0xb4, 0x21, 0xa4, 0x33, 0xf5, 0x69, 0x77, 0x07, 0x6b, 0x9a, 0x73, 0x01,
-	0xbb, 0xa9, 0xc4, 0xba, 0xb9, 0x08, 0x81, 0x75, 0x1d, 0xb0, 0x69, 0x76, 0x02, 0xfd, 0xad, 0xca,
-	0xcf, 0x87, 0x5e, 0x95, 0x5f, 0xbd, 0x1d, 0x6c, 0x07, 0x83, 0xa5, 0x74, 0xd5, 0xdd, 0x10, 0xf3,
-	0x44, 0x4c, 0x68, 0xa6, 0x94, 0x21, 0xa5, 0x36, 0xa5, 0x71, 0xee, 0x87, 0xfe, 0x56, 0x43, 0x47,
-	0xa4, 0x14, 0xc1, 0x38, 0x91, 0xda, 0x0f, 0x35, 0xd5, 0x2b, 0x2b, 0xa7, 0x15, 0x28, 0x4f, 0xf2,
-	0xaa, 0x5b, 0xbf, 0x99, 0xb0, 0xd7, 0x79, 0x4b, 0x27, 0x89, 0xe4, 0x02, 0x7d, 0x0b, 0x95, 0x2e,
-	0x25, 0xa1, 0x9c, 0x9f, 0xcd, 0xe9, 0xe4, 0x1a, 0xed, 0xaf, 0xdd, 0x5e, 0x0e, 0xba, 0xff, 0x03,
-	0xb8, 0xcf, 0x7e, 0xf9, 0xfb, 0x9f, 0xdf, 0xcd, 0x86, 0xfb, 0x44, 0xdf, 0xaf, 0x37, 0x9f, 0x34,
-	0x17, 0x64, 0x32, 0x0f, 0x18, 0x6d, 0xce, 0xb5, 0xd2, 0x44, 0x29, 0xbd, 0x34, 0x8e, 0x3f, 0x36,
-	0xd0, 0x00, 0xac, 0x61, 0x48, 0xd8, 0xbb, 0xc9, 0x3e, 0xd5, 0xb2, 0x8f, 0xdd, 0x83, 0x75, 0xd9,
-	0x28, 0x24, 0x2c, 0xd5, 0x1b, 0x42, 0xb1, 0x1d, 0x45, 0xe1, 0xf2, 0xdd, 0x04, 0x1b, 0x5a, 0xd0,
-	0x71, 0x1f, 0xae, 0x0b, 0x12, 0xa5, 0xa1, 0x15, 0x5b, 0x7f, 0x19, 0x50, 0xc5, 0x34, 0xb5, 0xb6,
-	0xcb, 0x63, 0x89, 0xbe, 0x86, 0xf2, 0x25, 0x95, 0xa7, 0x01, 0x23, 0x62, 0x89, 0x0e, 0xbd, 0xf4,
-	0xa9, 0xf0, 0xf2, 0xa7, 0xc2, 0xeb, 0xa8, 0xa7, 0xc2, 0x79, 0xa0, 0xb2, 0xad, 0x5d, 0xb1, 0x79,
-	0x3a, 0x64, 0xe7, 0xe9, 0x44, 0xa6, 0x1b, 0x37, 0xc7, 0xa9, 0xdc, 0x58, 0x6b, 0xbf, 0xe6, 0xd3,
-	0x24, 0xa4, 0xf7, 0x8f, 0xb0, 0x51, 0xb4, 0xa9, 0x45, 0x3f, 0x44, 0xcf, 0xef, 0x8b, 0x2e, 0xb4,
-	0x4e, 0xdc, 0xfc, 0x29, 0x7f, 0x8f, 0x5e, 0x1d, 0x1f, 0xff, 0xdc, 0xfa, 0x06, 0x4a, 0x7a, 0x4a,
-	0xa9, 0x50, 0x6e, 0xe9, 0xcf, 0x2d, 0x6e, 0xdd, 0x1d, 0xe6, 0xed, 0x6e, 0xf9, 0x8a, 0x97, 0xba,
-	0x75, 0x05, 0x56, 0x8f, 0xcd, 0x38, 0xea, 0x83, 0x35, 0x0c, 0x98, 0xbf, 0xd5, 0x9f, 0x2d, 0xeb,
-	0xee, 0x81, 0xce, 0x51, 0x43, 0xd5, 0x3c, 0x47, 0x14, 0x30, 0x7f, 0xbc, 0xab, 0x59, 0x27, 0xff,
-	0x05, 0x00, 0x00, 0xff, 0xff, 0x5b, 0x23, 0xe4, 0xb4, 0xc6, 0x07, 0x00, 0x00,
+	// 1072 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8d, 0x55, 0xdf, 0x6f, 0xe3, 0x44,
+	0x10, 0xbe, 0x38, 0x4e, 0x93, 0x8c, 0xa3, 0x24, 0xec, 0xf5, 0x7a, 0x3e, 0x1f, 0xe2, 0x22, 0x0b,
+	0xf5, 0x4a, 0x4f, 0x38, 0xe0, 0x82, 0x84, 0x4e, 0x3a, 0xa1, 0x36, 0x4d, 0xdb, 0x88, 0x5c, 0x14,
+	0xb9, 0x3d, 0x24, 0x7e, 0x48, 0x68, 0x13, 0x6f, 0x12, 0xab, 0x8e, 0x6d, 0xd6, 0x76, 0xaf, 0x11,
+	0xe2, 0x85, 0x47, 0x78, 0xe4, 0x99, 0x7f, 0x89, 0x17, 0xfe, 0x05, 0xfe, 0x0a, 0x1e, 0x10, 0xbb,
+	0x6b, 0x3b, 0x38, 0x69, 0xa2, 0xeb, 0xdb, 0xce, 0xce, 0x37, 0xdf, 0xce, 0xce, 0x7c, 0xb3, 0x0b,
+	0x40, 0x7d, 0x3f, 0x32, 0x02, 0xea, 0x47, 0x3e, 0x92, 0x82, 0x91, 0xf6, 0xfe, 0xd4, 0xf7, 0xa7,
+	0x2e, 0x69, 0xe3, 0xc0, 0x69, 0x63, 0xcf, 0xf3, 0x23, 0x1c, 0x39, 0xbe, 0x17, 0x26, 0x08, 0xed,
+	0x69, 0xea, 0x15, 0xd6, 0x28, 0x9e, 0xb4, 0xc9, 0x3c, 0x88, 0x16, 0x89, 0x53, 0xff, 0xad, 0x08,
+	0x4a, 0xdf, 0xc7, 0xb6, 0x45, 0x7e, 0x8c, 0x49, 0x18, 0x21, 0x0d, 0x2a, 0xae, 0x3f, 0x16, 0xf1,
+	0x6a, 0xa1, 0x55, 0x38, 0xa8, 0x5a, 0x4b, 0x1b, 0x7d, 0x09, 0x10, 0x60, 0x8a, 0xe7, 0x24, 0x22,
+	0x34, 0x54, 0xa5, 0x56, 0xf1, 0x40, 0x31, 0x9f, 0x19, 0xc1, 0xc8, 0xc8, 0x11, 0x18, 0xc3, 0x25,
+	0xa2, 0xeb, 0x45, 0x74, 0x61, 0xe5, 0x42, 0xd0, 0x1e, 0xec, 0xdc, 0x10, 0xea, 0x4c, 0x16, 0x6a,
+	0x91, 0x51, 0x57, 0xac, 0xd4, 0x42, 0x08, 0xe4, 0xc0, 0xc5, 0x9e, 0x2a, 0x8b, 0x03, 0xc5, 0x1a,
+	0xb5, 0x40, 0xe1, 0x91, 0xae, 0x4b, 0x5c, 0x27, 0x9c, 0xab, 0x25, 0xe6, 0x2a, 0x59, 0xf9, 0x2d,
+	0x34, 0x80, 0xc6, 0xb5, 0xe3, 0xd9, 0xc3, 0x1c, 0x6a, 0x47, 0xe4, 0xf4, 0xe1, 0x7a, 0x4e, 0x5f,
+	0xad, 0xc2, 0x92, 0xc4, 0xd6, 0x83, 0xb5, 0x57, 0xd0, 0x58, 0x4b, 0x1e, 0x35, 0xa1, 0x78, 0x4d,
+	0x16, 0x69, 0x21, 0xf8, 0x12, 0xed, 0x42, 0xe9, 0x06, 0xbb, 0x31, 0x61, 0xd7, 0xe7, 0x7b, 0x89,
+	0xf1, 0x52, 0xfa, 0xa2, 0xa0, 0x9d, 0xc0, 0xee, 0xa6, 0x73, 0xde, 0xc5, 0x51, 0xca, 0x71, 0xe8,
+	0x2f, 0xa0, 0xd1, 0xf1, 0xbd, 0x88, 0x78, 0x91, 0x45, 0xc2, 0x80, 0xb5, 0x90, 0x20, 0x15, 0xca,
+	0xe3, 0x64, 0x2b, 0xa5, 0xc8, 0x4c, 0xfd, 0x9f, 0x12, 0xd4, 0x2f, 0x59, 0xab, 0xe3, 0x70, 0x09,
+	0x46, 0x20, 0x39, 0x76, 0x82, 0x3b, 0x91, 0xd4, 0x82, 0xc5, 0x2c, 0x64, 0x40, 0x29, 0x8c, 0xf0,
+	0x34, 0x39, 0xad, 0x6e, 0xaa, 0xbc, 0x38, 0xab, 0x61, 0xdc, 0x9c, 0x12, 0x2b, 0x81, 0xa1, 0x03,
+	0x28, 0xd2, 0xd8, 0x13, 0x1d, 0xaa, 0x9b, 0x7b, 0x1b, 0xd0, 0x56, 0xec, 0x59, 0x1c, 0x82, 0x3e,
+	0x83, 0xb2, 0x4d, 0x22, 0xec, 0xb8, 0xa1, 0xe8, 0x9c, 0x62, 0x6a, 0x1b, 0xd0, 0xa7, 0x09, 0xc2,
+	0xca, 0xa0, 0xe8, 0x05, 0xc8, 0xac, 0xc4, 0x58, 0x74, 0x54, 0x31, 0x1f, 0x6f, 0x08, 0x79, 0xcd,
+	0xdc, 0x96, 0x00, 0x69, 0xff, 0x4a, 0x50, 0x4e, 0x19, 0xb8, 0x34, 0xe7, 0x24, 0x0c, 0x59, 0x8e,
+	0x21, 0xbb, 0x62, 0x91, 0x4b, 0x33, 0xb3, 0xd1, 0x31, 0xab, 0xd2, 0x0c, 0x7b, 0xdc, 0x95, 0xe8,
+	0xf2, 0xf9, 0xf6, 0x54, 0x8c, 0x4e, 0x82, 0x4c, 0x64, 0x90, 0xc5, 0xa1, 0x0f, 0x00, 0x66, 0x38,
+	0x4c, 0x7d, 0xa9, 0x40, 0x73, 0x3b, 0xbc, 0x6b, 0x84, 0x52, 0x9f, 0xa6, 0x2a, 0x4d, 0x0c, 0xde,
+	0x9e, 0xb7, 0x98, 0x7a, 0x8e, 0x37, 0x15, 0x17, 0x62, 0xed, 0x49, 0x4d, 0x8e, 0x77, 0xc9, 0x0d,
+	0x71, 0x99, 0x28, 0x05, 0x5e, 0x18, 0x7c, 0x04, 0x26, 0x0e, 0x71, 0xed, 0x50, 0x2d, 0x8b, 0xed,
+	0xd4, 0x42, 0xfb, 0x50, 0x67, 0xb2, 0xf1, 0xdf, 0x12, 0xfb, 0x8c, 0xe5, 0x18, 0x53, 0xa2, 0x56,
+	0x84, 0x7f, 0x6d, 0x97, 0x8f, 0xc5, 0x84, 0xb1, 0x13, 0x1a, 0x50, 0x87, 0x49, 0xa2, 0x2a, 0x40,
+	0xf9, 0x2d, 0xad, 0x0f, 0xb5, 0xfc, 0x05, 0x37, 0xe8, 0x6f, 0x3f, 0xaf, 0x3f, 0xc5, 0x6c, 0xf2,
+	0x52, 0x9d, 0x3a, 0x93, 0x49, 0x56, 0xa8, 0xbc, 0xaa, 0x0f, 0x41, 0xe6, 0xed, 0x40, 0xf5, 0xff,
+	0x95, 0x25, 0x54, 0xc5, 0x46, 0x96, 0xcf, 0x4f, 0x3a, 0x06, 0x62, 0xad, 0x1f, 0x41, 0x49, 0x28,
+	0x09, 0x3d, 0x82, 0xf7, 0xde, 0x0c, 0x2e, 0x87, 0xdd, 0x4e, 0xef, 0xac, 0xd7, 0x3d, 0xfd, 0xe1,
+	0xf2, 0xea, 0xf8, 0xbc, 0xdb, 0x7c, 0x80, 0x2a, 0x20, 0x0f, 0xfb, 0xc7, 0x83, 0x66, 0x01, 0x55,
+	0xa1, 0x74, 0x3c, 0x1c, 0xf6, 0xbf, 0x69, 0x4a, 0xfa, 0xe7, 0x50, 0x64, 0x82, 0x42, 0x0f, 0xa1,
+	0x91, 0x0f, 0xb1, 0xde, 0x0c, 0x58, 0x80, 0x02, 0x65, 0x16, 0x6b, 0x5d, 0x75, 0x4f, 0x59, 0x4c,
+	0x0d, 0x2a, 0x67, 0xbd, 0x41, 0xef, 0xf2, 0x82, 0x59, 0x92, 0x7e, 0x0b, 0xb5, 0x7c, 0xca, 0x5c,
+	0x1c, 0x3e, 0x75, 0xa6, 0x8e, 0x87, 0xdd, 0xec, 0xdd, 0xca, 0x6c, 0x31, 0x42, 0x31, 0xa5, 0x7c,
+	0x84, 0xa4, 0x74, 0x84, 0x12, 0x53, 0x78, 0x56, 0x1a, 0xbe, 0x54, 0x03, 0xf3, 0xc4, 0x9e, 0xc3,
+	0x9a, 0x63, 0xb3, 0x7e, 0x73, 0xad, 0x65, 0xa6, 0xfe, 0x87, 0x04, 0xf5, 0x73, 0x8a, 0x83, 0x59,
+	0xc7, 0x9f, 0xb3, 0xb3, 0x39, 0xcd, 0x91, 0x78, 0xd7, 0x22, 0x72, 0x2b, 0x8e, 0x56, 0xcc, 0x27,
+	0xbc, 0xa2, 0xab, 0x18, 0xe3, 0x6b, 0x01, 0xb8, 0x78, 0x60, 0xa5, 0x50, 0xf4, 0x31, 0xc8, 0xc4,
+	0x9e, 0x66, 0x4d, 0x78, 0xbc, 0x21, 0xa4, 0xcb, 0xdc, 0x2c, 0x40, 0xc0, 0xb4, 0x33, 0xd8, 0x49,
+	0x28, 0xee, 0xd3, 0x0a, 0x9e, 0x7e, 0x36, 0x9a, 0xfc, 0x62, 0xb5, 0xe5, 0xf8, 0x69, 0x16, 0xc8,
+	0x9c, 0x97, 0x0b, 0x31, 0xf4, 0x63, 0x3a, 0x26, 0x29, 0x53, 0x6a, 0x71, 0x36, 0x9b, 0xbd, 0x99,
+	0x19, 0x1b, 0x5f, 0xf3, 0xd1, 0xc0, 0x51, 0x44, 0x9d, 0x51, 0x1c, 0x89, 0x4a, 0xf1, 0x7a, 0xe4,
+	0x76, 0x4e, 0x14, 0xa8, 0x8e, 0xb3, 0xac, 0xcd, 0x5f, 0x25, 0xa8, 0x74, 0x6f, 0xc9, 0x38, 0x8e,
+	0xd8, 0x78, 0x7c, 0x0f, 0xca, 0x05, 0xc1, 0x6e, 0x34, 0xeb, 0xcc, 0xc8, 0xf8, 0x1a, 0x35, 0xd6,
+	0x5e, 0x66, 0x0d, 0xdd, 0x1d, 0x53, 0x7d, 0xff, 0x97, 0xbf, 0xfe, 0xfe, 0x5d, 0x6a, 0xe9, 0x4f,
+	0xc5, 0x7f, 0x76, 0xf3, 0x69, 0x7b, 0x8e, 0xc7, 0x33, 0xc7, 0x23, 0xed, 0x99, 0x60, 0x1a, 0x73,
+	0xa6, 0x97, 0x85, 0xc3, 0x4f, 0x0a, 0xec, 0x07, 0x90, 0x87, 0xfc, 0xaf, 0xb8, 0x17, 0xed, 0x33,
+	0x41, 0xfb, 0x44, 0xdf, 0x5d, 0xa7, 0xe5, 0xdf, 0x4d, 0xc2, 0x37, 0x64, 0xb2, 0x0c, 0x02, 0x77,
+	0x71, 0x3f, 0xc2, 0x96, 0x20, 0xd4, 0xf4, 0x47, 0xeb, 0x84, 0x98, 0x73, 0x08, 0x46, 0xf3, 0xcf,
+	0x02, 0xd4, 0x58, 0x80, 0x28, 0xed, 0x85, 0xcf, 0x4a, 0xf9, 0x2d, 0x54, 0xcf, 0x49, 0x74, 0xc2,
+	0x74, 0xc9, 0x46, 0x73, 0xcf, 0x48, 0xbe, 0x66, 0x23, 0xfb, 0x9a, 0x8d, 0x2e, 0xff, 0x9a, 0xb5,
+	0x87, 0xfc, 0xb4, 0xb5, 0x8f, 0x20, 0x3b, 0x0e, 0xa9, 0xd9, 0x71, 0x34, 0xe5, 0x0d, 0xdb, 0xa3,
+	0x84, 0x6e, 0x24, 0xb8, 0x5f, 0xfb, 0x76, 0xec, 0x92, 0xbb, 0x57, 0xd8, 0x48, 0xda, 0x16, 0xa4,
+	0x1f, 0xa1, 0xe7, 0x77, 0x49, 0xe7, 0x82, 0x27, 0x6c, 0xff, 0x94, 0xfd, 0xff, 0xaf, 0x0e, 0x0f,
+	0x7f, 0x36, 0xbf, 0x83, 0xb2, 0x50, 0x29, 0xa1, 0xbc, 0x5a, 0x62, 0xb9, 0xa5, 0x5a, 0xab, 0x62,
+	0xde, 0x5e, 0xad, 0x29, 0xc7, 0x25, 0xd5, 0xba, 0x02, 0xb9, 0xe7, 0x4d, 0x7c, 0xd4, 0x67, 0x7d,
+	0xe5, 0x4f, 0xe8, 0xb6, 0xfa, 0x6c, 0xd9, 0xd7, 0x77, 0xc5, 0x19, 0x75, 0x54, 0xcb, 0xce, 0x08,
+	0x18, 0xcb, 0x68, 0x47, 0xa0, 0x8e, 0xfe, 0x03, 0x48, 0xd1, 0xfa, 0x85, 0x36, 0x09, 0x00, 0x00,
 }
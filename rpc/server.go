@@ -22,6 +22,7 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/asteris-llc/converge/graph"
 	"github.com/asteris-llc/converge/helpers/logging"
 	"github.com/asteris-llc/converge/rpc/pb"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
@@ -38,13 +39,17 @@ type Server struct {
 	// Serving
 	ResourceRoot         string
 	EnableBinaryDownload bool
+
+	// Execution
+	Limits        graph.Limits
+	RemoveAllowed []string
 }
 
 // newGRPC constructs all GRPC servers and handlers
 func (s *Server) newGRPC() (*grpc.Server, error) {
 	server := grpc.NewServer(s.Security.Server()...)
 
-	pb.RegisterExecutorServer(server, &executor{})
+	pb.RegisterExecutorServer(server, &executor{limits: s.Limits, removeAllowed: s.RemoveAllowed})
 	pb.RegisterGrapherServer(server, &grapher{})
 	pb.RegisterResourceHostServer(
 		server,
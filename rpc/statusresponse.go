@@ -15,37 +15,186 @@
 package rpc
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"sort"
+
 	"github.com/asteris-llc/converge/graph/node"
+	"github.com/asteris-llc/converge/helpers/redact"
+	"github.com/asteris-llc/converge/load/registry"
 	"github.com/asteris-llc/converge/prettyprinters/human"
+	"github.com/asteris-llc/converge/resource"
 	"github.com/asteris-llc/converge/rpc/pb"
+	"github.com/pkg/errors"
 )
 
-func statusResponseFromPrintable(meta *node.Node, p human.Printable, stage pb.StatusResponse_Stage, run pb.StatusResponse_Run) *pb.StatusResponse {
+func statusResponseFromPrintable(redactor *redact.Redactor, salt []byte, meta *node.Node, p human.Printable, stage pb.StatusResponse_Stage, run pb.StatusResponse_Run) *pb.StatusResponse {
+	masker := newFingerprinter(redactor, salt)
+
 	resp := &pb.StatusResponse{
 		Id:    meta.ID, // TODO: deprecated, remove in 0.4.0
 		Stage: stage,
 		Run:   run,
 		Meta:  pb.MetaFromNode(meta),
 
+		// sensitive values are masked before they leave the server. Changes
+		// are computed from the real values so they're still reported
+		// correctly.
 		Details: &pb.StatusResponse_Details{
-			Messages:   p.Messages(),
+			Messages:   masker.Strings(p.Messages()),
 			Changes:    map[string]*pb.DiffResponse{},
 			HasChanges: p.HasChanges(),
-			Warning:    p.Warning(),
+			Warning:    masker.String(p.Warning()),
 		},
 	}
 
 	if err := p.Error(); err != nil {
-		resp.Details.Error = err.Error()
+		resp.Details.Error = masker.String(err.Error())
+	}
+
+	if failer, ok := p.(human.AllowedFailer); ok {
+		if err := failer.AllowedFailure(); err != nil {
+			resp.Details.AllowedFailure = masker.String(err.Error())
+		}
+	}
+
+	if tasker, ok := p.(resource.Tasker); ok {
+		describeTask(resp, tasker, masker)
 	}
 
-	for key, diff := range p.Changes() {
-		resp.Details.Changes[key] = &pb.DiffResponse{
-			Original: diff.Original(),
-			Current:  diff.Current(),
+	changes := p.Changes()
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		diff := changes[key]
+		diffResp := &pb.DiffResponse{
+			Original: masker.String(diff.Original()),
+			Current:  masker.String(diff.Current()),
 			Changes:  diff.Changes(),
 		}
+
+		if unified, ok := diff.(resource.UnifiedDiffer); ok {
+			diffResp.Unified = masker.Strings(unified.Unified())
+		}
+
+		resp.Details.Changes[key] = diffResp
 	}
 
+	resp.Details.Fingerprint = masker.Sum()
+
 	return resp
 }
+
+// describeTask fills in the kind, status level, and exported fields of the
+// task behind a result. These are used to build saved plans.
+func describeTask(resp *pb.StatusResponse, tasker resource.Tasker, masker *fingerprinter) {
+	if status := tasker.GetStatus(); status != nil {
+		resp.Details.Level = status.StatusCode().String()
+	}
+
+	task, ok := resource.ResolveTask(tasker)
+	if !ok {
+		return
+	}
+
+	if kind, ok := registry.NameForType(task); ok {
+		resp.Meta.Kind = kind
+	}
+
+	fields, err := resource.LookupMapFromInterface(task)
+	if err != nil || len(fields) == 0 {
+		return
+	}
+
+	if sensitive, ok := task.(redact.SensitiveFielder); ok {
+		for _, name := range sensitive.SensitiveFields() {
+			if val, ok := fields[name]; ok {
+				masker.Mask(fmt.Sprint(val))
+				fields[name] = redact.Mask
+			}
+		}
+	}
+
+	// fields that can't be serialized are left out rather than failing the
+	// whole response
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+
+	resp.Details.Fields = masker.String(string(encoded))
+}
+
+// saltSize is the size of the random salt fingerprints are keyed with
+const saltSize = 32
+
+// newSalt returns a random salt for fingerprints. A new salt is made for every
+// plan and saved with it, so that the same secret has a different fingerprint
+// in every plan and fingerprints can't be looked up in precomputed tables.
+func newSalt() ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, errors.Wrap(err, "could not generate salt")
+	}
+	return salt, nil
+}
+
+// fingerprinter masks sensitive values, and hashes the real values it masks
+// with an HMAC keyed by the salt of the plan. Saved plans only see masked
+// values, so they compare the hash to tell when a sensitive value has changed.
+type fingerprinter struct {
+	redactor *redact.Redactor
+	hash     hash.Hash
+	masked   bool
+}
+
+func newFingerprinter(redactor *redact.Redactor, salt []byte) *fingerprinter {
+	return &fingerprinter{redactor: redactor, hash: hmac.New(sha256.New, salt)}
+}
+
+// String masks sensitive values in a string
+func (f *fingerprinter) String(in string) string {
+	out := f.redactor.String(in)
+	if out != in {
+		f.Mask(in)
+	}
+	return out
+}
+
+// Strings masks sensitive values in a slice of strings
+func (f *fingerprinter) Strings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = f.String(s)
+	}
+	return out
+}
+
+// Mask records a value that was masked
+func (f *fingerprinter) Mask(in string) {
+	f.masked = true
+	f.hash.Write([]byte(in))
+	f.hash.Write([]byte{0})
+}
+
+// Sum returns the hash of every masked value, or an empty string if nothing
+// was masked
+func (f *fingerprinter) Sum() string {
+	if !f.masked {
+		return ""
+	}
+	return hex.EncodeToString(f.hash.Sum(nil))
+}
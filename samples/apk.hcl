@@ -0,0 +1,4 @@
+package.apk "curl" {
+  name  = "curl"
+  state = "present"
+}
@@ -2,3 +2,8 @@ package.apt "mc" {
   name  = "mc"
   state = "present"
 }
+
+package.apt "tools" {
+  packages = ["jq", "tree", "htop"]
+  state    = "present"
+}
@@ -0,0 +1,16 @@
+package.apt "transport" {
+  packages = ["apt-transport-https", "ca-certificates"]
+}
+
+package.apt.repository "docker" {
+  uri           = "https://download.docker.com/linux/ubuntu"
+  distribution  = "xenial"
+  components    = ["stable"]
+  architectures = ["amd64"]
+  key_url       = "https://download.docker.com/linux/ubuntu/gpg"
+  depends       = ["package.apt.transport"]
+}
+
+package.apt "docker" {
+  name = "docker-ce"
+}
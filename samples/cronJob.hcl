@@ -0,0 +1,11 @@
+cron.job "backup" {
+  name     = "backup"
+  schedule = "0 4 * * *"
+  command  = "/usr/local/bin/backup --date $(date +%F)"
+
+  env {
+    PATH = "/usr/local/bin:/usr/bin:/bin"
+  }
+
+  systemd_timer = true
+}
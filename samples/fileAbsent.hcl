@@ -0,0 +1,12 @@
+# file.content and file.directory remove their destination when state is absent
+file.content "old-config" {
+  destination = "old.conf"
+  state       = "absent"
+}
+
+# directories that are not empty are only removed if recursive is set
+file.directory "old-releases" {
+  destination = "releases"
+  state       = "absent"
+  recursive   = true
+}
@@ -0,0 +1,14 @@
+# file resources can back up the file they replace. The location of the backup
+# is exported as backuppath, so other resources can refer to it.
+file.content "config" {
+  destination = "app.conf"
+  content     = "version = 2"
+  backup      = true
+  backup_dir  = "backups"
+  backup_keep = 3
+}
+
+task "rollback-script" {
+  check = "test -f rollback.sh"
+  apply = "echo 'cp {{lookup `file.content.config.backuppath`}} app.conf' > rollback.sh"
+}
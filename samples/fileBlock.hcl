@@ -0,0 +1,12 @@
+# file.block manages the lines between two markers in a file, leaving the rest
+# of it alone
+file.block "hosts" {
+  destination = "hosts"
+  marker      = "# {mark} converge hosts"
+  create      = true
+
+  content = <<EOF
+10.0.0.10 db.local
+10.0.0.11 cache.local
+EOF
+}
@@ -0,0 +1,15 @@
+# file.line manages a single line in a file, leaving the rest of it alone
+file.line "forwarding" {
+  destination = "sysctl.conf"
+  line        = "net.ipv4.ip_forward = 1"
+  match       = "^net\\.ipv4\\.ip_forward\\s*="
+  create      = true
+}
+
+# lines can also be removed
+file.line "no-swappiness" {
+  destination = "sysctl.conf"
+  match       = "^vm\\.swappiness"
+  state       = "absent"
+  depends     = ["file.line.forwarding"]
+}
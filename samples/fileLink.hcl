@@ -0,0 +1,19 @@
+file.content "target" {
+  destination = "link-target.txt"
+  content     = "linked"
+}
+
+# file.link creates symbolic links by default
+file.link "symbolic" {
+  destination = "symbolic-link.txt"
+  target      = "link-target.txt"
+  depends     = ["file.content.target"]
+}
+
+# hard links point to the same file as their target
+file.link "hard" {
+  destination = "hard-link.txt"
+  target      = "link-target.txt"
+  type        = "hard"
+  depends     = ["file.content.target"]
+}
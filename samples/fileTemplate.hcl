@@ -0,0 +1,10 @@
+param "hostname" {
+  default = "converge.local"
+}
+
+file.template "motd" {
+  source      = "templates/motd.tmpl"
+  destination = "motd.txt"
+  mode        = "0644"
+  backup      = true
+}
@@ -0,0 +1,24 @@
+# for_each expands a resource once per element of a list or map. Each copy is
+# named after its key, and can use it in templates as `each.key` and `each.value`.
+
+param "shells" {
+  default {
+    alice = "/bin/zsh"
+    bob   = "/bin/bash"
+  }
+}
+
+file.content "shells" {
+  for_each    = "{{paramMap `shells`}}"
+  destination = "target/{{each.key}}.shell"
+  content     = "{{each.value}}"
+}
+
+# the expanded set can be depended on as a whole by the original name
+module "forEachModule.hcl" "dirs" {
+  params = {
+    dirs = ["first", "second"]
+  }
+
+  depends = ["file.content.shells"]
+}
@@ -0,0 +1,9 @@
+/* creates one directory per element of the dirs param */
+param "dirs" {
+  default = []
+}
+
+file.directory "dirs" {
+  for_each    = "{{paramList `dirs`}}"
+  destination = "target/{{each.key}}"
+}
@@ -0,0 +1,14 @@
+param "message" {
+  default = "Hello, World!"
+}
+
+file.content "config" {
+  destination = "target/handlers.txt"
+  content     = "{{param `message`}}"
+  notifies    = ["task.reload"]
+}
+
+task "reload" {
+  check = "exit 0"
+  apply = "echo reloading after config changed"
+}
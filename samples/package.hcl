@@ -0,0 +1,4 @@
+package "tools" {
+  packages = ["curl", "jq"]
+  state    = "present"
+}
@@ -0,0 +1,4 @@
+package.pacman "mc" {
+  name  = "mc"
+  state = "present"
+}
@@ -0,0 +1,10 @@
+param "api_token" {
+  description = "token used to authenticate with the API"
+  sensitive   = true
+  default     = "not-a-real-token"
+}
+
+file.content "config" {
+  destination = "api.conf"
+  content     = "token = {{param `api_token`}}\n"
+}
@@ -0,0 +1,21 @@
+# params can declare a type and constraints. Try running this with
+# `-p port=http` or `-p env=staging` to see the validation errors.
+
+param "port" {
+  type        = "int"
+  description = "the port to listen on"
+  min         = 1
+  max         = 65535
+  default     = 8080
+}
+
+param "env" {
+  type           = "string"
+  allowed_values = ["dev", "prod"]
+  default        = "dev"
+}
+
+file.content "config" {
+  destination = "typed.txt"
+  content     = "listen={{param `port`}} env={{param `env`}}"
+}
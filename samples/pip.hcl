@@ -0,0 +1,5 @@
+package.pip "requests" {
+  name    = "requests"
+  version = ">= 2.12, < 3.0"
+  pip     = "pip3"
+}
@@ -0,0 +1,14 @@
+systemd.unit.state "ssh-health" {
+  unit        = "ssh.service"
+  healthcheck = true
+}
+
+systemd.unit.state "apt-daily-health" {
+  unit        = "apt-daily.timer"
+  healthcheck = true
+}
+
+file.content "apt-daily-schedule" {
+  destination = "out.txt"
+  content     = "next run: {{lookup `systemd.unit.state.apt-daily-health.next_elapse`}}"
+}
@@ -1,6 +1,7 @@
 systemd.unit.state "ssh" {
-  unit  = "ssh.service"
-  state = "running"
+  unit   = "ssh.service"
+  state  = "running"
+  enable = true
 }
 
 systemd.unit.state "acpid" {
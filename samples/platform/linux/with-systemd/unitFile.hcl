@@ -0,0 +1,34 @@
+systemd.unit.file "app" {
+  name  = "app.service"
+  state = "enabled"
+
+  unit {
+    Description = "example application"
+    After       = "network.target"
+  }
+
+  service {
+    ExecStartPre = "/bin/mkdir -p /var/lib/app\n/bin/chmod 0700 /var/lib/app"
+    ExecStart    = "/usr/local/bin/app --data /var/lib/app"
+    Restart      = "on-failure"
+  }
+
+  install {
+    WantedBy = "multi-user.target"
+  }
+}
+
+systemd.unit.file "ssh-nice" {
+  name   = "ssh.service"
+  dropin = "nice"
+
+  service {
+    Nice = "5"
+  }
+}
+
+systemd.unit.state "app" {
+  unit    = "app.service"
+  state   = "running"
+  depends = ["systemd.unit.file.app"]
+}
@@ -2,3 +2,8 @@ package.rpm "mc" {
   name  = "mc"
   state = "present"
 }
+
+package.rpm "tools" {
+  packages = ["jq", "tree", "htop"]
+  state    = "present"
+}
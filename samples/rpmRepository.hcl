@@ -0,0 +1,13 @@
+package.rpm.repository "docker-ce-stable" {
+  description = "Docker CE Stable"
+  baseurl     = "https://download.docker.com/linux/centos/7/x86_64/stable"
+  key_url     = "https://download.docker.com/linux/centos/gpg"
+
+  options {
+    priority = "10"
+  }
+}
+
+package.rpm "docker" {
+  name = "docker-ce"
+}
@@ -0,0 +1,9 @@
+user.user "deploy" {
+  username = "deploy"
+}
+
+sudo.rule "deploy" {
+  users    = ["{{lookup `user.user.deploy.username`}}"]
+  commands = ["/bin/systemctl restart nginx", "/bin/systemctl reload nginx"]
+  nopasswd = true
+}
@@ -0,0 +1,20 @@
+param "name" {
+  default = "my-app"
+}
+
+param "workers" {
+  default = 2
+}
+
+file.content "config" {
+  destination = "target/templateFunctions.yml"
+
+  content = <<EOF
+name: {{param "name" | replace "-" "_" | upper}}
+workers: {{param "workers" | mul 2}}
+checksum: {{param "name" | sha256}}
+encoded: {{param "name" | base64encode}}
+settings:
+{{fromJson "{\"debug\": false, \"port\": 8080}" | toYaml | indent 2}}
+EOF
+}
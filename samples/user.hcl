@@ -2,3 +2,14 @@
 user.user "user" {
   username = "test"
 }
+
+# create a user with a shell, supplementary groups and SSH keys
+user.user "deploy" {
+  username                  = "deploy"
+  shell                     = "/bin/bash"
+  groups                    = ["adm"]
+  groups_mode               = "exact"
+  create_home               = true
+  authorized_keys           = ["ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIE8pzq4wD3fu9qZ8Xe1uJx7v2m6qkQ6hV6v0m2YF7wR9 deploy@example.com"]
+  authorized_keys_exclusive = true
+}
@@ -0,0 +1,4 @@
+package.zypper "mc" {
+  name  = "mc"
+  state = "present"
+}